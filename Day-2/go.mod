@@ -1,8 +0,0 @@
-module main.go
-
-go 1.25.4
-
-require (
-	github.com/golang/snappy v0.0.0-20180518054509-2e65f85255db // indirect
-	github.com/syndtr/goleveldb v1.0.0 // indirect
-)
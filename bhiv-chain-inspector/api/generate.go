@@ -0,0 +1,5 @@
+// Package inspectorv1 holds the Go stubs of the ChainInspector service
+// defined in inspector.proto.
+package inspectorv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative inspector.proto
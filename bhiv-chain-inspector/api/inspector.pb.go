@@ -0,0 +1,1091 @@
+// ChainInspector exposes the inspector's scans to other services.
+//
+// `inspector serve` implements this service over gRPC on --grpc-addr; the
+// Go stubs are generated into this directory by `go generate ./api`.
+//
+// The same methods are also served over HTTP/JSON on --addr, following
+// the Twirp routes: each RPC is a POST to
+//
+//   /twirp/inspector.v1.ChainInspector/<Method>
+//
+// with the request message as JSON (proto field names). Those responses
+// are the CLI's --json reports, a superset of the messages below, and
+// StreamBlocks responds with one block per line (application/x-ndjson).
+// HTTP errors are {"code": "...", "msg": "..."}, with the Twirp codes
+// that match the gRPC status codes.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: inspector.proto
+
+package inspectorv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Block mirrors internal/blocks/block.proto.
+type Block struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Hash          string                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	PrevHash      string                 `protobuf:"bytes,3,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`
+	Data          string                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	MerkleRoot    string                 `protobuf:"bytes,6,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	Difficulty    int64                  `protobuf:"varint,7,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+	Nonce         uint64                 `protobuf:"varint,8,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Signer        string                 `protobuf:"bytes,9,opt,name=signer,proto3" json:"signer,omitempty"`
+	Signature     string                 `protobuf:"bytes,10,opt,name=signature,proto3" json:"signature,omitempty"`
+	ChainId       string                 `protobuf:"bytes,11,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Block) Reset() {
+	*x = Block{}
+	mi := &file_inspector_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Block) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Block) ProtoMessage() {}
+
+func (x *Block) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Block.ProtoReflect.Descriptor instead.
+func (*Block) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Block) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Block) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Block) GetPrevHash() string {
+	if x != nil {
+		return x.PrevHash
+	}
+	return ""
+}
+
+func (x *Block) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *Block) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Block) GetMerkleRoot() string {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return ""
+}
+
+func (x *Block) GetDifficulty() int64 {
+	if x != nil {
+		return x.Difficulty
+	}
+	return 0
+}
+
+func (x *Block) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *Block) GetSigner() string {
+	if x != nil {
+		return x.Signer
+	}
+	return ""
+}
+
+func (x *Block) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *Block) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
+// Databases are identified by the paths the server was started with;
+// db_path may be omitted to use the server's -db.
+type ScanErrorsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DbPath        string                 `protobuf:"bytes,1,opt,name=db_path,json=dbPath,proto3" json:"db_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanErrorsRequest) Reset() {
+	*x = ScanErrorsRequest{}
+	mi := &file_inspector_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanErrorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanErrorsRequest) ProtoMessage() {}
+
+func (x *ScanErrorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanErrorsRequest.ProtoReflect.Descriptor instead.
+func (*ScanErrorsRequest) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ScanErrorsRequest) GetDbPath() string {
+	if x != nil {
+		return x.DbPath
+	}
+	return ""
+}
+
+// ScanErrorsResponse is the scan --json report, without the sections
+// only some scans fill in.
+type ScanErrorsResponse struct {
+	state                  protoimpl.MessageState   `protogen:"open.v1"`
+	ScanTime               string                   `protobuf:"bytes,1,opt,name=scan_time,json=scanTime,proto3" json:"scan_time,omitempty"`
+	DatabasePath           string                   `protobuf:"bytes,2,opt,name=database_path,json=databasePath,proto3" json:"database_path,omitempty"`
+	TotalBlocks            int64                    `protobuf:"varint,3,opt,name=total_blocks,json=totalBlocks,proto3" json:"total_blocks,omitempty"`
+	BlocksScanned          int64                    `protobuf:"varint,4,opt,name=blocks_scanned,json=blocksScanned,proto3" json:"blocks_scanned,omitempty"`
+	HeightsChecked         int64                    `protobuf:"varint,5,opt,name=heights_checked,json=heightsChecked,proto3" json:"heights_checked,omitempty"`
+	TotalErrors            int64                    `protobuf:"varint,6,opt,name=total_errors,json=totalErrors,proto3" json:"total_errors,omitempty"`
+	TotalWarnings          int64                    `protobuf:"varint,7,opt,name=total_warnings,json=totalWarnings,proto3" json:"total_warnings,omitempty"`
+	TotalInfo              int64                    `protobuf:"varint,8,opt,name=total_info,json=totalInfo,proto3" json:"total_info,omitempty"`
+	Suppressed             int64                    `protobuf:"varint,9,opt,name=suppressed,proto3" json:"suppressed,omitempty"`
+	CorruptedJson          []*ErrorEntry            `protobuf:"bytes,10,rep,name=corrupted_json,json=corruptedJson,proto3" json:"corrupted_json,omitempty"`
+	BadHash                []*ErrorEntry            `protobuf:"bytes,11,rep,name=bad_hash,json=badHash,proto3" json:"bad_hash,omitempty"`
+	TimestampFuture        []*ErrorEntry            `protobuf:"bytes,12,rep,name=timestamp_future,json=timestampFuture,proto3" json:"timestamp_future,omitempty"`
+	TimestampPast          []*ErrorEntry            `protobuf:"bytes,13,rep,name=timestamp_past,json=timestampPast,proto3" json:"timestamp_past,omitempty"`
+	TimestampNotIncreasing []*ErrorEntry            `protobuf:"bytes,14,rep,name=timestamp_not_increasing,json=timestampNotIncreasing,proto3" json:"timestamp_not_increasing,omitempty"`
+	DuplicateHashes        []*ErrorEntry            `protobuf:"bytes,15,rep,name=duplicate_hashes,json=duplicateHashes,proto3" json:"duplicate_hashes,omitempty"`
+	EmptyBlocks            []*ErrorEntry            `protobuf:"bytes,16,rep,name=empty_blocks,json=emptyBlocks,proto3" json:"empty_blocks,omitempty"`
+	PrevhashErrors         []*ErrorEntry            `protobuf:"bytes,17,rep,name=prevhash_errors,json=prevhashErrors,proto3" json:"prevhash_errors,omitempty"`
+	HeightErrors           []*ErrorEntry            `protobuf:"bytes,18,rep,name=height_errors,json=heightErrors,proto3" json:"height_errors,omitempty"`
+	MissingBlocks          []int64                  `protobuf:"varint,19,rep,packed,name=missing_blocks,json=missingBlocks,proto3" json:"missing_blocks,omitempty"`
+	OutOfOrderBlocks       []*ErrorEntry            `protobuf:"bytes,20,rep,name=out_of_order_blocks,json=outOfOrderBlocks,proto3" json:"out_of_order_blocks,omitempty"`
+	HealthScore            int64                    `protobuf:"varint,21,opt,name=health_score,json=healthScore,proto3" json:"health_score,omitempty"`
+	Status                 string                   `protobuf:"bytes,22,opt,name=status,proto3" json:"status,omitempty"`
+	Severities             map[string]string        `protobuf:"bytes,23,rep,name=severities,proto3" json:"severities,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Custom                 map[string]*ErrorEntries `protobuf:"bytes,24,rep,name=custom,proto3" json:"custom,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Checks                 []string                 `protobuf:"bytes,25,rep,name=checks,proto3" json:"checks,omitempty"`
+	HashAlgorithm          string                   `protobuf:"bytes,26,opt,name=hash_algorithm,json=hashAlgorithm,proto3" json:"hash_algorithm,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ScanErrorsResponse) Reset() {
+	*x = ScanErrorsResponse{}
+	mi := &file_inspector_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanErrorsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanErrorsResponse) ProtoMessage() {}
+
+func (x *ScanErrorsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanErrorsResponse.ProtoReflect.Descriptor instead.
+func (*ScanErrorsResponse) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScanErrorsResponse) GetScanTime() string {
+	if x != nil {
+		return x.ScanTime
+	}
+	return ""
+}
+
+func (x *ScanErrorsResponse) GetDatabasePath() string {
+	if x != nil {
+		return x.DatabasePath
+	}
+	return ""
+}
+
+func (x *ScanErrorsResponse) GetTotalBlocks() int64 {
+	if x != nil {
+		return x.TotalBlocks
+	}
+	return 0
+}
+
+func (x *ScanErrorsResponse) GetBlocksScanned() int64 {
+	if x != nil {
+		return x.BlocksScanned
+	}
+	return 0
+}
+
+func (x *ScanErrorsResponse) GetHeightsChecked() int64 {
+	if x != nil {
+		return x.HeightsChecked
+	}
+	return 0
+}
+
+func (x *ScanErrorsResponse) GetTotalErrors() int64 {
+	if x != nil {
+		return x.TotalErrors
+	}
+	return 0
+}
+
+func (x *ScanErrorsResponse) GetTotalWarnings() int64 {
+	if x != nil {
+		return x.TotalWarnings
+	}
+	return 0
+}
+
+func (x *ScanErrorsResponse) GetTotalInfo() int64 {
+	if x != nil {
+		return x.TotalInfo
+	}
+	return 0
+}
+
+func (x *ScanErrorsResponse) GetSuppressed() int64 {
+	if x != nil {
+		return x.Suppressed
+	}
+	return 0
+}
+
+func (x *ScanErrorsResponse) GetCorruptedJson() []*ErrorEntry {
+	if x != nil {
+		return x.CorruptedJson
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetBadHash() []*ErrorEntry {
+	if x != nil {
+		return x.BadHash
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetTimestampFuture() []*ErrorEntry {
+	if x != nil {
+		return x.TimestampFuture
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetTimestampPast() []*ErrorEntry {
+	if x != nil {
+		return x.TimestampPast
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetTimestampNotIncreasing() []*ErrorEntry {
+	if x != nil {
+		return x.TimestampNotIncreasing
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetDuplicateHashes() []*ErrorEntry {
+	if x != nil {
+		return x.DuplicateHashes
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetEmptyBlocks() []*ErrorEntry {
+	if x != nil {
+		return x.EmptyBlocks
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetPrevhashErrors() []*ErrorEntry {
+	if x != nil {
+		return x.PrevhashErrors
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetHeightErrors() []*ErrorEntry {
+	if x != nil {
+		return x.HeightErrors
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetMissingBlocks() []int64 {
+	if x != nil {
+		return x.MissingBlocks
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetOutOfOrderBlocks() []*ErrorEntry {
+	if x != nil {
+		return x.OutOfOrderBlocks
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetHealthScore() int64 {
+	if x != nil {
+		return x.HealthScore
+	}
+	return 0
+}
+
+func (x *ScanErrorsResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ScanErrorsResponse) GetSeverities() map[string]string {
+	if x != nil {
+		return x.Severities
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetCustom() map[string]*ErrorEntries {
+	if x != nil {
+		return x.Custom
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetChecks() []string {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+func (x *ScanErrorsResponse) GetHashAlgorithm() string {
+	if x != nil {
+		return x.HashAlgorithm
+	}
+	return ""
+}
+
+// ErrorEntry is one finding of a scan.
+type ErrorEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Expected      string                 `protobuf:"bytes,3,opt,name=expected,proto3" json:"expected,omitempty"`
+	Actual        string                 `protobuf:"bytes,4,opt,name=actual,proto3" json:"actual,omitempty"`
+	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorEntry) Reset() {
+	*x = ErrorEntry{}
+	mi := &file_inspector_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorEntry) ProtoMessage() {}
+
+func (x *ErrorEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorEntry.ProtoReflect.Descriptor instead.
+func (*ErrorEntry) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ErrorEntry) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ErrorEntry) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ErrorEntry) GetExpected() string {
+	if x != nil {
+		return x.Expected
+	}
+	return ""
+}
+
+func (x *ErrorEntry) GetActual() string {
+	if x != nil {
+		return x.Actual
+	}
+	return ""
+}
+
+func (x *ErrorEntry) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ErrorEntries struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*ErrorEntry          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorEntries) Reset() {
+	*x = ErrorEntries{}
+	mi := &file_inspector_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorEntries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorEntries) ProtoMessage() {}
+
+func (x *ErrorEntries) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorEntries.ProtoReflect.Descriptor instead.
+func (*ErrorEntries) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ErrorEntries) GetEntries() []*ErrorEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type CompareNodesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Db1Path       string                 `protobuf:"bytes,1,opt,name=db1_path,json=db1Path,proto3" json:"db1_path,omitempty"`
+	Db2Path       string                 `protobuf:"bytes,2,opt,name=db2_path,json=db2Path,proto3" json:"db2_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompareNodesRequest) Reset() {
+	*x = CompareNodesRequest{}
+	mi := &file_inspector_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareNodesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareNodesRequest) ProtoMessage() {}
+
+func (x *CompareNodesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareNodesRequest.ProtoReflect.Descriptor instead.
+func (*CompareNodesRequest) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CompareNodesRequest) GetDb1Path() string {
+	if x != nil {
+		return x.Db1Path
+	}
+	return ""
+}
+
+func (x *CompareNodesRequest) GetDb2Path() string {
+	if x != nil {
+		return x.Db2Path
+	}
+	return ""
+}
+
+// CompareNodesResponse is the compare --json report, without the sections
+// only some comparisons fill in.
+type CompareNodesResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ScanTime            string                 `protobuf:"bytes,1,opt,name=scan_time,json=scanTime,proto3" json:"scan_time,omitempty"`
+	Node1Path           string                 `protobuf:"bytes,2,opt,name=node1_path,json=node1Path,proto3" json:"node1_path,omitempty"`
+	Node2Path           string                 `protobuf:"bytes,3,opt,name=node2_path,json=node2Path,proto3" json:"node2_path,omitempty"`
+	Node1Height         int64                  `protobuf:"varint,4,opt,name=node1_height,json=node1Height,proto3" json:"node1_height,omitempty"`
+	Node2Height         int64                  `protobuf:"varint,5,opt,name=node2_height,json=node2Height,proto3" json:"node2_height,omitempty"`
+	MatchingBlocks      int64                  `protobuf:"varint,6,opt,name=matching_blocks,json=matchingBlocks,proto3" json:"matching_blocks,omitempty"`
+	MismatchedBlocks    []int64                `protobuf:"varint,7,rep,packed,name=mismatched_blocks,json=mismatchedBlocks,proto3" json:"mismatched_blocks,omitempty"`
+	Node1OnlyBlocks     []int64                `protobuf:"varint,8,rep,packed,name=node1_only_blocks,json=node1OnlyBlocks,proto3" json:"node1_only_blocks,omitempty"`
+	Node2OnlyBlocks     []int64                `protobuf:"varint,9,rep,packed,name=node2_only_blocks,json=node2OnlyBlocks,proto3" json:"node2_only_blocks,omitempty"`
+	DivergencePoint     int64                  `protobuf:"varint,10,opt,name=divergence_point,json=divergencePoint,proto3" json:"divergence_point,omitempty"`
+	HashMismatches      []string               `protobuf:"bytes,11,rep,name=hash_mismatches,json=hashMismatches,proto3" json:"hash_mismatches,omitempty"`
+	DataMismatches      []string               `protobuf:"bytes,12,rep,name=data_mismatches,json=dataMismatches,proto3" json:"data_mismatches,omitempty"`
+	TimestampMismatches []string               `protobuf:"bytes,13,rep,name=timestamp_mismatches,json=timestampMismatches,proto3" json:"timestamp_mismatches,omitempty"`
+	SyncPercentage      float64                `protobuf:"fixed64,14,opt,name=sync_percentage,json=syncPercentage,proto3" json:"sync_percentage,omitempty"`
+	Recommendations     []string               `protobuf:"bytes,15,rep,name=recommendations,proto3" json:"recommendations,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CompareNodesResponse) Reset() {
+	*x = CompareNodesResponse{}
+	mi := &file_inspector_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareNodesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareNodesResponse) ProtoMessage() {}
+
+func (x *CompareNodesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareNodesResponse.ProtoReflect.Descriptor instead.
+func (*CompareNodesResponse) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CompareNodesResponse) GetScanTime() string {
+	if x != nil {
+		return x.ScanTime
+	}
+	return ""
+}
+
+func (x *CompareNodesResponse) GetNode1Path() string {
+	if x != nil {
+		return x.Node1Path
+	}
+	return ""
+}
+
+func (x *CompareNodesResponse) GetNode2Path() string {
+	if x != nil {
+		return x.Node2Path
+	}
+	return ""
+}
+
+func (x *CompareNodesResponse) GetNode1Height() int64 {
+	if x != nil {
+		return x.Node1Height
+	}
+	return 0
+}
+
+func (x *CompareNodesResponse) GetNode2Height() int64 {
+	if x != nil {
+		return x.Node2Height
+	}
+	return 0
+}
+
+func (x *CompareNodesResponse) GetMatchingBlocks() int64 {
+	if x != nil {
+		return x.MatchingBlocks
+	}
+	return 0
+}
+
+func (x *CompareNodesResponse) GetMismatchedBlocks() []int64 {
+	if x != nil {
+		return x.MismatchedBlocks
+	}
+	return nil
+}
+
+func (x *CompareNodesResponse) GetNode1OnlyBlocks() []int64 {
+	if x != nil {
+		return x.Node1OnlyBlocks
+	}
+	return nil
+}
+
+func (x *CompareNodesResponse) GetNode2OnlyBlocks() []int64 {
+	if x != nil {
+		return x.Node2OnlyBlocks
+	}
+	return nil
+}
+
+func (x *CompareNodesResponse) GetDivergencePoint() int64 {
+	if x != nil {
+		return x.DivergencePoint
+	}
+	return 0
+}
+
+func (x *CompareNodesResponse) GetHashMismatches() []string {
+	if x != nil {
+		return x.HashMismatches
+	}
+	return nil
+}
+
+func (x *CompareNodesResponse) GetDataMismatches() []string {
+	if x != nil {
+		return x.DataMismatches
+	}
+	return nil
+}
+
+func (x *CompareNodesResponse) GetTimestampMismatches() []string {
+	if x != nil {
+		return x.TimestampMismatches
+	}
+	return nil
+}
+
+func (x *CompareNodesResponse) GetSyncPercentage() float64 {
+	if x != nil {
+		return x.SyncPercentage
+	}
+	return 0
+}
+
+func (x *CompareNodesResponse) GetRecommendations() []string {
+	if x != nil {
+		return x.Recommendations
+	}
+	return nil
+}
+
+type GetBlockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DbPath        string                 `protobuf:"bytes,1,opt,name=db_path,json=dbPath,proto3" json:"db_path,omitempty"`
+	Height        int64                  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlockRequest) Reset() {
+	*x = GetBlockRequest{}
+	mi := &file_inspector_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockRequest) ProtoMessage() {}
+
+func (x *GetBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockRequest.ProtoReflect.Descriptor instead.
+func (*GetBlockRequest) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetBlockRequest) GetDbPath() string {
+	if x != nil {
+		return x.DbPath
+	}
+	return ""
+}
+
+func (x *GetBlockRequest) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+// Streams blocks from_height..to_height inclusive; without to_height, up
+// to the chain tip.
+type StreamBlocksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DbPath        string                 `protobuf:"bytes,1,opt,name=db_path,json=dbPath,proto3" json:"db_path,omitempty"`
+	FromHeight    int64                  `protobuf:"varint,2,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	ToHeight      *int64                 `protobuf:"varint,3,opt,name=to_height,json=toHeight,proto3,oneof" json:"to_height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamBlocksRequest) Reset() {
+	*x = StreamBlocksRequest{}
+	mi := &file_inspector_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamBlocksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamBlocksRequest) ProtoMessage() {}
+
+func (x *StreamBlocksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamBlocksRequest.ProtoReflect.Descriptor instead.
+func (*StreamBlocksRequest) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamBlocksRequest) GetDbPath() string {
+	if x != nil {
+		return x.DbPath
+	}
+	return ""
+}
+
+func (x *StreamBlocksRequest) GetFromHeight() int64 {
+	if x != nil {
+		return x.FromHeight
+	}
+	return 0
+}
+
+func (x *StreamBlocksRequest) GetToHeight() int64 {
+	if x != nil && x.ToHeight != nil {
+		return *x.ToHeight
+	}
+	return 0
+}
+
+var File_inspector_proto protoreflect.FileDescriptor
+
+const file_inspector_proto_rawDesc = "" +
+	"\n" +
+	"\x0finspector.proto\x12\finspector.v1\"\xaa\x02\n" +
+	"\x05Block\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\tR\x04hash\x12\x1b\n" +
+	"\tprev_hash\x18\x03 \x01(\tR\bprevHash\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\tR\x04data\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\x12\x1f\n" +
+	"\vmerkle_root\x18\x06 \x01(\tR\n" +
+	"merkleRoot\x12\x1e\n" +
+	"\n" +
+	"difficulty\x18\a \x01(\x03R\n" +
+	"difficulty\x12\x14\n" +
+	"\x05nonce\x18\b \x01(\x04R\x05nonce\x12\x16\n" +
+	"\x06signer\x18\t \x01(\tR\x06signer\x12\x1c\n" +
+	"\tsignature\x18\n" +
+	" \x01(\tR\tsignature\x12\x19\n" +
+	"\bchain_id\x18\v \x01(\tR\achainId\",\n" +
+	"\x11ScanErrorsRequest\x12\x17\n" +
+	"\adb_path\x18\x01 \x01(\tR\x06dbPath\"\xbe\v\n" +
+	"\x12ScanErrorsResponse\x12\x1b\n" +
+	"\tscan_time\x18\x01 \x01(\tR\bscanTime\x12#\n" +
+	"\rdatabase_path\x18\x02 \x01(\tR\fdatabasePath\x12!\n" +
+	"\ftotal_blocks\x18\x03 \x01(\x03R\vtotalBlocks\x12%\n" +
+	"\x0eblocks_scanned\x18\x04 \x01(\x03R\rblocksScanned\x12'\n" +
+	"\x0fheights_checked\x18\x05 \x01(\x03R\x0eheightsChecked\x12!\n" +
+	"\ftotal_errors\x18\x06 \x01(\x03R\vtotalErrors\x12%\n" +
+	"\x0etotal_warnings\x18\a \x01(\x03R\rtotalWarnings\x12\x1d\n" +
+	"\n" +
+	"total_info\x18\b \x01(\x03R\ttotalInfo\x12\x1e\n" +
+	"\n" +
+	"suppressed\x18\t \x01(\x03R\n" +
+	"suppressed\x12?\n" +
+	"\x0ecorrupted_json\x18\n" +
+	" \x03(\v2\x18.inspector.v1.ErrorEntryR\rcorruptedJson\x123\n" +
+	"\bbad_hash\x18\v \x03(\v2\x18.inspector.v1.ErrorEntryR\abadHash\x12C\n" +
+	"\x10timestamp_future\x18\f \x03(\v2\x18.inspector.v1.ErrorEntryR\x0ftimestampFuture\x12?\n" +
+	"\x0etimestamp_past\x18\r \x03(\v2\x18.inspector.v1.ErrorEntryR\rtimestampPast\x12R\n" +
+	"\x18timestamp_not_increasing\x18\x0e \x03(\v2\x18.inspector.v1.ErrorEntryR\x16timestampNotIncreasing\x12C\n" +
+	"\x10duplicate_hashes\x18\x0f \x03(\v2\x18.inspector.v1.ErrorEntryR\x0fduplicateHashes\x12;\n" +
+	"\fempty_blocks\x18\x10 \x03(\v2\x18.inspector.v1.ErrorEntryR\vemptyBlocks\x12A\n" +
+	"\x0fprevhash_errors\x18\x11 \x03(\v2\x18.inspector.v1.ErrorEntryR\x0eprevhashErrors\x12=\n" +
+	"\rheight_errors\x18\x12 \x03(\v2\x18.inspector.v1.ErrorEntryR\fheightErrors\x12%\n" +
+	"\x0emissing_blocks\x18\x13 \x03(\x03R\rmissingBlocks\x12G\n" +
+	"\x13out_of_order_blocks\x18\x14 \x03(\v2\x18.inspector.v1.ErrorEntryR\x10outOfOrderBlocks\x12!\n" +
+	"\fhealth_score\x18\x15 \x01(\x03R\vhealthScore\x12\x16\n" +
+	"\x06status\x18\x16 \x01(\tR\x06status\x12P\n" +
+	"\n" +
+	"severities\x18\x17 \x03(\v20.inspector.v1.ScanErrorsResponse.SeveritiesEntryR\n" +
+	"severities\x12D\n" +
+	"\x06custom\x18\x18 \x03(\v2,.inspector.v1.ScanErrorsResponse.CustomEntryR\x06custom\x12\x16\n" +
+	"\x06checks\x18\x19 \x03(\tR\x06checks\x12%\n" +
+	"\x0ehash_algorithm\x18\x1a \x01(\tR\rhashAlgorithm\x1a=\n" +
+	"\x0fSeveritiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aU\n" +
+	"\vCustomEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x120\n" +
+	"\x05value\x18\x02 \x01(\v2\x1a.inspector.v1.ErrorEntriesR\x05value:\x028\x01\"\x86\x01\n" +
+	"\n" +
+	"ErrorEntry\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x1a\n" +
+	"\bexpected\x18\x03 \x01(\tR\bexpected\x12\x16\n" +
+	"\x06actual\x18\x04 \x01(\tR\x06actual\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\"B\n" +
+	"\fErrorEntries\x122\n" +
+	"\aentries\x18\x01 \x03(\v2\x18.inspector.v1.ErrorEntryR\aentries\"K\n" +
+	"\x13CompareNodesRequest\x12\x19\n" +
+	"\bdb1_path\x18\x01 \x01(\tR\adb1Path\x12\x19\n" +
+	"\bdb2_path\x18\x02 \x01(\tR\adb2Path\"\xe8\x04\n" +
+	"\x14CompareNodesResponse\x12\x1b\n" +
+	"\tscan_time\x18\x01 \x01(\tR\bscanTime\x12\x1d\n" +
+	"\n" +
+	"node1_path\x18\x02 \x01(\tR\tnode1Path\x12\x1d\n" +
+	"\n" +
+	"node2_path\x18\x03 \x01(\tR\tnode2Path\x12!\n" +
+	"\fnode1_height\x18\x04 \x01(\x03R\vnode1Height\x12!\n" +
+	"\fnode2_height\x18\x05 \x01(\x03R\vnode2Height\x12'\n" +
+	"\x0fmatching_blocks\x18\x06 \x01(\x03R\x0ematchingBlocks\x12+\n" +
+	"\x11mismatched_blocks\x18\a \x03(\x03R\x10mismatchedBlocks\x12*\n" +
+	"\x11node1_only_blocks\x18\b \x03(\x03R\x0fnode1OnlyBlocks\x12*\n" +
+	"\x11node2_only_blocks\x18\t \x03(\x03R\x0fnode2OnlyBlocks\x12)\n" +
+	"\x10divergence_point\x18\n" +
+	" \x01(\x03R\x0fdivergencePoint\x12'\n" +
+	"\x0fhash_mismatches\x18\v \x03(\tR\x0ehashMismatches\x12'\n" +
+	"\x0fdata_mismatches\x18\f \x03(\tR\x0edataMismatches\x121\n" +
+	"\x14timestamp_mismatches\x18\r \x03(\tR\x13timestampMismatches\x12'\n" +
+	"\x0fsync_percentage\x18\x0e \x01(\x01R\x0esyncPercentage\x12(\n" +
+	"\x0frecommendations\x18\x0f \x03(\tR\x0frecommendations\"B\n" +
+	"\x0fGetBlockRequest\x12\x17\n" +
+	"\adb_path\x18\x01 \x01(\tR\x06dbPath\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\x03R\x06height\"\x7f\n" +
+	"\x13StreamBlocksRequest\x12\x17\n" +
+	"\adb_path\x18\x01 \x01(\tR\x06dbPath\x12\x1f\n" +
+	"\vfrom_height\x18\x02 \x01(\x03R\n" +
+	"fromHeight\x12 \n" +
+	"\tto_height\x18\x03 \x01(\x03H\x00R\btoHeight\x88\x01\x01B\f\n" +
+	"\n" +
+	"_to_height2\xc2\x02\n" +
+	"\x0eChainInspector\x12O\n" +
+	"\n" +
+	"ScanErrors\x12\x1f.inspector.v1.ScanErrorsRequest\x1a .inspector.v1.ScanErrorsResponse\x12U\n" +
+	"\fCompareNodes\x12!.inspector.v1.CompareNodesRequest\x1a\".inspector.v1.CompareNodesResponse\x12>\n" +
+	"\bGetBlock\x12\x1d.inspector.v1.GetBlockRequest\x1a\x13.inspector.v1.Block\x12H\n" +
+	"\fStreamBlocks\x12!.inspector.v1.StreamBlocksRequest\x1a\x13.inspector.v1.Block0\x01B&Z$bhiv-chain-inspector/api;inspectorv1b\x06proto3"
+
+var (
+	file_inspector_proto_rawDescOnce sync.Once
+	file_inspector_proto_rawDescData []byte
+)
+
+func file_inspector_proto_rawDescGZIP() []byte {
+	file_inspector_proto_rawDescOnce.Do(func() {
+		file_inspector_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_inspector_proto_rawDesc), len(file_inspector_proto_rawDesc)))
+	})
+	return file_inspector_proto_rawDescData
+}
+
+var file_inspector_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_inspector_proto_goTypes = []any{
+	(*Block)(nil),                // 0: inspector.v1.Block
+	(*ScanErrorsRequest)(nil),    // 1: inspector.v1.ScanErrorsRequest
+	(*ScanErrorsResponse)(nil),   // 2: inspector.v1.ScanErrorsResponse
+	(*ErrorEntry)(nil),           // 3: inspector.v1.ErrorEntry
+	(*ErrorEntries)(nil),         // 4: inspector.v1.ErrorEntries
+	(*CompareNodesRequest)(nil),  // 5: inspector.v1.CompareNodesRequest
+	(*CompareNodesResponse)(nil), // 6: inspector.v1.CompareNodesResponse
+	(*GetBlockRequest)(nil),      // 7: inspector.v1.GetBlockRequest
+	(*StreamBlocksRequest)(nil),  // 8: inspector.v1.StreamBlocksRequest
+	nil,                          // 9: inspector.v1.ScanErrorsResponse.SeveritiesEntry
+	nil,                          // 10: inspector.v1.ScanErrorsResponse.CustomEntry
+}
+var file_inspector_proto_depIdxs = []int32{
+	3,  // 0: inspector.v1.ScanErrorsResponse.corrupted_json:type_name -> inspector.v1.ErrorEntry
+	3,  // 1: inspector.v1.ScanErrorsResponse.bad_hash:type_name -> inspector.v1.ErrorEntry
+	3,  // 2: inspector.v1.ScanErrorsResponse.timestamp_future:type_name -> inspector.v1.ErrorEntry
+	3,  // 3: inspector.v1.ScanErrorsResponse.timestamp_past:type_name -> inspector.v1.ErrorEntry
+	3,  // 4: inspector.v1.ScanErrorsResponse.timestamp_not_increasing:type_name -> inspector.v1.ErrorEntry
+	3,  // 5: inspector.v1.ScanErrorsResponse.duplicate_hashes:type_name -> inspector.v1.ErrorEntry
+	3,  // 6: inspector.v1.ScanErrorsResponse.empty_blocks:type_name -> inspector.v1.ErrorEntry
+	3,  // 7: inspector.v1.ScanErrorsResponse.prevhash_errors:type_name -> inspector.v1.ErrorEntry
+	3,  // 8: inspector.v1.ScanErrorsResponse.height_errors:type_name -> inspector.v1.ErrorEntry
+	3,  // 9: inspector.v1.ScanErrorsResponse.out_of_order_blocks:type_name -> inspector.v1.ErrorEntry
+	9,  // 10: inspector.v1.ScanErrorsResponse.severities:type_name -> inspector.v1.ScanErrorsResponse.SeveritiesEntry
+	10, // 11: inspector.v1.ScanErrorsResponse.custom:type_name -> inspector.v1.ScanErrorsResponse.CustomEntry
+	3,  // 12: inspector.v1.ErrorEntries.entries:type_name -> inspector.v1.ErrorEntry
+	4,  // 13: inspector.v1.ScanErrorsResponse.CustomEntry.value:type_name -> inspector.v1.ErrorEntries
+	1,  // 14: inspector.v1.ChainInspector.ScanErrors:input_type -> inspector.v1.ScanErrorsRequest
+	5,  // 15: inspector.v1.ChainInspector.CompareNodes:input_type -> inspector.v1.CompareNodesRequest
+	7,  // 16: inspector.v1.ChainInspector.GetBlock:input_type -> inspector.v1.GetBlockRequest
+	8,  // 17: inspector.v1.ChainInspector.StreamBlocks:input_type -> inspector.v1.StreamBlocksRequest
+	2,  // 18: inspector.v1.ChainInspector.ScanErrors:output_type -> inspector.v1.ScanErrorsResponse
+	6,  // 19: inspector.v1.ChainInspector.CompareNodes:output_type -> inspector.v1.CompareNodesResponse
+	0,  // 20: inspector.v1.ChainInspector.GetBlock:output_type -> inspector.v1.Block
+	0,  // 21: inspector.v1.ChainInspector.StreamBlocks:output_type -> inspector.v1.Block
+	18, // [18:22] is the sub-list for method output_type
+	14, // [14:18] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_inspector_proto_init() }
+func file_inspector_proto_init() {
+	if File_inspector_proto != nil {
+		return
+	}
+	file_inspector_proto_msgTypes[8].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_inspector_proto_rawDesc), len(file_inspector_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_inspector_proto_goTypes,
+		DependencyIndexes: file_inspector_proto_depIdxs,
+		MessageInfos:      file_inspector_proto_msgTypes,
+	}.Build()
+	File_inspector_proto = out.File
+	file_inspector_proto_goTypes = nil
+	file_inspector_proto_depIdxs = nil
+}
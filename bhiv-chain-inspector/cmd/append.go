@@ -0,0 +1,134 @@
+package main
+
+import (
+    "crypto/ed25519"
+    "fmt"
+    "log/slog"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// appendOptions are the flags of the append and mine commands.
+type appendOptions struct {
+    data       string
+    // difficulty is the proof of work of new blocks; -1 keeps the tip's.
+    difficulty int
+    key        ed25519.PrivateKey
+    // hashAlg is detected from the chain when empty.
+    hashAlg    blocks.HashAlgorithm
+}
+
+// appender builds blocks after the tip of a chain, linked to it and
+// hashed like it, and stores each with a single write.
+type appender struct {
+    storage *db.Storage
+    dbPath  string
+    opts    appendOptions
+    chainID string
+    // prev is the tip, nil for an empty chain.
+    prev    *blocks.Block
+}
+
+// newAppender checks that the tip of storage can be built on. The chain
+// ID of new blocks is the one the database declares, or else the tip's.
+func newAppender(storage *db.Storage, dbPath string, opts appendOptions) *appender {
+    if storage.Layout() != db.LayoutInspector {
+        storage.Close()
+        usageError(fmt.Errorf("appending writes the inspector layout, not %s", storage.Layout()))
+    }
+    a := &appender{storage: storage, dbPath: dbPath, opts: opts}
+    var err error
+    if a.chainID, err = storage.ChainID(); err != nil {
+        a.fail("cannot read chain ID", "err", err)
+    }
+    tip := storage.GetMaxHeight()
+    if tip >= 0 {
+        if a.prev, err = storage.LoadBlock(tip); err != nil {
+            a.fail("cannot load tip", "height", tip, "err", err)
+        }
+        if a.opts.hashAlg == "" {
+            if a.opts.hashAlg = errors.DetectHashAlgorithm(storage, errors.HashSampleSize).Algorithm; a.opts.hashAlg == "" {
+                a.fail("cannot detect the chain's hash algorithm, pass --hash-algorithm")
+            }
+        }
+        if err := a.prev.VerifyHashWith(a.opts.hashAlg); err != nil {
+            a.fail("tip fails its hash check, not appending to it", "height", tip, "err", err)
+        }
+        if a.chainID == "" {
+            a.chainID = a.prev.ChainID
+        }
+    }
+    if a.opts.hashAlg == "" {
+        a.opts.hashAlg = blocks.SHA256
+    }
+    return a
+}
+
+func (a *appender) fail(msg string, args ...any) {
+    a.storage.Close()
+    fatal(msg, append([]any{"db", a.dbPath}, args...)...)
+}
+
+// append stores the block after the tip with data and makes it the tip.
+func (a *appender) append(data string) *blocks.Block {
+    block := &blocks.Block{
+        Height:     0,
+        PrevHash:   "0",
+        Data:       data,
+        Timestamp:  time.Now().Unix(),
+        Difficulty: max(a.opts.difficulty, 0),
+        ChainID:    a.chainID,
+    }
+    if a.prev != nil {
+        block.Height = a.prev.Height + 1
+        block.PrevHash = a.prev.Hash
+        block.Timestamp = max(block.Timestamp, a.prev.Timestamp+1)
+        if a.opts.difficulty < 0 {
+            block.Difficulty = a.prev.Difficulty
+        }
+    }
+    block.MerkleRoot = blocks.MerkleRoot(block.Transactions())
+    hashAndSign(block, a.opts.hashAlg, a.opts.key)
+    if err := a.storage.SaveBlock(block); err != nil {
+        a.fail("cannot save block", "height", block.Height, "err", err)
+    }
+    slog.Info("block appended", "db", a.dbPath, "height", block.Height, "hash", block.Hash)
+    a.prev = block
+    return block
+}
+
+// runAppend appends one block with opts.data to the chain at dbPath.
+func runAppend(dbPath string, opts appendOptions, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    errors.OutputBlock(newAppender(storage, dbPath, opts).append(opts.data), out)
+}
+
+// runMine appends count blocks mined to opts.difficulty and reports the
+// work each took.
+func runMine(dbPath string, count int, opts appendOptions, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    a := newAppender(storage, dbPath, opts)
+
+    report := &errors.MineReport{
+        DatabasePath:  dbPath,
+        Difficulty:    opts.difficulty,
+        HashAlgorithm: a.opts.hashAlg.String(),
+    }
+    start := time.Now()
+    for i := 0; i < count; i++ {
+        blockStart := time.Now()
+        height := 0
+        if a.prev != nil {
+            height = a.prev.Height + 1
+        }
+        block := a.append(fmt.Sprintf("Mined block %d", height))
+        report.Add(block, time.Since(blockStart))
+    }
+    report.Finish(time.Since(start))
+    errors.OutputMineReport(report, out)
+}
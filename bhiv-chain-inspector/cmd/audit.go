@@ -0,0 +1,70 @@
+package main
+
+import (
+    "os"
+    "os/user"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tracing"
+)
+
+// newAuditor describes this run of command name for the audit log. Agent
+// tokens among the arguments are not recorded.
+func newAuditor(name string) *db.Auditor {
+    a := &db.Auditor{Operation: name, User: os.Getenv("USER"), Host: "unknown"}
+    if u, err := user.Current(); err == nil {
+        a.User = u.Username
+    }
+    if host, err := os.Hostname(); err == nil {
+        a.Host = host
+    }
+    redact := false
+    for _, arg := range os.Args[2:] {
+        switch {
+        case redact:
+            arg, redact = "[redacted]", false
+        case strings.HasPrefix(strings.TrimLeft(arg, "-"), "agent-token"):
+            if _, value, ok := strings.Cut(arg, "="); ok && value != "" {
+                arg = strings.TrimSuffix(arg, value) + "[redacted]"
+            } else {
+                redact = true
+            }
+        }
+        a.Args = append(a.Args, arg)
+    }
+    return a
+}
+
+// runAuditLog prints the audit entries of dbPath matching filter, and
+// exits 1 if the log's links show entries were removed or edited.
+func runAuditLog(dbPath string, filter errors.AuditFilter, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    entries, err := storage.AuditLog()
+    if err != nil {
+        storage.Close()
+        fatal("cannot read audit log", "db", dbPath, "err", err)
+    }
+    log := errors.FilterAuditLog(entries, dbPath, filter)
+    errors.OutputAuditLog(log, out)
+    if log.Broken > 0 {
+        storage.Close()
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
+
+// parseSince reads --since: a duration back from now, or a date.
+func parseSince(s string) (time.Time, error) {
+    if s == "" {
+        return time.Time{}, nil
+    }
+    if d, err := time.ParseDuration(s); err == nil {
+        return time.Now().Add(-d), nil
+    }
+    return time.ParseInLocation("2006-01-02", s, time.Local)
+}
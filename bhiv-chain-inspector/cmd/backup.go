@@ -0,0 +1,162 @@
+package main
+
+import (
+    "fmt"
+    "log/slog"
+    "os"
+    "time"
+
+    "bhiv-chain-inspector/internal/backup"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// runBackup archives the chain at dbPath to archive, then reads the
+// archive back to check it against its manifest.
+func runBackup(dbPath, archive string, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    if storage.Layout() != db.LayoutInspector {
+        storage.Close()
+        usageError(fmt.Errorf("backup archives the inspector layout, not %s", storage.Layout()))
+    }
+    fail := func(msg string, args ...any) {
+        storage.Close()
+        fatal(msg, append([]any{"db", dbPath, "archive", archive}, args...)...)
+    }
+
+    start := time.Now()
+    manifest, err := describeChain(storage, dbPath)
+    if err != nil {
+        fail("cannot read chain", "err", err)
+    }
+    if err := backup.Write(archive, storage, manifest); err != nil {
+        fail("cannot write archive", "err", err)
+    }
+    if _, err := backup.Verify(archive); err != nil {
+        fail("archive does not verify", "err", err)
+    }
+    info, err := os.Stat(archive)
+    if err != nil {
+        fail("cannot stat archive", "err", err)
+    }
+    errors.OutputBackup(&errors.BackupReport{
+        Operation:    "backup",
+        DatabasePath: dbPath,
+        Archive:      archive,
+        Size:         info.Size(),
+        Manifest:     manifest,
+        Verified:     true,
+        DurationMS:   time.Since(start).Milliseconds(),
+    }, out)
+}
+
+// describeChain fills in the manifest fields that describe the chain in
+// storage.
+func describeChain(storage *db.Storage, dbPath string) (*backup.Manifest, error) {
+    chainID, err := storage.ChainID()
+    if err != nil {
+        return nil, err
+    }
+    m := &backup.Manifest{
+        Source:        dbPath,
+        ChainID:       chainID,
+        Tip:           storage.GetMaxHeight(),
+        HashAlgorithm: string(errors.DetectHashAlgorithm(storage, errors.HashSampleSize).Algorithm),
+        Fingerprint:   errors.ComputeFingerprint(storage, 0).Digest,
+        Encoding:      string(storage.Encoding()),
+        Encrypted:     storage.Encrypted(),
+        Sealed:        storage.Sealed(),
+    }
+    if m.Tip >= 0 {
+        tip, err := storage.LoadBlock(m.Tip)
+        if err != nil {
+            return nil, err
+        }
+        m.TipHash = tip.Hash
+    }
+    return m, nil
+}
+
+// backupSchedule takes the periodic backups of watch and rotates them.
+type backupSchedule struct {
+    dir                   string
+    interval              time.Duration
+    keepDaily, keepWeekly int
+    // last is when the newest verified backup was taken.
+    last                  time.Time
+}
+
+// newBackupSchedule resumes the rotation in dir, timing the next backup
+// from the newest archive already there.
+func newBackupSchedule(dir string, interval time.Duration, keepDaily, keepWeekly int) (*backupSchedule, error) {
+    if err := os.MkdirAll(dir, 0o755); err != nil {
+        return nil, err
+    }
+    archives, err := backup.List(dir)
+    if err != nil {
+        return nil, err
+    }
+    s := &backupSchedule{dir: dir, interval: interval, keepDaily: keepDaily, keepWeekly: keepWeekly}
+    if len(archives) > 0 {
+        s.last = archives[0].Taken
+    }
+    return s, nil
+}
+
+// maybeRun takes a backup if one is due, records it in the scan history
+// and drops the archives the retention rules no longer keep. Failures are
+// logged and recorded; the next pass tries again.
+func (s *backupSchedule) maybeRun(storage *db.Storage, dbPath string) {
+    now := time.Now()
+    if now.Sub(s.last) < s.interval {
+        return
+    }
+    path := backup.RotationPath(s.dir, now)
+    record := &db.BackupRecord{Archive: path}
+    manifest, err := describeChain(storage, dbPath)
+    if err == nil {
+        err = backup.Write(path, storage, manifest)
+    }
+    if err == nil {
+        _, err = backup.Verify(path)
+    }
+    if err == nil {
+        s.last = now
+        record.Verified = true
+        record.Tip = manifest.Tip
+        record.Blocks = manifest.Blocks
+        if info, statErr := os.Stat(path); statErr == nil {
+            record.Size = info.Size()
+        }
+        slog.Info("backup taken", "db", dbPath, "archive", path, "blocks", record.Blocks, "tip", record.Tip)
+    } else {
+        record.Error = err.Error()
+        slog.Warn("backup failed", "db", dbPath, "archive", path, "err", err)
+    }
+    entry := &db.ScanHistoryEntry{Time: now.UnixNano(), Status: "BACKUP", Backup: record}
+    if err := storage.AppendScanHistory(entry); err != nil {
+        slog.Warn("cannot record backup", "db", dbPath, "err", err)
+    }
+    if !record.Verified {
+        return
+    }
+
+    archives, err := backup.List(s.dir)
+    if err != nil {
+        slog.Warn("cannot list backups", "dir", s.dir, "err", err)
+        return
+    }
+    _, drop := backup.Retain(archives, s.keepDaily, s.keepWeekly)
+    for _, a := range drop {
+        if db.DryRun() {
+            slog.Info("dry run: old backup not removed", "archive", a.Path)
+            continue
+        }
+        if err := os.Remove(a.Path); err != nil {
+            slog.Warn("cannot remove old backup", "archive", a.Path, "err", err)
+            continue
+        }
+        slog.Info("old backup removed", "archive", a.Path)
+    }
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// runBench writes a scratch chain of count blocks with the storage flags
+// in effect, then times reading it back, scanning it and comparing it with
+// itself. The chain is written under dir, or a temporary directory that is
+// removed afterwards.
+func runBench(dir string, count int, out errors.OutputOptions) {
+    scratch := dir == ""
+    if scratch {
+        tmp, err := os.MkdirTemp("", "inspector-bench-")
+        if err != nil {
+            fatal("cannot create scratch directory", "err", err)
+        }
+        defer os.RemoveAll(tmp)
+        dir = tmp
+    } else if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+        usageError(fmt.Errorf("bench writes a scratch chain; %s is not empty", dir))
+    }
+    storage := openStorage(dir)
+    defer storage.Close()
+    if storage.Layout() != db.LayoutInspector {
+        storage.Close()
+        usageError(fmt.Errorf("bench writes the inspector layout, not %s", storage.Layout()))
+    }
+    fail := func(msg string, args ...any) {
+        storage.Close()
+        if scratch {
+            os.RemoveAll(dir)
+        }
+        fatal(msg, args...)
+    }
+
+    // Blocks are built up front so that the write phase times only the
+    // storage.
+    chain := make([]*blocks.Block, count)
+    prevHash := "0"
+    for i := range chain {
+        txs := []string{fmt.Sprintf("Benchmark transaction %d", i)}
+        block := &blocks.Block{
+            Height:     i,
+            PrevHash:   prevHash,
+            Data:       txs[0],
+            Timestamp:  time.Now().Unix() - int64(count-i),
+            MerkleRoot: blocks.MerkleRoot(txs),
+        }
+        hashAndSign(block, blocks.SHA256, nil)
+        chain[i] = block
+        prevHash = block.Hash
+    }
+
+    report := &errors.BenchReport{
+        Backend:     "leveldb",
+        Encoding:    string(storage.Encoding()),
+        Compression: string(storage.Compression()),
+        Encrypted:   storage.Encrypted(),
+        Sealed:      storage.Sealed(),
+        Blocks:      count,
+    }
+    start := time.Now()
+    for _, block := range chain {
+        if err := storage.SaveBlock(block); err != nil {
+            fail("cannot save block", "height", block.Height, "err", err)
+        }
+    }
+    report.Add("write", time.Since(start))
+
+    start = time.Now()
+    for i := range count {
+        if _, err := storage.LoadBlock(i); err != nil {
+            fail("cannot load block", "height", i, "err", err)
+        }
+    }
+    report.Add("read", time.Since(start))
+
+    start = time.Now()
+    result := errors.ScanErrors(storage, dir, errors.ScanOptions{})
+    report.Add("scan", time.Since(start))
+    if result.TotalErrors > 0 {
+        fail("scratch chain did not scan clean", "errors", result.TotalErrors)
+    }
+
+    start = time.Now()
+    errors.CompareNodes(storage, storage, dir, dir, errors.CompareOptions{})
+    report.Add("compare", time.Since(start))
+
+    errors.OutputBenchReport(report, out)
+}
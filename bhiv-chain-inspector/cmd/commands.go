@@ -0,0 +1,1331 @@
+package main
+
+import (
+    "crypto/sha256"
+    "fmt"
+    "log/slog"
+    "maps"
+    "math/rand/v2"
+    "os"
+    "regexp"
+    "slices"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/config"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/notify"
+    "bhiv-chain-inspector/internal/plugins"
+    "bhiv-chain-inspector/internal/rules"
+    "bhiv-chain-inspector/internal/tracing"
+    "github.com/spf13/cobra"
+    flag "github.com/spf13/pflag"
+)
+
+// command is one subcommand, run as "inspector <name> [flags] [args]".
+// cobraCommand turns it into the cobra command that parses its flags and
+// prints its help.
+type command struct {
+    name     string
+    aliases  []string
+    args     string // synopsis of positional arguments; empty if none are accepted
+    summary  string
+    examples []string
+    // flagHelp replaces the usage of shared flags that mean something
+    // particular to this command.
+    flagHelp map[string]string
+    // setup registers the command's flags on fs and returns the function
+    // that runs the command once they are parsed.
+    setup func(fs *flag.FlagSet, g *globals) func()
+}
+
+// commands is the command table, in the order help lists it.
+var commands []*command
+
+func init() {
+    commands = []*command{
+        {
+            name:     "load",
+            summary:  "Load sample blockchain data",
+            examples: []string{
+                "inspector load -db ./data -blocks 50",
+                "inspector load -db ./data -blocks 50 --difficulty 16",
+                "openssl genpkey -algorithm ed25519 -out key.pem && inspector load -db ./data --signing-key key.pem",
+                "inspector load -db ./data --chain-id staging",
+                "inspector load -db ./data --compress zstd",
+                "openssl rand -hex 32 > db.key && inspector load -db ./data --encryption-key db.key",
+                "inspector load -db ./data --seal-key seal.key",
+                "inspector load -db ./data -blocks 1000 --error-profile profile.yaml",
+                "inspector load -db ./data -blocks 1000 --error-profile profile.yaml --seed 7",
+                "inspector load -db ./data -blocks 5000000 --batch-size 10000",
+                "inspector load -db ./data --payload transfer",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                numBlocks := fs.Int("blocks", 10, "Number of blocks to load")
+                difficulty := fs.Int("difficulty", 0, "Mine each block to this many leading zero bits of hash (0 = no proof of work)")
+                signingKey := fs.String("signing-key", "", "Sign each block with this ed25519 key (PKCS#8 PEM, or hex seed)")
+                chainID := fs.String("chain-id", "", "Stamp each block with this chain ID and declare it as the database's chain")
+                hashAlg := fs.String("hash-algorithm", "sha256", "Hash blocks with this algorithm: "+blocks.JoinHashAlgorithms())
+                errorProfile := fs.String("error-profile", "", "Corrupt blocks at the rates given in this YAML profile, reproducibly")
+                batchSize := fs.Int("batch-size", 1, "Write this many blocks per LevelDB batch; above 1, blocks are logged per batch")
+                sync := fs.Bool("sync", false, "Flush each batch to disk before writing the next")
+                payload := fs.String("payload", "text", "Transaction template: "+payloadTemplateNames()+", or a text/template file")
+                seed := fs.Uint64("seed", 0, "Make the chain deterministic: fixed timestamps, and this seed for --payload and --error-profile")
+                return func() {
+                    if *difficulty < 0 || *difficulty > 32 {
+                        usageError(fmt.Errorf("--difficulty must be between 0 and 32"))
+                    }
+                    if *batchSize < 1 {
+                        usageError(fmt.Errorf("--batch-size must be at least 1"))
+                    }
+                    chain := sampleChain{blocks: *numBlocks, difficulty: *difficulty, chainID: *chainID, batchSize: *batchSize, sync: *sync}
+                    var err error
+                    if chain.hashAlg, err = blocks.ParseHashAlgorithm(*hashAlg); err != nil {
+                        usageError(err)
+                    }
+                    if *signingKey != "" {
+                        if chain.key, err = loadSigningKey(*signingKey); err != nil {
+                            fatal("cannot load signing key", "err", err)
+                        }
+                    }
+                    if *errorProfile != "" {
+                        if chain.faults, err = newFaultPlan(*errorProfile); err != nil {
+                            usageError(err)
+                        }
+                    }
+                    fs.Visit(func(f *flag.Flag) { chain.seeded = chain.seeded || f.Name == "seed" })
+                    if chain.seeded && chain.faults != nil {
+                        chain.faults.reseed(*seed)
+                    }
+                    payloadSeed := rand.Uint64()
+                    if chain.seeded {
+                        payloadSeed = *seed
+                    }
+                    if chain.payload, err = newPayloadGenerator(*payload, payloadSeed); err != nil {
+                        usageError(err)
+                    }
+                    loadSampleData(*dbPath, chain)
+                }
+            },
+        },
+        {
+            name:     "append",
+            summary:  "Append a block with the given data after the tip of the chain",
+            examples: []string{
+                "inspector append -db ./data --data \"alice pays bob 5\"",
+                "inspector append -db ./data --data \"$(printf 'tx1\\ntx2')\" --signing-key key.pem",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                data := fs.String("data", "", "Data of the new block, one transaction per line")
+                difficulty := fs.Int("difficulty", -1, "Mine the block to this many leading zero bits; -1 keeps the tip's")
+                signingKey := fs.String("signing-key", "", "Sign the block with this ed25519 key (PKCS#8 PEM, or hex seed)")
+                hashAlg := fs.String("hash-algorithm", "", "Hash the block with this algorithm: "+blocks.JoinHashAlgorithms()+" (default: detected from the chain)")
+                return func() {
+                    if *difficulty > 32 {
+                        usageError(fmt.Errorf("--difficulty must be at most 32"))
+                    }
+                    opts := appendOptions{data: *data, difficulty: *difficulty}
+                    var err error
+                    if *hashAlg != "" {
+                        if opts.hashAlg, err = blocks.ParseHashAlgorithm(*hashAlg); err != nil {
+                            usageError(err)
+                        }
+                    }
+                    if *signingKey != "" {
+                        if opts.key, err = loadSigningKey(*signingKey); err != nil {
+                            fatal("cannot load signing key", "err", err)
+                        }
+                    }
+                    runAppend(*dbPath, opts, g.out)
+                }
+            },
+        },
+        {
+            name:     "mine",
+            summary:  "Append blocks with proof of work and report the hash rate",
+            examples: []string{
+                "inspector mine -db ./data --blocks 5 --difficulty 16",
+                "inspector mine -db ./data --blocks 3 --difficulty 12 --hash-algorithm sha256d",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                count := fs.Int("blocks", 1, "Number of blocks to mine")
+                difficulty := fs.Int("difficulty", 16, "Leading zero bits each block's hash must have")
+                signingKey := fs.String("signing-key", "", "Sign each block with this ed25519 key (PKCS#8 PEM, or hex seed)")
+                hashAlg := fs.String("hash-algorithm", "", "Hash blocks with this algorithm: "+blocks.JoinHashAlgorithms()+" (default: detected from the chain)")
+                return func() {
+                    if *difficulty < 1 || *difficulty > 32 {
+                        usageError(fmt.Errorf("--difficulty must be between 1 and 32"))
+                    }
+                    if *count < 1 {
+                        usageError(fmt.Errorf("--blocks must be at least 1"))
+                    }
+                    opts := appendOptions{difficulty: *difficulty}
+                    var err error
+                    if *hashAlg != "" {
+                        if opts.hashAlg, err = blocks.ParseHashAlgorithm(*hashAlg); err != nil {
+                            usageError(err)
+                        }
+                    }
+                    if *signingKey != "" {
+                        if opts.key, err = loadSigningKey(*signingKey); err != nil {
+                            fatal("cannot load signing key", "err", err)
+                        }
+                    }
+                    runMine(*dbPath, *count, opts, g.out)
+                }
+            },
+        },
+        {
+            name:     "corrupt",
+            summary:  "Inject a fault into one block (badhash, brokenlink, truncate, futurets) for testing",
+            examples: []string{
+                "inspector corrupt -db ./data --type badhash --height 3 && inspector scan -db ./data",
+                "inspector corrupt -db ./data --type truncate --height 7",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                kind := fs.String("type", "", "Fault to inject: "+corruptionTypeNames())
+                height := fs.Int("height", -1, "Height of the block to corrupt")
+                return func() {
+                    if *kind == "" || *height < 0 {
+                        usageError(fmt.Errorf("corrupt needs --type and --height"))
+                    }
+                    runCorrupt(*dbPath, *kind, *height, g.out)
+                }
+            },
+        },
+        {
+            name:     "backup",
+            summary:  "Archive a chain and its manifest to a compressed tar file, and verify it",
+            examples: []string{
+                "inspector backup -db ./data --out chain-backup.tar.zst",
+                "inspector backup -db ./data --out chain-backup.tar.gz --json",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                archive := fs.String("out", "", "Archive to write; .tar.zst, .tar.gz or .tar")
+                return func() {
+                    if *archive == "" {
+                        usageError(fmt.Errorf("backup needs --out"))
+                    }
+                    runBackup(*dbPath, *archive, g.out)
+                }
+            },
+        },
+        {
+            name:     "restore",
+            summary:  "Rebuild a database from a backup archive and verify the restored chain",
+            examples: []string{
+                "inspector restore --in chain-backup.tar.zst -db ./restored",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                archive := fs.String("in", "", "Archive written by backup")
+                return func() {
+                    if *archive == "" {
+                        usageError(fmt.Errorf("restore needs --in"))
+                    }
+                    runRestore(*archive, *dbPath, g.out)
+                }
+            },
+        },
+        {
+            name:     "undo",
+            summary:  "Revert the writes recorded in a --journal file",
+            examples: []string{
+                "inspector compare --heal --nodes ./node1,./node2,./node3 --journal heal.journal",
+                "inspector undo --journal heal.journal",
+            },
+            flagHelp: map[string]string{
+                "journal": "Journal to revert: its old values are put back, newest write first, and keys its writes created are deleted",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                return func() {
+                    runUndo(g.journal, g.out)
+                }
+            },
+        },
+        {
+            name:     "bench",
+            summary:  "Measure write, read, scan and compare throughput of the storage",
+            examples: []string{
+                "inspector bench --blocks 50000",
+                "inspector bench --blocks 50000 --compress zstd --encryption-key db.key",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                count := fs.Int("blocks", 10000, "Number of blocks in the scratch chain")
+                dir := fs.String("dir", "", "Write the scratch chain to this empty directory and keep it (default: a temporary directory)")
+                return func() {
+                    if *count < 1 {
+                        usageError(fmt.Errorf("--blocks must be at least 1"))
+                    }
+                    runBench(*dir, *count, g.out)
+                }
+            },
+        },
+        {
+            name:     "crash-test",
+            summary:  "Kill block writers mid-batch and check the chain they leave is consistent",
+            examples: []string{
+                "inspector crash-test --rounds 20 --batch-size 100",
+                "inspector crash-test --rounds 10 --batch-size 1000 --sync --compress zstd",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                t := crashTest{}
+                fs.StringVar(&t.dir, "dir", "", "Write the chain to this empty directory and keep it (default: a temporary directory)")
+                fs.IntVar(&t.rounds, "rounds", 10, "Number of writers to kill")
+                fs.IntVar(&t.batchSize, "batch-size", 100, "Blocks per batch the writers write")
+                fs.BoolVar(&t.sync, "sync", false, "Flush each batch to disk before writing the next")
+                fs.DurationVar(&t.maxDelay, "max-delay", 500*time.Millisecond, "Kill each writer after a random time up to this")
+                return func() {
+                    if t.rounds < 1 || t.batchSize < 1 || t.maxDelay <= 0 {
+                        usageError(fmt.Errorf("--rounds, --batch-size and --max-delay must be positive"))
+                    }
+                    runCrashTest(t, g.out)
+                }
+            },
+        },
+        {
+            name:     "fuzz-corpus",
+            summary:  "Export stored block values as seeds for the decoder fuzz tests",
+            examples: []string{
+                "inspector fuzz-corpus -db ./data && go test ./internal/db -fuzz FuzzDecodeBlock",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                out := fs.String("out", "internal/db/testdata/fuzz/FuzzDecodeBlock", "Corpus directory to write the values to")
+                return func() {
+                    runFuzzCorpus(*dbPath, *out)
+                }
+            },
+        },
+        {
+            name:    "scan",
+            aliases: []string{"scan-errors"},
+            summary: "Scan a chain for errors; exits 1 unless it is healthy",
+            examples: []string{
+                "inspector scan -db ./data",
+                "inspector scan -db ./data --json",
+                "inspector scan -db ./data --baseline last-scan.json",
+                "inspector scan -db ./data --events nats://localhost:4222/chain.findings",
+                "inspector scan -db ./data --json --sign-key key.pem --signature scan.json.sig > scan.json",
+                "inspector scan --db-shards ./shard0,./shard1,./shard2 --shard-size 100000",
+                "inspector scan -db ./data -v --max-errors-per-type 20",
+                "inspector scan -db ./data --memory-limit 256MB --spill-file findings.ndjson",
+                "inspector scan -db ./data --verify-cache ./data.verify-cache",
+            },
+            flagHelp: map[string]string{
+                "report-template": "Print the scan report through this Go text/template file instead of the built-in layout",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                var shards shardFlags
+                shards.register(fs)
+                var scan scanFlags
+                scan.register(fs)
+                baselinePath := fs.String("baseline", "", "Previous scan --json report to diff against; only new errors fail")
+                noHistory := fs.Bool("no-history", false, "Do not record this scan in the database's scan history or move its checkpoint")
+                maxPerType := fs.Int("max-errors-per-type", 0, "List at most this many findings (ranges of consecutive heights count as one) of each class, then \"and N more\" (default all)")
+                var memoryLimit byteSize
+                fs.Var(&memoryLimit, "memory-limit", "Keep at most this much of the findings in memory, e.g. 256MB, and write the rest as NDJSON to --spill-file (default no limit)")
+                spillFile := fs.String("spill-file", "", "File the findings beyond --memory-limit are written to (default a new temporary file)")
+                cachePath := fs.String("verify-cache", "", "Sidecar file remembering the blocks found clean, whose checks later scans skip while the chain up to them is unchanged")
+                cacheMaxAge := fs.Duration("verify-cache-max-age", 24*time.Hour, "Rebuild the --verify-cache file after this long, for checks that depend on the clock (0: never)")
+                eventsDest := eventsFlag(fs)
+                var sign signFlags
+                sign.register(fs)
+                return func() {
+                    if *maxPerType < 0 {
+                        usageError(fmt.Errorf("--max-errors-per-type must not be negative"))
+                    }
+                    opts := scan.options()
+                    if memoryLimit > 0 {
+                        // Baselines and events need every finding at hand.
+                        if *baselinePath != "" || *eventsDest != "" {
+                            usageError(fmt.Errorf("--memory-limit cannot be combined with --baseline or --events"))
+                        }
+                        opts.Spill = errors.NewFindingSpill(int64(memoryLimit), *spillFile)
+                    } else if *spillFile != "" {
+                        usageError(fmt.Errorf("--spill-file needs --memory-limit"))
+                    }
+                    if *cachePath != "" {
+                        opts.Cache = &errors.VerifyCache{Path: *cachePath, Settings: scan.cacheSettings(g), MaxAge: *cacheMaxAge}
+                    }
+                    signer := sign.signer(g.out)
+                    path := shards.path(fs, *dbPath)
+                    runScan(path, *baselinePath, !*noHistory, *maxPerType, newEmitter(*eventsDest, path), signer, g.out, opts)
+                }
+            },
+        },
+        {
+            name:     "scan-fleet",
+            args:     "[database...]",
+            summary:  "Scan every database matching a glob and rank the nodes by health; exits 1 unless all are healthy",
+            examples: []string{
+                "inspector scan-fleet --dbs './nodes/*'",
+                "inspector scan-fleet --json --dbs ./nodes/* > fleet.json",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                pattern := fs.String("dbs", "", "Glob of the databases to scan, e.g. './nodes/*'; further arguments are scanned too, so an unquoted glob works")
+                var scan scanFlags
+                scan.register(fs)
+                return func() {
+                    if *pattern == "" {
+                        usageError(fmt.Errorf("--dbs is required"))
+                    }
+                    runScanFleet(fleetDatabases(append([]string{*pattern}, fs.Args()...)), g.out, scan.options())
+                }
+            },
+        },
+        {
+            name:     "verify",
+            summary:  "Verify the chain end to end: one PASSED/FAILED verdict per check; exits 1 on failure",
+            examples: []string{"inspector verify -db ./data", "inspector verify -db ./data -v"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                var shards shardFlags
+                shards.register(fs)
+                var scan scanFlags
+                scan.register(fs)
+                return func() { runVerify(shards.path(fs, *dbPath), g.out, scan.options()) }
+            },
+        },
+        {
+            name:     "verify-new",
+            summary:  "Verify only the blocks added since the last verified tip, then advance it; exits 1 on failure",
+            examples: []string{
+                "inspector verify-new -db ./data",
+                "inspector verify-new -db ./data --json | jq .advanced",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                var shards shardFlags
+                shards.register(fs)
+                var scan scanFlags
+                scan.register(fs)
+                return func() { runVerifyNew(shards.path(fs, *dbPath), g.out, scan.options()) }
+            },
+        },
+        {
+            name:     "view",
+            args:     "<height>",
+            summary:  "Show one block, by height or by hash",
+            examples: []string{
+                "inspector view -db ./data 42",
+                "inspector view -db ./data --json 42 | jq '.hash_valid and .linkage_valid'",
+                "inspector view -db ./data --hash 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                hash := fs.String("hash", "", "Show the block with this hash instead, found through the hash index (see index)")
+                hashAlg := fs.String("hash-algorithm", "", "Recompute the --json hash with this algorithm: "+blocks.JoinHashAlgorithms()+" (default: whichever the hash matches)")
+                return func() {
+                    var alg blocks.HashAlgorithm
+                    if *hashAlg != "" {
+                        var err error
+                        if alg, err = blocks.ParseHashAlgorithm(*hashAlg); err != nil {
+                            usageError(err)
+                        }
+                    }
+                    if *hash != "" {
+                        if fs.NArg() != 0 {
+                            usageError(fmt.Errorf("view takes a height or --hash, not both"))
+                        }
+                        runViewHash(*dbPath, *hash, alg, g.out)
+                        return
+                    }
+                    if fs.NArg() != 1 {
+                        usageError(fmt.Errorf("view needs one height"))
+                    }
+                    runView(*dbPath, fs.Arg(0), alg, g.out)
+                }
+            },
+        },
+        {
+            name:     "list",
+            summary:  "Show a table of a range of blocks: height, hash, time and stored size",
+            examples: []string{
+                "inspector list -db ./data --from 100 --to 120",
+                "inspector list -db ./data --last 50 --json",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                from := fs.Int("from", -1, "First height to list (default 0)")
+                to := fs.Int("to", -1, "Last height to list (default the tip)")
+                last := fs.Int("last", 0, fmt.Sprintf("List this many blocks up to the tip (default %d without --from or --to)", defaultListLast))
+                return func() {
+                    if *last < 0 {
+                        usageError(fmt.Errorf("--last cannot be negative"))
+                    }
+                    runList(*dbPath, *from, *to, *last, g.out)
+                }
+            },
+        },
+        {
+            name:     "search",
+            summary:  "Find the transactions matching a regular expression, with the heights holding them",
+            examples: []string{
+                `inspector search -db ./data --regex "tx-42[0-9]+"`,
+                `inspector search -db ./data --regex '"to":"0xab' --context 2 --max 20`,
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                pattern := fs.String("regex", "", "Regular expression (RE2) to match against each transaction")
+                context := fs.Int("context", 1, "Transactions of the same block to show before and after each match")
+                limit := fs.Int("max", 0, "Stop after this many matches (0: no limit)")
+                return func() {
+                    if *pattern == "" {
+                        usageError(fmt.Errorf("search needs --regex"))
+                    }
+                    re, err := regexp.Compile(*pattern)
+                    if err != nil {
+                        usageError(fmt.Errorf("--regex: %v", err))
+                    }
+                    if *context < 0 || *limit < 0 {
+                        usageError(fmt.Errorf("--context and --max cannot be negative"))
+                    }
+                    runSearch(*dbPath, re, *context, *limit, g.out)
+                }
+            },
+        },
+        {
+            name:     "stats",
+            summary:  "Chain height, block count, block time and size distributions, gaps and duplicate hashes",
+            examples: []string{
+                "inspector stats -db ./data",
+                "inspector stats -db ./data --timeseries hourly --format csv > blocks.csv",
+                "inspector stats --db-shards ./shard0,./shard1 --shard-size 100000",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                var shards shardFlags
+                shards.register(fs)
+                timeseries := fs.String("timeseries", "", "Count blocks and average intervals per period instead: "+errors.TimeSeriesPeriods())
+                return func() {
+                    if g.format == "csv" && *timeseries == "" {
+                        usageError(fmt.Errorf("--format csv needs --timeseries"))
+                    }
+                    path := shards.path(fs, *dbPath)
+                    if *timeseries != "" {
+                        runTimeSeries(path, *timeseries, g.out)
+                        return
+                    }
+                    runStats(path, g.out)
+                }
+            },
+        },
+        {
+            name:     "db-stats",
+            summary:  "Key count, value bytes, block size percentiles, largest blocks and LevelDB levels",
+            examples: []string{"inspector db-stats -db ./data"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                var shards shardFlags
+                shards.register(fs)
+                return func() { runDBStats(shards.path(fs, *dbPath), g.out) }
+            },
+        },
+        {
+            name:     "index",
+            summary:  "Build the hash index view --hash looks blocks up in",
+            examples: []string{"inspector index -db ./data"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                return func() { runIndex(*dbPath, false, g.out) }
+            },
+        },
+        {
+            name:     "reindex",
+            summary:  "Drop the hash index, stale entries included, and build it again",
+            examples: []string{"inspector reindex -db ./data"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                return func() { runIndex(*dbPath, true, g.out) }
+            },
+        },
+        {
+            name:     "compact",
+            summary:  "Compact the LevelDB keyspace and report the space reclaimed",
+            examples: []string{"inspector compact -db ./data"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                return func() { runCompact(*dbPath, g.out) }
+            },
+        },
+        {
+            name:     "recover",
+            summary:  "Rebuild a corrupt or missing LevelDB manifest from the tables on disk",
+            examples: []string{"cp -r ./data ./data.bak && inspector recover -db ./data"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                return func() { runRecover(*dbPath, g.out) }
+            },
+        },
+        {
+            name:     "keys",
+            summary:  "List keys that are neither blocks nor metadata; exits 1 if there are any",
+            examples: []string{"inspector keys -db ./data"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                return func() { runKeys(*dbPath, g.out) }
+            },
+        },
+        {
+            name:     "namespaces",
+            summary:  "List the chains stored under --chain namespaces of a database, with block counts and tips",
+            examples: []string{"inspector namespaces -db ./data"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                return func() { runNamespaces(*dbPath, g.out) }
+            },
+        },
+        {
+            name:     "prove",
+            summary:  "Merkle inclusion proof of one transaction (by index or tx ID)",
+            examples: []string{"inspector prove -db ./data --height 42 --tx 0 --json > proof.json"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                height := fs.Int("height", 0, "Height of the block holding the transaction")
+                tx := fs.String("tx", "", "Transaction index in the block, or its tx ID (SHA-256 hex)")
+                return func() {
+                    if *tx == "" {
+                        usageError(fmt.Errorf("prove needs --tx"))
+                    }
+                    runProve(*dbPath, *height, *tx, g.out)
+                }
+            },
+        },
+        {
+            name:    "verify-proof",
+            summary: "Check a proof from prove --json against the block header; exits 1 if invalid",
+            examples: []string{
+                "inspector verify-proof --proof proof.json -db ./data",
+                "inspector prove -db ./data --height 42 --tx 0 --json | inspector verify-proof -db ./data",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                proofPath := fs.String("proof", "-", "Proof file written by prove --json (- reads stdin)")
+                dbPath := fs.String("db", "", "Database holding the trusted block header (empty checks the proof against itself)")
+                return func() { runVerifyProof(*proofPath, *dbPath, g.out) }
+            },
+        },
+        {
+            name:    "compare",
+            summary: "Compare two nodes, or heal three or more by majority vote",
+            examples: []string{
+                "inspector compare -db1 ./node1 -db2 ./node2",
+                "inspector compare -db1 ./node1 -db2 agent://host-b:9090",
+                "inspector compare -db1 ./node1 -db2 ./node2 --bisect",
+                "inspector compare -db1 ./node1 -db2 ./node2 --show-diff",
+                "inspector compare -db1 ./node1 -db2 ./node2 --diff-style side-by-side",
+                "inspector compare -db1 ./node1 -db2 ./node2 --format dot | dot -Tsvg > fork.svg",
+                "inspector compare --heal --dry-run --nodes ./node1,./node2,./node3",
+                "inspector compare -db1 ./node1 -db2 ./node2 --json --sign-key key.pem --signature compare.json.sig > compare.json",
+                "inspector compare -db1 ./data -db2 ./data --chain main --chain2 testnet",
+            },
+            flagHelp: map[string]string{
+                "format":          "Output format: " + strings.Join(errors.FormatNames(), ", ") + ", or dot for a Graphviz fork graph",
+                "report-template": "Print the comparison through this Go text/template file instead of the built-in layout",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                db1Path := fs.String("db1", "./node1-data", "First database, or agent://host:port")
+                db2Path := fs.String("db2", "./node2-data", "Second database, or agent://host:port")
+                segmentSize := segmentFlag(fs, "Heights per fingerprint segment; identical segments are skipped (negative compares every block)")
+                bisect := fs.Bool("bisect", false, "Find the divergence point in O(log n) block loads, then compare only the blocks after it")
+                showDiff := fs.Bool("show-diff", false, "Print a unified diff of the JSON of each block the nodes hold in different versions")
+                diffStyle := fs.String("diff-style", errors.DiffUnified, "Style of --show-diff: "+strings.Join(errors.DiffStyles, ", ")+"; setting it implies --show-diff")
+                agentToken := agentTokenFlag(fs)
+                heal := fs.Bool("heal", false, "Rewrite divergent blocks with the version held by the majority of --nodes")
+                nodeList := fs.String("nodes", "", "Comma-separated databases of three or more nodes (--heal)")
+                healLog := fs.String("heal-log", "heal-audit.jsonl", "Append one JSON line per healed block to this file")
+                chain2 := fs.String("chain2", "", "Chain namespace of -db2 (default --chain); -db1 and -db2 may then be one database")
+                var sign signFlags
+                sign.register(fs)
+                return func() {
+                    signer := sign.signer(g.out)
+                    if *heal {
+                        if g.format == "dot" {
+                            fatal("--format dot cannot be combined with --heal")
+                        }
+                        if signer != nil {
+                            usageError(fmt.Errorf("--sign-key signs comparison reports, not --heal"))
+                        }
+                        runHeal(strings.Split(*nodeList, ","), g.dryRun, *healLog, g.out)
+                        return
+                    }
+                    chain2Set := false
+                    fs.Visit(func(f *flag.Flag) { chain2Set = chain2Set || f.Name == "chain2" })
+                    if !chain2Set {
+                        *chain2 = g.chain
+                    } else if *chain2 != "" {
+                        if err := db.ValidNamespace(*chain2); err != nil {
+                            usageError(fmt.Errorf("--chain2: %w", err))
+                        }
+                    }
+                    if !slices.Contains(errors.DiffStyles, *diffStyle) {
+                        usageError(fmt.Errorf("unknown --diff-style %q (want %s)", *diffStyle, strings.Join(errors.DiffStyles, " or ")))
+                    }
+                    fs.Visit(func(f *flag.Flag) { *showDiff = *showDiff || f.Name == "diff-style" })
+                    out := g.out
+                    out.DiffStyle = *diffStyle
+                    runCompare(*db1Path, *db2Path, *chain2, *agentToken, *segmentSize, *bisect, *showDiff, g.format == "dot", signer, out)
+                }
+            },
+        },
+        {
+            name:    "compare3",
+            summary: "Compare two nodes with a backup of their common past to tell which one diverged",
+            examples: []string{
+                "inspector compare3 --base ./backup --left ./node1 --right ./node2",
+                "inspector compare3 --base ./backup --left ./node1 --right agent://host-b:9090 --format csv",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                basePath := fs.String("base", "", "Database both nodes descend from, such as a backup, or agent://host:port")
+                leftPath := fs.String("left", "", "First node's database, or agent://host:port")
+                rightPath := fs.String("right", "", "Second node's database, or agent://host:port")
+                segmentSize := segmentFlag(fs, "Heights per fingerprint segment; segments identical on all three chains are skipped (negative compares every block)")
+                agentToken := agentTokenFlag(fs)
+                return func() {
+                    if *basePath == "" || *leftPath == "" || *rightPath == "" {
+                        usageError(fmt.Errorf("compare3 needs --base, --left and --right"))
+                    }
+                    if *basePath == *leftPath || *basePath == *rightPath || *leftPath == *rightPath {
+                        usageError(fmt.Errorf("--base, --left and --right must be three different databases"))
+                    }
+                    runCompare3(*basePath, *leftPath, *rightPath, *agentToken, *segmentSize, g.out)
+                }
+            },
+        },
+        {
+            name:    "common-ancestor",
+            summary: "Find the highest block two nodes agree on, to restore a forked node to",
+            examples: []string{
+                "inspector common-ancestor -db1 ./node1 -db2 ./node2",
+                "inspector common-ancestor -db1 agent://host-a:9090 -db2 agent://host-b:9090 -q",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                db1Path := fs.String("db1", "./node1-data", "First database, or agent://host:port")
+                db2Path := fs.String("db2", "./node2-data", "Second database, or agent://host:port")
+                segmentSize := segmentFlag(fs, "Heights per fingerprint segment; identical leading segments are not probed (negative skips fingerprints and walks to confirm identical chains)")
+                agentToken := agentTokenFlag(fs)
+                return func() {
+                    if *db1Path == *db2Path {
+                        usageError(fmt.Errorf("-db1 and -db2 must be different databases"))
+                    }
+                    runCommonAncestor(*db1Path, *db2Path, *agentToken, *segmentSize, g.out)
+                }
+            },
+        },
+        {
+            name:     "verify-report",
+            args:     "[report.json]",
+            summary:  "Check a scan or compare --json report against its --sign-key signature; exits 1 if altered",
+            examples: []string{
+                "openssl pkey -in key.pem -pubout -out key.pub",
+                "inspector verify-report --report scan.json --signature scan.json.sig --public-key key.pub",
+                "inspector verify-report scan.json --signature scan.json.sig --public-key key.pub -q",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                report := fs.String("report", "", "JSON report as written by scan or compare --json (or the argument)")
+                sigPath := fs.String("signature", "report.sig", "Detached signature written by --sign-key")
+                publicKey := fs.String("public-key", "", "ed25519 public key the report must be signed with (PKIX PEM, or hex)")
+                return func() {
+                    switch {
+                    case fs.NArg() > 1:
+                        usageError(fmt.Errorf("verify-report takes one report, got %q", fs.Args()))
+                    case fs.NArg() == 1 && *report != "":
+                        usageError(fmt.Errorf("give the report as --report or as the argument, not both"))
+                    case fs.NArg() == 1:
+                        *report = fs.Arg(0)
+                    }
+                    if *report == "" {
+                        usageError(fmt.Errorf("verify-report needs a report"))
+                    }
+                    runVerifyReport(*report, *sigPath, *publicKey, g.out)
+                }
+            },
+        },
+        {
+            name:     "diff-snapshots",
+            summary:  "Show the blocks appended, rewritten and deleted between two snapshots of one node",
+            examples: []string{
+                "inspector diff-snapshots --before ./snap-monday --after ./snap-tuesday",
+                "inspector diff-snapshots --before ./snap-monday --after ./data --json",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                before := fs.String("before", "", "Database of the earlier snapshot")
+                after := fs.String("after", "", "Database of the later snapshot")
+                return func() { runDiffSnapshots(*before, *after, g.out) }
+            },
+        },
+        {
+            name:     "fingerprint",
+            summary:  "Digest of the whole chain and of each segment of heights",
+            examples: []string{"inspector fingerprint -db ./data --segment 1000"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := fs.String("db", "./leveldb-data", "Path to LevelDB database, or agent://host:port")
+                segmentSize := segmentFlag(fs, "Heights per fingerprint segment")
+                agentToken := agentTokenFlag(fs)
+                return func() { runFingerprint(*dbPath, *segmentSize, *agentToken, g.out) }
+            },
+        },
+        {
+            name:     "audit-log",
+            summary:  "Show who wrote which blocks when (heal, append, load, restore, undo...); exits 1 if the log was tampered with",
+            examples: []string{
+                "inspector audit-log -db ./data",
+                "inspector audit-log -db ./data --operation compare --since 24h -v",
+                "inspector audit-log -db ./data --height 42 --json",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                operation := fs.String("operation", "", "Only entries of this command (heal for compare --heal)")
+                since := fs.String("since", "", "Only entries from this long ago (24h) or this date (2006-01-02) on")
+                height := fs.Int("height", -1, "Only entries that changed the block at this height")
+                last := fs.Int("last", 0, "Only the last N matching entries")
+                return func() {
+                    from, err := parseSince(*since)
+                    if err != nil {
+                        usageError(fmt.Errorf("--since: want a duration or a date: %w", err))
+                    }
+                    runAuditLog(*dbPath, errors.AuditFilter{Operation: *operation, Since: from, Height: *height, Last: *last}, g.out)
+                }
+            },
+        },
+        {
+            name:     "trend",
+            summary:  "Show health score and error counts of past scans",
+            examples: []string{"inspector trend -db ./data --last 20"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                last := fs.Int("last", 0, "Only show the last N entries")
+                return func() { runTrend(*dbPath, *last, g.out) }
+            },
+        },
+        {
+            name:    "watch",
+            summary: "Keep the database open and validate new blocks every --interval",
+            examples: []string{
+                "inspector watch -db ./data --interval 10m",
+                "inspector watch -db ./data --slack-webhook https://hooks.slack.com/services/...",
+                "inspector watch -db ./data --backup-dir /backups/chain --backup-interval 24h --keep-daily 7 --keep-weekly 4",
+            },
+            flagHelp: map[string]string{
+                "report-template": "Print the report of every pass through this Go text/template file instead of the built-in layout",
+            },
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                var scan scanFlags
+                scan.register(fs)
+                interval := fs.Duration("interval", 10*time.Minute, "Time between passes")
+                webhookURL := fs.String("webhook", "", "POST results with errors to this URL")
+                webhookSecret := fs.String("webhook-secret", os.Getenv("INSPECTOR_WEBHOOK_SECRET"), "HMAC key for signing webhook requests (default $INSPECTOR_WEBHOOK_SECRET)")
+                webhookRetries := fs.Int("webhook-retries", 3, "Retries for failed webhook and chat deliveries")
+                slackURL := fs.String("slack-webhook", os.Getenv("SLACK_WEBHOOK_URL"), "Slack incoming webhook for summaries (default $SLACK_WEBHOOK_URL)")
+                discordURL := fs.String("discord-webhook", os.Getenv("DISCORD_WEBHOOK_URL"), "Discord webhook for summaries (default $DISCORD_WEBHOOK_URL)")
+                eventsDest := eventsFlag(fs)
+                backupDir := fs.String("backup-dir", "", "Take rotating backups into this directory")
+                backupInterval := fs.Duration("backup-interval", 24*time.Hour, "Time between backups (--backup-dir)")
+                keepDaily := fs.Int("keep-daily", 7, "Keep the newest backup of each of this many days (--backup-dir)")
+                keepWeekly := fs.Int("keep-weekly", 4, "Keep the newest backup of each of this many weeks (--backup-dir)")
+                return func() {
+                    var backups *backupSchedule
+                    if *backupDir != "" {
+                        if *backupInterval <= 0 || *keepDaily < 0 || *keepWeekly < 0 {
+                            usageError(fmt.Errorf("--backup-interval must be positive and --keep-daily, --keep-weekly not negative"))
+                        }
+                        var err error
+                        if backups, err = newBackupSchedule(*backupDir, *backupInterval, *keepDaily, *keepWeekly); err != nil {
+                            fatal("cannot use backup directory", "dir", *backupDir, "err", err)
+                        }
+                    }
+                    var notifiers []notify.Notifier
+                    if *webhookURL != "" {
+                        notifiers = append(notifiers, &notify.Webhook{URL: *webhookURL, Secret: *webhookSecret, Retries: *webhookRetries})
+                    }
+                    if *slackURL != "" {
+                        notifiers = append(notifiers, &notify.Slack{URL: *slackURL, Retries: *webhookRetries})
+                    }
+                    if *discordURL != "" {
+                        notifiers = append(notifiers, &notify.Discord{URL: *discordURL, Retries: *webhookRetries})
+                    }
+                    runWatch(*dbPath, *interval, g.out, scan.options(), notifiers, newEmitter(*eventsDest, *dbPath), backups)
+                }
+            },
+        },
+        {
+            name:     "follow",
+            summary:  "Print a verdict for each block as it is appended (like tail -f)",
+            examples: []string{"inspector follow -db ./data --json", "inspector follow -db ./data --fs-watch=false --poll 5s"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                var scan scanFlags
+                scan.register(fs)
+                poll := fs.Duration("poll", time.Second, "How often to check for new blocks (with --fs-watch, a fallback)")
+                fsWatch := fs.Bool("fs-watch", true, "Check as soon as the database directory is written to (Linux)")
+                return func() { runFollow(*dbPath, *poll, *fsWatch, g.out, scan.options()) }
+            },
+        },
+        {
+            name:     "tui",
+            summary:  "Interactive dashboard: block list, block details, re-scan with r",
+            examples: []string{"inspector tui -db ./data"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                var scan scanFlags
+                scan.register(fs)
+                return func() { runTUI(*dbPath, g.out, scan.options()) }
+            },
+        },
+        {
+            name:     "shell",
+            summary:  "Interactive prompt on one open database (view 42, scan 0..100, compare ./node2)",
+            examples: []string{"inspector shell -db ./data"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                var scan scanFlags
+                scan.register(fs)
+                agentToken := agentTokenFlag(fs)
+                return func() { runShell(*dbPath, *agentToken, g.out, scan.options()) }
+            },
+        },
+        {
+            name:     "healthcheck",
+            summary:  "Scan the chain tip; exit 0/1 with a one-line JSON body (probes)",
+            examples: []string{"inspector healthcheck -db ./data --tip 50"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                var scan scanFlags
+                scan.register(fs)
+                tip := fs.Int("tip", 100, "Number of heights at the chain tip to check (0 = all)")
+                return func() { runHealthcheck(*dbPath, *tip, scan.options()) }
+            },
+        },
+        {
+            name:     "agent",
+            summary:  "Serve this node's blocks so a remote compare can use -db2 agent://host:port",
+            examples: []string{"inspector agent -db ./data -addr :9090"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                addr := addrFlag(fs)
+                agentToken := agentTokenFlag(fs)
+                return func() { runAgent(*dbPath, *addr, *agentToken) }
+            },
+        },
+        {
+            name:     "serve",
+            summary:  "Serve the ChainInspector API (api/inspector.proto) over gRPC and HTTP/JSON, and live scans at /ws/scan",
+            examples: []string{"inspector serve -db ./data -addr :8080 --grpc-addr :9090"},
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                dbPath := dbFlag(fs)
+                fs.String("db1", "", "Additional database to serve")
+                fs.String("db2", "", "Additional database to serve")
+                addr := addrFlag(fs)
+                grpcAddr := fs.String("grpc-addr", ":9090", "Listen address of the gRPC service (empty to serve HTTP/JSON only)")
+                origins := fs.String("allowed-origins", "", "Comma-separated browser origins, besides this server's own, that may open /ws/scan (* for any)")
+                var scan scanFlags
+                scan.register(fs)
+                return func() {
+                    databases := []string{*dbPath}
+                    fs.Visit(func(f *flag.Flag) {
+                        if (f.Name == "db1" || f.Name == "db2") && f.Value.String() != "" {
+                            databases = append(databases, f.Value.String())
+                        }
+                    })
+                    runServe(*addr, *grpcAddr, databases, strings.Split(*origins, ","), scan.options())
+                }
+            },
+        },
+        {
+            name:    "version",
+            summary: "Print the version",
+            setup: func(fs *flag.FlagSet, g *globals) func() {
+                return func() { fmt.Printf("BHIV Chain Inspector v%s\n", version) }
+            },
+        },
+    }
+}
+
+// rootCommand returns the inspector command with every command of the
+// table below it, and the global flags they share. Help, and the
+// completion command, come from cobra.
+func rootCommand() *cobra.Command {
+    cobra.EnableCommandSorting = false
+    root := &cobra.Command{
+        Use:   "inspector",
+        Short: "BHIV Blockchain Inspector CLI",
+    }
+    g := &globals{}
+    g.register(root.PersistentFlags())
+    for _, cmd := range commands {
+        root.AddCommand(cmd.cobraCommand(g, root.PersistentFlags()))
+    }
+    return root
+}
+
+// cobraCommand returns cmd as a cobra command. Its flags are registered
+// on its own flag set; the global flags are inherited from the root.
+func (cmd *command) cobraCommand(g *globals, global *flag.FlagSet) *cobra.Command {
+    c := &cobra.Command{
+        Use:     strings.TrimSpace(cmd.name + " " + cmd.args),
+        Aliases: cmd.aliases,
+        Short:   cmd.summary,
+        Args: func(_ *cobra.Command, args []string) error {
+            if len(args) > 0 && cmd.args == "" {
+                return fmt.Errorf("%s takes no arguments, got %q", cmd.name, args)
+            }
+            return nil
+        },
+    }
+    if len(cmd.examples) > 0 {
+        c.Example = "  " + strings.Join(cmd.examples, "\n  ")
+    }
+    fs := c.Flags()
+    run := cmd.setup(fs, g)
+    for name, usage := range cmd.flagHelp {
+        // A local copy of the global flag shares its value and shadows it
+        // in this command's help.
+        f := *global.Lookup(name)
+        f.Usage = usage
+        fs.AddFlag(&f)
+    }
+    c.Run = func(*cobra.Command, []string) {
+        g.init(cmd.name)
+        run()
+    }
+    return c
+}
+
+func dbFlag(fs *flag.FlagSet) *string {
+    return fs.String("db", "./leveldb-data", "Path to LevelDB database")
+}
+
+func addrFlag(fs *flag.FlagSet) *string {
+    return fs.String("addr", ":8080", "Listen address")
+}
+
+func segmentFlag(fs *flag.FlagSet, usage string) *int {
+    return fs.Int("segment", errors.DefaultSegmentSize, usage)
+}
+
+func agentTokenFlag(fs *flag.FlagSet) *string {
+    return fs.String("agent-token", os.Getenv("INSPECTOR_AGENT_TOKEN"), "Bearer token required by/sent to remote agents (default $INSPECTOR_AGENT_TOKEN)")
+}
+
+func eventsFlag(fs *flag.FlagSet) *string {
+    return fs.String("events", "", "Publish findings and scan summaries to nats://host:4222/subject or kafka+http://proxy:8082/topic")
+}
+
+// globals are the output and diagnostics flags every command accepts.
+type globals struct {
+    json, quiet, verbose  bool
+    ascii, noColor        bool
+    format                string
+    logFormat, logLevel   string
+    otlpEndpoint          string
+    encoding, layout      string
+    compress, keyFile     string
+    sealKeyFile, journal  string
+    force, dryRun         bool
+    timeFormat, tz        string
+    chain                 string
+    reportTemplate        string
+    maxReadMBps           float64
+    maxReadsPerSec        float64
+
+    // out is built from the flags by init.
+    out errors.OutputOptions
+}
+
+func (g *globals) register(fs *flag.FlagSet) {
+    fs.BoolVar(&g.json, "json", false, "Output in JSON format")
+    fs.StringVar(&g.format, "format", "text", "Output format: "+strings.Join(errors.FormatNames(), ", "))
+    fs.BoolVarP(&g.quiet, "quiet", "q", false, "Print only a one-line summary")
+    fs.BoolVarP(&g.verbose, "verbose", "v", false, "Print per-block results and full error details")
+    fs.StringVar(&g.reportTemplate, "report-template", "", "Print the report through this Go text/template file instead of the built-in layout (scan, watch and compare)")
+    fs.BoolVar(&g.ascii, "ascii", false, "Use plain ASCII instead of box drawing characters and emoji")
+    fs.BoolVar(&g.noColor, "no-color", false, "Disable colored output (default: color when stdout is a terminal)")
+    fs.StringVar(&g.timeFormat, "time-format", "default", "Format of printed times: default (2006-01-02 15:04:05), rfc3339, unix")
+    fs.StringVar(&g.tz, "tz", "local", "Time zone of printed times and stats --timeseries periods: UTC, local, or an IANA zone such as Europe/Berlin")
+    fs.StringVar(&g.logFormat, "log-format", "text", "Diagnostic log format on stderr: text, json")
+    fs.StringVar(&g.logLevel, "log-level", "", "Diagnostic log level: debug, info, warn, error (default follows -q/-v)")
+    fs.StringVar(&g.encoding, "encoding", "json", "Encoding of stored block values: json, proto, cbor, gob, or auto to detect it per block (writes JSON)")
+    fs.StringVar(&g.compress, "compress", "none", "Compression of written block values: none, gzip, zstd (compressed values are always detected on read)")
+    fs.StringVar(&g.keyFile, "encryption-key", "", "Encrypt written block values with AES-GCM under the key in this file (hex or raw, 16/24/32 bytes) and decrypt them on read")
+    fs.StringVar(&g.sealKeyFile, "seal-key", "", "Append an HMAC seal keyed with the secret in this file to written blocks, and verify seals when scanning (class tampered_seals)")
+    fs.StringVar(&g.journal, "journal", "", "Append the old value of every key a command overwrites to this file, for undo")
+    fs.BoolVar(&g.force, "force", false, "Run destructive commands without asking for confirmation")
+    fs.BoolVar(&g.dryRun, "dry-run", false, "Write nothing: log the blocks a command would change (new databases are built in memory)")
+    fs.StringVar(&g.chain, "chain", "", "Read and write the chain stored under this namespace (keys main/block-N for --chain main) of a database holding several")
+    fs.Float64Var(&g.maxReadMBps, "max-read-mbps", 0, "Read at most this many megabytes (10^6 bytes) per second from databases, to spare a live node on the same disk (0: no limit)")
+    fs.Float64Var(&g.maxReadsPerSec, "max-reads-per-sec", 0, "Read at most this many keys per second from databases (0: no limit)")
+    fs.StringVar(&g.layout, "layout", "inspector", "Key schema of the database: inspector; geth (go-ethereum chaindata) or cometbft (CometBFT/Tendermint blockstore.db), both read-only")
+    fs.StringVar(&g.otlpEndpoint, "otlp-endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "OTLP/HTTP collector for trace spans, e.g. http://localhost:4318 (default $OTEL_EXPORTER_OTLP_ENDPOINT)")
+}
+
+// init validates the global flags of command name, builds the output
+// options and installs the logger and, if configured, the tracer.
+func (g *globals) init(name string) {
+    if g.json && g.format == errors.FormatText {
+        g.format = errors.FormatJSON
+    }
+    if g.format == "dot" {
+        if name != "compare" {
+            usageError(fmt.Errorf("--format dot is only supported by compare"))
+        }
+    } else if _, err := errors.LookupFormatter(g.format); err != nil {
+        usageError(fmt.Errorf("--format: %w", err))
+    }
+
+    timeFormat, err := errors.ParseTimeFormat(g.timeFormat, g.tz)
+    if err != nil {
+        usageError(err)
+    }
+    errors.SetTimeFormat(timeFormat)
+
+    encoding, err := db.ParseEncoding(g.encoding)
+    if err != nil {
+        usageError(err)
+    }
+    db.SetDefaultEncoding(encoding)
+    compression, err := db.ParseCompression(g.compress)
+    if err != nil {
+        usageError(err)
+    }
+    db.SetDefaultCompression(compression)
+    if g.keyFile != "" {
+        key, err := loadEncryptionKey(g.keyFile)
+        if err == nil {
+            err = db.SetDefaultEncryptionKey(key)
+        }
+        if err != nil {
+            usageError(fmt.Errorf("--encryption-key: %w", err))
+        }
+    }
+    if g.sealKeyFile != "" {
+        key, err := loadSealKey(g.sealKeyFile)
+        if err != nil {
+            usageError(fmt.Errorf("--seal-key: %w", err))
+        }
+        db.SetDefaultSealKey(key)
+    }
+    layout, err := db.ParseLayout(g.layout)
+    if err != nil {
+        usageError(err)
+    }
+    db.SetDefaultLayout(layout)
+    if g.chain != "" {
+        if err := db.ValidNamespace(g.chain); err != nil {
+            usageError(fmt.Errorf("--chain: %w", err))
+        }
+        if layout != db.LayoutInspector {
+            usageError(fmt.Errorf("--chain needs --layout inspector"))
+        }
+    }
+    db.SetDefaultNamespace(g.chain)
+    limit := db.ReadLimit{BytesPerSec: g.maxReadMBps * 1e6, ReadsPerSec: g.maxReadsPerSec}
+    if err := db.SetDefaultReadLimit(limit); err != nil {
+        usageError(fmt.Errorf("--max-read-mbps, --max-reads-per-sec: %w", err))
+    }
+    // undo reads the journal rather than writing to it.
+    if g.journal != "" && name != "undo" {
+        journal, err := db.OpenJournal(g.journal)
+        if err != nil {
+            usageError(fmt.Errorf("--journal: %w", err))
+        }
+        db.SetDefaultJournal(journal)
+    }
+    safety.force, safety.dryRun = g.force, g.dryRun
+    db.SetDefaultDryRun(g.dryRun)
+    // bench and crash-test write scratch databases of their own.
+    if name != "bench" && name != "crash-test" {
+        db.SetDefaultAuditor(newAuditor(name))
+    }
+
+    // compare --format dot prints the graph itself; the report options
+    // stay text.
+    format := g.format
+    if format == "dot" {
+        format = errors.FormatText
+    }
+    g.out = errors.OutputOptions{
+        Format: format,
+        JSON:   format == errors.FormatJSON,
+        ASCII:  g.ascii,
+        Color:  !g.noColor && errors.ColorSupported(),
+        Out:    os.Stdout,
+        Diag:   os.Stderr,
+    }
+    if g.reportTemplate != "" {
+        if name != "scan" && name != "watch" && name != "compare" {
+            usageError(fmt.Errorf("--report-template is only supported by scan, watch and compare"))
+        }
+        if g.format != errors.FormatText {
+            usageError(fmt.Errorf("--report-template replaces --json and --format; use one of them"))
+        }
+        tmpl, err := errors.ParseReportTemplate(g.reportTemplate)
+        if err != nil {
+            usageError(fmt.Errorf("--report-template: %w", err))
+        }
+        g.out.Template = tmpl
+    }
+    switch {
+    case g.quiet:
+        g.out.Verbosity = errors.VerbosityQuiet
+    case g.verbose:
+        g.out.Verbosity = errors.VerbosityVerbose
+    }
+
+    if g.logLevel == "" {
+        g.logLevel = defaultLogLevel(g.out.Verbosity)
+    }
+    logger, err := newLogger(g.out.DiagWriter(), g.logFormat, g.logLevel)
+    if err != nil {
+        usageError(err)
+    }
+    slog.SetDefault(logger)
+
+    if g.otlpEndpoint != "" {
+        service := os.Getenv("OTEL_SERVICE_NAME")
+        if service == "" {
+            service = "bhiv-chain-inspector"
+        }
+        tracing.Setup(g.otlpEndpoint, service)
+        slog.Debug("tracing enabled", "endpoint", g.otlpEndpoint, "service", service)
+    }
+}
+
+// scanFlags select and tune the checks of the commands that scan a chain.
+type scanFlags struct {
+    configPath   string
+    suppressPath string
+    checkList    string
+    pluginPaths  stringList
+    validators   string
+    chainID      string
+    hashAlg      string
+    nearDups     float64
+}
+
+func (s *scanFlags) register(fs *flag.FlagSet) {
+    fs.StringVar(&s.configPath, "config", "", "YAML or JSON config: severity, health weights, expression rules")
+    fs.StringVar(&s.suppressPath, "suppressions", "", "YAML or JSON file of accepted findings to ignore")
+    fs.StringVar(&s.checkList, "checks", "", "Comma-separated checks to run (default all): "+strings.Join(errors.CheckNames(), ","))
+    fs.Var(&s.pluginPaths, "plugin", "Go plugin (.so) providing extra checks; repeatable")
+    fs.StringVar(&s.validators, "validators", "", "YAML or JSON validator set (ed25519 keys with optional from/to epochs); every block must be signed by a key authorized at its height")
+    fs.StringVar(&s.hashAlg, "hash-algorithm", "", "Check block hashes with this algorithm (default: detect from a sample of blocks): "+blocks.JoinHashAlgorithms())
+    fs.StringVar(&s.chainID, "chain-id", "", "Chain ID every block must carry (default: the chain ID the database declares)")
+    fs.Float64Var(&s.nearDups, "near-duplicates", 0, "Also report blocks whose data is at least this similar (0-1, e.g. 0.9) to an earlier block's, class near_duplicate_data (default off)")
+}
+
+// options loads plugins, the config and suppressions into scan options.
+func (s *scanFlags) options() errors.ScanOptions {
+    for _, path := range s.pluginPaths {
+        names, err := plugins.Load(path)
+        if err != nil {
+            fatal("cannot load plugin", "err", err)
+        }
+        slog.Debug("plugin loaded", "path", path, "checks", names)
+    }
+
+    cfg, err := config.Load(s.configPath)
+    if err != nil {
+        fatal("cannot load config", "err", err)
+    }
+    ruleNames, err := rules.Register(cfg.Rules)
+    if err != nil {
+        fatal("invalid rules config", "config", s.configPath, "err", err)
+    }
+    if len(ruleNames) > 0 {
+        slog.Debug("rules loaded", "rules", ruleNames)
+    }
+    if d := cfg.Difficulty; d.RetargetInterval > 0 {
+        check, err := errors.NewRetargetCheck(errors.RetargetRule{
+            Interval:        d.RetargetInterval,
+            TargetBlockTime: d.TargetBlockTime,
+            MaxStep:         d.MaxStep,
+        })
+        if err != nil {
+            fatal("invalid difficulty config", "config", s.configPath, "err", err)
+        }
+        errors.RegisterCheck(check)
+    }
+    if s.validators != "" {
+        validators, err := errors.LoadValidators(s.validators)
+        if err != nil {
+            fatal("cannot load validators", "err", err)
+        }
+        errors.RegisterCheck(errors.NewSignatureCheck(validators))
+    }
+    severity, err := errors.ParseSeverityMap(cfg.Severity)
+    if err != nil {
+        fatal("invalid severity config", "config", s.configPath, "err", err)
+    }
+    health, err := errors.NewHealthModel(cfg.Health.Weights, cfg.Health.MaxPenaltyPerBlock)
+    if err != nil {
+        fatal("invalid health config", "config", s.configPath, "err", err)
+    }
+    // Without --checks, Checks stays nil so the scanner picks the checks
+    // that apply to the database's layout.
+    var checks []errors.Check
+    if s.checkList != "" {
+        if checks, err = errors.SelectChecks(strings.Split(s.checkList, ",")); err != nil {
+            fatal("invalid --checks", "err", err)
+        }
+    }
+    suppressions, err := errors.LoadSuppressions(s.suppressPath)
+    if err != nil {
+        fatal("cannot load suppressions", "err", err)
+    }
+    var hashAlg blocks.HashAlgorithm
+    if s.hashAlg != "" {
+        if hashAlg, err = blocks.ParseHashAlgorithm(s.hashAlg); err != nil {
+            usageError(err)
+        }
+    }
+    if s.nearDups < 0 || s.nearDups > 1 {
+        usageError(fmt.Errorf("--near-duplicates must be between 0 and 1, got %v", s.nearDups))
+    }
+    return errors.ScanOptions{
+        Severity:       severity,
+        Suppress:       suppressions,
+        Health:         &health,
+        Checks:         checks,
+        ChainID:        s.chainID,
+        HashAlgorithm:  hashAlg,
+        NearDuplicates: s.nearDups,
+    }
+}
+
+// cacheSettings identifies the files the verdicts of a scan depend on, by
+// content, for errors.VerifyCache: a changed rule or key drops the cache.
+func (s *scanFlags) cacheSettings(g *globals) string {
+    var settings strings.Builder
+    files := map[string]string{"config": s.configPath, "validators": s.validators,
+        "encryption-key": g.keyFile, "seal-key": g.sealKeyFile}
+    for i, path := range s.pluginPaths {
+        files[fmt.Sprintf("plugin%d", i)] = path
+    }
+    for _, name := range slices.Sorted(maps.Keys(files)) {
+        if files[name] == "" {
+            continue
+        }
+        data, err := os.ReadFile(files[name])
+        if err != nil {
+            fatal("cannot read "+name, "path", files[name], "err", err)
+        }
+        fmt.Fprintf(&settings, "%s=%x\n", name, sha256.Sum256(data))
+    }
+    return settings.String()
+}
+
+// usageError reports a command line mistake and exits with status 2, as
+// for unknown flags.
+func usageError(err error) {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    os.Exit(2)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+    "bufio"
+    "fmt"
+    "log/slog"
+    "os"
+    "strings"
+)
+
+// safety holds the global --force and --dry-run flags for confirm.
+var safety struct {
+    force  bool
+    dryRun bool
+}
+
+// confirm is called by destructive commands before they change anything,
+// with what they are about to change. It asks on the terminal whether to
+// go on and exits if not. --force skips the question, and so does
+// --dry-run, as nothing is written; without a terminal to ask on, the
+// command refuses to run.
+func confirm(what string) {
+    switch {
+    case safety.dryRun:
+        slog.Info("dry run: would " + what)
+        return
+    case safety.force:
+        slog.Info("forced: " + what)
+        return
+    }
+    if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+        usageError(fmt.Errorf("refusing to %s without confirmation: pass --force, or --dry-run to preview", what))
+    }
+    fmt.Fprintf(os.Stderr, "This will %s.\nContinue? [y/N] ", what)
+    answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+    switch strings.ToLower(strings.TrimSpace(answer)) {
+    case "y", "yes":
+        return
+    }
+    fmt.Fprintln(os.Stderr, "Aborted.")
+    os.Exit(1)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+    "fmt"
+    "maps"
+    "slices"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// corruptionTypes are the faults the corrupt command injects, with the
+// classes a scan should then report for the block. Faults that change a
+// hash also break the next block's link.
+var corruptionTypes = map[string][]string{
+    // badhash changes the stored hash only.
+    "badhash": {errors.ClassBadHash},
+    // brokenlink points the block at another parent and rehashes it, so
+    // its own hash stays valid.
+    "brokenlink": {errors.ClassPrevHashErrors},
+    // truncate cuts the stored value in half.
+    "truncate": {errors.ClassCorruptedJSON},
+    // futurets moves the timestamp a day ahead and rehashes the block.
+    "futurets": {errors.ClassTimestampFuture},
+}
+
+// corruptionTypeNames lists corruptionTypes for usage messages.
+func corruptionTypeNames() string {
+    return strings.Join(slices.Sorted(maps.Keys(corruptionTypes)), ", ")
+}
+
+// runCorrupt injects fault kind into the block at height, for exercising
+// and demonstrating the scanner. Rehashed blocks are re-mined at their
+// difficulty but not re-signed.
+func runCorrupt(dbPath, kind string, height int, out errors.OutputOptions) {
+    classes, ok := corruptionTypes[kind]
+    if !ok {
+        usageError(fmt.Errorf("unknown --type %q (want one of %s)", kind, corruptionTypeNames()))
+    }
+    confirm(fmt.Sprintf("inject a %s fault into block %d of %s", kind, height, dbPath))
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    if storage.Layout() != db.LayoutInspector {
+        storage.Close()
+        usageError(fmt.Errorf("corrupt writes the inspector layout, not %s", storage.Layout()))
+    }
+    fail := func(msg string, args ...any) {
+        storage.Close()
+        fatal(msg, append([]any{"db", dbPath, "height", height}, args...)...)
+    }
+
+    report := &errors.CorruptionReport{DatabasePath: dbPath, Height: height, Type: kind, ExpectedClasses: classes}
+    if kind == "truncate" {
+        raw, err := storage.LoadBlockRaw(height)
+        if err != nil {
+            fail("cannot load block", "err", err)
+        }
+        report.Before = fmt.Sprintf("%d bytes", len(raw))
+        report.After = fmt.Sprintf("%d bytes", len(raw)/2)
+        if err := storage.SaveBlockRaw(height, raw[:len(raw)/2]); err != nil {
+            fail("cannot save block", "err", err)
+        }
+        errors.OutputCorruption(report, out)
+        return
+    }
+
+    block, err := storage.LoadBlock(height)
+    if err != nil {
+        fail("cannot load block", "err", err)
+    }
+    alg := errors.DetectHashAlgorithm(storage, errors.HashSampleSize).Algorithm
+    if alg == "" {
+        alg = blocks.SHA256
+    }
+    switch kind {
+    case "badhash":
+        report.Before = block.Hash
+        block.Hash = flipHex(block.Hash)
+        report.After = block.Hash
+    case "brokenlink":
+        report.Before = block.PrevHash
+        block.PrevHash = flipHex(block.PrevHash)
+        report.After = block.PrevHash
+        hashAndSign(block, alg, nil)
+    case "futurets":
+        report.Before = errors.FormatUnix(block.Timestamp)
+        block.Timestamp = time.Now().Add(24 * time.Hour).Unix()
+        report.After = errors.FormatUnix(block.Timestamp)
+        hashAndSign(block, alg, nil)
+    }
+    if err := storage.SaveBlock(block); err != nil {
+        fail("cannot save block", "err", err)
+    }
+    errors.OutputCorruption(report, out)
+}
+
+// flipHex changes the last character of a hex string, or returns a
+// non-empty value for an empty one.
+func flipHex(s string) string {
+    if s == "" {
+        return "0"
+    }
+    last := "0"
+    if s[len(s)-1] == '0' {
+        last = "1"
+    }
+    return s[:len(s)-1] + last
+}
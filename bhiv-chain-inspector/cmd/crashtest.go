@@ -0,0 +1,176 @@
+package main
+
+import (
+    "fmt"
+    "math/rand/v2"
+    "os"
+    "os/exec"
+    "strconv"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tracing"
+)
+
+// crashWriterEnv marks the child process of crash-test that writes blocks
+// until it is killed; it holds the database directory. crashTipEnv holds
+// the tip the parent found, which saves the writer looking for it.
+const (
+    crashWriterEnv = "INSPECTOR_CRASH_WRITER"
+    crashTipEnv    = "INSPECTOR_CRASH_TIP"
+)
+
+// crashTest configures runCrashTest.
+type crashTest struct {
+    dir       string
+    rounds    int
+    batchSize int
+    sync      bool
+    // maxDelay bounds the random time a writer runs before it is killed.
+    maxDelay  time.Duration
+}
+
+// runCrashTest starts writer processes that append blocks in batches and
+// kills each one at a random moment, like a power cut to the process. After
+// every kill it reopens the database and checks that it opens, has no
+// gaps below its tip, ends on a batch boundary and scans clean. It exits 1
+// unless every round does.
+func runCrashTest(t crashTest, out errors.OutputOptions) {
+    if dir := os.Getenv(crashWriterEnv); dir != "" {
+        tip, _ := strconv.Atoi(os.Getenv(crashTipEnv))
+        runCrashWriter(dir, tip, t.batchSize, t.sync)
+        return
+    }
+    scratch := t.dir == ""
+    if scratch {
+        tmp, err := os.MkdirTemp("", "inspector-crash-")
+        if err != nil {
+            fatal("cannot create scratch directory", "err", err)
+        }
+        defer os.RemoveAll(tmp)
+        t.dir = tmp
+    } else if entries, _ := os.ReadDir(t.dir); len(entries) > 0 {
+        usageError(fmt.Errorf("crash-test writes a scratch chain; %s is not empty", t.dir))
+    }
+    self, err := os.Executable()
+    if err != nil {
+        fatal("cannot find own executable", "err", err)
+    }
+
+    report := &errors.CrashTestReport{Directory: t.dir, BatchSize: t.batchSize, Sync: t.sync, Passed: true}
+    tip := -1
+    for round := 1; round <= t.rounds; round++ {
+        child := exec.Command(self, os.Args[1:]...)
+        child.Env = append(os.Environ(), crashWriterEnv+"="+t.dir, crashTipEnv+"="+strconv.Itoa(tip))
+        child.Stderr = os.Stderr
+        if err := child.Start(); err != nil {
+            fatal("cannot start writer", "err", err)
+        }
+        delay := time.Duration(rand.Int64N(int64(t.maxDelay)))
+        exited := make(chan error, 1)
+        go func() { exited <- child.Wait() }()
+
+        select {
+        case <-exited:
+            // The writer only stops when killed.
+            if scratch {
+                os.RemoveAll(t.dir)
+            }
+            fatal("writer exited before the kill", "round", round, "exit_code", child.ProcessState.ExitCode())
+        case <-time.After(delay):
+            child.Process.Kill()
+            <-exited
+        }
+
+        result := errors.CrashRound{Round: round, KilledAfterMS: delay.Milliseconds()}
+        result.Problems = checkAfterCrash(t.dir, t.batchSize, &result.Tip)
+        result.Written = result.Tip - tip
+        tip = result.Tip
+        report.Add(result)
+    }
+
+    errors.OutputCrashTest(report, out)
+    if !report.Passed {
+        if scratch {
+            os.RemoveAll(t.dir)
+        }
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
+
+// checkAfterCrash reopens the database a writer was killed on, sets tip
+// and returns what is inconsistent about it.
+func checkAfterCrash(dir string, batchSize int, tip *int) []string {
+    storage, err := db.NewStorage(dir)
+    if err == nil {
+        if err = storage.CheckEncryptionKey(); err != nil {
+            storage.Close()
+        }
+    }
+    if err != nil {
+        *tip = -1
+        return []string{fmt.Sprintf("database does not open: %v", err)}
+    }
+    defer storage.Close()
+
+    var problems []string
+    *tip = storage.GetMaxHeight()
+    values := 0
+    if err := storage.BlockValues(func(int, []byte) error { values++; return nil }); err != nil {
+        problems = append(problems, fmt.Sprintf("cannot iterate blocks: %v", err))
+    }
+    if missing := *tip + 1 - values; missing > 0 {
+        problems = append(problems, fmt.Sprintf("%d block(s) missing below the tip", missing))
+    }
+    if (*tip+1)%batchSize != 0 {
+        problems = append(problems, fmt.Sprintf("tip %d splits a batch of %d", *tip, batchSize))
+    }
+    if result := errors.ScanErrors(storage, dir, errors.ScanOptions{}); result.TotalErrors > 0 {
+        problems = append(problems, fmt.Sprintf("scan found %d error(s)", result.TotalErrors))
+    }
+    return problems
+}
+
+// runCrashWriter appends blocks after tip to the chain in dir, one batch
+// at a time, until it is killed.
+func runCrashWriter(dir string, tip, batchSize int, sync bool) {
+    storage := openStorage(dir)
+    defer storage.Close()
+
+    prevHash := "0"
+    height := tip + 1
+    if height > 0 {
+        tip, err := storage.LoadBlock(height - 1)
+        if err != nil {
+            storage.Close()
+            fatal("cannot load tip", "db", dir, "err", err)
+        }
+        prevHash = tip.Hash
+    }
+    batch := storage.NewBatch()
+    for ; ; height++ {
+        txs := []string{"crash test transaction " + strconv.Itoa(height)}
+        block := &blocks.Block{
+            Height:     height,
+            PrevHash:   prevHash,
+            Data:       txs[0],
+            Timestamp:  sampleEpoch + int64(height),
+            MerkleRoot: blocks.MerkleRoot(txs),
+        }
+        hashAndSign(block, blocks.SHA256, nil)
+        if err := batch.SaveBlock(block); err != nil {
+            storage.Close()
+            fatal("cannot save block", "height", height, "err", err)
+        }
+        if batch.Len() == batchSize {
+            if err := batch.Write(sync); err != nil {
+                storage.Close()
+                fatal("cannot write batch", "height", height, "err", err)
+            }
+        }
+        prevHash = block.Hash
+    }
+}
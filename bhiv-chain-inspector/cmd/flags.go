@@ -0,0 +1,59 @@
+package main
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string {
+    return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+    *l = append(*l, value)
+    return nil
+}
+
+func (l *stringList) Type() string {
+    return "stringArray"
+}
+
+// byteSize is a size flag in bytes, given with an optional decimal (KB,
+// MB, GB) or binary (KiB, MiB, GiB) unit, e.g. 512MB.
+type byteSize int64
+
+var byteUnits = []struct {
+    suffix string
+    size   int64
+}{
+    {"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30},
+    {"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"B", 1},
+}
+
+func (b *byteSize) String() string {
+    return strconv.FormatInt(int64(*b), 10)
+}
+
+func (b *byteSize) Set(value string) error {
+    number, unit := strings.TrimSpace(value), int64(1)
+    for _, u := range byteUnits {
+        if n, ok := strings.CutSuffix(number, u.suffix); ok {
+            number, unit = strings.TrimSpace(n), u.size
+            break
+        }
+    }
+    n, err := strconv.ParseFloat(number, 64)
+    if err != nil || n < 0 {
+        return fmt.Errorf("invalid size %q, want e.g. 512MB or 1GiB", value)
+    }
+    *b = byteSize(n * float64(unit))
+    return nil
+}
+
+func (b *byteSize) Type() string {
+    return "size"
+}
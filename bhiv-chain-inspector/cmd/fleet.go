@@ -0,0 +1,73 @@
+package main
+
+import (
+    "fmt"
+    "log/slog"
+    "os"
+    "path/filepath"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tracing"
+)
+
+// fleetDatabases expands the --dbs glob, and any further paths the shell
+// expanded it into, to the directories they match.
+func fleetDatabases(patterns []string) []string {
+    var dirs []string
+    seen := make(map[string]bool)
+    for _, pattern := range patterns {
+        matches, err := filepath.Glob(pattern)
+        if err != nil {
+            usageError(fmt.Errorf("--dbs: %w", err))
+        }
+        for _, path := range matches {
+            if info, err := os.Stat(path); err != nil || !info.IsDir() || seen[path] {
+                continue
+            }
+            seen[path] = true
+            dirs = append(dirs, path)
+        }
+    }
+    if len(dirs) == 0 {
+        usageError(fmt.Errorf("--dbs matches no directories"))
+    }
+    return dirs
+}
+
+// runScanFleet scans every database read-only, without recording history,
+// and exits 1 unless all of them are healthy.
+func runScanFleet(paths []string, out errors.OutputOptions, opts errors.ScanOptions) {
+    var results []*errors.ErrorScanResult
+    unreadable := make(map[string]error)
+    for _, path := range paths {
+        // NewStorage creates missing inspector databases; a fleet scan
+        // must not.
+        if _, err := os.Stat(filepath.Join(path, "CURRENT")); err != nil {
+            unreadable[path] = fmt.Errorf("not a LevelDB database")
+            continue
+        }
+        storage, err := db.NewStorage(path)
+        if err == nil {
+            if err = storage.CheckEncryptionKey(); err != nil {
+                storage.Close()
+            }
+        }
+        if err != nil {
+            slog.Warn("cannot open fleet database", "db", path, "err", err)
+            unreadable[path] = err
+            continue
+        }
+        result := errors.ScanErrors(storage, path, opts)
+        storage.Close()
+        slog.Debug("fleet database scanned", "db", path, "status", result.Status, "health_score", result.HealthScore)
+        results = append(results, result)
+    }
+
+    report := errors.NewFleetReport(results, unreadable)
+    errors.OutputFleetReport(report, out)
+    if report.Failed() {
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
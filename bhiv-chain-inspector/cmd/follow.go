@@ -0,0 +1,94 @@
+package main
+
+import (
+    "context"
+    "log/slog"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/fswatch"
+)
+
+// followRetry is the first delay before a pass is retried when the
+// database is held by a writer; it doubles up to the poll interval.
+const followRetry = 50 * time.Millisecond
+
+// runFollow validates the existing chain once, then prints a verdict for
+// each appended block as soon as it is seen, like `tail -f`. Only blocks
+// arriving after startup are printed.
+//
+// The database is opened read-only for each pass and closed after it,
+// since a LevelDB database open in this process cannot be written by
+// another. With fsWatch, writes to the database directory start a pass
+// right away and poll only paces a fallback pass; read-only opens write
+// no files, so passes do not wake the watch themselves. Without it, or
+// where the directory cannot be watched, a pass runs every poll.
+func runFollow(dbPath string, poll time.Duration, fsWatch bool, out errors.OutputOptions, opts errors.ScanOptions) {
+    if poll <= 0 {
+        fatal("--poll must be positive", "poll", poll)
+    }
+    var changes <-chan struct{}
+    if fsWatch {
+        if watcher, err := fswatch.Watch(dbPath); err != nil {
+            slog.Warn("cannot watch database directory, polling instead", "db", dbPath, "err", err)
+        } else {
+            defer watcher.Close()
+            changes = watcher.Changes()
+        }
+    }
+    db.SetDefaultDryRun(true)
+    storage := openStorage(dbPath)
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    opts.OnBlock = nil
+    follower := errors.NewWatcher(storage, dbPath, opts)
+    initial := follower.Pass()
+    storage.Close()
+    slog.Info("following database", "db", dbPath, "tip", initial.TotalBlocks-1,
+        "errors", initial.TotalErrors, "poll", poll, "fs_watch", changes != nil)
+
+    follower.OnBlock(func(v errors.BlockVerdict) {
+        errors.PrintBlockVerdict(v, out)
+    })
+    ticker := time.NewTicker(poll)
+    defer ticker.Stop()
+
+    var retry <-chan time.Time
+    delay := followRetry
+    pass := func() {
+        reopened, err := db.NewStorage(dbPath)
+        if err != nil {
+            // Most likely a writer holds the database. Its writes wake the
+            // watch again, but the last of them may come before it closes,
+            // so retry; until then, further writes wait for the retry.
+            slog.Debug("cannot open database, retrying", "db", dbPath, "in", delay, "err", err)
+            retry = time.After(delay)
+            delay = min(delay*2, poll)
+            return
+        }
+        retry, delay = nil, followRetry
+        follower.SetStorage(reopened)
+        follower.Pass()
+        reopened.Close()
+    }
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-changes:
+            if retry == nil {
+                pass()
+            }
+        case <-retry:
+            pass()
+        case <-ticker.C:
+            pass()
+        }
+    }
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+    "fmt"
+    "log/slog"
+    "os"
+    "path/filepath"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// runFuzzCorpus writes every stored block value, decodable or not, as a
+// seed of the FuzzDecodeBlock target in internal/db, so that fuzzing
+// starts from values real nodes wrote.
+func runFuzzCorpus(dbPath, outDir string) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    if storage.Layout() != db.LayoutInspector {
+        storage.Close()
+        usageError(fmt.Errorf("fuzz-corpus exports the inspector layout, not %s", storage.Layout()))
+    }
+    if err := os.MkdirAll(outDir, 0o755); err != nil {
+        storage.Close()
+        fatal("cannot create corpus directory", "dir", outDir, "err", err)
+    }
+
+    count := 0
+    err := storage.BlockValues(func(height int, value []byte) error {
+        entry := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", value)
+        count++
+        return os.WriteFile(filepath.Join(outDir, fmt.Sprintf("block-%d", height)), []byte(entry), 0o644)
+    })
+    if err != nil {
+        storage.Close()
+        fatal("cannot export corpus", "db", dbPath, "dir", outDir, "err", err)
+    }
+    slog.Info("fuzz corpus written", "db", dbPath, "dir", outDir, "values", count)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+    "encoding/json"
+    "log/slog"
+    "os"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// runHeal repairs divergent blocks by majority vote across nodes, logging
+// every rewrite (or planned rewrite, with dryRun) to the audit log.
+func runHeal(nodes []string, dryRun bool, auditPath string, out errors.OutputOptions) {
+    var paths []string
+    for _, node := range nodes {
+        if node = strings.TrimSpace(node); node != "" {
+            paths = append(paths, node)
+        }
+    }
+    if len(paths) < 3 {
+        fatal("--heal needs --nodes with at least three databases for a majority", "nodes", len(paths))
+    }
+    confirm("rewrite the blocks of " + strings.Join(paths, ", ") + " that disagree with the majority")
+    // Heal writes are audited apart from the read-only comparisons.
+    db.SetDefaultAuditor(newAuditor("heal"))
+
+    var storages []*db.Storage
+    closeAll := func() {
+        for _, s := range storages {
+            s.Close()
+        }
+    }
+    defer closeAll()
+    for _, path := range paths {
+        storage, err := db.NewStorage(path)
+        if err != nil {
+            closeAll()
+            fatal("cannot open database", "db", path, "err", err)
+        }
+        storages = append(storages, storage)
+    }
+
+    audit, err := os.OpenFile(auditPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+    if err != nil {
+        closeAll()
+        fatal("cannot open heal audit log", "path", auditPath, "err", err)
+    }
+    defer audit.Close()
+    enc := json.NewEncoder(audit)
+
+    result := errors.HealNodes(storages, paths, errors.HealOptions{
+        DryRun: dryRun,
+        OnAction: func(a errors.HealAction) {
+            if err := enc.Encode(a); err != nil {
+                slog.Error("cannot write heal audit log", "path", auditPath, "err", err)
+            }
+            switch {
+            case a.Error != "":
+                slog.Error("heal write failed", "height", a.Height, "node", a.Node, "err", a.Error)
+            case a.DryRun:
+                slog.Info("would heal block", "height", a.Height, "node", a.Node)
+            default:
+                slog.Info("block healed", "height", a.Height, "node", a.Node)
+            }
+        },
+    })
+    errors.OutputHealResult(result, out)
+
+    if result.Failed > 0 || len(result.NoMajority) > 0 {
+        closeAll()
+        audit.Close()
+        os.Exit(1)
+    }
+}
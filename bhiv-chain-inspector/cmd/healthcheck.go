@@ -0,0 +1,76 @@
+package main
+
+import (
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tracing"
+)
+
+// probeResult is the body printed by healthcheck, kept small for probe
+// logs and `docker inspect`.
+type probeResult struct {
+    Status      string `json:"status"`
+    Tip         int    `json:"tip"`
+    Checked     int    `json:"checked"`
+    Errors      int    `json:"errors"`
+    HealthScore int    `json:"health_score"`
+    Reason      string `json:"reason,omitempty"`
+}
+
+// runHealthcheck scans the last tip heights and exits 0 when they are
+// free of errors, 1 otherwise. It never records scan history and always
+// prints a single JSON line, suitable for liveness/readiness probes and
+// Docker HEALTHCHECK. The checks of the whole database are left out, so
+// that a probe costs the heights it scans, and the tip is looked up once.
+// The database is opened read-only, as by follow, so that a probe never
+// creates one or takes the exclusive lock a node holds.
+func runHealthcheck(dbPath string, tip int, opts errors.ScanOptions) {
+    probe := probeResult{Status: "unhealthy", Tip: -1}
+    defer func() {
+        json.NewEncoder(os.Stdout).Encode(probe)
+        if probe.Status != "healthy" {
+            tracing.Shutdown()
+            os.Exit(1)
+        }
+    }()
+
+    for _, dir := range strings.Split(dbPath, ",") {
+        if _, err := os.Stat(filepath.Join(dir, "CURRENT")); err != nil {
+            probe.Reason = "database not found"
+            return
+        }
+    }
+    db.SetDefaultDryRun(true)
+    storage, err := db.NewStorage(dbPath)
+    if err != nil {
+        probe.Reason = err.Error()
+        return
+    }
+    defer storage.Close()
+
+    probe.Tip = storage.GetMaxHeight()
+    if probe.Tip < 0 {
+        probe.Reason = "empty database"
+        return
+    }
+    if tip > 0 {
+        opts.FromHeight = probe.Tip - tip + 1
+    }
+    opts.Tip = probe.Tip
+    opts.SkipStoreChecks = true
+    result := errors.ScanErrors(storage, dbPath, opts)
+
+    probe.Checked = result.HeightsChecked
+    probe.Errors = result.TotalErrors
+    probe.HealthScore = result.HealthScore
+    if result.TotalErrors == 0 {
+        probe.Status = "healthy"
+    } else {
+        probe.Reason = "errors in tip region"
+    }
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+    stderrors "errors"
+    "fmt"
+    "log/slog"
+    "regexp"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// runIndex builds the hash index of the database, so view --hash finds
+// blocks without decoding the whole chain. With rebuild, as reindex, it
+// first drops every entry, stale ones included.
+func runIndex(dbPath string, rebuild bool, out errors.OutputOptions) {
+    if rebuild {
+        confirm("drop the hash index of " + dbPath + " and build it again")
+    }
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    began := time.Now()
+    report := &errors.IndexReport{DatabasePath: dbPath, Rebuilt: rebuild}
+    var err error
+    if rebuild {
+        if report.Dropped, err = storage.DropHashIndex(); err != nil {
+            storage.Close()
+            fatal("cannot drop hash index", "db", dbPath, "err", err)
+        }
+    }
+    if report.Indexed, report.Skipped, err = storage.BuildHashIndex(); err != nil {
+        storage.Close()
+        fatal("cannot build hash index", "db", dbPath, "err", err)
+    }
+    report.DurationMS = time.Since(began).Milliseconds()
+    errors.OutputIndex(report, out)
+}
+
+var hexHash = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// runViewHash prints the block whose hash is hash, found through the hash
+// index, or by decoding every block when the index has not been built.
+func runViewHash(dbPath, hash string, alg blocks.HashAlgorithm, out errors.OutputOptions) {
+    if !hexHash.MatchString(hash) {
+        usageError(fmt.Errorf("bad hash %q", hash))
+    }
+    hash = strings.ToLower(hash)
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    height, err := storage.LookupHash(hash)
+    if stderrors.Is(err, db.ErrNotIndexed) {
+        slog.Warn("no hash index, decoding every block; run inspector index to build it", "db", dbPath)
+        height, err = storage.FindHash(hash)
+    }
+    if err != nil {
+        storage.Close()
+        fatal("cannot find block", "db", dbPath, "hash", hash, "err", err)
+    }
+    block, err := storage.LoadBlock(height)
+    if err != nil {
+        storage.Close()
+        fatal("cannot load block", "db", dbPath, "height", height, "err", err)
+    }
+    errors.OutputBlockView(errors.NewBlockView(block, storage, alg), out)
+}
@@ -1,127 +1,588 @@
 package main
 
 import (
-    "flag"
+    "crypto/ed25519"
+    "encoding/hex"
     "fmt"
+    "log/slog"
+    "net"
+    "net/http"
     "os"
+    "path/filepath"
+    "slices"
+    "strings"
     "time"
+    // --tz accepts IANA zones on hosts without a zoneinfo database.
+    _ "time/tzdata"
 
+    "bhiv-chain-inspector/internal/agent"
     "bhiv-chain-inspector/internal/blocks"
     "bhiv-chain-inspector/internal/db"
     "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/events"
+    "bhiv-chain-inspector/internal/server"
+    "bhiv-chain-inspector/internal/tracing"
+    "github.com/spf13/cobra"
+    "google.golang.org/grpc"
 )
 
 const version = "1.0.0"
 
 func main() {
-    dbPath := flag.String("db", "./leveldb-data", "Path to LevelDB database")
-    db1Path := flag.String("db1", "./node1-data", "Path to first database")
-    db2Path := flag.String("db2", "./node2-data", "Path to second database")
-    cmd := flag.String("cmd", "scan-errors", "Command: load, scan-errors, compare")
-    numBlocks := flag.Int("blocks", 10, "Number of blocks to load")
-    jsonOutput := flag.Bool("json", false, "Output in JSON format")
-    showVersion := flag.Bool("version", false, "Show version")
-    
-    flag.Parse()
-
-    if *showVersion {
-        fmt.Printf("BHIV Chain Inspector v%s\n", version)
-        return
+    root := rootCommand()
+    root.SetArgs(longFlags(root, commandLine(os.Args[1:])))
+    err := root.Execute()
+    tracing.Shutdown()
+    if err != nil {
+        // cobra has printed the error and the command's usage.
+        os.Exit(2)
     }
+}
 
-    switch *cmd {
-    case "load":
-        loadSampleData(*dbPath, *numBlocks)
-
-    case "scan-errors":
-        runScan(*dbPath, *jsonOutput)
+// commandLine returns the arguments naming the command first. The old form
+// "-cmd <name>" is still accepted, and a command line of only flags runs
+// scan as -cmd used to default to it.
+func commandLine(args []string) []string {
+    for i, arg := range args {
+        var name string
+        switch {
+        case arg == "-cmd" || arg == "--cmd":
+            if i+1 < len(args) {
+                name = args[i+1]
+                args = append(args[:i:i], args[i+2:]...)
+            }
+        case strings.HasPrefix(arg, "-cmd=") || strings.HasPrefix(arg, "--cmd="):
+            name = arg[strings.Index(arg, "=")+1:]
+            args = append(args[:i:i], args[i+1:]...)
+        default:
+            continue
+        }
+        fmt.Fprintf(os.Stderr, "Warning: -cmd is deprecated; use: inspector %s [flags]\n", name)
+        return append([]string{name}, args...)
+    }
 
-    case "compare":
-        runCompare(*db1Path, *db2Path, *jsonOutput)
+    if len(args) == 0 {
+        return nil
+    }
+    switch args[0] {
+    case "-h", "-help", "--help":
+        return []string{"help"}
+    case "-version", "--version":
+        return []string{"version"}
+    }
+    if strings.HasPrefix(args[0], "-") {
+        return append([]string{"scan"}, args...)
+    }
+    return args
+}
 
-    default:
-        printUsage()
+// longFlags rewrites the long flags given with a single dash, as in
+// "load -db ./data -blocks 50", to the two dashes cobra expects; "-q" and
+// "-v" stay shorthands. Everything after a "--" is left alone.
+func longFlags(root *cobra.Command, args []string) []string {
+    cmd, _, err := root.Find(args)
+    if err != nil {
+        return args
+    }
+    cmd.InitDefaultHelpFlag()
+    args = slices.Clone(args)
+    for i, arg := range args {
+        if arg == "--" {
+            break
+        }
+        if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") {
+            continue
+        }
+        name, _, _ := strings.Cut(arg[1:], "=")
+        if len(name) > 1 && (cmd.Flags().Lookup(name) != nil || cmd.InheritedFlags().Lookup(name) != nil) {
+            args[i] = "-" + arg
+        }
     }
+    return args
+}
+
+// fatal logs msg at error level and exits; used for failures that prevent
+// any report from being produced.
+func fatal(msg string, args ...any) {
+    slog.Error(msg, args...)
+    tracing.Shutdown()
+    os.Exit(1)
 }
 
-func loadSampleData(dbPath string, numBlocks int) {
+func openStorage(dbPath string) *db.Storage {
     storage, err := db.NewStorage(dbPath)
     if err != nil {
-        fmt.Printf("Error: %v\n", err)
-        os.Exit(1)
+        fatal("cannot open database", "db", dbPath, "err", err)
+    }
+    if err := storage.CheckEncryptionKey(); err != nil {
+        storage.Close()
+        fatal("cannot read database, check --encryption-key", "db", dbPath, "err", err)
+    }
+    return storage
+}
+
+// sampleTxsPerBlock is the number of transactions, one per line of Data,
+// in each sample block.
+const sampleTxsPerBlock = 3
+
+// sampleEpoch and sampleFuture replace the clock of a seeded sample
+// chain: its first block is stamped 2024-01-01 and a futurets fault moves a
+// block to 2100-01-01.
+const (
+    sampleEpoch  = 1704067200
+    sampleFuture = 4102444800
+)
+
+// sampleChain describes the chain loadSampleData writes.
+type sampleChain struct {
+    blocks     int
+    // difficulty, when positive, mines each block to this many bits.
+    difficulty int
+    // key, when set, signs each block.
+    key        ed25519.PrivateKey
+    // chainID, when set, is stamped on each block and declared.
+    chainID    string
+    hashAlg    blocks.HashAlgorithm
+    // payload renders the transactions of each block.
+    payload    *payloadGenerator
+    // faults, when set, corrupts blocks as an error profile asks.
+    faults     *faultPlan
+    // batchSize blocks are written at a time, with an fsync after each
+    // batch when sync is set.
+    batchSize  int
+    sync       bool
+    // seeded chains use the fixed sampleEpoch clock and a seeded payload,
+    // so that runs with the same flags write the same blocks.
+    seeded     bool
+}
+
+func loadSampleData(dbPath string, chain sampleChain) {
+    storage := openStorage(dbPath)
+    if tip := storage.GetMaxHeight(); tip >= 0 && chain.blocks > 0 {
+        storage.Close()
+        confirm(fmt.Sprintf("overwrite blocks 0-%d of %s, which already holds blocks up to %d", chain.blocks-1, dbPath, tip))
+        storage = openStorage(dbPath)
     }
     defer storage.Close()
 
-    fmt.Printf("Loading %d sample blocks into %s...\n", numBlocks, dbPath)
+    if chain.chainID != "" {
+        declared, err := storage.ChainID()
+        if err != nil {
+            storage.Close()
+            fatal("cannot read chain ID", "db", dbPath, "err", err)
+        }
+        if declared != "" && declared != chain.chainID {
+            storage.Close()
+            fatal("database belongs to another chain", "db", dbPath, "chain_id", declared, "requested", chain.chainID)
+        }
+        if err := storage.SetChainID(chain.chainID); err != nil {
+            storage.Close()
+            fatal("cannot declare chain ID", "db", dbPath, "err", err)
+        }
+    }
 
-    prevHash := "0"
-    for i := 0; i < numBlocks; i++ {
-        timestamp := time.Now().Unix() + int64(i*10)
-        data := fmt.Sprintf("Transaction data for block %d", i)
-        hash := blocks.ComputeHash(i, prevHash, data, timestamp)
+    slog.Info("loading sample blocks", "count", chain.blocks, "db", dbPath, "difficulty", chain.difficulty, "hash_algorithm", chain.hashAlg)
 
+    start, future := time.Now().Unix(), time.Now().Add(24*time.Hour).Unix()
+    if chain.seeded {
+        start, future = sampleEpoch, sampleFuture
+    }
+    began := time.Now()
+    batch := storage.NewBatch()
+    prevHash := "0"
+    for i := 0; i < chain.blocks; i++ {
+        timestamp := start + int64(i*10)
+        txs := make([]string, sampleTxsPerBlock)
+        for t := range txs {
+            tx, err := chain.payload.tx(i, t)
+            if err != nil {
+                storage.Close()
+                fatal("cannot render payload", "height", i, "err", err)
+            }
+            txs[t] = tx
+        }
         block := &blocks.Block{
-            Height:    i,
-            Hash:      hash,
-            PrevHash:  prevHash,
-            Data:      data,
-            Timestamp: timestamp,
+            Height:     i,
+            PrevHash:   prevHash,
+            Data:       strings.Join(txs, "\n"),
+            Timestamp:  timestamp,
+            MerkleRoot: blocks.MerkleRoot(txs),
+            Difficulty: chain.difficulty,
+            ChainID:    chain.chainID,
+        }
+        fault := ""
+        if chain.faults != nil {
+            fault = chain.faults.next(i, i == chain.blocks-1)
+        }
+        switch fault {
+        case "brokenlink":
+            block.PrevHash = flipHex(block.PrevHash)
+        case "futurets":
+            block.Timestamp = future
+        }
+        hashAndSign(block, chain.hashAlg, chain.key)
+        if fault == "badhash" {
+            block.Hash = flipHex(block.Hash)
+        }
+
+        if fault != faultMissing {
+            var err error
+            if fault == "truncate" {
+                var value []byte
+                if value, err = storage.EncodeBlock(block); err == nil {
+                    err = batch.SaveBlockRaw(i, value[:len(value)/2])
+                }
+            } else {
+                err = batch.SaveBlock(block)
+            }
+            if err != nil {
+                storage.Close()
+                fatal("cannot save block", "height", i, "err", err)
+            }
+        }
+        if batch.Len() >= chain.batchSize || i == chain.blocks-1 {
+            if err := batch.Write(chain.sync); err != nil {
+                storage.Close()
+                fatal("cannot save blocks", "height", i, "err", err)
+            }
+            if chain.batchSize > 1 {
+                slog.Debug("batch stored", "through_height", i)
+            }
         }
 
-        if err := storage.SaveBlock(block); err != nil {
-            fmt.Printf("Error saving block %d: %v\n", i, err)
-            os.Exit(1)
+        if chain.batchSize <= 1 {
+            slog.Debug("block stored", "height", i, "hash", block.Hash, "nonce", block.Nonce, "fault", fault)
         }
+        prevHash = block.Hash
+    }
 
-        fmt.Printf("✔ Block %d stored\n", i)
-        prevHash = hash
+    if chain.key != nil {
+        slog.Info("blocks signed", "public_key", hex.EncodeToString(chain.key.Public().(ed25519.PublicKey)))
     }
+    if chain.faults != nil {
+        slog.Info("faults injected", "faults", chain.faults.summary())
+    }
+    elapsed := time.Since(began)
+    slog.Info("data loading complete", "count", chain.blocks, "db", dbPath,
+        "duration", elapsed.Round(time.Millisecond), "blocks_per_sec", int(float64(chain.blocks)/max(elapsed.Seconds(), 1e-9)))
+}
 
-    fmt.Println("\nData loading complete!")
+// hashAndSign sets the hash of block with alg, mining it if it has a
+// difficulty, and signs it when key is set.
+func hashAndSign(block *blocks.Block, alg blocks.HashAlgorithm, key ed25519.PrivateKey) {
+    if block.Difficulty > 0 {
+        block.MineWith(alg)
+    } else {
+        block.Hash = block.ComputedHashWith(alg)
+    }
+    if key != nil {
+        block.Sign(key)
+    }
 }
 
-func runScan(dbPath string, jsonMode bool) {
-    storage, err := db.NewStorage(dbPath)
+// newEmitter returns an event emitter for dest, or nil when dest is empty.
+func newEmitter(dest, dbPath string) *events.Emitter {
+    if dest == "" {
+        return nil
+    }
+    pub, err := events.NewPublisher(dest)
     if err != nil {
-        fmt.Printf("Error: %v\n", err)
+        fatal("invalid --events", "err", err)
+    }
+    return events.NewEmitter(pub, dbPath)
+}
+
+// verdictPrinter returns the per-block callback for verbose text output.
+func verdictPrinter(out errors.OutputOptions) func(errors.BlockVerdict) {
+    if out.FormatName() != errors.FormatText || out.Verbosity < errors.VerbosityVerbose {
+        return nil
+    }
+    return func(v errors.BlockVerdict) {
+        errors.PrintBlockVerdict(v, out)
+    }
+}
+
+// capVerdicts passes verdicts to onBlock with at most max issues of each
+// class in all; a block whose issues were all dropped is not passed on,
+// so it does not print as OK. A max of 0 passes everything.
+func capVerdicts(onBlock func(errors.BlockVerdict), max int) func(errors.BlockVerdict) {
+    if onBlock == nil || max <= 0 {
+        return onBlock
+    }
+    printed := make(map[string]int)
+    return func(v errors.BlockVerdict) {
+        if len(v.Issues) == 0 {
+            onBlock(v)
+            return
+        }
+        var issues []errors.Issue
+        for _, issue := range v.Issues {
+            if printed[issue.Class] < max {
+                printed[issue.Class]++
+                issues = append(issues, issue)
+            }
+        }
+        if len(issues) > 0 {
+            onBlock(errors.BlockVerdict{Height: v.Height, Issues: issues})
+        }
+    }
+}
+
+// runScan scans dbPath and exits non-zero unless the chain is healthy.
+// Findings downgraded to warning or info do not affect the exit code. With
+// a baseline, only new errors (regressions) fail the run.
+// maxPerType caps the findings of each class printed (see Summarize).
+func runScan(dbPath, baselinePath string, recordHistory bool, maxPerType int, emitter *events.Emitter, signer *reportSigner, out errors.OutputOptions, opts errors.ScanOptions) {
+    var baseline *errors.ErrorScanResult
+    if baselinePath != "" {
+        var err error
+        if baseline, err = errors.LoadScanResult(baselinePath); err != nil {
+            fatal("cannot load baseline", "err", err)
+        }
+    }
+
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    start := time.Now()
+    slog.Debug("scan started", "db", dbPath)
+    opts.OnBlock = capVerdicts(verdictPrinter(out), maxPerType)
+    if emitter != nil {
+        opts.OnBlock = emitter.OnBlock(opts.OnBlock)
+    }
+    result := errors.ScanErrors(storage, dbPath, opts)
+    slog.Debug("scan finished", "db", dbPath, "blocks", result.BlocksScanned,
+        "errors", result.TotalErrors, "duration", time.Since(start))
+    if opts.Spill != nil {
+        if err := opts.Spill.Close(); err != nil {
+            slog.Warn("cannot write findings beyond the memory limit", "err", err)
+        }
+    }
+    checkpoint, err := storage.LoadCheckpoint()
+    if err != nil {
+        slog.Warn("cannot read checkpoint", "db", dbPath, "err", err)
+    }
+    if result.Reorg = errors.DetectReorg(storage, checkpoint); result.Reorg != nil {
+        slog.Warn("chain reorganised since the last checkpoint", "db", dbPath,
+            "depth", result.Reorg.Depth, "fork_height", result.Reorg.ForkHeight,
+            "old_tip", result.Reorg.OldTip.Height, "new_tip", result.Reorg.NewTip.Height)
+    }
+    // Databases of foreign layouts are read-only; history and checkpoints
+    // are only kept alongside the inspector's own metadata keys.
+    if recordHistory && storage.Layout() == db.LayoutInspector {
+        if err := storage.AppendScanHistory(result.HistoryEntry(start)); err != nil {
+            slog.Warn("cannot record scan history", "db", dbPath, "err", err)
+        }
+        if cp := errors.NewCheckpoint(storage, result.TotalBlocks-1); cp != nil {
+            if err := storage.SaveCheckpoint(cp); err != nil {
+                slog.Warn("cannot save checkpoint", "db", dbPath, "err", err)
+            }
+        }
+    }
+    if emitter != nil {
+        if err := emitter.Emit(result, start); err != nil {
+            slog.Warn("cannot publish events", "err", err)
+        }
+    }
+    if baseline != nil {
+        result.Baseline = errors.DiffAgainstBaseline(result, baseline, baselinePath)
+    }
+    result.Summarize(maxPerType)
+    signer.print(out, func(out errors.OutputOptions) { errors.OutputScanResult(result, out) })
+
+    failed := result.Status != "HEALTHY"
+    if result.Baseline != nil {
+        failed = result.Baseline.NewErrors() > 0
+    }
+    if failed {
+        storage.Close()
+        tracing.Shutdown()
         os.Exit(1)
     }
+}
+
+func runTrend(dbPath string, last int, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
     defer storage.Close()
 
-    result := errors.ScanErrors(storage, dbPath)
-    errors.OutputScanResult(result, jsonMode)
+    entries, err := storage.ScanHistory()
+    if err != nil {
+        storage.Close()
+        fatal("cannot read scan history", "db", dbPath, "err", err)
+    }
+    if last > 0 {
+        entries = errors.LastScans(entries, last)
+    }
+    errors.OutputTrend(entries, dbPath, out)
+}
+
+// runCompare compares two chains; with dot it prints the fork as a
+// Graphviz graph instead of the summary.
+func runCompare(db1Path, db2Path, chain2, agentToken string, segmentSize int, bisect, showDiff, dot bool, signer *reportSigner, out errors.OutputOptions) {
+    reader1, close1 := openReader(db1Path, agentToken)
+    defer close1()
+    reader2, close2 := openChainReader(reader1, db1Path, db2Path, chain2, agentToken)
+    defer close2()
+
+    start := time.Now()
+    slog.Debug("comparison started", "node1", db1Path, "node2", db2Path)
+    onBlock := verdictPrinter(out)
+    if dot {
+        onBlock = nil
+    }
+    result := errors.CompareNodes(reader1, reader2, db1Path, db2Path, errors.CompareOptions{
+        OnBlock:     onBlock,
+        SegmentSize: segmentSize,
+        Bisect:      bisect,
+        ShowDiff:    showDiff,
+    })
+    for _, r := range []db.BlockReader{reader1, reader2} {
+        if c, ok := r.(*agent.Client); ok && c.Err() != nil {
+            close1()
+            close2()
+            fatal("remote agent failed", "err", c.Err())
+        }
+    }
+    slog.Debug("comparison finished", "matching", result.MatchingBlocks,
+        "mismatched", len(result.MismatchedBlocks), "duration", time.Since(start))
+    if dot {
+        errors.OutputForkDOT(result, reader1, reader2, out)
+        return
+    }
+    signer.print(out, func(out errors.OutputOptions) { errors.OutputComparisonResult(result, out) })
 }
 
-func runCompare(db1Path, db2Path string, jsonMode bool) {
-    storage1, err := db.NewStorage(db1Path)
+func runCompare3(basePath, leftPath, rightPath, agentToken string, segmentSize int, out errors.OutputOptions) {
+    base, closeBase := openReader(basePath, agentToken)
+    defer closeBase()
+    left, closeLeft := openReader(leftPath, agentToken)
+    defer closeLeft()
+    right, closeRight := openReader(rightPath, agentToken)
+    defer closeRight()
+
+    start := time.Now()
+    slog.Debug("three-way comparison started", "base", basePath, "left", leftPath, "right", rightPath)
+    result := errors.CompareThreeWay(base, left, right, basePath, leftPath, rightPath, errors.CompareOptions{
+        OnBlock:     verdictPrinter(out),
+        SegmentSize: segmentSize,
+    })
+    for _, r := range []db.BlockReader{base, left, right} {
+        if c, ok := r.(*agent.Client); ok && c.Err() != nil {
+            closeBase()
+            closeLeft()
+            closeRight()
+            fatal("remote agent failed", "err", c.Err())
+        }
+    }
+    slog.Debug("three-way comparison finished", "unchanged", result.Unchanged,
+        "diverged", len(result.Heights), "duration", time.Since(start))
+    errors.OutputThreeWayResult(result, out)
+}
+
+func runCommonAncestor(db1Path, db2Path, agentToken string, segmentSize int, out errors.OutputOptions) {
+    reader1, close1 := openReader(db1Path, agentToken)
+    defer close1()
+    reader2, close2 := openReader(db2Path, agentToken)
+    defer close2()
+
+    result := errors.FindCommonAncestor(reader1, reader2, db1Path, db2Path, segmentSize)
+    for _, r := range []db.BlockReader{reader1, reader2} {
+        if c, ok := r.(*agent.Client); ok && c.Err() != nil {
+            close1()
+            close2()
+            fatal("remote agent failed", "err", c.Err())
+        }
+    }
+    slog.Debug("common ancestor found", "height", result.Height, "probes", result.Probes)
+    errors.OutputCommonAncestor(result, out)
+}
+
+func runFingerprint(dbPath string, segmentSize int, agentToken string, out errors.OutputOptions) {
+    reader, closeReader := openReader(dbPath, agentToken)
+    defer closeReader()
+
+    fp, err := errors.FingerprintOf(reader, segmentSize)
     if err != nil {
-        fmt.Printf("Error opening Node1: %v\n", err)
-        os.Exit(1)
+        closeReader()
+        fatal("cannot fingerprint chain", "db", dbPath, "err", err)
+    }
+    errors.OutputFingerprint(fp, dbPath, out)
+}
+
+// openReader opens a local database, or connects to a remote agent when
+// path starts with agent://.
+func openReader(path, agentToken string) (db.BlockReader, func()) {
+    if strings.HasPrefix(path, agent.Scheme) {
+        return agent.Dial(path, agentToken), func() {}
     }
-    defer storage1.Close()
+    storage := openStorage(path)
+    return storage, func() { storage.Close() }
+}
 
-    storage2, err := db.NewStorage(db2Path)
+// openChainReader opens db2Path for compare on the chain namespace
+// chain2. When db1Path is the same database on another chain, its storage
+// is shared, as leveldb cannot open a database twice.
+func openChainReader(reader1 db.BlockReader, db1Path, db2Path, chain2, agentToken string) (db.BlockReader, func()) {
+    if chain2 == db.DefaultNamespace() {
+        return openReader(db2Path, agentToken)
+    }
+    if strings.HasPrefix(db2Path, agent.Scheme) {
+        usageError(fmt.Errorf("--chain2 applies to local databases; an agent serves the chain it was started with"))
+    }
+    if storage1, ok := reader1.(*db.Storage); ok && filepath.Clean(db1Path) == filepath.Clean(db2Path) {
+        view, err := storage1.WithNamespace(chain2)
+        if err != nil {
+            usageError(fmt.Errorf("--chain2: %w", err))
+        }
+        return view, func() {}
+    }
+    storage := openStorage(db2Path)
+    view, err := storage.WithNamespace(chain2)
     if err != nil {
-        fmt.Printf("Error opening Node2: %v\n", err)
-        os.Exit(1)
+        storage.Close()
+        usageError(fmt.Errorf("--chain2: %w", err))
+    }
+    return view, func() { storage.Close() }
+}
+
+// runAgent serves dbPath to remote inspectors until the process is stopped.
+func runAgent(dbPath, addr, token string) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    if token == "" {
+        slog.Warn("agent has no token; anyone who can reach it can read the chain", "addr", addr)
+    }
+    slog.Info("serving chain to remote inspectors", "db", dbPath, "addr", addr)
+    if err := http.ListenAndServe(addr, agent.Handler(storage, token)); err != nil {
+        storage.Close()
+        fatal("agent stopped", "err", err)
+    }
+}
+
+// runServe serves the ChainInspector API for the given databases until the
+// process is stopped.
+func runServe(addr, grpcAddr string, databases, origins []string, opts errors.ScanOptions) {
+    srv := server.New(databases, opts)
+    for _, origin := range origins {
+        if origin = strings.TrimSpace(origin); origin != "" {
+            srv.AllowedOrigins = append(srv.AllowedOrigins, origin)
+        }
+    }
+    if grpcAddr != "" {
+        lis, err := net.Listen("tcp", grpcAddr)
+        if err != nil {
+            fatal("cannot listen for gRPC", "addr", grpcAddr, "err", err)
+        }
+        g := grpc.NewServer()
+        srv.RegisterGRPC(g)
+        go func() {
+            if err := g.Serve(lis); err != nil {
+                fatal("gRPC server stopped", "err", err)
+            }
+        }()
+    }
+    slog.Info("serving ChainInspector", "addr", addr, "prefix", server.PathPrefix, "grpc_addr", grpcAddr, "databases", srv.Databases)
+    if err := http.ListenAndServe(addr, srv.Handler()); err != nil {
+        fatal("server stopped", "err", err)
     }
-    defer storage2.Close()
-
-    result := errors.CompareNodes(storage1, storage2, db1Path, db2Path)
-    errors.OutputComparisonResult(result, jsonMode)
-}
-
-func printUsage() {
-    fmt.Println("\nBHIV Blockchain Inspector CLI")
-    fmt.Println("\nUsage:")
-    fmt.Println("  inspector -cmd <command> [options]")
-    fmt.Println("\nCommands:")
-    fmt.Println("  load        Load sample blockchain data")
-    fmt.Println("  scan-errors Scan blockchain for errors")
-    fmt.Println("  compare     Compare two blockchain nodes")
-    fmt.Println("\nExamples:")
-    fmt.Println("  inspector -cmd load -db ./data -blocks 50")
-    fmt.Println("  inspector -cmd scan-errors -db ./data")
-    fmt.Println("  inspector -cmd scan-errors -db ./data --json")
-    fmt.Println("  inspector -cmd compare -db1 ./node1 -db2 ./node2")
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+    "crypto/ed25519"
+    "crypto/x509"
+    "encoding/hex"
+    "encoding/pem"
+    "fmt"
+    "os"
+    "strings"
+)
+
+// loadSigningKey reads an ed25519 private key: PKCS#8 PEM as written by
+// "openssl genpkey -algorithm ed25519", or a hex-encoded 32-byte seed.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    if block, _ := pem.Decode(data); block != nil {
+        parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", path, err)
+        }
+        key, ok := parsed.(ed25519.PrivateKey)
+        if !ok {
+            return nil, fmt.Errorf("%s: not an ed25519 key (%T)", path, parsed)
+        }
+        return key, nil
+    }
+    seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+    if err != nil || len(seed) != ed25519.SeedSize {
+        return nil, fmt.Errorf("%s: want PKCS#8 PEM or a %d-byte hex seed", path, ed25519.SeedSize)
+    }
+    return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// loadPublicKey reads an ed25519 public key: PKIX PEM as written by
+// "openssl pkey -pubout", or 32 hex-encoded bytes. A PKCS#8 private key
+// stands for its public key.
+func loadPublicKey(path string) (ed25519.PublicKey, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    if block, _ := pem.Decode(data); block != nil && block.Type == "PRIVATE KEY" {
+        key, err := loadSigningKey(path)
+        if err != nil {
+            return nil, err
+        }
+        return key.Public().(ed25519.PublicKey), nil
+    }
+    if block, _ := pem.Decode(data); block != nil {
+        parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", path, err)
+        }
+        key, ok := parsed.(ed25519.PublicKey)
+        if !ok {
+            return nil, fmt.Errorf("%s: not an ed25519 key (%T)", path, parsed)
+        }
+        return key, nil
+    }
+    key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+    if err != nil || len(key) != ed25519.PublicKeySize {
+        return nil, fmt.Errorf("%s: want PKIX PEM or a %d-byte hex public key", path, ed25519.PublicKeySize)
+    }
+    return key, nil
+}
+
+// loadEncryptionKey reads an AES key: 16, 24 or 32 bytes, hex-encoded as
+// written by "openssl rand -hex 32", or raw.
+func loadEncryptionKey(path string) ([]byte, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+    if err != nil {
+        key = data
+    }
+    switch len(key) {
+    case 16, 24, 32:
+        return key, nil
+    }
+    return nil, fmt.Errorf("%s: want a 16, 24 or 32-byte AES key, raw or hex", path)
+}
+
+// loadSealKey reads the secret blocks are sealed with: hex, or the raw
+// bytes of the file. It must be at least 16 bytes.
+func loadSealKey(path string) ([]byte, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+    if err != nil {
+        key = data
+    }
+    if len(key) < 16 {
+        return nil, fmt.Errorf("%s: want a secret of at least 16 bytes, raw or hex", path)
+    }
+    return key, nil
+}
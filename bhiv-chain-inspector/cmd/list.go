@@ -0,0 +1,41 @@
+package main
+
+import (
+    "fmt"
+
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// defaultListLast is the number of blocks list shows without a range.
+const defaultListLast = 20
+
+// runList prints the blocks from from to to, or the last blocks up to the
+// tip. Negative from and to are unset: from defaults to 0 and to to the
+// tip, and with neither set list shows the last defaultListLast blocks.
+func runList(dbPath string, from, to, last int, out errors.OutputOptions) {
+    if last > 0 && (from >= 0 || to >= 0) {
+        usageError(fmt.Errorf("--last cannot be combined with --from or --to"))
+    }
+    if from >= 0 && to >= 0 && from > to {
+        usageError(fmt.Errorf("--from %d is past --to %d", from, to))
+    }
+    if last == 0 && from < 0 && to < 0 {
+        last = defaultListLast
+    }
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    if to < 0 || last > 0 {
+        tip := storage.GetMaxHeight()
+        if tip < 0 {
+            storage.Close()
+            fatal("database holds no blocks", "db", dbPath)
+        }
+        to = tip
+    }
+    if last > 0 {
+        from = max(to-last+1, storage.FirstHeight())
+    }
+    from = max(from, storage.FirstHeight())
+    errors.OutputBlockList(errors.ListBlocks(storage, dbPath, from, to), out)
+}
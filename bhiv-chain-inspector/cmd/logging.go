@@ -0,0 +1,42 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "log/slog"
+    "strings"
+
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// newLogger builds the diagnostic logger. Diagnostics always go to w
+// (stderr) so that reports on stdout can be piped separately.
+func newLogger(w io.Writer, format, level string) (*slog.Logger, error) {
+    var lvl slog.Level
+    if err := lvl.UnmarshalText([]byte(level)); err != nil {
+        return nil, fmt.Errorf("invalid log level %q: use debug, info, warn or error", level)
+    }
+
+    opts := &slog.HandlerOptions{Level: lvl}
+    switch strings.ToLower(format) {
+    case "text":
+        return slog.New(slog.NewTextHandler(w, opts)), nil
+    case "json":
+        return slog.New(slog.NewJSONHandler(w, opts)), nil
+    default:
+        return nil, fmt.Errorf("invalid log format %q: use text or json", format)
+    }
+}
+
+// defaultLogLevel picks a log level matching the output verbosity when
+// --log-level was not given explicitly.
+func defaultLogLevel(verbosity errors.Verbosity) string {
+    switch {
+    case verbosity <= errors.VerbosityQuiet:
+        return "warn"
+    case verbosity >= errors.VerbosityVerbose:
+        return "debug"
+    default:
+        return "info"
+    }
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+    "encoding/hex"
+    "fmt"
+    "maps"
+    "math/rand/v2"
+    "os"
+    "slices"
+    "strings"
+    "text/template"
+)
+
+// payloadTemplates are the built-in --payload templates. Each renders one
+// transaction, a line of a sample block's Data.
+var payloadTemplates = map[string]string{
+    "text":     `Transaction data for block {{.Height}} tx {{.Index}}`,
+    "transfer": `transfer from={{address}} to={{address}} amount={{amount 1000}} fee={{amount 1}} nonce={{int 1000000}}`,
+    "json":     `{"type":"{{pick "transfer" "stake" "swap"}}","from":"{{address}}","to":"{{address}}","amount":{{amount 1000}},"nonce":{{int 1000000}}}`,
+}
+
+// payloadTemplateNames lists payloadTemplates for usage messages.
+func payloadTemplateNames() string {
+    return strings.Join(slices.Sorted(maps.Keys(payloadTemplates)), ", ")
+}
+
+// payloadGenerator renders the transactions of sample blocks from a
+// text/template. Besides .Height and .Index, templates can call:
+//
+//  address     a random 20-byte hex address
+//  hash        a random 32-byte hex hash
+//  amount MAX  a random amount below MAX, with two decimals
+//  int N       a random integer in [0, N)
+//  pick A B..  one of its arguments
+type payloadGenerator struct {
+    tmpl *template.Template
+    rng  *rand.Rand
+}
+
+// newPayloadGenerator parses spec, a payloadTemplates name or a template
+// file, drawing random values from seed.
+func newPayloadGenerator(spec string, seed uint64) (*payloadGenerator, error) {
+    text, ok := payloadTemplates[spec]
+    if !ok {
+        data, err := os.ReadFile(spec)
+        if err != nil {
+            return nil, fmt.Errorf("--payload %q is neither a file nor one of %s", spec, payloadTemplateNames())
+        }
+        text = strings.TrimSpace(string(data))
+    }
+    p := &payloadGenerator{rng: rand.New(rand.NewPCG(seed, 1))}
+    randomHex := func(n int) string {
+        b := make([]byte, n)
+        for i := range b {
+            b[i] = byte(p.rng.Uint32())
+        }
+        return hex.EncodeToString(b)
+    }
+    funcs := template.FuncMap{
+        "address": func() string { return "0x" + randomHex(20) },
+        "hash":    func() string { return randomHex(32) },
+        "amount":  func(limit float64) string { return fmt.Sprintf("%.2f", p.rng.Float64()*limit) },
+        "int":     func(n int) int { return p.rng.IntN(max(n, 1)) },
+        "pick":    func(choices ...string) string { return choices[p.rng.IntN(len(choices))] },
+    }
+    tmpl, err := template.New(spec).Funcs(funcs).Parse(text)
+    if err != nil {
+        return nil, err
+    }
+    p.tmpl = tmpl
+    return p, nil
+}
+
+// tx renders transaction index of the block at height. Transactions are
+// lines of Data, so the template must render a single line.
+func (p *payloadGenerator) tx(height, index int) (string, error) {
+    var b strings.Builder
+    err := p.tmpl.Execute(&b, struct{ Height, Index int }{height, index})
+    if err != nil {
+        return "", err
+    }
+    if strings.Contains(b.String(), "\n") {
+        return "", fmt.Errorf("template %s renders more than one line", p.tmpl.Name())
+    }
+    return b.String(), nil
+}
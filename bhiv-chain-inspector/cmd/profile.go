@@ -0,0 +1,79 @@
+package main
+
+import (
+    "fmt"
+    "maps"
+    "math/rand/v2"
+    "slices"
+    "strings"
+
+    "bhiv-chain-inspector/internal/config"
+)
+
+// faultMissing is the profile fault that leaves a height unwritten; the
+// others are corruptionTypes.
+const faultMissing = "missing"
+
+// faultPlan picks the fault, if any, of each sample block from an error
+// profile. Faults are drawn in name order from a generator seeded by the
+// profile, so a profile always corrupts the same heights.
+type faultPlan struct {
+    rng     *rand.Rand
+    names   []string
+    limits  []float64 // cumulative percentages, in names order
+    // heights lists the heights each fault was injected at.
+    heights map[string][]int
+}
+
+// newFaultPlan loads the error profile at path.
+func newFaultPlan(path string) (*faultPlan, error) {
+    profile, err := config.LoadErrorProfile(path)
+    if err != nil {
+        return nil, err
+    }
+    plan := &faultPlan{
+        rng:     rand.New(rand.NewPCG(profile.Seed, profile.Seed)),
+        heights: make(map[string][]int),
+    }
+    total := 0.0
+    for _, name := range slices.Sorted(maps.Keys(profile.Faults)) {
+        if _, ok := corruptionTypes[name]; !ok && name != faultMissing {
+            return nil, fmt.Errorf("%s: unknown fault %q (want one of %s, %s)", path, name, corruptionTypeNames(), faultMissing)
+        }
+        total += profile.Faults[name]
+        plan.names = append(plan.names, name)
+        plan.limits = append(plan.limits, total)
+    }
+    return plan, nil
+}
+
+// reseed restarts the plan from seed, in place of the profile's.
+func (p *faultPlan) reseed(seed uint64) {
+    p.rng = rand.New(rand.NewPCG(seed, seed))
+}
+
+// next returns the fault of the block at height, or "" to leave it
+// intact. The last block is never left missing: the tip is the highest
+// stored block, so the chain would just end lower.
+func (p *faultPlan) next(height int, last bool) string {
+    draw := p.rng.Float64() * 100
+    for i, limit := range p.limits {
+        if draw < limit {
+            if last && p.names[i] == faultMissing {
+                return ""
+            }
+            p.heights[p.names[i]] = append(p.heights[p.names[i]], height)
+            return p.names[i]
+        }
+    }
+    return ""
+}
+
+// summary lists the faults injected, for logging.
+func (p *faultPlan) summary() string {
+    var parts []string
+    for _, name := range p.names {
+        parts = append(parts, fmt.Sprintf("%s=%d", name, len(p.heights[name])))
+    }
+    return strings.Join(parts, " ")
+}
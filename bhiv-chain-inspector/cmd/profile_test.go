@@ -0,0 +1,85 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "slices"
+    "testing"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// TestErrorProfileScan loads a profiled chain and checks that a scan
+// reports every fault the profile injected, missing heights included.
+func TestErrorProfileScan(t *testing.T) {
+    dir := t.TempDir()
+    profile := filepath.Join(dir, "profile.yaml")
+    text := "seed: 42\nfaults:\n  missing: 20\n  badhash: 2\n  brokenlink: 2\n  truncate: 1\n  futurets: 1\n"
+    if err := os.WriteFile(profile, []byte(text), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    plan, err := newFaultPlan(profile)
+    if err != nil {
+        t.Fatal(err)
+    }
+    payload, err := newPayloadGenerator("text", 42)
+    if err != nil {
+        t.Fatal(err)
+    }
+    dbPath := filepath.Join(dir, "chain")
+    const numBlocks = 2000
+    loadSampleData(dbPath, sampleChain{blocks: numBlocks, hashAlg: "sha256", payload: payload,
+        faults: plan, batchSize: 100, seeded: true})
+
+    storage, err := db.NewStorage(dbPath)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer storage.Close()
+    if tip := storage.GetMaxHeight(); tip != numBlocks-1 {
+        t.Fatalf("tip %d, want %d", tip, numBlocks-1)
+    }
+    result := errors.ScanErrors(storage, dbPath, errors.ScanOptions{})
+
+    if len(plan.heights[faultMissing]) < numBlocks/10 {
+        t.Fatalf("profile left only %d heights missing", len(plan.heights[faultMissing]))
+    }
+    // A broken link is only seen when the height below decodes: there is
+    // no parent to link to otherwise.
+    unreadable := append(slices.Clone(plan.heights[faultMissing]), plan.heights["truncate"]...)
+    var brokenLinks []int
+    for _, h := range plan.heights["brokenlink"] {
+        if h == 0 || !slices.Contains(unreadable, h-1) {
+            brokenLinks = append(brokenLinks, h)
+        }
+    }
+
+    tests := []struct {
+        class string
+        got   []int
+        want  []int
+    }{
+        {errors.ClassMissingBlocks, result.MissingBlocks, plan.heights[faultMissing]},
+        {errors.ClassBadHash, entryHeights(result.BadHash), plan.heights["badhash"]},
+        {errors.ClassPrevHashErrors, entryHeights(result.PrevHashErrors), brokenLinks},
+        {errors.ClassCorruptedJSON, entryHeights(result.CorruptedJSON), plan.heights["truncate"]},
+        {errors.ClassTimestampFuture, entryHeights(result.TimestampFuture), plan.heights["futurets"]},
+    }
+    for _, tt := range tests {
+        if !slices.Equal(tt.got, tt.want) {
+            t.Errorf("%s: scan reported %d heights %v, profile injected %d at %v", tt.class, len(tt.got), tt.got, len(tt.want), tt.want)
+        }
+    }
+    if want := numBlocks - len(plan.heights[faultMissing]) - len(plan.heights["truncate"]); result.BlocksScanned != want {
+        t.Errorf("scanned %d blocks, want %d", result.BlocksScanned, want)
+    }
+}
+
+func entryHeights(entries []errors.ErrorEntry) []int {
+    heights := []int{}
+    for _, e := range entries {
+        heights = append(heights, e.Height)
+    }
+    return heights
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+    "log/slog"
+    "os"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tracing"
+)
+
+func runProve(dbPath string, height int, tx string, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    proof, err := errors.BuildInclusionProof(storage, height, tx)
+    if err != nil {
+        storage.Close()
+        fatal("cannot build proof", "db", dbPath, "height", height, "err", err)
+    }
+    errors.OutputInclusionProof(proof, out)
+}
+
+// runVerifyProof checks a proof against the header of the proven block in
+// dbPath, or only against itself when dbPath is empty, and exits 1 when it
+// does not hold.
+func runVerifyProof(proofPath, dbPath string, out errors.OutputOptions) {
+    proof, err := errors.LoadInclusionProof(proofPath)
+    if err != nil {
+        fatal("cannot load proof", "err", err)
+    }
+
+    var header *blocks.Block
+    if dbPath == "" {
+        slog.Warn("no -db given; checking the proof against its own Merkle root only")
+    } else {
+        storage := openStorage(dbPath)
+        header, err = storage.LoadBlock(proof.Height)
+        storage.Close()
+        if err != nil {
+            fatal("cannot load block header", "db", dbPath, "height", proof.Height, "err", err)
+        }
+    }
+
+    v := errors.VerifyInclusionProof(proof, header)
+    errors.OutputProofVerification(v, out)
+    if !v.Valid {
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
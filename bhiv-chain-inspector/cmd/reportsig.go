@@ -0,0 +1,97 @@
+package main
+
+import (
+    "bytes"
+    "crypto/ed25519"
+    "fmt"
+    "os"
+
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tracing"
+    flag "github.com/spf13/pflag"
+)
+
+// signFlags are the flags of the commands whose JSON reports can be
+// signed.
+type signFlags struct {
+    keyFile string
+    sigPath string
+}
+
+func (s *signFlags) register(fs *flag.FlagSet) {
+    fs.StringVar(&s.keyFile, "sign-key", "", "Sign the --json report with this ed25519 key (PKCS#8 PEM, or hex seed), writing a detached signature to --signature")
+    fs.StringVar(&s.sigPath, "signature", "report.sig", "File the detached signature of the report is written to (--sign-key)")
+}
+
+// signer loads the signing key, or returns nil without --sign-key.
+func (s *signFlags) signer(out errors.OutputOptions) *reportSigner {
+    if s.keyFile == "" {
+        return nil
+    }
+    if !out.JSON {
+        usageError(fmt.Errorf("--sign-key signs JSON reports; add --json"))
+    }
+    key, err := loadSigningKey(s.keyFile)
+    if err != nil {
+        usageError(fmt.Errorf("--sign-key: %w", err))
+    }
+    return &reportSigner{key: key, path: s.sigPath}
+}
+
+// reportSigner signs the exact bytes of a printed report.
+type reportSigner struct {
+    key  ed25519.PrivateKey
+    path string
+}
+
+// print prints a report through output and, when r is not nil, writes the
+// signature of the bytes it printed.
+func (r *reportSigner) print(out errors.OutputOptions, output func(errors.OutputOptions)) {
+    if r == nil {
+        output(out)
+        return
+    }
+    var report bytes.Buffer
+    w := out.Writer()
+    out.Out = &report
+    output(out)
+    if _, err := w.Write(report.Bytes()); err != nil {
+        fatal("cannot write report", "err", err)
+    }
+    if err := errors.WriteReportSignature(r.path, errors.SignReport(report.Bytes(), r.key)); err != nil {
+        fatal("cannot write report signature", "path", r.path, "err", err)
+    }
+}
+
+// runVerifyReport checks a report against its detached signature and
+// exits 1 unless it is valid. With a public key file the signer must be
+// that key.
+func runVerifyReport(reportPath, sigPath, publicKeyFile string, out errors.OutputOptions) {
+    report, err := os.ReadFile(reportPath)
+    if err != nil {
+        fatal("cannot read report", "err", err)
+    }
+    sig, err := errors.LoadReportSignature(sigPath)
+    if err != nil {
+        fatal("cannot read signature", "err", err)
+    }
+    var trusted ed25519.PublicKey
+    if publicKeyFile != "" {
+        if trusted, err = loadPublicKey(publicKeyFile); err != nil {
+            usageError(fmt.Errorf("--public-key: %w", err))
+        }
+    }
+
+    v := &errors.ReportVerification{Report: reportPath, Signature: sigPath,
+        PublicKey: sig.PublicKey, Trusted: trusted != nil}
+    if err := errors.CheckReportSignature(report, sig, trusted); err != nil {
+        v.Error = err.Error()
+    } else {
+        v.Valid = true
+    }
+    errors.OutputReportVerification(v, out)
+    if !v.Valid {
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
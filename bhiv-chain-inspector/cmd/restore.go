@@ -0,0 +1,105 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "time"
+
+    "bhiv-chain-inspector/internal/backup"
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tracing"
+)
+
+// restoreBatchSize is the number of block values restore writes at once.
+const restoreBatchSize = 1000
+
+// runRestore rebuilds a database at dbPath from archive, then verifies
+// the restored chain: its tip and fingerprint must match the manifest and
+// a full scan must find no errors. It exits 1 unless all of them hold.
+func runRestore(archive, dbPath string, out errors.OutputOptions) {
+    if entries, _ := os.ReadDir(dbPath); len(entries) > 0 {
+        usageError(fmt.Errorf("restore needs a new database; %s is not empty", dbPath))
+    }
+    start := time.Now()
+    r, err := backup.Open(archive)
+    if err != nil {
+        fatal("cannot open archive", "archive", archive, "err", err)
+    }
+    defer r.Close()
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    if storage.Layout() != db.LayoutInspector {
+        storage.Close()
+        usageError(fmt.Errorf("restore writes the inspector layout, not %s", storage.Layout()))
+    }
+    fail := func(msg string, args ...any) {
+        storage.Close()
+        r.Close()
+        fatal(msg, append([]any{"db", dbPath, "archive", archive}, args...)...)
+    }
+
+    m := &r.Manifest
+    batch := storage.NewBatch()
+    for {
+        height, value, err := r.Next()
+        if err == io.EOF {
+            break
+        }
+        if err == nil {
+            err = batch.SaveBlockRaw(height, value)
+        }
+        if err == nil && batch.Len() >= restoreBatchSize {
+            err = batch.Write(false)
+        }
+        if err != nil {
+            fail("cannot restore blocks", "err", err)
+        }
+    }
+    if err := batch.Write(true); err != nil {
+        fail("cannot restore blocks", "err", err)
+    }
+    if m.ChainID != "" {
+        if err := storage.SetChainID(m.ChainID); err != nil {
+            fail("cannot restore chain ID", "err", err)
+        }
+    }
+
+    restored, err := describeChain(storage, dbPath)
+    if err != nil {
+        fail("cannot read restored chain", "err", err)
+    }
+    var problems []string
+    if restored.Tip != m.Tip || restored.TipHash != m.TipHash {
+        problems = append(problems, fmt.Sprintf("tip is %d (%s), archive says %d (%s)", restored.Tip, restored.TipHash, m.Tip, m.TipHash))
+    }
+    if restored.Fingerprint != m.Fingerprint {
+        problems = append(problems, "fingerprint differs from the archive's")
+    }
+    if result := errors.ScanErrors(storage, dbPath, errors.ScanOptions{HashAlgorithm: blocks.HashAlgorithm(m.HashAlgorithm)}); result.TotalErrors > 0 {
+        problems = append(problems, fmt.Sprintf("scan found %d error(s)", result.TotalErrors))
+    }
+
+    info, _ := os.Stat(archive)
+    report := &errors.BackupReport{
+        Operation:    "restore",
+        DatabasePath: dbPath,
+        Archive:      archive,
+        Manifest:     m,
+        Verified:     len(problems) == 0,
+        Problems:     problems,
+        DurationMS:   time.Since(start).Milliseconds(),
+    }
+    if info != nil {
+        report.Size = info.Size()
+    }
+    errors.OutputBackup(report, out)
+    if !report.Verified {
+        storage.Close()
+        r.Close()
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
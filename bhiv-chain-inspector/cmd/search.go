@@ -0,0 +1,24 @@
+package main
+
+import (
+    "os"
+    "regexp"
+
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tracing"
+)
+
+// runSearch prints the transactions matching re. Like grep it exits 1
+// when nothing matches.
+func runSearch(dbPath string, re *regexp.Regexp, context, limit int, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    result := errors.SearchBlocks(storage, dbPath, re, context, limit)
+    errors.OutputSearch(result, re, out)
+    if len(result.Matches) == 0 {
+        storage.Close()
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
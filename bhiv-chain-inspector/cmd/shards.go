@@ -0,0 +1,45 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+    flag "github.com/spf13/pflag"
+)
+
+// shardFlags are the flags of the commands that read a chain split by
+// height across several databases.
+type shardFlags struct {
+    dirs string
+    size int
+}
+
+func (s *shardFlags) register(fs *flag.FlagSet) {
+    fs.StringVar(&s.dirs, "db-shards", "", "Comma-separated databases holding consecutive height ranges of one chain, read as one instead of -db")
+    fs.IntVar(&s.size, "shard-size", 100000, "Blocks per shard of --db-shards: the first holds heights 0 to size-1, the next size to 2*size-1, and so on")
+}
+
+// path returns the database to open: dbPath, or the shards of --db-shards.
+func (s *shardFlags) path(fs *flag.FlagSet, dbPath string) string {
+    if s.dirs == "" {
+        return dbPath
+    }
+    fs.Visit(func(f *flag.Flag) {
+        if f.Name == "db" {
+            usageError(fmt.Errorf("-db and --db-shards cannot be combined"))
+        }
+    })
+    if s.size <= 0 {
+        usageError(fmt.Errorf("--shard-size must be positive"))
+    }
+    var dirs []string
+    for _, dir := range strings.Split(s.dirs, ",") {
+        if dir = strings.TrimSpace(dir); dir == "" {
+            usageError(fmt.Errorf("--db-shards has an empty directory"))
+        }
+        dirs = append(dirs, dir)
+    }
+    db.SetDefaultShardSize(s.size)
+    return db.ShardPath(dirs)
+}
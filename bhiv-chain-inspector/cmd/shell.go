@@ -0,0 +1,44 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "strings"
+
+    "bhiv-chain-inspector/internal/agent"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/shell"
+)
+
+// runShell opens dbPath once and runs the interactive prompt on it.
+func runShell(dbPath, agentToken string, out errors.OutputOptions, opts errors.ScanOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    sh := &shell.Shell{
+        Storage: storage,
+        DBPath:  dbPath,
+        Out:     out,
+        Scan:    opts,
+        Open: func(path string) (db.BlockReader, func(), error) {
+            if strings.HasPrefix(path, agent.Scheme) {
+                return agent.Dial(path, agentToken), func() {}, nil
+            }
+            // Unlike the one-shot commands, a typo here should not
+            // create an empty database.
+            if _, err := os.Stat(path); err != nil {
+                return nil, nil, fmt.Errorf("no database at %s", path)
+            }
+            other, err := db.NewStorage(path)
+            if err != nil {
+                return nil, nil, err
+            }
+            return other, func() { other.Close() }, nil
+        },
+    }
+    if err := sh.Run(); err != nil {
+        storage.Close()
+        fatal("shell stopped", "err", err)
+    }
+}
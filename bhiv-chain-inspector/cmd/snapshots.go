@@ -0,0 +1,40 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tracing"
+)
+
+// runDiffSnapshots prints the history of a node between two snapshots of
+// its database, and exits 1 when blocks of the earlier snapshot were
+// rewritten or deleted; appended blocks are ordinary growth.
+func runDiffSnapshots(beforePath, afterPath string, out errors.OutputOptions) {
+    if beforePath == "" || afterPath == "" {
+        usageError(fmt.Errorf("diff-snapshots needs --before and --after"))
+    }
+    if filepath.Clean(beforePath) == filepath.Clean(afterPath) {
+        usageError(fmt.Errorf("--before and --after are the same database"))
+    }
+    before := openStorage(beforePath)
+    defer before.Close()
+    after := openStorage(afterPath)
+    defer after.Close()
+
+    diff, err := errors.DiffSnapshots(before, after, beforePath, afterPath)
+    if err != nil {
+        before.Close()
+        after.Close()
+        fatal("cannot read snapshot", "err", err)
+    }
+    errors.OutputSnapshotDiff(diff, out)
+    if diff.Changed() {
+        before.Close()
+        after.Close()
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tui"
+)
+
+// runTUI opens dbPath and shows the interactive dashboard until the user
+// quits. The database stays open, so re-scans skip reopening it.
+func runTUI(dbPath string, out errors.OutputOptions, opts errors.ScanOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    err := tui.Run(storage, dbPath, opts, tui.Options{ASCII: out.ASCII, Color: out.Color})
+    if err != nil {
+        storage.Close()
+        fatal("cannot start tui", "err", err)
+    }
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+    "fmt"
+    "slices"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// runUndo reverts the writes recorded in the journal at path, newest
+// first, so that every key ends with the value it had before the first
+// journaled write.
+func runUndo(path string, out errors.OutputOptions) {
+    if path == "" {
+        usageError(fmt.Errorf("undo needs --journal"))
+    }
+    entries, err := db.ReadJournal(path)
+    if err != nil {
+        fatal("cannot read journal", "journal", path, "err", err)
+    }
+    confirm(fmt.Sprintf("revert the %d write(s) recorded in %s", len(entries), path))
+
+    report := &errors.UndoReport{Journal: path, Databases: []string{}}
+    storages := make(map[string]*db.Storage)
+    closeAll := func() {
+        for _, s := range storages {
+            s.Close()
+        }
+    }
+    defer closeAll()
+    for _, e := range slices.Backward(entries) {
+        storage := storages[e.Database]
+        if storage == nil {
+            if storage, err = db.NewStorage(e.Database); err != nil {
+                closeAll()
+                fatal("cannot open database", "db", e.Database, "err", err)
+            }
+            storages[e.Database] = storage
+            report.Databases = append(report.Databases, e.Database)
+        }
+        if err := storage.Revert(e); err != nil {
+            closeAll()
+            fatal("cannot revert key", "db", e.Database, "key", e.Key, "err", err)
+        }
+        if e.Existed {
+            report.Restored++
+        } else {
+            report.Deleted++
+        }
+    }
+    errors.OutputUndo(report, out)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "strconv"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/tracing"
+)
+
+// runVerify checks the whole chain and prints a pass/fail verdict per
+// class, exiting 1 when any class fails. It runs the same checks as scan.
+func runVerify(dbPath string, out errors.OutputOptions, opts errors.ScanOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    opts.OnBlock = verdictPrinter(out)
+    report := errors.NewVerifyReport(errors.ScanErrors(storage, dbPath, opts))
+    errors.OutputVerifyReport(report, out)
+    if !report.Passed {
+        storage.Close()
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
+
+// runVerifyNew verifies the blocks added since the verified tip and, when
+// they pass, moves the verified tip up to the chain's, so that each run
+// only reads what was appended since the last. It exits 1 when they fail,
+// leaving the verified tip where it was.
+func runVerifyNew(dbPath string, out errors.OutputOptions, opts errors.ScanOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    verified, err := storage.LoadVerifiedTip()
+    if err != nil {
+        storage.Close()
+        fatal("cannot read verified tip", "db", dbPath, "err", err)
+    }
+    opts.OnBlock = verdictPrinter(out)
+    report := errors.VerifyNewBlocks(storage, dbPath, verified, opts)
+    // The verified tip is only kept alongside the inspector's own metadata
+    // keys.
+    if report.Passed && report.Tip >= report.FromHeight && storage.Layout() == db.LayoutInspector {
+        if cp := errors.NewCheckpoint(storage, report.Tip); cp != nil {
+            if err := storage.SaveVerifiedTip(cp); err != nil {
+                storage.Close()
+                fatal("cannot save verified tip", "db", dbPath, "err", err)
+            }
+            report.Advanced = !safety.dryRun
+        }
+    }
+    errors.OutputNewBlocksReport(report, out)
+    if !report.Passed {
+        storage.Close()
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
+
+// runView prints the block at the height given as argument, checked with
+// alg (empty: whichever algorithm its hash matches).
+func runView(dbPath, arg string, alg blocks.HashAlgorithm, out errors.OutputOptions) {
+    height, err := strconv.Atoi(arg)
+    if err != nil || height < 0 {
+        usageError(fmt.Errorf("bad height %q", arg))
+    }
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    block, err := storage.LoadBlock(height)
+    if err != nil {
+        storage.Close()
+        fatal("cannot load block", "db", dbPath, "height", height, "err", err)
+    }
+    errors.OutputBlockView(errors.NewBlockView(block, storage, alg), out)
+}
+
+func runStats(dbPath string, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    errors.OutputStats(errors.ComputeStats(storage), dbPath, out)
+}
+
+// runTimeSeries prints the block production of each period.
+func runTimeSeries(dbPath, period string, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    series, err := errors.ComputeTimeSeries(storage, dbPath, period)
+    if err != nil {
+        storage.Close()
+        usageError(err)
+    }
+    errors.OutputTimeSeries(series, out)
+}
+
+func runDBStats(dbPath string, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    stats, err := storage.Stats()
+    if err != nil {
+        storage.Close()
+        fatal("cannot read storage stats", "db", dbPath, "err", err)
+    }
+    errors.OutputStorageStats(&errors.StorageStatsReport{DatabasePath: dbPath, StorageStats: stats}, out)
+}
+
+// runKeys lists the keys that are neither blocks nor metadata, exiting 1
+// when there are any.
+func runKeys(dbPath string, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+    if storage.Layout() != db.LayoutInspector {
+        storage.Close()
+        usageError(fmt.Errorf("keys audits the inspector layout, not %s", storage.Layout()))
+    }
+
+    audit, err := storage.AuditKeys()
+    if err != nil {
+        storage.Close()
+        fatal("cannot audit keys", "db", dbPath, "err", err)
+    }
+    errors.OutputKeyAudit(&errors.KeyAuditReport{DatabasePath: dbPath, KeyAudit: audit}, out)
+    if len(audit.Unknown) > 0 {
+        storage.Close()
+        tracing.Shutdown()
+        os.Exit(1)
+    }
+}
+
+// runNamespaces lists the chains stored in the database.
+func runNamespaces(dbPath string, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    namespaces, err := storage.Namespaces()
+    if err != nil {
+        storage.Close()
+        fatal("cannot list chain namespaces", "db", dbPath, "err", err)
+    }
+    errors.OutputNamespaces(&errors.NamespaceReport{DatabasePath: dbPath, Namespaces: namespaces}, out)
+}
+
+// runCompact compacts the database and reports the space reclaimed.
+func runCompact(dbPath string, out errors.OutputOptions) {
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    start := time.Now()
+    before, err := storage.TableStats()
+    if err == nil {
+        err = storage.Compact()
+    }
+    var after db.TableStats
+    if err == nil {
+        after, err = storage.TableStats()
+    }
+    if err != nil {
+        storage.Close()
+        fatal("cannot compact database", "db", dbPath, "err", err)
+    }
+    errors.OutputMaintenance(&errors.MaintenanceReport{
+        DatabasePath: dbPath,
+        Operation:    "compact",
+        Before:       &before,
+        After:        after,
+        MaxHeight:    storage.GetMaxHeight(),
+        DurationMS:   time.Since(start).Milliseconds(),
+    }, out)
+}
+
+// runRecover rebuilds the manifest of a database that no longer opens,
+// then reports what the recovered database holds.
+func runRecover(dbPath string, out errors.OutputOptions) {
+    confirm("rewrite the manifest of " + dbPath + " from the tables on disk; keys in lost tables are dropped")
+    start := time.Now()
+    storage, err := db.Recover(dbPath)
+    if err != nil {
+        fatal("cannot recover database", "db", dbPath, "err", err)
+    }
+    defer storage.Close()
+
+    after, err := storage.TableStats()
+    if err != nil {
+        storage.Close()
+        fatal("cannot read recovered database", "db", dbPath, "err", err)
+    }
+    errors.OutputMaintenance(&errors.MaintenanceReport{
+        DatabasePath: dbPath,
+        Operation:    "recover",
+        After:        after,
+        MaxHeight:    storage.GetMaxHeight(),
+        DurationMS:   time.Since(start).Milliseconds(),
+    }, out)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+    "context"
+    "log/slog"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+    "bhiv-chain-inspector/internal/events"
+    "bhiv-chain-inspector/internal/notify"
+)
+
+// runWatch keeps dbPath open and validates newly appended blocks every
+// interval until interrupted. Each pass with findings is reported like a
+// scan; every finding is also logged, so log-based alerting sees it, and
+// passes with errors are sent to the notifiers.
+func runWatch(dbPath string, interval time.Duration, out errors.OutputOptions, opts errors.ScanOptions, notifiers []notify.Notifier, emitter *events.Emitter, backups *backupSchedule) {
+    if interval <= 0 {
+        fatal("--interval must be positive", "interval", interval)
+    }
+    storage := openStorage(dbPath)
+    defer storage.Close()
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    if emitter != nil {
+        opts.OnBlock = emitter.OnBlock(opts.OnBlock)
+    }
+    watcher := errors.NewWatcher(storage, dbPath, opts)
+    slog.Info("watching database", "db", dbPath, "interval", interval)
+
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        if reorg := checkReorg(storage, dbPath, out); reorg {
+            // Validate the rewritten chain from scratch on this pass.
+            watcher = errors.NewWatcher(storage, dbPath, opts)
+        }
+        watchPass(watcher, storage, dbPath, out, notifiers, emitter)
+        if backups != nil {
+            backups.maybeRun(storage, dbPath)
+        }
+        select {
+        case <-ctx.Done():
+            slog.Info("watch stopped", "db", dbPath)
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// checkReorg reports whether the chain was rewritten below the stored
+// checkpoint since the last pass, or since the last scan or watch before
+// this one.
+func checkReorg(storage *db.Storage, dbPath string, out errors.OutputOptions) bool {
+    checkpoint, err := storage.LoadCheckpoint()
+    if err != nil {
+        slog.Warn("cannot read checkpoint", "db", dbPath, "err", err)
+        return false
+    }
+    reorg := errors.DetectReorg(storage, checkpoint)
+    if reorg == nil {
+        return false
+    }
+    slog.Error("chain reorganised since the last checkpoint", "db", dbPath,
+        "depth", reorg.Depth, "fork_height", reorg.ForkHeight,
+        "old_tip", reorg.OldTip.Height, "new_tip", reorg.NewTip.Height)
+    errors.OutputReorg(reorg, out)
+    return true
+}
+
+// watchPass runs one pass, reports it and advances the stored checkpoint.
+func watchPass(watcher *errors.Watcher, storage *db.Storage, dbPath string, out errors.OutputOptions, notifiers []notify.Notifier, emitter *events.Emitter) {
+    start := time.Now()
+    result := watcher.Pass()
+    if result.HeightsChecked == 0 {
+        slog.Debug("no new blocks", "db", dbPath, "tip", result.TotalBlocks-1)
+        return
+    }
+
+    for _, issue := range result.Issues() {
+        level := slog.LevelError
+        if issue.Severity != errors.SeverityError {
+            level = slog.LevelWarn
+        }
+        slog.Log(context.Background(), level, "new finding",
+            "class", issue.Class, "severity", issue.Severity, "msg", issue.Message)
+    }
+    slog.Info("watch pass", "db", dbPath, "heights", result.HeightsChecked,
+        "tip", result.TotalBlocks-1, "errors", result.TotalErrors,
+        "warnings", result.TotalWarnings, "duration", time.Since(start))
+
+    if cp := watcher.Checkpoint(); cp != nil {
+        if err := storage.SaveCheckpoint(cp); err != nil {
+            slog.Warn("cannot save checkpoint", "db", dbPath, "err", err)
+        }
+    }
+    if result.TotalErrors+result.TotalWarnings > 0 {
+        errors.OutputScanResult(result, out)
+    }
+    if result.TotalErrors > 0 {
+        notify.NotifyAll(notifiers, result)
+    }
+    if emitter != nil {
+        if err := emitter.Emit(result, start); err != nil {
+            slog.Warn("cannot publish events", "err", err)
+        }
+    }
+}
@@ -0,0 +1,105 @@
+// Package agent serves a chain to remote inspectors and reads chains
+// served by remote agents, so that nodes on different hosts can be
+// compared without copying their databases.
+//
+// The protocol is plain HTTP:
+//
+//  GET /v1/height                  {"height": N}
+//  GET /v1/blocks?from=H&count=C   one {"height": h, "block": {...}} per line
+//  GET /v1/fingerprint?segment=S   the chain fingerprint (see errors.Fingerprint)
+//
+// Heights whose block is missing or undecodable are left out of /v1/blocks.
+// When the agent has a token, requests must send "Authorization: Bearer
+// <token>".
+package agent
+
+import (
+    "crypto/subtle"
+    "encoding/json"
+    "log/slog"
+    "net/http"
+    "strconv"
+    "sync"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// maxBatch caps the blocks returned by one /v1/blocks request.
+const maxBatch = 10000
+
+// Scheme prefixes database arguments that name a remote agent, e.g.
+// -db2 agent://host-b:9090.
+const Scheme = "agent://"
+
+type entry struct {
+    Height int           `json:"height"`
+    Block  *blocks.Block `json:"block"`
+}
+
+// Handler serves storage. Reads are serialized because the caller may
+// share storage with other work.
+func Handler(storage *db.Storage, token string) http.Handler {
+    var mu sync.Mutex
+    mux := http.NewServeMux()
+
+    mux.HandleFunc("/v1/height", func(w http.ResponseWriter, r *http.Request) {
+        mu.Lock()
+        height := storage.GetMaxHeight()
+        mu.Unlock()
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(map[string]int{"height": height})
+    })
+
+    mux.HandleFunc("/v1/blocks", func(w http.ResponseWriter, r *http.Request) {
+        from, err1 := strconv.Atoi(r.URL.Query().Get("from"))
+        count, err2 := strconv.Atoi(r.URL.Query().Get("count"))
+        if err1 != nil || err2 != nil || from < 0 || count <= 0 {
+            http.Error(w, "from and count must be non-negative integers", http.StatusBadRequest)
+            return
+        }
+        if count > maxBatch {
+            count = maxBatch
+        }
+
+        mu.Lock()
+        defer mu.Unlock()
+        w.Header().Set("Content-Type", "application/x-ndjson")
+        enc := json.NewEncoder(w)
+        for h := from; h < from+count; h++ {
+            block, err := storage.LoadBlock(h)
+            if err != nil {
+                continue
+            }
+            if err := enc.Encode(entry{Height: h, Block: block}); err != nil {
+                return
+            }
+        }
+    })
+
+    mux.HandleFunc("/v1/fingerprint", func(w http.ResponseWriter, r *http.Request) {
+        segment, err := strconv.Atoi(r.URL.Query().Get("segment"))
+        if err != nil || segment <= 0 {
+            segment = errors.DefaultSegmentSize
+        }
+        mu.Lock()
+        fp := errors.ComputeFingerprint(storage, segment)
+        mu.Unlock()
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(fp)
+    })
+
+    if token == "" {
+        return mux
+    }
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        want := "Bearer " + token
+        if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+            slog.Warn("agent request rejected", "remote", r.RemoteAddr, "path", r.URL.Path)
+            http.Error(w, "unauthorized", http.StatusUnauthorized)
+            return
+        }
+        mux.ServeHTTP(w, r)
+    })
+}
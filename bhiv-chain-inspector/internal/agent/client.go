@@ -0,0 +1,146 @@
+package agent
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// batchSize is how many heights the client fetches per request.
+const batchSize = 1000
+
+// Client reads a chain from a remote agent. It implements db.BlockReader
+// and prefetches blocks in batches, so sequential reads cost one request
+// per batchSize heights.
+type Client struct {
+    base   string
+    token  string
+    http   *http.Client
+
+    // cache holds the current batch, covering [cacheFrom, cacheFrom+batchSize).
+    cache     map[int]*blocks.Block
+    cacheFrom int
+    err       error
+}
+
+// Dial returns a client for addr, given as host:port or agent://host:port.
+func Dial(addr, token string) *Client {
+    return &Client{
+        base:      "http://" + strings.TrimPrefix(addr, Scheme),
+        token:     token,
+        http:      &http.Client{Timeout: 2 * time.Minute},
+        cacheFrom: -1,
+    }
+}
+
+// Err reports the first transport error; reads after a failure behave as
+// if the blocks were missing, so callers should check Err when done.
+func (c *Client) Err() error {
+    return c.err
+}
+
+func (c *Client) get(path string) (*http.Response, error) {
+    req, err := http.NewRequest(http.MethodGet, c.base+path, nil)
+    if err != nil {
+        return nil, err
+    }
+    if c.token != "" {
+        req.Header.Set("Authorization", "Bearer "+c.token)
+    }
+    resp, err := c.http.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    if resp.StatusCode != http.StatusOK {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+        resp.Body.Close()
+        return nil, fmt.Errorf("agent %s: %s: %s", c.base, resp.Status, strings.TrimSpace(string(msg)))
+    }
+    return resp, nil
+}
+
+// GetMaxHeight returns the remote chain height, -1 on error or if empty.
+func (c *Client) GetMaxHeight() int {
+    resp, err := c.get("/v1/height")
+    if err != nil {
+        c.fail(err)
+        return -1
+    }
+    defer resp.Body.Close()
+    var body struct {
+        Height int `json:"height"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+        c.fail(err)
+        return -1
+    }
+    return body.Height
+}
+
+// Fingerprint has the agent fingerprint its chain, so comparing identical
+// chains transfers only digests.
+func (c *Client) Fingerprint(segmentSize int) (*errors.Fingerprint, error) {
+    resp, err := c.get(fmt.Sprintf("/v1/fingerprint?segment=%d", segmentSize))
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    var fp errors.Fingerprint
+    if err := json.NewDecoder(resp.Body).Decode(&fp); err != nil {
+        return nil, fmt.Errorf("agent %s: bad fingerprint: %w", c.base, err)
+    }
+    return &fp, nil
+}
+
+// LoadBlock returns the remote block at height.
+func (c *Client) LoadBlock(height int) (*blocks.Block, error) {
+    if c.cacheFrom < 0 || height < c.cacheFrom || height >= c.cacheFrom+batchSize {
+        if err := c.fetch(height); err != nil {
+            c.fail(err)
+            return nil, err
+        }
+    }
+    block, ok := c.cache[height]
+    if !ok {
+        return nil, fmt.Errorf("block %d on agent %s: %w", height, c.base, db.ErrBlockMissing)
+    }
+    return block, nil
+}
+
+func (c *Client) fetch(from int) error {
+    resp, err := c.get(fmt.Sprintf("/v1/blocks?from=%d&count=%d", from, batchSize))
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    cache := make(map[int]*blocks.Block)
+    scanner := bufio.NewScanner(resp.Body)
+    scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+    for scanner.Scan() {
+        var e entry
+        if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+            return fmt.Errorf("agent %s: bad response: %w", c.base, err)
+        }
+        cache[e.Height] = e.Block
+    }
+    if err := scanner.Err(); err != nil {
+        return err
+    }
+    c.cache, c.cacheFrom = cache, from
+    return nil
+}
+
+func (c *Client) fail(err error) {
+    if c.err == nil {
+        c.err = err
+    }
+}
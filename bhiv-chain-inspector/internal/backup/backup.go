@@ -0,0 +1,262 @@
+// Package backup writes and reads portable chain archives: a tar stream,
+// compressed as its file name says (.tar.zst, .tar.gz or .tar), holding
+// manifest.json followed by the stored value of every block.
+//
+// Values are archived exactly as stored, so a restored database is
+// byte-for-byte the original; reading an encrypted or sealed chain still
+// needs its keys.
+package backup
+
+import (
+    "archive/tar"
+    "compress/gzip"
+    "crypto/sha256"
+    "encoding/binary"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "hash"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+    "github.com/klauspost/compress/zstd"
+)
+
+const (
+    manifestName = "manifest.json"
+    blockPrefix  = "blocks/block-"
+    // Version is the archive format written by Write.
+    Version      = 1
+)
+
+// Manifest describes the chain in an archive.
+type Manifest struct {
+    Version       int    `json:"version"`
+    Created       string `json:"created"`
+    Source        string `json:"source"`
+    ChainID       string `json:"chain_id,omitempty"`
+    Tip           int    `json:"tip"`
+    TipHash       string `json:"tip_hash"`
+    HashAlgorithm string `json:"hash_algorithm"`
+    // Fingerprint is the digest of the chain's fingerprint; a restored
+    // chain must have the same.
+    Fingerprint   string `json:"fingerprint"`
+    Encoding      string `json:"encoding"`
+    Encrypted     bool   `json:"encrypted"`
+    Sealed        bool   `json:"sealed"`
+    // Blocks is the number of block values archived, including any past
+    // gaps in the chain; Digest covers their heights and values.
+    Blocks        int    `json:"blocks"`
+    Digest        string `json:"digest"`
+}
+
+// digest accumulates Manifest.Digest.
+type digest struct{ h hash.Hash }
+
+func newDigest() digest { return digest{sha256.New()} }
+
+func (d digest) add(height int, value []byte) {
+    var n [16]byte
+    binary.BigEndian.PutUint64(n[:8], uint64(height))
+    binary.BigEndian.PutUint64(n[8:], uint64(len(value)))
+    d.h.Write(n[:])
+    d.h.Write(value)
+}
+
+func (d digest) String() string { return hex.EncodeToString(d.h.Sum(nil)) }
+
+// Write archives every block value of storage to path under manifest,
+// whose Version, Created, Blocks and Digest it fills in. The archive is
+// written to a temporary file renamed into place once complete.
+func Write(path string, storage *db.Storage, manifest *Manifest) error {
+    manifest.Version = Version
+    manifest.Created = time.Now().UTC().Format(time.RFC3339)
+    manifest.Blocks = 0
+    sum := newDigest()
+    err := storage.BlockValues(func(height int, value []byte) error {
+        manifest.Blocks++
+        sum.add(height, value)
+        return nil
+    })
+    if err != nil {
+        return err
+    }
+    manifest.Digest = sum.String()
+
+    tmp := path + ".tmp"
+    f, err := os.Create(tmp)
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp)
+    defer f.Close()
+    compressed := compressor(path, f)
+    tw := tar.NewWriter(compressed)
+    header, err := json.MarshalIndent(manifest, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err := writeEntry(tw, manifestName, append(header, '\n')); err != nil {
+        return err
+    }
+    err = storage.BlockValues(func(height int, value []byte) error {
+        return writeEntry(tw, blockPrefix+strconv.Itoa(height), value)
+    })
+    if err == nil {
+        err = tw.Close()
+    }
+    if err == nil {
+        err = compressed.Close()
+    }
+    if err == nil {
+        err = f.Sync()
+    }
+    if err == nil {
+        err = f.Close()
+    }
+    if err != nil {
+        return err
+    }
+    return os.Rename(tmp, path)
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+    err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+    if err == nil {
+        _, err = tw.Write(data)
+    }
+    return err
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// compressor wraps w in the compression path's extension asks for. The
+// zstd encoder and decoder run without background goroutines, so neither
+// needs closing on an error path; closing the encoder flushes the last
+// frame but does not close w.
+func compressor(path string, w io.Writer) io.WriteCloser {
+    switch {
+    case strings.HasSuffix(path, ".zst") || strings.HasSuffix(path, ".tzst"):
+        zw, _ := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+        return zw
+    case strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".tgz"):
+        return gzip.NewWriter(w)
+    }
+    return nopCloser{w}
+}
+
+// decompressor undoes compressor, telling the compression from the
+// stream rather than the name.
+func decompressor(f *os.File) (io.Reader, error) {
+    var magic [4]byte
+    n, _ := io.ReadFull(f, magic[:])
+    if _, err := f.Seek(0, io.SeekStart); err != nil {
+        return nil, err
+    }
+    switch {
+    case n == 4 && string(magic[:]) == "\x28\xb5\x2f\xfd":
+        return zstd.NewReader(f, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+    case n >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+        return gzip.NewReader(f)
+    }
+    return f, nil
+}
+
+// Reader reads the block values of an archive in archive order, checking
+// them against the manifest.
+type Reader struct {
+    Manifest Manifest
+    f        *os.File
+    tr       *tar.Reader
+    sum      digest
+    blocks   int
+}
+
+// Open opens the archive at path and reads its manifest.
+func Open(path string) (*Reader, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    r := &Reader{f: f, sum: newDigest()}
+    stream, err := decompressor(f)
+    if err != nil {
+        f.Close()
+        return nil, err
+    }
+    r.tr = tar.NewReader(stream)
+    header, err := r.tr.Next()
+    if err == nil && header.Name != manifestName {
+        err = fmt.Errorf("first entry is %q, not %s", header.Name, manifestName)
+    }
+    if err == nil {
+        err = json.NewDecoder(r.tr).Decode(&r.Manifest)
+    }
+    if err == nil && r.Manifest.Version != Version {
+        err = fmt.Errorf("archive format version %d, want %d", r.Manifest.Version, Version)
+    }
+    if err != nil {
+        f.Close()
+        return nil, fmt.Errorf("%s: not a chain archive: %w", path, err)
+    }
+    return r, nil
+}
+
+// Next returns the next block value. After the last one it checks the
+// count and digest of the values against the manifest and returns io.EOF
+// if they match.
+func (r *Reader) Next() (int, []byte, error) {
+    header, err := r.tr.Next()
+    if err == io.EOF {
+        switch {
+        case r.blocks != r.Manifest.Blocks:
+            return 0, nil, fmt.Errorf("archive holds %d block(s), manifest says %d", r.blocks, r.Manifest.Blocks)
+        case r.sum.String() != r.Manifest.Digest:
+            return 0, nil, fmt.Errorf("block values do not match the manifest digest")
+        }
+        return 0, nil, io.EOF
+    }
+    if err != nil {
+        return 0, nil, err
+    }
+    height, err := strconv.Atoi(strings.TrimPrefix(header.Name, blockPrefix))
+    if !strings.HasPrefix(header.Name, blockPrefix) || err != nil || height < 0 {
+        return 0, nil, fmt.Errorf("unexpected archive entry %q", header.Name)
+    }
+    value, err := io.ReadAll(r.tr)
+    if err != nil {
+        return 0, nil, err
+    }
+    r.blocks++
+    r.sum.add(height, value)
+    return height, value, nil
+}
+
+func (r *Reader) Close() error {
+    return r.f.Close()
+}
+
+// Verify reads the whole archive at path, checking it against its
+// manifest, and returns the manifest.
+func Verify(path string) (*Manifest, error) {
+    r, err := Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer r.Close()
+    for {
+        _, _, err := r.Next()
+        if err == io.EOF {
+            return &r.Manifest, nil
+        }
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", path, err)
+        }
+    }
+}
@@ -0,0 +1,83 @@
+package backup
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "slices"
+    "strings"
+    "time"
+)
+
+// The archives of a backup rotation are named after the time they were
+// taken, in UTC.
+const (
+    rotationPrefix = "chain-"
+    rotationLayout = "20060102-150405"
+    rotationSuffix = ".tar.zst"
+)
+
+// Archive is one archive of a rotation directory.
+type Archive struct {
+    Path  string
+    Taken time.Time
+}
+
+// RotationPath is the path of an archive taken at t in dir.
+func RotationPath(dir string, t time.Time) string {
+    return filepath.Join(dir, rotationPrefix+t.UTC().Format(rotationLayout)+rotationSuffix)
+}
+
+// List returns the rotation archives in dir, newest first. Other files
+// are ignored.
+func List(dir string) ([]Archive, error) {
+    entries, err := os.ReadDir(dir)
+    if err != nil {
+        return nil, err
+    }
+    var archives []Archive
+    for _, e := range entries {
+        name := e.Name()
+        if !strings.HasPrefix(name, rotationPrefix) || !strings.HasSuffix(name, rotationSuffix) {
+            continue
+        }
+        stamp := strings.TrimSuffix(strings.TrimPrefix(name, rotationPrefix), rotationSuffix)
+        taken, err := time.Parse(rotationLayout, stamp)
+        if err != nil {
+            continue
+        }
+        archives = append(archives, Archive{Path: filepath.Join(dir, name), Taken: taken})
+    }
+    slices.SortFunc(archives, func(a, b Archive) int { return b.Taken.Compare(a.Taken) })
+    return archives, nil
+}
+
+// Retain splits archives, newest first, into those a rotation keeps and
+// those it drops: the newest archive of each of the last daily days that
+// have one, and of each of the last weekly ISO weeks. The newest archive
+// is always kept.
+func Retain(archives []Archive, daily, weekly int) (keep, drop []Archive) {
+    days := make(map[string]bool)
+    weeks := make(map[string]bool)
+    for i, a := range archives {
+        day := a.Taken.UTC().Format("2006-01-02")
+        year, week := a.Taken.UTC().ISOWeek()
+        weekKey := fmt.Sprintf("%d-W%02d", year, week)
+
+        kept := i == 0
+        if !days[day] && len(days) < daily {
+            days[day] = true
+            kept = true
+        }
+        if !weeks[weekKey] && len(weeks) < weekly {
+            weeks[weekKey] = true
+            kept = true
+        }
+        if kept {
+            keep = append(keep, a)
+        } else {
+            drop = append(drop, a)
+        }
+    }
+    return keep, drop
+}
@@ -1,9 +1,24 @@
 package blocks
 
 type Block struct {
-    Height    int    `json:"height"`
-    Hash      string `json:"hash"`
-    PrevHash  string `json:"prev_hash"`
-    Data      string `json:"data"`
-    Timestamp int64  `json:"timestamp"`
+    Height     int    `json:"height"`
+    Hash       string `json:"hash"`
+    PrevHash   string `json:"prev_hash"`
+    Data       string `json:"data"`
+    Timestamp  int64  `json:"timestamp"`
+    // MerkleRoot commits to the transactions in Data; see MerkleRoot.
+    // Blocks written before it was introduced leave it empty.
+    MerkleRoot string `json:"merkle_root,omitempty"`
+    // Difficulty is the number of leading zero bits the hash must have;
+    // zero for chains without proof of work. Nonce is the value found by
+    // mining. Both are covered by the hash when Difficulty is set.
+    Difficulty int    `json:"difficulty,omitempty"`
+    Nonce      uint64 `json:"nonce,omitempty"`
+    // Signer is the hex ed25519 public key that produced Signature, the
+    // hex signature of Hash; see Sign. Both are empty on unsigned chains.
+    Signer     string `json:"signer,omitempty"`
+    Signature  string `json:"signature,omitempty"`
+    // ChainID names the chain the block belongs to, e.g. "mainnet"; it is
+    // covered by the hash when set.
+    ChainID    string `json:"chain_id,omitempty"`
 }
@@ -0,0 +1,301 @@
+package blocks
+
+import (
+    "encoding/binary"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math"
+)
+
+// CBOR (RFC 8949) blocks are a map keyed by the block's JSON field names.
+// Like the protobuf codec, this one is written by hand to keep the
+// inspector on the standard library.
+
+// cborMaxDepth bounds the nesting of decoded items.
+const cborMaxDepth = 32
+
+// MarshalCBOR encodes b as a CBOR map with the keys of its JSON encoding,
+// omitting the same empty optional fields.
+func (b *Block) MarshalCBOR() []byte {
+    type field struct {
+        key string
+        val any
+    }
+    fields := []field{
+        {"height", int64(b.Height)},
+        {"hash", b.Hash},
+        {"prev_hash", b.PrevHash},
+        {"data", b.Data},
+        {"timestamp", b.Timestamp},
+    }
+    optional := []field{
+        {"merkle_root", b.MerkleRoot},
+        {"difficulty", int64(b.Difficulty)},
+        {"nonce", b.Nonce},
+        {"signer", b.Signer},
+        {"signature", b.Signature},
+        {"chain_id", b.ChainID},
+    }
+    for _, f := range optional {
+        if f.val != "" && f.val != int64(0) && f.val != uint64(0) {
+            fields = append(fields, f)
+        }
+    }
+
+    buf := cborHead(nil, 5, uint64(len(fields)))
+    for _, f := range fields {
+        buf = cborHead(buf, 3, uint64(len(f.key)))
+        buf = append(buf, f.key...)
+        switch v := f.val.(type) {
+        case string:
+            buf = cborHead(buf, 3, uint64(len(v)))
+            buf = append(buf, v...)
+        case uint64:
+            buf = cborHead(buf, 0, v)
+        case int64:
+            if v < 0 {
+                buf = cborHead(buf, 1, uint64(-1-v))
+            } else {
+                buf = cborHead(buf, 0, uint64(v))
+            }
+        }
+    }
+    return buf
+}
+
+// cborHead appends the initial byte and argument of an item of major
+// type major, in its shortest form.
+func cborHead(buf []byte, major byte, arg uint64) []byte {
+    major <<= 5
+    switch {
+    case arg < 24:
+        return append(buf, major|byte(arg))
+    case arg <= math.MaxUint8:
+        return append(buf, major|24, byte(arg))
+    case arg <= math.MaxUint16:
+        return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(arg))
+    case arg <= math.MaxUint32:
+        return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(arg))
+    }
+    return binary.BigEndian.AppendUint64(append(buf, major|27), arg)
+}
+
+// UnmarshalCBOR decodes a CBOR map into b. The map's values are checked
+// against the field types of the JSON encoding; unknown keys are ignored
+// and trailing bytes are an error.
+func (b *Block) UnmarshalCBOR(data []byte) error {
+    d := &cborDecoder{data: data}
+    v, err := d.item(0)
+    if err != nil {
+        return err
+    }
+    if len(d.data) > 0 {
+        return fmt.Errorf("cbor: %d trailing bytes", len(d.data))
+    }
+    if _, ok := v.(map[string]any); !ok {
+        return errors.New("cbor: block is not a map")
+    }
+    // The decoded map has JSON's shape, so JSON's rules decide which
+    // values fit which fields.
+    encoded, err := json.Marshal(v)
+    if err != nil {
+        return fmt.Errorf("cbor: %w", err)
+    }
+    *b = Block{}
+    if err := json.Unmarshal(encoded, b); err != nil {
+        return fmt.Errorf("cbor: %w", err)
+    }
+    return nil
+}
+
+type cborDecoder struct {
+    data []byte
+}
+
+var errCBORTruncated = errors.New("cbor: truncated item")
+
+// head reads an initial byte and its argument. indefinite is set for
+// additional information 31, which has no argument.
+func (d *cborDecoder) head() (major byte, arg uint64, indefinite bool, err error) {
+    if len(d.data) == 0 {
+        return 0, 0, false, errCBORTruncated
+    }
+    major, info := d.data[0]>>5, d.data[0]&31
+    d.data = d.data[1:]
+    size := 0
+    switch {
+    case info < 24:
+        return major, uint64(info), false, nil
+    case info == 31:
+        return major, 0, true, nil
+    case info <= 27:
+        size = 1 << (info - 24)
+    default:
+        return 0, 0, false, fmt.Errorf("cbor: reserved additional information %d", info)
+    }
+    if len(d.data) < size {
+        return 0, 0, false, errCBORTruncated
+    }
+    for _, c := range d.data[:size] {
+        arg = arg<<8 | uint64(c)
+    }
+    d.data = d.data[size:]
+    return major, arg, false, nil
+}
+
+// item decodes one data item into uint64, int64, float64, string,
+// []byte, bool, nil, []any or map[string]any.
+func (d *cborDecoder) item(depth int) (any, error) {
+    if depth > cborMaxDepth {
+        return nil, errors.New("cbor: nested too deeply")
+    }
+    if len(d.data) == 0 {
+        return nil, errCBORTruncated
+    }
+    info := d.data[0] & 31
+    major, arg, indefinite, err := d.head()
+    if err != nil {
+        return nil, err
+    }
+    if indefinite && (major < 2 || major == 6) {
+        return nil, fmt.Errorf("cbor: indefinite length for major type %d", major)
+    }
+    switch major {
+    case 0:
+        return arg, nil
+    case 1:
+        if arg > math.MaxInt64 {
+            return nil, errors.New("cbor: negative integer overflows int64")
+        }
+        return -1 - int64(arg), nil
+    case 2, 3:
+        s, err := d.str(major, arg, indefinite)
+        if err != nil {
+            return nil, err
+        }
+        if major == 2 {
+            return []byte(s), nil
+        }
+        return s, nil
+    case 4:
+        var list []any
+        for n := uint64(0); indefinite || n < arg; n++ {
+            if indefinite && d.brk() {
+                break
+            }
+            v, err := d.item(depth + 1)
+            if err != nil {
+                return nil, err
+            }
+            list = append(list, v)
+        }
+        return list, nil
+    case 5:
+        m := make(map[string]any)
+        for n := uint64(0); indefinite || n < arg; n++ {
+            if indefinite && d.brk() {
+                break
+            }
+            k, err := d.item(depth + 1)
+            if err != nil {
+                return nil, err
+            }
+            key, ok := k.(string)
+            if !ok {
+                return nil, errors.New("cbor: map key is not a text string")
+            }
+            if m[key], err = d.item(depth + 1); err != nil {
+                return nil, err
+            }
+        }
+        return m, nil
+    case 6:
+        return d.item(depth + 1)
+    }
+    if indefinite {
+        return nil, errors.New("cbor: unexpected break")
+    }
+    return d.simple(info, arg)
+}
+
+// brk consumes the break code that ends an indefinite-length item.
+func (d *cborDecoder) brk() bool {
+    if len(d.data) > 0 && d.data[0] == 0xff {
+        d.data = d.data[1:]
+        return true
+    }
+    return false
+}
+
+// str reads a byte or text string of length n, or the definite-length
+// chunks of an indefinite one.
+func (d *cborDecoder) str(major byte, n uint64, indefinite bool) (string, error) {
+    if !indefinite {
+        if n > uint64(len(d.data)) {
+            return "", errCBORTruncated
+        }
+        s := string(d.data[:n])
+        d.data = d.data[n:]
+        return s, nil
+    }
+    var s string
+    for !d.brk() {
+        chunkMajor, chunkLen, chunkIndefinite, err := d.head()
+        if err != nil {
+            return "", err
+        }
+        if chunkMajor != major || chunkIndefinite {
+            return "", errors.New("cbor: bad chunk in indefinite-length string")
+        }
+        chunk, err := d.str(major, chunkLen, false)
+        if err != nil {
+            return "", err
+        }
+        s += chunk
+    }
+    return s, nil
+}
+
+// simple decodes major type 7, whose additional information info tells
+// simple values (false, true, null, undefined) from floats.
+func (d *cborDecoder) simple(info byte, arg uint64) (any, error) {
+    switch info {
+    case 25:
+        return float16(uint16(arg)), nil
+    case 26:
+        return float64(math.Float32frombits(uint32(arg))), nil
+    case 27:
+        return math.Float64frombits(arg), nil
+    }
+    switch arg {
+    case 20:
+        return false, nil
+    case 21:
+        return true, nil
+    case 22, 23:
+        return nil, nil
+    }
+    return nil, fmt.Errorf("cbor: unsupported simple value %d", arg)
+}
+
+// float16 converts an IEEE 754 half-precision value.
+func float16(h uint16) float64 {
+    exp, frac := int(h>>10&0x1f), float64(h&0x3ff)
+    var f float64
+    switch exp {
+    case 0:
+        f = math.Ldexp(frac, -24)
+    case 31:
+        f = math.Inf(1)
+        if frac != 0 {
+            f = math.NaN()
+        }
+    default:
+        f = math.Ldexp(frac+1024, exp-25)
+    }
+    if h&0x8000 != 0 {
+        f = -f
+    }
+    return f
+}
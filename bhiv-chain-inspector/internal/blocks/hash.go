@@ -3,9 +3,15 @@ package blocks
 import (
     "crypto/sha256"
     "encoding/hex"
+    "errors"
+    "fmt"
+    "math/bits"
     "strconv"
 )
 
+// ErrHashMismatch is returned, wrapped, by VerifyHash.
+var ErrHashMismatch = errors.New("hash mismatch")
+
 func ComputeHash(height int, prevHash string, data string, timestamp int64) string {
     record := strconv.Itoa(height) + prevHash + data + strconv.FormatInt(timestamp, 10)
     h := sha256.New()
@@ -13,3 +19,89 @@ func ComputeHash(height int, prevHash string, data string, timestamp int64) stri
     hashed := h.Sum(nil)
     return hex.EncodeToString(hashed)
 }
+
+// ComputeHashPoW is ComputeHash for proof-of-work blocks, which also
+// commit to their difficulty and nonce.
+func ComputeHashPoW(height int, prevHash string, data string, timestamp int64, difficulty int, nonce uint64) string {
+    record := strconv.Itoa(height) + prevHash + data + strconv.FormatInt(timestamp, 10) +
+        strconv.Itoa(difficulty) + strconv.FormatUint(nonce, 10)
+    sum := sha256.Sum256([]byte(record))
+    return hex.EncodeToString(sum[:])
+}
+
+// ComputedHash returns the hash the block should have: ComputeHashPoW for
+// proof-of-work blocks (non-zero difficulty), ComputeHash otherwise. A
+// block with a ChainID also commits to it, as a prefix of the record.
+func (b *Block) ComputedHash() string {
+    return b.ComputedHashWith(SHA256)
+}
+
+// ComputedHashWith is ComputedHash for chains hashed with alg.
+func (b *Block) ComputedHashWith(alg HashAlgorithm) string {
+    return alg.Sum([]byte(b.record()))
+}
+
+// record is the string a block's hash is computed over.
+func (b *Block) record() string {
+    record := strconv.Itoa(b.Height) + b.PrevHash + b.Data + strconv.FormatInt(b.Timestamp, 10)
+    if b.Difficulty != 0 {
+        record += strconv.Itoa(b.Difficulty) + strconv.FormatUint(b.Nonce, 10)
+    }
+    if b.ChainID != "" {
+        record = b.ChainID + "|" + record
+    }
+    return record
+}
+
+// VerifyHash checks that b.Hash is the hash of the block's other fields.
+func (b *Block) VerifyHash() error {
+    return b.VerifyHashWith(SHA256)
+}
+
+// VerifyHashWith is VerifyHash for chains hashed with alg.
+func (b *Block) VerifyHashWith(alg HashAlgorithm) error {
+    if computed := b.ComputedHashWith(alg); b.Hash != computed {
+        return fmt.Errorf("block %d: %w: stored %s, computed %s", b.Height, ErrHashMismatch, b.Hash, computed)
+    }
+    return nil
+}
+
+// LeadingZeroBits counts the leading zero bits of a hex hash. It returns
+// -1 if hash is not valid hex.
+func LeadingZeroBits(hash string) int {
+    raw, err := hex.DecodeString(hash)
+    if err != nil {
+        return -1
+    }
+    n := 0
+    for _, b := range raw {
+        if b != 0 {
+            return n + bits.LeadingZeros8(b)
+        }
+        n += 8
+    }
+    return n
+}
+
+// MeetsDifficulty reports whether the block's hash has at least
+// Difficulty leading zero bits.
+func (b *Block) MeetsDifficulty() bool {
+    return LeadingZeroBits(b.Hash) >= b.Difficulty
+}
+
+// Mine searches nonces from 0 until the block's hash meets its
+// difficulty, and sets Nonce and Hash. Each extra bit of difficulty
+// doubles the expected work.
+func (b *Block) Mine() {
+    b.MineWith(SHA256)
+}
+
+// MineWith is Mine for chains hashed with alg.
+func (b *Block) MineWith(alg HashAlgorithm) {
+    for b.Nonce = 0; ; b.Nonce++ {
+        b.Hash = b.ComputedHashWith(alg)
+        if b.MeetsDifficulty() {
+            return
+        }
+    }
+}
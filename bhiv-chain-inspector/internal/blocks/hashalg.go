@@ -0,0 +1,70 @@
+package blocks
+
+import (
+    "crypto/sha256"
+    "crypto/sha3"
+    "crypto/sha512"
+    "encoding/hex"
+    "fmt"
+    "strings"
+)
+
+// HashAlgorithm names the function block hashes are computed with. The
+// zero value is SHA256, the algorithm of chains written by this tool.
+type HashAlgorithm string
+
+const (
+    SHA256   HashAlgorithm = "sha256"
+    // SHA256d is SHA-256 applied twice, as Bitcoin does.
+    SHA256d  HashAlgorithm = "sha256d"
+    SHA512   HashAlgorithm = "sha512"
+    SHA3_256 HashAlgorithm = "sha3-256"
+)
+
+// HashAlgorithms lists the supported algorithms, most common first.
+var HashAlgorithms = []HashAlgorithm{SHA256, SHA256d, SHA512, SHA3_256}
+
+// ParseHashAlgorithm returns the algorithm called name.
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+    for _, alg := range HashAlgorithms {
+        if string(alg) == strings.ToLower(name) {
+            return alg, nil
+        }
+    }
+    return "", fmt.Errorf("unknown hash algorithm %q (want one of %s)", name, JoinHashAlgorithms())
+}
+
+// JoinHashAlgorithms lists the supported algorithm names, for help text.
+func JoinHashAlgorithms() string {
+    names := make([]string, len(HashAlgorithms))
+    for i, alg := range HashAlgorithms {
+        names[i] = string(alg)
+    }
+    return strings.Join(names, ", ")
+}
+
+// Sum returns the hex digest of data.
+func (a HashAlgorithm) Sum(data []byte) string {
+    switch a {
+    case SHA256d:
+        first := sha256.Sum256(data)
+        sum := sha256.Sum256(first[:])
+        return hex.EncodeToString(sum[:])
+    case SHA512:
+        sum := sha512.Sum512(data)
+        return hex.EncodeToString(sum[:])
+    case SHA3_256:
+        sum := sha3.Sum256(data)
+        return hex.EncodeToString(sum[:])
+    }
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+// String returns the algorithm's name, "sha256" for the zero value.
+func (a HashAlgorithm) String() string {
+    if a == "" {
+        return string(SHA256)
+    }
+    return string(a)
+}
@@ -0,0 +1,133 @@
+package blocks
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "strings"
+)
+
+// Transactions splits the block data into its transactions, one per line.
+// Data without newlines is a single transaction; empty data has none.
+func (b *Block) Transactions() []string {
+    if b.Data == "" {
+        return nil
+    }
+    return strings.Split(b.Data, "\n")
+}
+
+// TxID is the hex SHA-256 of a transaction, the leaf of the Merkle tree.
+func TxID(tx string) string {
+    sum := sha256.Sum256([]byte(tx))
+    return hex.EncodeToString(sum[:])
+}
+
+// Domain separation prefixes of RFC 6962: a leaf hash can never equal an
+// interior node's, so a proof cannot pass an interior node off as a leaf.
+const (
+    leafPrefix = 0x00
+    nodePrefix = 0x01
+)
+
+// MerkleRoot returns the root of the RFC 6962 Merkle tree whose leaves are
+// the transactions' TxIDs: each leaf is SHA-256 of 0x00 and the raw TxID,
+// each parent SHA-256 of 0x01 and its children's hashes. An odd node at
+// the end of a level moves up a level unpaired; pairing it with itself,
+// as Bitcoin does, gives [a b c] and [a b c c] the same root
+// (CVE-2012-2459). There is no root for no transactions.
+func MerkleRoot(txs []string) string {
+    if len(txs) == 0 {
+        return ""
+    }
+    level := leaves(txs)
+    for len(level) > 1 {
+        level = nextLevel(level)
+    }
+    return hex.EncodeToString(level[0])
+}
+
+// ComputeMerkleRoot returns the Merkle root of the block's transactions.
+func (b *Block) ComputeMerkleRoot() string {
+    return MerkleRoot(b.Transactions())
+}
+
+func leaves(txs []string) [][]byte {
+    level := make([][]byte, len(txs))
+    for i, tx := range txs {
+        id := sha256.Sum256([]byte(tx))
+        level[i] = hashLeaf(id[:])
+    }
+    return level
+}
+
+func nextLevel(level [][]byte) [][]byte {
+    next := make([][]byte, 0, (len(level)+1)/2)
+    for i := 0; i < len(level); i += 2 {
+        if i+1 == len(level) {
+            next = append(next, level[i])
+            break
+        }
+        next = append(next, hashPair(level[i], level[i+1]))
+    }
+    return next
+}
+
+func hashLeaf(txID []byte) []byte {
+    h := sha256.New()
+    h.Write([]byte{leafPrefix})
+    h.Write(txID)
+    return h.Sum(nil)
+}
+
+func hashPair(left, right []byte) []byte {
+    h := sha256.New()
+    h.Write([]byte{nodePrefix})
+    h.Write(left)
+    h.Write(right)
+    return h.Sum(nil)
+}
+
+// ProofStep is one sibling on the path from a leaf to the Merkle root.
+type ProofStep struct {
+    Hash string `json:"hash"`
+    // Left is set when the sibling is the left child, i.e. it comes first
+    // when the pair is hashed.
+    Left bool   `json:"left"`
+}
+
+// MerkleProof returns the inclusion proof of txs[index]: the siblings from
+// the leaf up to, but not including, the root. Levels where the node
+// moves up unpaired add no step.
+func MerkleProof(txs []string, index int) []ProofStep {
+    level := leaves(txs)
+    path := []ProofStep{}
+    for len(level) > 1 {
+        if sibling := index ^ 1; sibling < len(level) {
+            path = append(path, ProofStep{Hash: hex.EncodeToString(level[sibling]), Left: sibling < index})
+        }
+        level = nextLevel(level)
+        index /= 2
+    }
+    return path
+}
+
+// VerifyMerkleProof reports whether path leads from the leaf of the
+// transaction with hash txID to root.
+func VerifyMerkleProof(txID string, path []ProofStep, root string) bool {
+    id, err := hex.DecodeString(txID)
+    if err != nil || len(id) != sha256.Size {
+        return false
+    }
+    node := hashLeaf(id)
+    for _, step := range path {
+        sibling, err := hex.DecodeString(step.Hash)
+        if err != nil || len(sibling) != sha256.Size {
+            return false
+        }
+        if step.Left {
+            node = hashPair(sibling, node)
+        } else {
+            node = hashPair(node, sibling)
+        }
+    }
+    return hex.EncodeToString(node) == root
+}
@@ -0,0 +1,95 @@
+package blocks
+
+import (
+    "encoding/hex"
+    "fmt"
+    "testing"
+)
+
+func sampleTxs(n int) []string {
+    txs := make([]string, n)
+    for i := range txs {
+        txs[i] = fmt.Sprintf("tx %d pays %d", i, i*7)
+    }
+    return txs
+}
+
+func TestMerkleProofRoundTrip(t *testing.T) {
+    for n := 1; n <= 17; n++ {
+        txs := sampleTxs(n)
+        root := MerkleRoot(txs)
+        for i, tx := range txs {
+            if path := MerkleProof(txs, i); !VerifyMerkleProof(TxID(tx), path, root) {
+                t.Errorf("%d txs: proof of tx %d does not verify", n, i)
+            }
+        }
+    }
+}
+
+func TestMerkleProofRejects(t *testing.T) {
+    txs := sampleTxs(4)
+    root := MerkleRoot(txs)
+    path := MerkleProof(txs, 0)
+    // The interior node over txs 0 and 1, as the proof of tx 0 climbs.
+    firstPair := hex.EncodeToString(hashPair(leaves(txs)[0], leaves(txs)[1]))
+    flipped := []ProofStep{{Hash: path[0].Hash, Left: !path[0].Left}, path[1]}
+    tampered := []ProofStep{{Hash: TxID("forged"), Left: path[0].Left}, path[1]}
+
+    tests := []struct {
+        name string
+        txID string
+        path []ProofStep
+        root string
+    }{
+        {"interior node as leaf", firstPair, path[1:], root},
+        {"other transaction", TxID(txs[2]), path, root},
+        {"unknown transaction", TxID("tx 9 pays 0"), path, root},
+        {"sibling swapped sides", TxID(txs[0]), flipped, root},
+        {"tampered sibling", TxID(txs[0]), tampered, root},
+        {"step dropped", TxID(txs[0]), path[:1], root},
+        {"other root", TxID(txs[0]), path, MerkleRoot(sampleTxs(5))},
+        {"malformed tx ID", "zz", path, root},
+        {"short sibling", TxID(txs[0]), []ProofStep{{Hash: "ab"}, path[1]}, root},
+    }
+    for _, tt := range tests {
+        if VerifyMerkleProof(tt.txID, tt.path, tt.root) {
+            t.Errorf("%s: proof verified", tt.name)
+        }
+    }
+}
+
+// TestMerkleRootDuplicateLast guards against CVE-2012-2459: repeating the
+// last transaction must change the root.
+func TestMerkleRootDuplicateLast(t *testing.T) {
+    for n := 1; n <= 9; n++ {
+        txs := sampleTxs(n)
+        if MerkleRoot(txs) == MerkleRoot(append(txs, txs[n-1])) {
+            t.Errorf("%d txs: root unchanged by repeating the last one", n)
+        }
+    }
+}
+
+// TestMerkleRootRFC6962 checks the shape of small trees against the RFC
+// 6962 definition, which splits at the largest power of two below n.
+func TestMerkleRootRFC6962(t *testing.T) {
+    var mth func(leaves [][]byte) []byte
+    mth = func(leaves [][]byte) []byte {
+        if len(leaves) == 1 {
+            return leaves[0]
+        }
+        k := 1
+        for k*2 < len(leaves) {
+            k *= 2
+        }
+        return hashPair(mth(leaves[:k]), mth(leaves[k:]))
+    }
+    for n := 1; n <= 33; n++ {
+        txs := sampleTxs(n)
+        if got, want := MerkleRoot(txs), hex.EncodeToString(mth(leaves(txs))); got != want {
+            t.Errorf("%d txs: root %s, RFC 6962 gives %s", n, got, want)
+        }
+    }
+    if MerkleRoot(nil) != "" {
+        t.Error("root of no transactions is not empty")
+    }
+}
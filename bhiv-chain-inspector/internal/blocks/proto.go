@@ -0,0 +1,132 @@
+package blocks
+
+import (
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "unicode/utf8"
+)
+
+// Protobuf wire types used by block.proto.
+const (
+    wireVarint  = 0
+    wireFixed64 = 1
+    wireBytes   = 2
+    wireFixed32 = 5
+)
+
+// MarshalProto encodes b as the Block message of block.proto. Fields with
+// their zero value are omitted, as proto3 does.
+func (b *Block) MarshalProto() []byte {
+    var buf []byte
+    varint := func(field int, v uint64) {
+        if v != 0 {
+            buf = binary.AppendUvarint(buf, uint64(field)<<3|wireVarint)
+            buf = binary.AppendUvarint(buf, v)
+        }
+    }
+    str := func(field int, s string) {
+        if s != "" {
+            buf = binary.AppendUvarint(buf, uint64(field)<<3|wireBytes)
+            buf = binary.AppendUvarint(buf, uint64(len(s)))
+            buf = append(buf, s...)
+        }
+    }
+    varint(1, uint64(int64(b.Height)))
+    str(2, b.Hash)
+    str(3, b.PrevHash)
+    str(4, b.Data)
+    varint(5, uint64(b.Timestamp))
+    str(6, b.MerkleRoot)
+    varint(7, uint64(int64(b.Difficulty)))
+    varint(8, b.Nonce)
+    str(9, b.Signer)
+    str(10, b.Signature)
+    str(11, b.ChainID)
+    return buf
+}
+
+// UnmarshalProto decodes a Block message into b. Unknown fields are
+// skipped; truncated input, unsupported wire types, fields with the wrong
+// wire type and strings that are not UTF-8 are errors.
+func (b *Block) UnmarshalProto(data []byte) error {
+    *b = Block{}
+    for len(data) > 0 {
+        tag, n := binary.Uvarint(data)
+        if n <= 0 {
+            return errors.New("proto: bad field tag")
+        }
+        data = data[n:]
+        field, wire := int(tag>>3), int(tag&7)
+        if field == 0 {
+            return errors.New("proto: field number 0")
+        }
+
+        var v uint64
+        var s string
+        switch wire {
+        case wireVarint:
+            if v, n = binary.Uvarint(data); n <= 0 {
+                return fmt.Errorf("proto: field %d: truncated varint", field)
+            }
+            data = data[n:]
+        case wireBytes:
+            size, n := binary.Uvarint(data)
+            if n <= 0 || size > uint64(len(data)-n) {
+                return fmt.Errorf("proto: field %d: truncated length-delimited value", field)
+            }
+            s, data = string(data[n:n+int(size)]), data[n+int(size):]
+        case wireFixed64, wireFixed32:
+            size := 8
+            if wire == wireFixed32 {
+                size = 4
+            }
+            if len(data) < size {
+                return fmt.Errorf("proto: field %d: truncated fixed value", field)
+            }
+            data = data[size:]
+        default:
+            return fmt.Errorf("proto: field %d: unsupported wire type %d", field, wire)
+        }
+
+        want := wireBytes
+        switch field {
+        case 1, 5, 7, 8:
+            want = wireVarint
+        case 2, 3, 4, 6, 9, 10, 11:
+            if !utf8.ValidString(s) {
+                return fmt.Errorf("proto: field %d: invalid UTF-8", field)
+            }
+        default:
+            continue
+        }
+        if wire != want {
+            return fmt.Errorf("proto: field %d: wire type %d, want %d", field, wire, want)
+        }
+        switch field {
+        case 1:
+            b.Height = int(int64(v))
+        case 2:
+            b.Hash = s
+        case 3:
+            b.PrevHash = s
+        case 4:
+            b.Data = s
+        case 5:
+            b.Timestamp = int64(v)
+        case 6:
+            b.MerkleRoot = s
+        case 7:
+            b.Difficulty = int(int64(v))
+        case 8:
+            b.Nonce = v
+        case 9:
+            b.Signer = s
+        case 10:
+            b.Signature = s
+        case 11:
+            b.ChainID = s
+        }
+    }
+    return nil
+}
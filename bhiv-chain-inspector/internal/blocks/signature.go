@@ -0,0 +1,38 @@
+package blocks
+
+import (
+    "crypto/ed25519"
+    "encoding/hex"
+    "errors"
+    "fmt"
+)
+
+// Signature errors returned, wrapped, by VerifySignature.
+var (
+    ErrUnsigned     = errors.New("block is not signed")
+    ErrBadSignature = errors.New("bad signature")
+)
+
+// Sign signs the block's hash with key and records the signer's public
+// key. Set Hash before signing; the signature covers nothing else.
+func (b *Block) Sign(key ed25519.PrivateKey) {
+    b.Signer = hex.EncodeToString(key.Public().(ed25519.PublicKey))
+    b.Signature = hex.EncodeToString(ed25519.Sign(key, []byte(b.Hash)))
+}
+
+// VerifySignature checks that Signature is Signer's ed25519 signature of
+// the block hash. It does not check whether Signer is trusted.
+func (b *Block) VerifySignature() error {
+    if b.Signature == "" || b.Signer == "" {
+        return fmt.Errorf("block %d: %w", b.Height, ErrUnsigned)
+    }
+    pub, err := hex.DecodeString(b.Signer)
+    if err != nil || len(pub) != ed25519.PublicKeySize {
+        return fmt.Errorf("block %d: %w: malformed signer key", b.Height, ErrBadSignature)
+    }
+    sig, err := hex.DecodeString(b.Signature)
+    if err != nil || !ed25519.Verify(pub, []byte(b.Hash), sig) {
+        return fmt.Errorf("block %d: %w", b.Height, ErrBadSignature)
+    }
+    return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+// Config is the inspector configuration file (--config), in YAML or JSON.
+//
+//  severity:
+//    empty_blocks: warning
+//    timestamp_past: info
+//  health:
+//    max_penalty_per_block: 1
+type Config struct {
+    // Severity maps an error class (e.g. "empty_blocks") to error,
+    // warning or info. Classes not listed are errors.
+    Severity map[string]string `json:"severity"`
+
+    // Health configures the health score model.
+    Health HealthConfig `json:"health"`
+
+    // Rules are user-defined expression checks run by every scan.
+    Rules []RuleConfig `json:"rules"`
+
+    // Difficulty configures the retarget rule of proof-of-work chains.
+    Difficulty DifficultyConfig `json:"difficulty"`
+}
+
+// DifficultyConfig enables the retarget check when RetargetInterval is
+// set: every RetargetInterval blocks, difficulty may move by up to MaxStep
+// bits (default 2) towards TargetBlockTime seconds per block, and must not
+// change in between.
+//
+//  difficulty:
+//    retarget_interval: 2016
+//    target_block_time: 600
+//    max_step: 2
+type DifficultyConfig struct {
+    RetargetInterval int   `json:"retarget_interval"`
+    TargetBlockTime  int64 `json:"target_block_time"`
+    MaxStep          int   `json:"max_step"`
+}
+
+// RuleConfig is one user-defined rule. The expression must hold for every
+// block; blocks where it is false are reported under the rule's name.
+//
+//  rules:
+//    - name: slow_block
+//      expr: block.timestamp - prev.timestamp < 60
+//      message: more than a minute since the previous block
+type RuleConfig struct {
+    Name    string `json:"name"`
+    Expr    string `json:"expr"`
+    Message string `json:"message"`
+}
+
+// HealthConfig weighs error classes for the health score.
+//
+//  health:
+//    weights:
+//      bad_hash: 1
+//      empty_blocks: 0.1
+//    max_penalty_per_block: 1
+type HealthConfig struct {
+    Weights            map[string]float64 `json:"weights"`
+    MaxPenaltyPerBlock *float64           `json:"max_penalty_per_block"`
+}
+
+// Load reads the config file at path. An empty path yields an empty config.
+func Load(path string) (*Config, error) {
+    cfg := &Config{}
+    if path == "" {
+        return cfg, nil
+    }
+    if err := DecodeFile(path, cfg); err != nil {
+        return nil, err
+    }
+    return cfg, nil
+}
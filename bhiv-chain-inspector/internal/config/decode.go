@@ -0,0 +1,44 @@
+package config
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "os"
+
+    "gopkg.in/yaml.v3"
+)
+
+// DecodeFile reads a YAML or JSON document from path into v. Unknown keys
+// are rejected so that typos in config files surface as errors.
+func DecodeFile(path string, v interface{}) error {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return err
+    }
+    if err := Decode(data, v); err != nil {
+        return fmt.Errorf("%s: %w", path, err)
+    }
+    return nil
+}
+
+// Decode parses a YAML or JSON document into v. JSON is YAML too; either
+// is decoded through its JSON form, so the json tags of v's fields name
+// the keys.
+func Decode(data []byte, v interface{}) error {
+    var tree interface{}
+    if err := yaml.Unmarshal(data, &tree); err != nil {
+        return err
+    }
+    encoded, err := json.Marshal(tree)
+    if err != nil {
+        return err
+    }
+    return decodeJSON(encoded, v)
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+    dec := json.NewDecoder(bytes.NewReader(data))
+    dec.DisallowUnknownFields()
+    return dec.Decode(v)
+}
@@ -0,0 +1,38 @@
+package config
+
+import "fmt"
+
+// ErrorProfile is the fault profile of "load --error-profile": the
+// percentage of blocks to corrupt with each fault, by the fault names of
+// the corrupt command plus "missing". The same seed and block count
+// corrupt the same heights in the same way.
+//
+//  seed: 42
+//  faults:
+//    badhash: 2
+//    missing: 1
+//    futurets: 0.5
+type ErrorProfile struct {
+    Seed   uint64             `json:"seed"`
+    Faults map[string]float64 `json:"faults"`
+}
+
+// LoadErrorProfile reads the profile at path, rejecting percentages
+// outside 0-100 and profiles that would corrupt more than every block.
+func LoadErrorProfile(path string) (*ErrorProfile, error) {
+    profile := &ErrorProfile{}
+    if err := DecodeFile(path, profile); err != nil {
+        return nil, err
+    }
+    total := 0.0
+    for fault, percent := range profile.Faults {
+        if percent < 0 || percent > 100 {
+            return nil, fmt.Errorf("%s: fault %s: %v%% is not a percentage", path, fault, percent)
+        }
+        total += percent
+    }
+    if total > 100 {
+        return nil, fmt.Errorf("%s: faults add up to %v%% of blocks", path, total)
+    }
+    return profile, nil
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// auditLogPrefix keys the audit log, ordered by the nanosecond time of
+// each entry. There is no API to delete or rewrite entries, and each one
+// carries the hash of the one before, so AuditLog notices entries removed
+// or edited behind the inspector's back.
+const auditLogPrefix = "audit-log-"
+
+// AuditChangesKept is the number of block changes an audit entry lists;
+// Blocks still counts them all.
+const AuditChangesKept = 100
+
+// Auditor says who is running which operation, for the audit entries of
+// the databases it writes to.
+type Auditor struct {
+    Operation string
+    Args      []string
+    User      string
+    Host      string
+}
+
+// AuditEntry is one operation that wrote blocks to the database.
+type AuditEntry struct {
+    // Time is in Unix nanoseconds.
+    Time      int64         `json:"time"`
+    User      string        `json:"user"`
+    Host      string        `json:"host"`
+    Operation string        `json:"operation"`
+    Args      []string      `json:"args,omitempty"`
+    Blocks    int           `json:"blocks"`
+    Changes   []BlockChange `json:"changes"`
+    // PrevHash is the SHA-256 of the stored previous entry; empty for the
+    // first.
+    PrevHash  string        `json:"prev_hash"`
+    // Broken is set by AuditLog when PrevHash does not match the entry
+    // stored before this one.
+    Broken    bool          `json:"broken,omitempty"`
+}
+
+// BlockChange is one block write. Hashes are empty where there was no
+// block (created, deleted) and "undecodable" for values that do not
+// decode.
+type BlockChange struct {
+    Height     int    `json:"height"`
+    BeforeHash string `json:"before_hash"`
+    AfterHash  string `json:"after_hash"`
+}
+
+var defaultAuditor *Auditor
+
+// SetDefaultAuditor makes storage opened afterwards record the blocks it
+// writes, and append them as one audit entry when closed; nil turns
+// auditing off.
+func SetDefaultAuditor(a *Auditor) {
+    defaultAuditor = a
+}
+
+// auditTrail collects the block writes of one open Storage. It is shared
+// by the views of WithContext.
+type auditTrail struct {
+    auditor *Auditor
+    blocks  int
+    changes []BlockChange
+}
+
+func (t *auditTrail) add(changes ...BlockChange) {
+    t.blocks += len(changes)
+    for _, c := range changes {
+        if len(t.changes) < AuditChangesKept {
+            t.changes = append(t.changes, c)
+        }
+    }
+}
+
+// auditHash identifies the block in value for an audit entry.
+func (s *Storage) auditHash(value []byte) string {
+    if value == nil {
+        return ""
+    }
+    block, _, err := s.DecodeBlock(value)
+    if err != nil {
+        return "undecodable"
+    }
+    return block.Hash
+}
+
+// blockChange describes writing value (nil to delete) to key, if key is a
+// block key and the storage is audited.
+func (s *Storage) blockChange(key, value []byte) (BlockChange, bool) {
+    if s.audit == nil || classifyKey(string(key)) != "block" {
+        return BlockChange{}, false
+    }
+    height, _ := strconv.Atoi(string(key[len("block-"):]))
+    old, _ := s.db.Get(key, nil)
+    return BlockChange{height, s.auditHash(old), s.auditHash(value)}, true
+}
+
+// flushAudit appends the entry of the writes made since the last flush,
+// if there were any.
+func (s *Storage) flushAudit() error {
+    if s.audit == nil || s.audit.blocks == 0 {
+        return nil
+    }
+    a := s.audit.auditor
+    entry := AuditEntry{
+        Time:      time.Now().UnixNano(),
+        User:      a.User,
+        Host:      a.Host,
+        Operation: a.Operation,
+        Args:      a.Args,
+        Blocks:    s.audit.blocks,
+        Changes:   s.audit.changes,
+    }
+    s.audit.blocks, s.audit.changes = 0, nil
+
+    iter := s.db.NewIterator(util.BytesPrefix([]byte(auditLogPrefix)), nil)
+    if iter.Last() {
+        sum := sha256.Sum256(iter.Value())
+        entry.PrevHash = hex.EncodeToString(sum[:])
+    }
+    iter.Release()
+    if err := iter.Error(); err != nil {
+        return err
+    }
+    data, err := json.Marshal(entry)
+    if err != nil {
+        return err
+    }
+    key := []byte(fmt.Sprintf("%s%020d", auditLogPrefix, entry.Time))
+    return s.db.Put(key, data, nil)
+}
+
+// AuditLog returns the audit entries, oldest first, with Broken set on
+// entries whose link to the one before does not hold.
+func (s *Storage) AuditLog() ([]AuditEntry, error) {
+    iter := s.db.NewIterator(util.BytesPrefix([]byte(auditLogPrefix)), nil)
+    defer iter.Release()
+
+    var entries []AuditEntry
+    prevHash := ""
+    for iter.Next() {
+        var entry AuditEntry
+        if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+            return nil, fmt.Errorf("corrupt audit entry %s: %w", iter.Key(), err)
+        }
+        entry.Broken = !strings.EqualFold(entry.PrevHash, prevHash)
+        sum := sha256.Sum256(iter.Value())
+        prevHash = hex.EncodeToString(sum[:])
+        entries = append(entries, entry)
+    }
+    return entries, iter.Error()
+}
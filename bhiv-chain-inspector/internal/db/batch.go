@@ -0,0 +1,84 @@
+package db
+
+import (
+    "fmt"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "github.com/syndtr/goleveldb/leveldb"
+    "github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// Batch collects block values to write to a Storage at once, for loading
+// large chains without a LevelDB write per block.
+type Batch struct {
+    s       *Storage
+    batch   leveldb.Batch
+    blocks  int
+    // changes are audited once written.
+    changes []BlockChange
+}
+
+// NewBatch returns an empty batch of writes to s.
+func (s *Storage) NewBatch() *Batch {
+    return &Batch{s: s}
+}
+
+// SaveBlockRaw adds data, as from EncodeBlock, as the block at height,
+// and indexes the hash it decodes to, if any.
+func (b *Batch) SaveBlockRaw(height int, data []byte) error {
+    block, _, err := b.s.DecodeBlock(data)
+    if err != nil {
+        block = nil
+    }
+    return b.save(height, data, block)
+}
+
+// SaveBlock adds block, encoded as by EncodeBlock, and its hash index
+// entry.
+func (b *Batch) SaveBlock(block *blocks.Block) error {
+    data, err := b.s.EncodeBlock(block)
+    if err != nil {
+        return err
+    }
+    return b.save(block.Height, data, block)
+}
+
+// save adds data as the block at height, and moves the hash index entry
+// of the block it replaces to block, nil if data does not decode. With a
+// journal, the old values are journaled now rather than on Write.
+func (b *Batch) save(height int, data []byte, block *blocks.Block) error {
+    key := []byte(fmt.Sprintf("block-%d", height))
+    old, err := b.s.db.Get(key, nil)
+    if err != nil && err != leveldb.ErrNotFound {
+        return err
+    }
+    if err := b.s.journalWrite(key); err != nil {
+        return err
+    }
+    if change, audited := b.s.blockChange(key, data); audited {
+        b.changes = append(b.changes, change)
+    }
+    b.batch.Put(key, data)
+    b.blocks++
+    return b.reindex(height, old, block)
+}
+
+// Len is the number of blocks waiting.
+func (b *Batch) Len() int {
+    return b.blocks
+}
+
+// Write applies the waiting writes atomically and empties the batch. With
+// sync it returns only once they are flushed to disk.
+func (b *Batch) Write(sync bool) error {
+    var err error
+    if !b.s.dryRun {
+        err = b.s.db.Write(&b.batch, &opt.WriteOptions{Sync: sync})
+    }
+    if err == nil && b.s.audit != nil {
+        b.s.audit.add(b.changes...)
+    }
+    b.batch.Reset()
+    b.blocks, b.changes = 0, nil
+    return err
+}
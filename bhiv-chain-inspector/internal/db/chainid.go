@@ -0,0 +1,27 @@
+package db
+
+import "github.com/syndtr/goleveldb/leveldb"
+
+const chainIDKey = "chain-id"
+
+// ChainID returns the chain ID the database declares, or "" if it
+// declares none. A CometBFT blockstore declares the chain ID of its base
+// block.
+func (s *Storage) ChainID() (string, error) {
+    if s.layout == LayoutCometBFT {
+        return s.cometChainID()
+    }
+    data, err := s.db.Get([]byte(chainIDKey), nil)
+    if err == leveldb.ErrNotFound {
+        return "", nil
+    }
+    if err != nil {
+        return "", err
+    }
+    return string(data), nil
+}
+
+// SetChainID declares the chain the database holds.
+func (s *Storage) SetChainID(id string) error {
+    return s.put([]byte(chainIDKey), []byte(id))
+}
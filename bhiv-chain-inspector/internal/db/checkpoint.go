@@ -0,0 +1,77 @@
+package db
+
+import (
+    "encoding/json"
+
+    "github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+    checkpointKey  = "scan-checkpoint"
+    verifiedTipKey = "verified-tip"
+)
+
+// CheckpointHashes is the number of hashes a Checkpoint keeps, and so the
+// deepest reorg whose fork point can be found.
+const CheckpointHashes = 100
+
+// Checkpoint records the tip of the chain as last verified, so that later
+// runs can resume from it and notice if it was rewritten.
+type Checkpoint struct {
+    Height int    `json:"height"`
+    Hash   string `json:"hash"`
+    Time   int64  `json:"time"`
+    // Recent holds the hashes of the blocks up to Height, oldest first and
+    // at most CheckpointHashes of them; empty in older checkpoints.
+    Recent []string `json:"recent,omitempty"`
+}
+
+// SaveCheckpoint replaces the stored checkpoint of scan and watch.
+func (s *Storage) SaveCheckpoint(cp *Checkpoint) error {
+    return s.saveCheckpointAt(checkpointKey, cp)
+}
+
+// LoadCheckpoint returns the stored checkpoint of scan and watch, or nil
+// if there is none.
+func (s *Storage) LoadCheckpoint() (*Checkpoint, error) {
+    return s.loadCheckpointAt(checkpointKey)
+}
+
+// SaveVerifiedTip replaces the verified tip of verify-new. It is kept
+// apart from the scan checkpoint, which scans move up whatever they find,
+// so that only blocks that passed verification count as verified.
+func (s *Storage) SaveVerifiedTip(cp *Checkpoint) error {
+    return s.saveCheckpointAt(verifiedTipKey, cp)
+}
+
+// LoadVerifiedTip returns the verified tip of verify-new, or nil if there
+// is none.
+func (s *Storage) LoadVerifiedTip() (*Checkpoint, error) {
+    return s.loadCheckpointAt(verifiedTipKey)
+}
+
+func (s *Storage) saveCheckpointAt(key string, cp *Checkpoint) error {
+    if s.dryRun {
+        return nil
+    }
+    data, err := json.Marshal(cp)
+    if err != nil {
+        return err
+    }
+    return s.db.Put([]byte(key), data, nil)
+}
+
+func (s *Storage) loadCheckpointAt(key string) (*Checkpoint, error) {
+    data, err := s.db.Get([]byte(key), nil)
+    if err == leveldb.ErrNotFound {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    var cp Checkpoint
+    if err := json.Unmarshal(data, &cp); err != nil {
+        return nil, err
+    }
+    return &cp, nil
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+    "bytes"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "github.com/syndtr/goleveldb/leveldb"
+)
+
+// CometBFT (and Tendermint) blockstore keys, up to v0.38: the store state
+// under "blockStore", and the BlockMeta of each height under "H:<height>".
+// Block parts ("P:"), commits ("C:", "SC:") and the hash index ("BH:")
+// are not read.
+const cometStateKey = "blockStore"
+
+func cometMetaKey(height int) []byte {
+    return []byte(fmt.Sprintf("H:%d", height))
+}
+
+// cometState is the BlockStoreState: the lowest and highest heights held.
+// It is protobuf since Tendermint v0.34 and amino JSON before.
+type cometState struct {
+    Base   int64 `json:"base,string"`
+    Height int64 `json:"height,string"`
+}
+
+func (s *Storage) cometState() (*cometState, error) {
+    data, err := s.db.Get([]byte(cometStateKey), nil)
+    if err != nil {
+        return nil, err
+    }
+    var state cometState
+    if bytes.HasPrefix(data, []byte("{")) {
+        return &state, json.Unmarshal(data, &state)
+    }
+    err = protoFields(data, func(field, wire int, v uint64, _ []byte) error {
+        switch {
+        case field == 1 && wire == 0:
+            state.Base = int64(v)
+        case field == 2 && wire == 0:
+            state.Height = int64(v)
+        }
+        return nil
+    })
+    return &state, err
+}
+
+// cometMaxHeight is the height of the last block stored, or -1.
+func (s *Storage) cometMaxHeight() int {
+    state, err := s.cometState()
+    if err != nil || state.Height == 0 {
+        return -1
+    }
+    return int(state.Height)
+}
+
+// cometFirstHeight is the store's base: the chain's initial height, or the
+// lowest height kept by a pruned node.
+func (s *Storage) cometFirstHeight() int {
+    state, err := s.cometState()
+    if err != nil || state.Base < 0 {
+        return 0
+    }
+    return int(state.Base)
+}
+
+// cometChainID is the chain ID in the header of the store's base block.
+func (s *Storage) cometChainID() (string, error) {
+    raw, err := s.db.Get(cometMetaKey(s.cometFirstHeight()), nil)
+    if err != nil {
+        if err == leveldb.ErrNotFound {
+            return "", nil
+        }
+        return "", err
+    }
+    block, err := decodeCometMeta(raw)
+    if err != nil {
+        return "", err
+    }
+    return block.ChainID, nil
+}
+
+// decodeCometMeta maps a BlockMeta to a Block: the block ID hash, the
+// header's last block ID hash ("0" at the initial height), height, time
+// (whole seconds) and chain ID. Data is left empty, as the transactions
+// live in the block parts.
+func decodeCometMeta(raw []byte) (*blocks.Block, error) {
+    var block blocks.Block
+    var header []byte
+    err := protoFields(raw, func(field, wire int, _ uint64, b []byte) error {
+        switch {
+        case field == 1 && wire == 2:
+            hash, err := cometBlockIDHash(b)
+            block.Hash = hash
+            return err
+        case field == 3 && wire == 2:
+            header = b
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    if block.Hash == "" || header == nil {
+        return nil, errors.New("cometbft block meta: no block ID or header")
+    }
+
+    block.PrevHash = "0"
+    err = protoFields(header, func(field, wire int, v uint64, b []byte) error {
+        switch {
+        case field == 2 && wire == 2:
+            block.ChainID = string(b)
+        case field == 3 && wire == 0:
+            block.Height = int(int64(v))
+        case field == 4 && wire == 2:
+            return protoFields(b, func(field, wire int, v uint64, _ []byte) error {
+                if field == 1 && wire == 0 {
+                    block.Timestamp = int64(v)
+                }
+                return nil
+            })
+        case field == 5 && wire == 2:
+            hash, err := cometBlockIDHash(b)
+            if hash != "" {
+                block.PrevHash = hash
+            }
+            return err
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("cometbft header: %w", err)
+    }
+    return &block, nil
+}
+
+// cometBlockIDHash returns the hex hash of a BlockID message.
+func cometBlockIDHash(data []byte) (string, error) {
+    var hash string
+    err := protoFields(data, func(field, wire int, _ uint64, b []byte) error {
+        if field == 1 && wire == 2 {
+            hash = hex.EncodeToString(b)
+        }
+        return nil
+    })
+    return hash, err
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+    "bytes"
+    "compress/gzip"
+    "fmt"
+    "io"
+
+    "github.com/klauspost/compress/zstd"
+)
+
+// Compression is how block values are compressed on write. Reads detect
+// the compression of each value from its magic bytes, so a database may
+// mix compressed and plain blocks whatever the setting.
+type Compression string
+
+const (
+    CompressionNone Compression = "none"
+    CompressionGzip Compression = "gzip"
+    CompressionZstd Compression = "zstd"
+)
+
+// Compressions lists the supported compressions.
+var Compressions = []Compression{CompressionNone, CompressionGzip, CompressionZstd}
+
+// maxDecompressedSize caps a decompressed block value, so a corrupt or
+// hostile value cannot exhaust memory.
+const maxDecompressedSize = 64 << 20
+
+var (
+    gzipMagic = []byte{0x1f, 0x8b}
+    zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// zstdEncoder and zstdDecoder are shared by every storage; EncodeAll and
+// DecodeAll are safe for concurrent use. The decoder refuses to produce
+// more than maxDecompressedSize bytes.
+var (
+    zstdEncoder, _ = zstd.NewWriter(nil)
+    zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+)
+
+// ParseCompression returns the compression called name.
+func ParseCompression(name string) (Compression, error) {
+    for _, c := range Compressions {
+        if string(c) == name {
+            return c, nil
+        }
+    }
+    return "", fmt.Errorf("unknown compression %q (want none, gzip or zstd)", name)
+}
+
+// defaultCompression is the compression of storages opened by NewStorage.
+var defaultCompression = CompressionNone
+
+// SetDefaultCompression sets the compression blocks are written with by
+// storages opened afterwards; the CLI calls it for --compress.
+func SetDefaultCompression(c Compression) {
+    defaultCompression = c
+}
+
+func (c Compression) compress(data []byte) ([]byte, error) {
+    switch c {
+    case CompressionGzip:
+        var buf bytes.Buffer
+        zw := gzip.NewWriter(&buf)
+        if _, err := zw.Write(data); err != nil {
+            return nil, err
+        }
+        if err := zw.Close(); err != nil {
+            return nil, err
+        }
+        return buf.Bytes(), nil
+    case CompressionZstd:
+        return zstdEncoder.EncodeAll(data, nil), nil
+    }
+    return data, nil
+}
+
+// decompress returns data without its compression, and which one it had.
+// No block encoding starts with either magic number.
+func decompress(data []byte) ([]byte, Compression, error) {
+    switch {
+    case bytes.HasPrefix(data, gzipMagic):
+        zr, err := gzip.NewReader(bytes.NewReader(data))
+        if err != nil {
+            return nil, CompressionGzip, fmt.Errorf("gzip: %w", err)
+        }
+        out, err := io.ReadAll(io.LimitReader(zr, maxDecompressedSize+1))
+        if err == nil && len(out) > maxDecompressedSize {
+            err = fmt.Errorf("value exceeds %d bytes", maxDecompressedSize)
+        }
+        if err != nil {
+            return nil, CompressionGzip, fmt.Errorf("gzip: %w", err)
+        }
+        return out, CompressionGzip, nil
+    case bytes.HasPrefix(data, zstdMagic):
+        out, err := zstdDecoder.DecodeAll(data, nil)
+        if err != nil {
+            return nil, CompressionZstd, fmt.Errorf("zstd: %w", err)
+        }
+        return out, CompressionZstd, nil
+    }
+    return data, CompressionNone, nil
+}
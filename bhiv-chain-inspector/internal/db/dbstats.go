@@ -0,0 +1,153 @@
+package db
+
+import (
+    "slices"
+    "strconv"
+    "strings"
+
+    "github.com/syndtr/goleveldb/leveldb"
+)
+
+// largestBlocksListed is the number of blocks StorageStats.Largest holds.
+const largestBlocksListed = 5
+
+// StorageStats describes the disk usage of a database: its keys and
+// values, the sizes of its stored blocks, and LevelDB's own tables.
+type StorageStats struct {
+    TotalKeys       int          `json:"total_keys"`
+    TotalKeyBytes   int64        `json:"total_key_bytes"`
+    TotalValueBytes int64        `json:"total_value_bytes"`
+    Blocks          int          `json:"blocks"`
+    BlockBytes      int64        `json:"block_bytes"`
+    // BlockSizes summarises the stored (encoded) size of each block.
+    BlockSizes      SizeSummary  `json:"block_sizes"`
+    Largest         []BlockSize  `json:"largest_blocks"`
+    Levels          []LevelStats `json:"levels"`
+    // OpenedTables and BlockCacheBytes are LevelDB's in-memory state.
+    OpenedTables    int          `json:"opened_tables"`
+    BlockCacheBytes int          `json:"block_cache_bytes"`
+}
+
+// SizeSummary are the mean and nearest-rank percentiles of value sizes.
+type SizeSummary struct {
+    Mean float64 `json:"mean"`
+    P50  int     `json:"p50"`
+    P90  int     `json:"p90"`
+    P99  int     `json:"p99"`
+    Max  int     `json:"max"`
+}
+
+// BlockSize is the stored size of the block at Height.
+type BlockSize struct {
+    Height int `json:"height"`
+    Size   int `json:"size"`
+}
+
+// LevelStats is one level of the LevelDB tree. Read and Write are the
+// bytes compactions have read from and written to it.
+type LevelStats struct {
+    Level  int   `json:"level"`
+    Tables int   `json:"tables"`
+    Size   int64 `json:"size"`
+    Read   int64 `json:"compaction_read"`
+    Write  int64 `json:"compaction_write"`
+}
+
+// Stats iterates the whole keyspace. Blocks are the "block-<height>" keys
+// of the inspector layout; for other layouts they are the values the
+// layout reads from FirstHeight to GetMaxHeight.
+func (s *Storage) Stats() (*StorageStats, error) {
+    stats := &StorageStats{Largest: []BlockSize{}, Levels: []LevelStats{}}
+    var sizes []BlockSize
+    iter := s.db.NewIterator(nil, nil)
+    for iter.Next() {
+        key, size := string(iter.Key()), len(iter.Value())
+        stats.TotalKeys++
+        stats.TotalKeyBytes += int64(len(key))
+        stats.TotalValueBytes += int64(size)
+        if s.layout == LayoutInspector && classifyKey(key) == "block" {
+            height, _ := strconv.Atoi(strings.TrimPrefix(key, "block-"))
+            sizes = append(sizes, BlockSize{height, size})
+        }
+    }
+    iter.Release()
+    if err := iter.Error(); err != nil {
+        return nil, err
+    }
+    if s.layout != LayoutInspector {
+        for height := s.FirstHeight(); height <= s.GetMaxHeight(); height++ {
+            if raw, err := s.loadBlockRaw(height); err == nil {
+                sizes = append(sizes, BlockSize{height, len(raw)})
+            }
+        }
+    }
+    stats.summarize(sizes)
+
+    var ldb leveldb.DBStats
+    if err := s.db.Stats(&ldb); err != nil {
+        return nil, err
+    }
+    for level := range ldb.LevelSizes {
+        stats.Levels = append(stats.Levels, LevelStats{
+            Level:  level,
+            Tables: ldb.LevelTablesCounts[level],
+            Size:   ldb.LevelSizes[level],
+            Read:   ldb.LevelRead[level],
+            Write:  ldb.LevelWrite[level],
+        })
+    }
+    stats.OpenedTables = ldb.OpenedTablesCount
+    stats.BlockCacheBytes = ldb.BlockCacheSize
+    return stats, nil
+}
+
+func (stats *StorageStats) summarize(sizes []BlockSize) {
+    summary := SummarizeBlockSizes(sizes, largestBlocksListed)
+    stats.Blocks = summary.Blocks
+    stats.BlockBytes = summary.Bytes
+    stats.BlockSizes = summary.Sizes
+    stats.Largest = summary.Largest
+}
+
+// BlockSizes summarises the stored sizes of a set of blocks.
+type BlockSizes struct {
+    Blocks  int         `json:"blocks"`
+    Bytes   int64       `json:"bytes"`
+    Sizes   SizeSummary `json:"sizes"`
+    Largest []BlockSize `json:"largest_blocks"`
+}
+
+// SummarizeBlockSizes totals sizes and lists the largest blocks, up to
+// largest of them, biggest first. sizes is not modified.
+func SummarizeBlockSizes(sizes []BlockSize, largest int) *BlockSizes {
+    summary := &BlockSizes{Blocks: len(sizes), Largest: []BlockSize{}}
+    if len(sizes) == 0 {
+        return summary
+    }
+    sizes = slices.Clone(sizes)
+    slices.SortFunc(sizes, func(a, b BlockSize) int {
+        if a.Size != b.Size {
+            return b.Size - a.Size
+        }
+        return a.Height - b.Height
+    })
+    summary.Largest = append(summary.Largest, sizes[:min(largest, len(sizes))]...)
+
+    // sizes is in descending order; rank r of n ascending is n-r.
+    n := len(sizes)
+    percentile := func(p int) int {
+        rank := (p*n + 99) / 100
+        return sizes[n-max(rank, 1)].Size
+    }
+    for _, b := range sizes {
+        summary.Bytes += int64(b.Size)
+    }
+    summary.Sizes = SizeSummary{
+        Mean: float64(summary.Bytes) / float64(n),
+        P50:  percentile(50),
+        P90:  percentile(90),
+        P99:  percentile(99),
+        Max:  sizes[0].Size,
+    }
+    return summary
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+    "log/slog"
+    "os"
+    "path/filepath"
+
+    "github.com/syndtr/goleveldb/leveldb"
+    "github.com/syndtr/goleveldb/leveldb/opt"
+    "github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+var defaultDryRun bool
+
+// SetDefaultDryRun makes storage opened afterwards write nothing to disk.
+// An existing database is opened read-only: its writes are skipped, and
+// the blocks they would have changed are logged on Close. A database that
+// does not exist yet is created in memory instead and written as usual,
+// so that commands building one can still check what they built.
+func SetDefaultDryRun(on bool) {
+    defaultDryRun = on
+}
+
+// DryRun reports whether storage is opened as a dry run.
+func DryRun() bool {
+    return defaultDryRun
+}
+
+// openDryRun opens dbPath as SetDefaultDryRun describes, reporting whether
+// writes must be skipped.
+func openDryRun(dbPath string) (*leveldb.DB, bool, error) {
+    if _, err := os.Stat(filepath.Join(dbPath, "CURRENT")); err != nil {
+        database, err := leveldb.Open(storage.NewMemStorage(), nil)
+        return database, false, err
+    }
+    database, err := leveldb.OpenFile(dbPath, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+    return database, true, err
+}
+
+// logDryRun logs the block writes a dry run skipped.
+func (s *Storage) logDryRun() {
+    if s.audit == nil || s.audit.blocks == 0 {
+        return
+    }
+    for _, c := range s.audit.changes {
+        slog.Info("dry run: block not written", "db", s.path, "height", c.Height,
+            "before_hash", c.BeforeHash, "after_hash", c.AfterHash)
+    }
+    slog.Info("dry run: nothing written", "db", s.path, "blocks", s.audit.blocks)
+    s.audit.blocks, s.audit.changes = 0, nil
+}
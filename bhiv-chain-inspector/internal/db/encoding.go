@@ -0,0 +1,155 @@
+package db
+
+import (
+    "bytes"
+    "encoding/gob"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+)
+
+// Encoding is the format block values are stored in.
+type Encoding string
+
+const (
+    EncodingJSON  Encoding = "json"
+    // EncodingProto is the Block message of internal/blocks/block.proto.
+    EncodingProto Encoding = "proto"
+    // EncodingCBOR is a CBOR map keyed by the JSON field names.
+    EncodingCBOR  Encoding = "cbor"
+    // EncodingGob is a gob stream of one blocks.Block.
+    EncodingGob   Encoding = "gob"
+    // EncodingAuto sniffs each value and decodes it with the first
+    // encoding in autoEncodings that accepts it; blocks are written as
+    // JSON. It lets mixed-encoding databases be read in one pass.
+    EncodingAuto  Encoding = "auto"
+)
+
+// Encodings lists the supported encodings.
+var Encodings = []Encoding{EncodingJSON, EncodingProto, EncodingCBOR, EncodingGob, EncodingAuto}
+
+// autoEncodings is the order EncodingAuto tries. Gob and CBOR carry
+// their own structure and go before protobuf, which accepts many byte
+// strings.
+var autoEncodings = []Encoding{EncodingJSON, EncodingCBOR, EncodingGob, EncodingProto}
+
+// errUnrecognized is returned by EncodingAuto for values no encoding
+// accepts.
+var errUnrecognized = errors.New("not JSON, CBOR, gob or protobuf")
+
+// ParseEncoding returns the encoding called name.
+func ParseEncoding(name string) (Encoding, error) {
+    names := make([]string, len(Encodings))
+    for i, e := range Encodings {
+        if string(e) == name {
+            return e, nil
+        }
+        names[i] = string(e)
+    }
+    return "", fmt.Errorf("unknown encoding %q (want one of %s)", name, strings.Join(names, ", "))
+}
+
+// defaultEncoding is the encoding of storages opened by NewStorage.
+var defaultEncoding = EncodingJSON
+
+// SetDefaultEncoding sets the encoding of storages opened afterwards, like
+// slog.SetDefault; the CLI calls it for --encoding. Use WithEncoding for
+// a single storage.
+func SetDefaultEncoding(e Encoding) {
+    defaultEncoding = e
+}
+
+// Label is the name of the encoding in messages, e.g. "Corrupted JSON".
+func (e Encoding) Label() string {
+    switch e {
+    case EncodingProto:
+        return "protobuf"
+    case EncodingCBOR:
+        return "CBOR"
+    case EncodingGob:
+        return "gob"
+    case EncodingAuto:
+        return "value"
+    }
+    return "JSON"
+}
+
+func (e Encoding) marshal(block *blocks.Block) ([]byte, error) {
+    switch e {
+    case EncodingProto:
+        return block.MarshalProto(), nil
+    case EncodingCBOR:
+        return block.MarshalCBOR(), nil
+    case EncodingGob:
+        var buf bytes.Buffer
+        err := gob.NewEncoder(&buf).Encode(block)
+        return buf.Bytes(), err
+    }
+    return json.Marshal(block)
+}
+
+// unmarshal decodes data and returns the encoding it was decoded with,
+// which for EncodingAuto is the detected one.
+func (e Encoding) unmarshal(data []byte) (*blocks.Block, Encoding, error) {
+    if e == EncodingAuto {
+        return unmarshalAuto(data)
+    }
+    var block blocks.Block
+    var err error
+    switch e {
+    case EncodingProto:
+        err = block.UnmarshalProto(data)
+    case EncodingCBOR:
+        err = block.UnmarshalCBOR(data)
+    case EncodingGob:
+        err = gob.NewDecoder(bytes.NewReader(data)).Decode(&block)
+    default:
+        err = json.Unmarshal(data, &block)
+    }
+    if err != nil {
+        return nil, e, err
+    }
+    return &block, e, nil
+}
+
+// unmarshalAuto tries autoEncodings in order. When a value looks like
+// JSON or CBOR but does not decode, that decoder's error is returned
+// rather than errUnrecognized.
+func unmarshalAuto(data []byte) (*blocks.Block, Encoding, error) {
+    var firstErr error
+    for _, e := range autoEncodings {
+        if !e.sniff(data) {
+            continue
+        }
+        block, _, err := e.unmarshal(data)
+        // Stray bytes often parse as protobuf made of unknown fields; a
+        // block without a hash is not taken for one.
+        if err == nil && (e != EncodingProto || block.Hash != "") {
+            return block, e, nil
+        }
+        if firstErr == nil && (e == EncodingJSON || e == EncodingCBOR) {
+            firstErr = fmt.Errorf("%s: %w", e.Label(), err)
+        }
+    }
+    if firstErr == nil {
+        firstErr = errUnrecognized
+    }
+    return nil, EncodingAuto, firstErr
+}
+
+// sniff reports whether data may be in encoding e, from its first byte.
+// Gob and protobuf have no reliable marker and are always tried.
+func (e Encoding) sniff(data []byte) bool {
+    switch e {
+    case EncodingJSON:
+        trimmed := bytes.TrimLeft(data, " \t\r\n")
+        return len(trimmed) > 0 && trimmed[0] == '{'
+    case EncodingCBOR:
+        // A map (major type 5), possibly behind the self-describe tag.
+        return len(data) > 0 && (data[0]>>5 == 5 || bytes.HasPrefix(data, []byte{0xd9, 0xd9, 0xf7}))
+    }
+    return len(data) > 0
+}
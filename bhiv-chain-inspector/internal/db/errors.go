@@ -0,0 +1,14 @@
+package db
+
+import "errors"
+
+// Sentinel errors returned, wrapped with the height, by the block readers.
+// Test for them with errors.Is.
+var (
+    // ErrBlockMissing means no block is stored at the height.
+    ErrBlockMissing = errors.New("block missing")
+    // ErrCorruptJSON means the stored block does not decode, as JSON or in
+    // whichever encoding the storage reads. The decoder's error is wrapped
+    // as well, so errors.As can reach *json.SyntaxError.
+    ErrCorruptJSON = errors.New("corrupt block JSON")
+)
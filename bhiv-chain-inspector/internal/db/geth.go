@@ -0,0 +1,113 @@
+package db
+
+import (
+    "bytes"
+    "encoding/binary"
+    "encoding/hex"
+    "fmt"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "github.com/syndtr/goleveldb/leveldb"
+    "github.com/syndtr/goleveldb/leveldb/util"
+    "golang.org/x/crypto/sha3"
+)
+
+// go-ethereum chaindata keys (core/rawdb/schema.go). Headers are stored
+// RLP-encoded under "h" + number + hash; the canonical hash of a number
+// under "h" + number + "n"; the number of a hash under "H" + hash.
+const (
+    gethHeaderPrefix  = "h"
+    gethNumberPrefix  = "H"
+    gethHashSuffix    = "n"
+    gethHeadHeaderKey = "LastHeader"
+)
+
+// Header list positions of the fields the inspector reads.
+const (
+    gethParentHash = 0
+    gethNumber     = 8
+    gethTime       = 11
+)
+
+func gethNumberKey(number uint64) []byte {
+    return binary.BigEndian.AppendUint64([]byte(gethHeaderPrefix), number)
+}
+
+// gethHeaderRaw returns the RLP of the canonical header at height.
+func (s *Storage) gethHeaderRaw(height int) ([]byte, error) {
+    if height < 0 {
+        return nil, leveldb.ErrNotFound
+    }
+    hash, err := s.db.Get(append(gethNumberKey(uint64(height)), gethHashSuffix...), nil)
+    if err == nil {
+        return s.db.Get(append(gethNumberKey(uint64(height)), hash...), nil)
+    }
+    return nil, err
+}
+
+// gethMaxHeight is the number of the head header, or -1.
+func (s *Storage) gethMaxHeight() int {
+    hash, err := s.db.Get([]byte(gethHeadHeaderKey), nil)
+    if err != nil {
+        return -1
+    }
+    number, err := s.db.Get(append([]byte(gethNumberPrefix), hash...), nil)
+    if err != nil || len(number) != 8 {
+        return -1
+    }
+    return int(binary.BigEndian.Uint64(number))
+}
+
+// gethFirstHeight is the lowest canonical header kept in LevelDB. Geth
+// moves old blocks to its append-only "ancient" freezer files, which the
+// inspector does not read, so this is usually far above genesis.
+func (s *Storage) gethFirstHeight() int {
+    iter := s.db.NewIterator(util.BytesPrefix([]byte(gethHeaderPrefix)), nil)
+    defer iter.Release()
+    for iter.Next() {
+        key := iter.Key()
+        if len(key) == 10 && key[9] == gethHashSuffix[0] {
+            return int(binary.BigEndian.Uint64(key[1:9]))
+        }
+    }
+    return 0
+}
+
+// decodeGethHeader maps an RLP header to a Block. Hash is the Keccak-256
+// of the header, as geth computes it, so a rewritten header breaks the
+// PrevHash linkage of its child. The genesis parent hash (all zeros) is
+// mapped to "0", the inspector's genesis convention. Data and the other
+// optional fields are left empty.
+func decodeGethHeader(raw []byte) (*blocks.Block, error) {
+    fields, err := rlpList(raw)
+    if err != nil {
+        return nil, err
+    }
+    if len(fields) <= gethTime {
+        return nil, fmt.Errorf("geth header: %d fields, want at least %d", len(fields), gethTime+1)
+    }
+    parent := fields[gethParentHash]
+    if len(parent) != 32 {
+        return nil, fmt.Errorf("geth header: parent hash of %d bytes", len(parent))
+    }
+    number, err := rlpUint(fields[gethNumber])
+    if err != nil {
+        return nil, fmt.Errorf("geth header number: %w", err)
+    }
+    timestamp, err := rlpUint(fields[gethTime])
+    if err != nil {
+        return nil, fmt.Errorf("geth header time: %w", err)
+    }
+    hash := sha3.NewLegacyKeccak256()
+    hash.Write(raw)
+    block := &blocks.Block{
+        Height:    int(number),
+        Hash:      hex.EncodeToString(hash.Sum(nil)),
+        PrevHash:  hex.EncodeToString(parent),
+        Timestamp: int64(timestamp),
+    }
+    if number == 0 && bytes.Equal(parent, make([]byte, 32)) {
+        block.PrevHash = "0"
+    }
+    return block, nil
+}
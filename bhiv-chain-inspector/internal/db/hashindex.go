@@ -0,0 +1,226 @@
+package db
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "slices"
+    "strconv"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "github.com/syndtr/goleveldb/leveldb"
+    "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// The hash index maps "hash-<block hash>" to the decimal height of the
+// block. Every block write moves the entry of the block it replaces to the
+// new one; the index command adds entries for blocks written before, then
+// sets hashIndexKey to mark the index complete. Entries are journaled with
+// the blocks they derive from, so that undo restores both, and lookups
+// ignore entries whose block has since been overwritten otherwise.
+const (
+    hashIndexPrefix = "hash-"
+    hashIndexKey    = "hash-index"
+)
+
+// ErrNotIndexed means a hash was not found in an index that has never been
+// built, so the block may still exist; see BuildHashIndex and FindHash.
+var ErrNotIndexed = errors.New("hash index not built")
+
+func hashKey(hash string) []byte {
+    return []byte(hashIndexPrefix + strings.ToLower(hash))
+}
+
+// HashIndexed reports whether the hash index has been built.
+func (s *Storage) HashIndexed() (bool, error) {
+    _, err := s.db.Get([]byte(hashIndexKey), nil)
+    if err == leveldb.ErrNotFound {
+        return false, nil
+    }
+    return err == nil, err
+}
+
+// LookupHash returns the height of the block whose hash is hash. A miss
+// is ErrBlockMissing once the index is built and ErrNotIndexed before.
+func (s *Storage) LookupHash(hash string) (int, error) {
+    data, err := s.db.Get(hashKey(hash), nil)
+    if err != nil && err != leveldb.ErrNotFound {
+        return 0, err
+    }
+    if err == nil {
+        height, _ := strconv.Atoi(string(data))
+        if block, err := s.loadBlock(height); err == nil && strings.EqualFold(block.Hash, hash) {
+            return height, nil
+        }
+    }
+    indexed, err := s.HashIndexed()
+    if err != nil {
+        return 0, err
+    }
+    if !indexed {
+        return 0, fmt.Errorf("hash %s: %w", hash, ErrNotIndexed)
+    }
+    return 0, fmt.Errorf("hash %s: %w", hash, ErrBlockMissing)
+}
+
+// FindHash looks for the block whose hash is hash by decoding every stored
+// block, for databases without a hash index.
+func (s *Storage) FindHash(hash string) (int, error) {
+    found := -1
+    err := s.BlockValues(func(height int, value []byte) error {
+        if block, _, err := s.DecodeBlock(value); err == nil && strings.EqualFold(block.Hash, hash) {
+            found = height
+            return errStopBlockValues
+        }
+        return nil
+    })
+    if err != nil && err != errStopBlockValues {
+        return 0, err
+    }
+    if found < 0 {
+        return 0, fmt.Errorf("hash %s: %w", hash, ErrBlockMissing)
+    }
+    return found, nil
+}
+
+var errStopBlockValues = errors.New("stop")
+
+// BuildHashIndex indexes the hash of every stored block that decodes, and
+// marks the index built. It returns the number of blocks indexed and of
+// values skipped because they do not decode.
+func (s *Storage) BuildHashIndex() (indexed, skipped int, err error) {
+    if s.layout != LayoutInspector {
+        return 0, 0, fmt.Errorf("the hash index needs the inspector layout, not %s", s.layout)
+    }
+    var batch leveldb.Batch
+    err = s.BlockValues(func(height int, value []byte) error {
+        block, _, err := s.DecodeBlock(value)
+        if err != nil || block.Hash == "" {
+            skipped++
+            return nil
+        }
+        batch.Put(hashKey(block.Hash), []byte(strconv.Itoa(height)))
+        indexed++
+        if batch.Len() >= 1000 {
+            var err error
+            if !s.dryRun {
+                err = s.db.Write(&batch, nil)
+            }
+            batch.Reset()
+            return err
+        }
+        return nil
+    })
+    if err != nil {
+        return indexed, skipped, err
+    }
+    if s.dryRun {
+        return indexed, skipped, nil
+    }
+    batch.Put([]byte(hashIndexKey), []byte(strconv.Itoa(indexed)))
+    return indexed, skipped, s.db.Write(&batch, nil)
+}
+
+// DropHashIndex deletes every hash index entry and the built marker, and
+// returns the number of entries deleted.
+func (s *Storage) DropHashIndex() (int, error) {
+    var batch leveldb.Batch
+    dropped := 0
+    iter := s.db.NewIterator(util.BytesPrefix([]byte(hashIndexPrefix)), nil)
+    for iter.Next() {
+        if string(iter.Key()) != hashIndexKey {
+            dropped++
+        }
+        batch.Delete(slices.Clone(iter.Key()))
+    }
+    iter.Release()
+    if err := iter.Error(); err != nil || s.dryRun {
+        return dropped, err
+    }
+    return dropped, s.db.Write(&batch, nil)
+}
+
+// IndexProblem is a hash index entry that disagrees with the stored
+// blocks, or a block the built index lacks.
+type IndexProblem struct {
+    Hash   string `json:"hash"`
+    Height int    `json:"height"`
+    Reason string `json:"reason"`
+}
+
+// AuditHashIndex checks that every entry names a block with its hash and,
+// once the index is built, that every block that decodes is indexed. A
+// block whose hash is indexed at another block with the same hash is not
+// reported: the index holds one height per hash.
+func (s *Storage) AuditHashIndex() ([]IndexProblem, error) {
+    var problems []IndexProblem
+    iter := s.db.NewIterator(util.BytesPrefix([]byte(hashIndexPrefix)), nil)
+    for iter.Next() {
+        key := string(iter.Key())
+        if key == hashIndexKey {
+            continue
+        }
+        hash := key[len(hashIndexPrefix):]
+        height, err := strconv.Atoi(string(iter.Value()))
+        if err != nil {
+            problems = append(problems, IndexProblem{hash, -1, fmt.Sprintf("height %q is not a number", iter.Value())})
+            continue
+        }
+        switch block, err := s.loadBlock(height); {
+        case err != nil:
+            problems = append(problems, IndexProblem{hash, height, "stale: the block cannot be loaded"})
+        case strings.ToLower(block.Hash) != hash:
+            problems = append(problems, IndexProblem{hash, height, "stale: the block has another hash"})
+        }
+    }
+    iter.Release()
+    if err := iter.Error(); err != nil {
+        return nil, err
+    }
+
+    indexed, err := s.HashIndexed()
+    if err != nil || !indexed {
+        return problems, err
+    }
+    err = s.BlockValues(func(height int, value []byte) error {
+        block, _, err := s.DecodeBlock(value)
+        if err != nil || block.Hash == "" {
+            return nil
+        }
+        _, err = s.LookupHash(block.Hash)
+        if errors.Is(err, ErrBlockMissing) {
+            problems = append(problems, IndexProblem{strings.ToLower(block.Hash), height, "missing: the block is not indexed"})
+            return nil
+        }
+        return err
+    })
+    return problems, err
+}
+
+// reindex adds to b the hash index changes of writing block at height
+// over old, the value stored there before (nil if none): the entry of the
+// old block is deleted if it still names height, and block's is added.
+func (b *Batch) reindex(height int, old []byte, block *blocks.Block) error {
+    value := []byte(strconv.Itoa(height))
+    if old != nil {
+        prev, _, err := b.s.DecodeBlock(old)
+        if err == nil && prev.Hash != "" && (block == nil || !strings.EqualFold(prev.Hash, block.Hash)) {
+            key := hashKey(prev.Hash)
+            if indexed, err := b.s.db.Get(key, nil); err == nil && bytes.Equal(indexed, value) {
+                if err := b.s.journalWrite(key); err != nil {
+                    return err
+                }
+                b.batch.Delete(key)
+            }
+        }
+    }
+    if block != nil && block.Hash != "" {
+        key := hashKey(block.Hash)
+        if err := b.s.journalWrite(key); err != nil {
+            return err
+        }
+        b.batch.Put(key, value)
+    }
+    return nil
+}
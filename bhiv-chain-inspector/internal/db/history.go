@@ -0,0 +1,65 @@
+package db
+
+import (
+    "encoding/json"
+    "fmt"
+
+    "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const scanHistoryPrefix = "scan-history-"
+
+// ScanHistoryEntry is the summary of one scan, stored alongside the chain
+// so that health can be tracked over time. Entries for backups taken by
+// watch have Status "BACKUP" and Backup set instead of scan results.
+type ScanHistoryEntry struct {
+    Time          int64          `json:"time"`
+    HealthScore   int            `json:"health_score"`
+    BlocksScanned int            `json:"blocks_scanned"`
+    TotalErrors   int            `json:"total_errors"`
+    TotalWarnings int            `json:"total_warnings"`
+    Status        string         `json:"status"`
+    ErrorCounts   map[string]int `json:"error_counts,omitempty"`
+    Backup        *BackupRecord  `json:"backup,omitempty"`
+}
+
+// BackupRecord describes a backup in the scan history.
+type BackupRecord struct {
+    Archive  string `json:"archive"`
+    Tip      int    `json:"tip"`
+    Blocks   int    `json:"blocks"`
+    Size     int64  `json:"size"`
+    // Verified is set when the archive was read back against its
+    // manifest; Error says why the backup failed otherwise.
+    Verified bool   `json:"verified"`
+    Error    string `json:"error,omitempty"`
+}
+
+// AppendScanHistory stores entry under a key ordered by its time.
+func (s *Storage) AppendScanHistory(entry *ScanHistoryEntry) error {
+    if s.dryRun {
+        return nil
+    }
+    key := []byte(fmt.Sprintf("%s%020d", scanHistoryPrefix, entry.Time))
+    data, err := json.Marshal(entry)
+    if err != nil {
+        return err
+    }
+    return s.db.Put(key, data, nil)
+}
+
+// ScanHistory returns all stored scan summaries, oldest first.
+func (s *Storage) ScanHistory() ([]ScanHistoryEntry, error) {
+    iter := s.db.NewIterator(util.BytesPrefix([]byte(scanHistoryPrefix)), nil)
+    defer iter.Release()
+
+    var entries []ScanHistoryEntry
+    for iter.Next() {
+        var entry ScanHistoryEntry
+        if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+            return nil, fmt.Errorf("corrupt history entry %s: %w", iter.Key(), err)
+        }
+        entries = append(entries, entry)
+    }
+    return entries, iter.Error()
+}
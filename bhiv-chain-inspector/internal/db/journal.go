@@ -0,0 +1,144 @@
+package db
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "os"
+    "sync"
+    "time"
+
+    "github.com/syndtr/goleveldb/leveldb"
+)
+
+// Journal is a write-ahead log of the values that writes replace: before
+// a Storage with a journal writes a key, it appends the key's old value
+// and syncs the file, so that a bad repair can be undone (see Revert).
+type Journal struct {
+    mu   sync.Mutex
+    file *os.File
+}
+
+// JournalEntry is the state of one key before one write.
+type JournalEntry struct {
+    Database string `json:"database"`
+    Key      string `json:"key"`
+    // Existed is false when the write created the key; Value is then nil.
+    Existed  bool   `json:"existed"`
+    Value    []byte `json:"value,omitempty"`
+    Time     int64  `json:"time"`
+}
+
+var defaultJournal *Journal
+
+// SetDefaultJournal makes storage opened afterwards journal its writes to
+// j; nil turns journaling off.
+func SetDefaultJournal(j *Journal) {
+    defaultJournal = j
+}
+
+// OpenJournal opens the journal at path for appending, creating it if
+// needed.
+func OpenJournal(path string) (*Journal, error) {
+    f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+    if err != nil {
+        return nil, err
+    }
+    return &Journal{file: f}, nil
+}
+
+func (j *Journal) Close() error {
+    return j.file.Close()
+}
+
+func (j *Journal) record(e JournalEntry) error {
+    line, err := json.Marshal(e)
+    if err != nil {
+        return err
+    }
+    j.mu.Lock()
+    defer j.mu.Unlock()
+    if _, err := j.file.Write(append(line, '\n')); err != nil {
+        return fmt.Errorf("journal: %w", err)
+    }
+    if err := j.file.Sync(); err != nil {
+        return fmt.Errorf("journal: %w", err)
+    }
+    return nil
+}
+
+// ReadJournal returns the entries of the journal at path, oldest first.
+func ReadJournal(path string) ([]JournalEntry, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    var entries []JournalEntry
+    scanner := bufio.NewScanner(f)
+    scanner.Buffer(nil, 2*maxDecompressedSize)
+    for line := 1; scanner.Scan(); line++ {
+        var e JournalEntry
+        if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+            return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+        }
+        entries = append(entries, e)
+    }
+    return entries, scanner.Err()
+}
+
+// journalWrite records the current value of key in the storage's journal,
+// if it has one, before key is written.
+func (s *Storage) journalWrite(key []byte) error {
+    if s.journal == nil {
+        return nil
+    }
+    old, err := s.db.Get(key, nil)
+    if err != nil && err != leveldb.ErrNotFound {
+        return err
+    }
+    return s.journal.record(JournalEntry{
+        Database: s.path,
+        Key:      string(key),
+        Existed:  err == nil,
+        Value:    old,
+        Time:     time.Now().Unix(),
+    })
+}
+
+// put writes key, journaling its old value first.
+func (s *Storage) put(key, value []byte) error {
+    if err := s.journalWrite(key); err != nil {
+        return err
+    }
+    change, audited := s.blockChange(key, value)
+    if !s.dryRun {
+        if err := s.db.Put(key, value, nil); err != nil {
+            return err
+        }
+    }
+    if audited {
+        s.audit.add(change)
+    }
+    return nil
+}
+
+// Revert restores the key of e to its journaled state, without
+// journaling the change.
+func (s *Storage) Revert(e JournalEntry) error {
+    key := []byte(e.Key)
+    var err error
+    change, audited := s.blockChange(key, e.Value)
+    switch {
+    case s.dryRun:
+    case !e.Existed:
+        err = s.db.Delete(key, nil)
+    default:
+        err = s.db.Put(key, e.Value, nil)
+    }
+    if err == nil && audited {
+        s.audit.add(change)
+    }
+    return err
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+    "maps"
+    "path/filepath"
+    "slices"
+    "testing"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "github.com/syndtr/goleveldb/leveldb"
+)
+
+func journalBlock(height int, data string) *blocks.Block {
+    block := &blocks.Block{Height: height, Data: data, Timestamp: 1704067200 + int64(height)}
+    block.MineWith(blocks.SHA256)
+    return block
+}
+
+// keyspace returns every key and value of the database at path.
+func keyspace(t *testing.T, path string) map[string]string {
+    t.Helper()
+    database, err := leveldb.OpenFile(path, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer database.Close()
+    keys := make(map[string]string)
+    iter := database.NewIterator(nil, nil)
+    defer iter.Release()
+    for iter.Next() {
+        keys[string(iter.Key())] = string(iter.Value())
+    }
+    if err := iter.Error(); err != nil {
+        t.Fatal(err)
+    }
+    return keys
+}
+
+// TestJournalUndo replays a journal newest first, as "inspector undo"
+// does, and expects the keyspace the journaled writes started from: block
+// values, hash index entries and the chain ID alike.
+func TestJournalUndo(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "chain")
+    journalPath := filepath.Join(t.TempDir(), "undo.journal")
+
+    s, err := NewStorage(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for h := range 5 {
+        if err := s.SaveBlock(journalBlock(h, "original")); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if _, _, err := s.BuildHashIndex(); err != nil {
+        t.Fatal(err)
+    }
+    if err := s.SetChainID("before"); err != nil {
+        t.Fatal(err)
+    }
+    s.Close()
+    before := keyspace(t, path)
+
+    journal, err := OpenJournal(journalPath)
+    if err != nil {
+        t.Fatal(err)
+    }
+    SetDefaultJournal(journal)
+    t.Cleanup(func() { SetDefaultJournal(nil) })
+    if s, err = NewStorage(path); err != nil {
+        t.Fatal(err)
+    }
+    writes := []func() error{
+        // Rewriting a block moves its hash index entry.
+        func() error { return s.SaveBlock(journalBlock(2, "rewritten")) },
+        func() error { return s.SaveBlock(journalBlock(2, "rewritten twice")) },
+        // A value that does not decode drops the entry.
+        func() error { return s.SaveBlockRaw(3, []byte("not a block")) },
+        // Blocks past the tip are created, with new entries.
+        func() error {
+            batch := s.NewBatch()
+            for h := 5; h < 8; h++ {
+                if err := batch.SaveBlock(journalBlock(h, "appended")); err != nil {
+                    return err
+                }
+            }
+            return batch.Write(true)
+        },
+        // The same block at another height takes over its entry.
+        func() error { return s.SaveBlockRaw(8, mustEncode(t, s, journalBlock(1, "original"))) },
+        func() error { return s.SetChainID("after") },
+    }
+    for i, write := range writes {
+        if err := write(); err != nil {
+            t.Fatalf("write %d: %v", i, err)
+        }
+    }
+    s.Close()
+    journal.Close()
+    SetDefaultJournal(nil)
+    if after := keyspace(t, path); maps.Equal(after, before) {
+        t.Fatal("the writes changed nothing")
+    }
+
+    entries, err := ReadJournal(journalPath)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if s, err = NewStorage(path); err != nil {
+        t.Fatal(err)
+    }
+    for _, e := range slices.Backward(entries) {
+        if err := s.Revert(e); err != nil {
+            t.Fatalf("reverting %s: %v", e.Key, err)
+        }
+    }
+    s.Close()
+
+    after := keyspace(t, path)
+    for _, key := range slices.Sorted(maps.Keys(before)) {
+        if value, ok := after[key]; !ok {
+            t.Errorf("%s: missing after undo", key)
+        } else if value != before[key] {
+            t.Errorf("%s: %q after undo, want %q", key, value, before[key])
+        }
+    }
+    for _, key := range slices.Sorted(maps.Keys(after)) {
+        if _, ok := before[key]; !ok {
+            t.Errorf("%s: left behind by undo", key)
+        }
+    }
+}
+
+func mustEncode(t *testing.T, s *Storage, block *blocks.Block) []byte {
+    t.Helper()
+    data, err := s.EncodeBlock(block)
+    if err != nil {
+        t.Fatalf("encoding block %d: %v", block.Height, err)
+    }
+    return data
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+    "strconv"
+    "strings"
+    "unicode"
+
+    "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// KeyAudit classifies every key of an inspector-layout database.
+type KeyAudit struct {
+    TotalKeys     int          `json:"total_keys"`
+    BlockKeys     int          `json:"block_keys"`
+    MetadataKeys  int          `json:"metadata_keys"`
+    // NamespaceKeys are the keys of chains other than the one audited.
+    NamespaceKeys int          `json:"namespace_keys"`
+    Unknown       []UnknownKey `json:"unknown_keys"`
+}
+
+// UnknownKey is a key that is neither a block nor metadata this tool
+// writes: a stray key, leftover metadata of another tool, or a typo'd
+// write.
+type UnknownKey struct {
+    // Key is printable as is, or Go-quoted if it holds other bytes.
+    Key    string `json:"key"`
+    Reason string `json:"reason"`
+    Size   int    `json:"value_size"`
+}
+
+// AuditKeys iterates the whole keyspace. Keys are expected to be
+// "block-<height>" with a canonical decimal height, one of the metadata
+// keys (chain ID, scan checkpoint, verified tip, scan history), or either
+// of those prefixed with the namespace of another chain.
+func (s *Storage) AuditKeys() (*KeyAudit, error) {
+    audit := &KeyAudit{Unknown: []UnknownKey{}}
+    iter := s.db.NewIterator(nil, nil)
+    defer iter.Release()
+    for iter.Next() {
+        key := string(iter.Key())
+        audit.TotalKeys++
+        switch reason := classifyKey(key); reason {
+        case "block":
+            audit.BlockKeys++
+        case "metadata":
+            audit.MetadataKeys++
+        case "namespace":
+            audit.NamespaceKeys++
+        default:
+            audit.Unknown = append(audit.Unknown, UnknownKey{Key: printableKey(key), Reason: reason, Size: len(iter.Value())})
+        }
+    }
+    return audit, iter.Error()
+}
+
+// BlockValues calls fn with the height and raw value of every block key,
+// in key order, including blocks past gaps in the chain. It stops at the
+// first error fn returns.
+func (s *Storage) BlockValues(fn func(height int, value []byte) error) error {
+    iter := s.db.NewIterator(util.BytesPrefix([]byte("block-")), nil)
+    defer iter.Release()
+    for iter.Next() {
+        key := string(iter.Key())
+        if classifyKey(key) != "block" {
+            continue
+        }
+        height, _ := strconv.Atoi(key[len("block-"):])
+        if err := fn(height, iter.Value()); err != nil {
+            return err
+        }
+    }
+    return iter.Error()
+}
+
+// classifyKey returns "block", "metadata", "namespace" for the block and
+// metadata keys of other chains, or why key is unexpected.
+func classifyKey(key string) string {
+    switch {
+    case strings.Contains(key, "/") && namespacedKey(key):
+        return "namespace"
+    case key == chainIDKey || key == checkpointKey || key == verifiedTipKey || key == hashIndexKey:
+        return "metadata"
+    case strings.HasPrefix(key, hashIndexPrefix):
+        if hash := key[len(hashIndexPrefix):]; hash != "" && strings.Trim(hash, "0123456789abcdef") == "" {
+            return "metadata"
+        }
+        return "malformed hash index key"
+    case strings.HasPrefix(key, scanHistoryPrefix):
+        if suffix := key[len(scanHistoryPrefix):]; len(suffix) == 20 && digits(suffix) {
+            return "metadata"
+        }
+        return "malformed scan history key"
+    case strings.HasPrefix(key, auditLogPrefix):
+        if suffix := key[len(auditLogPrefix):]; len(suffix) == 20 && digits(suffix) {
+            return "metadata"
+        }
+        return "malformed audit log key"
+    case strings.HasPrefix(key, "block-"):
+        height := key[len("block-"):]
+        n, err := strconv.Atoi(height)
+        switch {
+        case !digits(height):
+            return "block key without a decimal height"
+        case err != nil:
+            return "block key height out of range"
+        case strconv.Itoa(n) != height:
+            return "block key with a non-canonical height (leading zeros)"
+        }
+        return "block"
+    case strings.HasPrefix(strings.ToLower(key), "block"):
+        return "misspelled block key"
+    }
+    return "unknown key"
+}
+
+func digits(s string) bool {
+    for _, c := range s {
+        if c < '0' || c > '9' {
+            return false
+        }
+    }
+    return s != ""
+}
+
+func printableKey(key string) string {
+    for _, r := range key {
+        if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+            return strconv.Quote(key)
+        }
+    }
+    return key
+}
@@ -0,0 +1,47 @@
+package db
+
+import "fmt"
+
+// Layout is the key schema of a database: where blocks are stored and
+// how they are encoded.
+type Layout string
+
+const (
+    // LayoutInspector stores each block under "block-<height>", in the
+    // storage's Encoding. It is the layout this tool writes.
+    LayoutInspector Layout = "inspector"
+    // LayoutGeth reads go-ethereum chaindata: RLP headers under geth's
+    // header keys. Only height, hash, parent hash and timestamp are mapped.
+    LayoutGeth      Layout = "geth"
+    // LayoutCometBFT reads a CometBFT or Tendermint blockstore.db: the
+    // BlockMeta of each height. Height, hash, last block hash, time and
+    // chain ID are mapped.
+    LayoutCometBFT  Layout = "cometbft"
+)
+
+// Layouts lists the supported layouts.
+var Layouts = []Layout{LayoutInspector, LayoutGeth, LayoutCometBFT}
+
+// ParseLayout returns the layout called name.
+func ParseLayout(name string) (Layout, error) {
+    for _, l := range Layouts {
+        if string(l) == name {
+            return l, nil
+        }
+    }
+    return "", fmt.Errorf("unknown layout %q (want inspector, geth or cometbft)", name)
+}
+
+// defaultLayout is the layout of storages opened by NewStorage.
+var defaultLayout = LayoutInspector
+
+// SetDefaultLayout sets the layout of storages opened afterwards; the CLI
+// calls it for --layout. Databases of other layouts than LayoutInspector
+// are opened read-only, so the inspector never writes to a node's data.
+func SetDefaultLayout(l Layout) {
+    defaultLayout = l
+}
+
+// EncodingRLP is reported by DecodeBlock for geth headers. It cannot be
+// selected with --encoding; it follows from LayoutGeth.
+const EncodingRLP Encoding = "rlp"
@@ -0,0 +1,61 @@
+package db
+
+import (
+    "fmt"
+
+    "github.com/syndtr/goleveldb/leveldb"
+    "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// TableStats is the on-disk footprint of LevelDB's sorted tables.
+type TableStats struct {
+    Tables int   `json:"tables"`
+    Size   int64 `json:"size"`
+}
+
+// TableStats sums the tables of every level.
+func (s *Storage) TableStats() (TableStats, error) {
+    var ldb leveldb.DBStats
+    if err := s.db.Stats(&ldb); err != nil {
+        return TableStats{}, err
+    }
+    var t TableStats
+    for level := range ldb.LevelSizes {
+        t.Tables += ldb.LevelTablesCounts[level]
+        t.Size += ldb.LevelSizes[level]
+    }
+    return t, nil
+}
+
+// Compact compacts the whole keyspace, dropping overwritten and deleted
+// values. Databases of other layouts are opened read-only and cannot be
+// compacted.
+func (s *Storage) Compact() error {
+    if s.layout != LayoutInspector {
+        return fmt.Errorf("cannot compact a %s database: it is opened read-only", s.layout)
+    }
+    if s.dryRun {
+        return nil
+    }
+    return s.db.CompactRange(util.Range{})
+}
+
+// Recover opens the database at dbPath ignoring its manifest, rebuilding
+// it from the tables on disk; use it when NewStorage fails with a corrupt
+// or missing manifest. Recovered storage has the defaults of NewStorage.
+func Recover(dbPath string) (*Storage, error) {
+    if defaultLayout != LayoutInspector {
+        return nil, fmt.Errorf("cannot recover a %s database: it is only opened read-only", defaultLayout)
+    }
+    if defaultDryRun {
+        return nil, fmt.Errorf("recover rewrites the manifest and cannot be a dry run")
+    }
+    if defaultShardSize > 0 {
+        return nil, fmt.Errorf("recover one shard at a time")
+    }
+    database, err := leveldb.RecoverFile(dbPath, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to recover database: %w", err)
+    }
+    return withDefaults(database, dbPath), nil
+}
@@ -0,0 +1,235 @@
+package db
+
+import (
+    "fmt"
+    "maps"
+    "regexp"
+    "slices"
+    "strconv"
+    "strings"
+
+    "github.com/syndtr/goleveldb/leveldb"
+    "github.com/syndtr/goleveldb/leveldb/iterator"
+    "github.com/syndtr/goleveldb/leveldb/opt"
+    "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// A namespace is a logical chain sharing one inspector-layout database
+// with others: its keys, blocks and metadata alike, are those of a whole
+// database prefixed with "<name>/", so "main/block-42" is block 42 of
+// chain main. The unprefixed keys are the default chain.
+
+var namespaceName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ValidNamespace checks that name can prefix keys: letters, digits, ".",
+// "_" and "-".
+func ValidNamespace(name string) error {
+    if !namespaceName.MatchString(name) {
+        return fmt.Errorf("bad chain namespace %q: want letters, digits, '.', '_' or '-'", name)
+    }
+    return nil
+}
+
+var defaultNamespace string
+
+// SetDefaultNamespace makes storage opened afterwards use the chain
+// called name; "" is the default chain.
+func SetDefaultNamespace(name string) {
+    defaultNamespace = name
+}
+
+// DefaultNamespace returns the chain set by SetDefaultNamespace.
+func DefaultNamespace() string {
+    return defaultNamespace
+}
+
+// keyValueStore is the part of *leveldb.DB a Storage uses, so that a
+// namespace can stand in for the whole database.
+type keyValueStore interface {
+    Get(key []byte, ro *opt.ReadOptions) ([]byte, error)
+    Put(key, value []byte, wo *opt.WriteOptions) error
+    Delete(key []byte, wo *opt.WriteOptions) error
+    Write(batch *leveldb.Batch, wo *opt.WriteOptions) error
+    NewIterator(slice *util.Range, ro *opt.ReadOptions) iterator.Iterator
+    CompactRange(r util.Range) error
+    Stats(s *leveldb.DBStats) error
+    Close() error
+}
+
+// namespacedDB prefixes every key of the underlying database. Stats and
+// Close apply to the whole database.
+type namespacedDB struct {
+    keyValueStore
+    prefix []byte
+}
+
+func newNamespacedDB(database keyValueStore, name string) *namespacedDB {
+    return &namespacedDB{keyValueStore: database, prefix: []byte(name + "/")}
+}
+
+func (n *namespacedDB) key(key []byte) []byte {
+    return append(slices.Clip(n.prefix), key...)
+}
+
+func (n *namespacedDB) Get(key []byte, ro *opt.ReadOptions) ([]byte, error) {
+    return n.keyValueStore.Get(n.key(key), ro)
+}
+
+func (n *namespacedDB) Put(key, value []byte, wo *opt.WriteOptions) error {
+    return n.keyValueStore.Put(n.key(key), value, wo)
+}
+
+func (n *namespacedDB) Delete(key []byte, wo *opt.WriteOptions) error {
+    return n.keyValueStore.Delete(n.key(key), wo)
+}
+
+func (n *namespacedDB) Write(batch *leveldb.Batch, wo *opt.WriteOptions) error {
+    prefixed := &prefixedBatch{n: n}
+    if err := batch.Replay(prefixed); err != nil {
+        return err
+    }
+    return n.keyValueStore.Write(&prefixed.batch, wo)
+}
+
+// rangeOf maps a range of keys of the namespace, nil for all of them, to
+// the underlying database.
+func (n *namespacedDB) rangeOf(slice *util.Range) *util.Range {
+    all := util.BytesPrefix(n.prefix)
+    if slice == nil {
+        return all
+    }
+    r := &util.Range{Start: n.key(slice.Start), Limit: all.Limit}
+    if slice.Limit != nil {
+        r.Limit = n.key(slice.Limit)
+    }
+    return r
+}
+
+func (n *namespacedDB) NewIterator(slice *util.Range, ro *opt.ReadOptions) iterator.Iterator {
+    return &namespacedIterator{Iterator: n.keyValueStore.NewIterator(n.rangeOf(slice), ro), n: n}
+}
+
+func (n *namespacedDB) CompactRange(r util.Range) error {
+    return n.keyValueStore.CompactRange(*n.rangeOf(&r))
+}
+
+// prefixedBatch copies the writes of a batch with the namespace's keys.
+type prefixedBatch struct {
+    n     *namespacedDB
+    batch leveldb.Batch
+}
+
+func (p *prefixedBatch) Put(key, value []byte) {
+    p.batch.Put(p.n.key(key), value)
+}
+
+func (p *prefixedBatch) Delete(key []byte) {
+    p.batch.Delete(p.n.key(key))
+}
+
+// namespacedIterator strips the namespace from the keys it returns.
+type namespacedIterator struct {
+    iterator.Iterator
+    n *namespacedDB
+}
+
+func (it *namespacedIterator) Key() []byte {
+    if key := it.Iterator.Key(); key != nil {
+        return key[len(it.n.prefix):]
+    }
+    return nil
+}
+
+func (it *namespacedIterator) Seek(key []byte) bool {
+    return it.Iterator.Seek(it.n.key(key))
+}
+
+// Namespace is the chain the storage reads and writes; "" for the
+// default chain.
+func (s *Storage) Namespace() string {
+    return s.namespace
+}
+
+// WithNamespace returns a view of the same database on the chain called
+// name, "" for the default chain. Closing either closes both.
+func (s *Storage) WithNamespace(name string) (*Storage, error) {
+    if name != "" {
+        if err := ValidNamespace(name); err != nil {
+            return nil, err
+        }
+        if s.layout != LayoutInspector {
+            return nil, fmt.Errorf("chain namespaces need the inspector layout, not %s", s.layout)
+        }
+    }
+    view := *s
+    view.namespace = name
+    view.db = s.raw
+    if name != "" {
+        view.db = newNamespacedDB(s.raw, name)
+    }
+    return &view, nil
+}
+
+// NamespaceInfo summarises one chain of a database.
+type NamespaceInfo struct {
+    // Name is "" for the default chain.
+    Name         string `json:"name"`
+    Blocks       int    `json:"blocks"`
+    MaxHeight    int    `json:"max_height"`
+    MetadataKeys int    `json:"metadata_keys"`
+}
+
+// Namespaces iterates the whole database and lists the chains holding
+// blocks or metadata, the default chain first.
+func (s *Storage) Namespaces() ([]NamespaceInfo, error) {
+    if s.layout != LayoutInspector {
+        return nil, fmt.Errorf("chain namespaces need the inspector layout, not %s", s.layout)
+    }
+    found := make(map[string]*NamespaceInfo)
+    iter := s.raw.NewIterator(nil, nil)
+    for iter.Next() {
+        name, key := "", string(iter.Key())
+        if classifyKey(key) == "namespace" {
+            name, key, _ = strings.Cut(key, "/")
+        }
+        info := found[name]
+        if info == nil {
+            info = &NamespaceInfo{Name: name, MaxHeight: -1}
+            found[name] = info
+        }
+        switch classifyKey(key) {
+        case "block":
+            height, _ := strconv.Atoi(key[len("block-"):])
+            info.Blocks++
+            info.MaxHeight = max(info.MaxHeight, height)
+        case "metadata":
+            info.MetadataKeys++
+        }
+    }
+    iter.Release()
+    if err := iter.Error(); err != nil {
+        return nil, err
+    }
+
+    var list []NamespaceInfo
+    for _, name := range slices.Sorted(maps.Keys(found)) {
+        if info := found[name]; info.Blocks > 0 || info.MetadataKeys > 0 {
+            list = append(list, *info)
+        }
+    }
+    return list, nil
+}
+
+// namespacedKey reports whether key is a block or metadata key of a
+// namespace other than the default chain.
+func namespacedKey(key string) bool {
+    name, rest, ok := strings.Cut(key, "/")
+    if !ok || !namespaceName.MatchString(name) {
+        return false
+    }
+    switch classifyKey(rest) {
+    case "block", "metadata":
+        return true
+    }
+    return false
+}
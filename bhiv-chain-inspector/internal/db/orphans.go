@@ -0,0 +1,77 @@
+package db
+
+import (
+    "fmt"
+    "strings"
+)
+
+// OrphanBlock is a stored block off the canonical chain: a block value
+// under a key other than its canonical "block-<height>" one, left by a
+// reorg or a writer with another key scheme.
+type OrphanBlock struct {
+    // Key is printable as is, or Go-quoted if it holds other bytes.
+    Key      string `json:"key"`
+    Height   int    `json:"height"`
+    Hash     string `json:"hash"`
+    PrevHash string `json:"prev_hash"`
+    Reason   string `json:"reason"`
+}
+
+// FindOrphans iterates the whole keyspace of an inspector-layout
+// database. Non-canonical keys are reported when their value decodes to a
+// block with a hash, with how that block relates to the canonical chain.
+func (s *Storage) FindOrphans() ([]OrphanBlock, error) {
+    if s.layout != LayoutInspector {
+        return nil, nil
+    }
+    var orphans []OrphanBlock
+    iter := s.db.NewIterator(nil, nil)
+    for iter.Next() {
+        key := string(iter.Key())
+        switch classifyKey(key) {
+        case "block", "metadata", "namespace":
+            continue
+        }
+        block, _, err := s.DecodeBlock(iter.Value())
+        if err != nil || block.Hash == "" {
+            continue
+        }
+        orphans = append(orphans, OrphanBlock{
+            Key:      printableKey(key),
+            Height:   block.Height,
+            Hash:     block.Hash,
+            PrevHash: block.PrevHash,
+        })
+    }
+    iter.Release()
+    if err := iter.Error(); err != nil {
+        return nil, err
+    }
+
+    sideHashes := make(map[string]bool)
+    for _, o := range orphans {
+        sideHashes[o.Hash] = true
+    }
+    for i := range orphans {
+        orphans[i].Reason = s.sideChainReason(&orphans[i], sideHashes)
+    }
+    return orphans, nil
+}
+
+// sideChainReason says how o, stored under a non-canonical key, relates
+// to the canonical chain.
+func (s *Storage) sideChainReason(o *OrphanBlock, sideHashes map[string]bool) string {
+    if main, err := s.loadBlock(o.Height); err == nil && strings.EqualFold(main.Hash, o.Hash) {
+        return fmt.Sprintf("second copy of canonical block %d", o.Height)
+    }
+    if o.Height == 0 || o.PrevHash == "0" {
+        return "side-chain genesis"
+    }
+    if parent, err := s.loadBlock(o.Height - 1); err == nil && parent.Hash == o.PrevHash {
+        return fmt.Sprintf("side chain forking after canonical block %d", o.Height-1)
+    }
+    if sideHashes[o.PrevHash] {
+        return "side chain, child of another off-chain block"
+    }
+    return "orphan: its parent is not stored"
+}
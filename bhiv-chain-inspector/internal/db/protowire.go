@@ -0,0 +1,53 @@
+package db
+
+import (
+    "encoding/binary"
+    "errors"
+    "fmt"
+)
+
+// protoFields walks the fields of a protobuf message, calling fn with the
+// value of each varint field (v) or length-delimited field (b). Fixed-width
+// fields are skipped. It is enough to read the messages of foreign layouts
+// without a protobuf dependency.
+func protoFields(data []byte, fn func(field, wire int, v uint64, b []byte) error) error {
+    for len(data) > 0 {
+        tag, n := binary.Uvarint(data)
+        if n <= 0 || tag>>3 == 0 {
+            return errors.New("proto: bad field tag")
+        }
+        data = data[n:]
+        field, wire := int(tag>>3), int(tag&7)
+        var v uint64
+        var b []byte
+        switch wire {
+        case 0:
+            if v, n = binary.Uvarint(data); n <= 0 {
+                return fmt.Errorf("proto: field %d: truncated varint", field)
+            }
+            data = data[n:]
+        case 2:
+            size, n := binary.Uvarint(data)
+            if n <= 0 || size > uint64(len(data)-n) {
+                return fmt.Errorf("proto: field %d: truncated length-delimited value", field)
+            }
+            b, data = data[n:n+int(size)], data[n+int(size):]
+        case 1, 5:
+            size := 8
+            if wire == 5 {
+                size = 4
+            }
+            if len(data) < size {
+                return fmt.Errorf("proto: field %d: truncated fixed value", field)
+            }
+            data = data[size:]
+            continue
+        default:
+            return fmt.Errorf("proto: field %d: unsupported wire type %d", field, wire)
+        }
+        if err := fn(field, wire, v, b); err != nil {
+            return err
+        }
+    }
+    return nil
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+    "errors"
+    "fmt"
+)
+
+// rlpSplit reads the RLP item at the start of data and returns its
+// content, whether it is a list, and the bytes after it.
+func rlpSplit(data []byte) (content []byte, list bool, rest []byte, err error) {
+    if len(data) == 0 {
+        return nil, false, nil, errors.New("rlp: empty input")
+    }
+    b := data[0]
+    var offset, size int
+    switch {
+    case b < 0x80:
+        return data[:1], false, data[1:], nil
+    case b <= 0xb7:
+        offset, size = 1, int(b-0x80)
+    case b <= 0xbf:
+        offset, size, err = rlpLongSize(data, int(b-0xb7))
+    case b <= 0xf7:
+        offset, size, list = 1, int(b-0xc0), true
+    default:
+        offset, size, err = rlpLongSize(data, int(b-0xf7))
+        list = true
+    }
+    if err != nil {
+        return nil, false, nil, err
+    }
+    if size > len(data)-offset {
+        return nil, false, nil, fmt.Errorf("rlp: item of %d bytes overruns input", size)
+    }
+    return data[offset : offset+size], list, data[offset+size:], nil
+}
+
+// rlpLongSize reads the n-byte big-endian size that follows a long item's
+// first byte.
+func rlpLongSize(data []byte, n int) (offset, size int, err error) {
+    if n > 4 || len(data) < 1+n {
+        return 0, 0, errors.New("rlp: bad length prefix")
+    }
+    for _, c := range data[1 : 1+n] {
+        size = size<<8 | int(c)
+    }
+    return 1 + n, size, nil
+}
+
+// rlpList decodes data as a single RLP list and returns the contents of
+// its items.
+func rlpList(data []byte) ([][]byte, error) {
+    content, list, rest, err := rlpSplit(data)
+    if err != nil {
+        return nil, err
+    }
+    if !list {
+        return nil, errors.New("rlp: not a list")
+    }
+    if len(rest) > 0 {
+        return nil, fmt.Errorf("rlp: %d trailing bytes", len(rest))
+    }
+    var items [][]byte
+    for len(content) > 0 {
+        var item []byte
+        if item, _, content, err = rlpSplit(content); err != nil {
+            return nil, err
+        }
+        items = append(items, item)
+    }
+    return items, nil
+}
+
+// rlpUint decodes a big-endian RLP integer of up to 8 bytes.
+func rlpUint(b []byte) (uint64, error) {
+    if len(b) > 8 {
+        return 0, fmt.Errorf("rlp: %d-byte integer overflows uint64", len(b))
+    }
+    var n uint64
+    for _, c := range b {
+        n = n<<8 | uint64(c)
+    }
+    return n, nil
+}
@@ -0,0 +1,229 @@
+package db
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+
+    "github.com/syndtr/goleveldb/leveldb"
+    "github.com/syndtr/goleveldb/leveldb/comparer"
+    "github.com/syndtr/goleveldb/leveldb/iterator"
+    "github.com/syndtr/goleveldb/leveldb/opt"
+    "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// A sharded chain is split across several LevelDB directories by height:
+// shard i holds blocks i*size to (i+1)*size-1, and the first shard holds
+// the metadata as well. Blocks stored in the wrong shard are seen when
+// iterating but not when loaded by height.
+
+var defaultShardSize int
+
+// SetDefaultShardSize makes storage opened afterwards treat its path as a
+// comma-separated list of shards of size blocks each; 0 opens one
+// database.
+func SetDefaultShardSize(size int) {
+    defaultShardSize = size
+}
+
+// ShardPath joins shard directories into the path NewStorage opens when a
+// shard size is set.
+func ShardPath(dirs []string) string {
+    return strings.Join(dirs, ",")
+}
+
+// openShards opens every shard of dbPath with options, or as a dry run,
+// reporting whether writes must be skipped.
+func openShards(dbPath string, options *opt.Options, dryRun bool) (*shardedDB, bool, error) {
+    sharded := &shardedDB{size: defaultShardSize}
+    skip := false
+    for _, dir := range strings.Split(dbPath, ",") {
+        var database *leveldb.DB
+        var err error
+        if dryRun {
+            var existing bool
+            database, existing, err = openDryRun(dir)
+            skip = skip || existing
+        } else {
+            database, err = leveldb.OpenFile(dir, options)
+        }
+        if err != nil {
+            sharded.Close()
+            return nil, false, fmt.Errorf("shard %s: %w", dir, err)
+        }
+        sharded.shards = append(sharded.shards, database)
+    }
+    return sharded, skip, nil
+}
+
+// shardedDB routes block keys to the shard of their height and every
+// other key to the first shard.
+type shardedDB struct {
+    shards []*leveldb.DB
+    size   int
+}
+
+// keyHeight returns the height of a block key, of any namespace.
+func keyHeight(key []byte) (int, bool) {
+    k := string(key)
+    if classifyKey(k) == "namespace" {
+        _, k, _ = strings.Cut(k, "/")
+    }
+    if classifyKey(k) != "block" {
+        return 0, false
+    }
+    height, _ := strconv.Atoi(k[len("block-"):])
+    return height, true
+}
+
+// shardOf returns the index of the shard key belongs in, or -1 for a
+// block past the last shard.
+func (d *shardedDB) shardOf(key []byte) int {
+    height, ok := keyHeight(key)
+    if !ok {
+        return 0
+    }
+    if i := height / d.size; i < len(d.shards) {
+        return i
+    }
+    return -1
+}
+
+func (d *shardedDB) pastLastShard(key []byte) error {
+    height, _ := keyHeight(key)
+    return fmt.Errorf("block %d is past the last shard (%d shards of %d blocks)", height, len(d.shards), d.size)
+}
+
+func (d *shardedDB) Get(key []byte, ro *opt.ReadOptions) ([]byte, error) {
+    i := d.shardOf(key)
+    if i < 0 {
+        return nil, leveldb.ErrNotFound
+    }
+    return d.shards[i].Get(key, ro)
+}
+
+func (d *shardedDB) Put(key, value []byte, wo *opt.WriteOptions) error {
+    i := d.shardOf(key)
+    if i < 0 {
+        return d.pastLastShard(key)
+    }
+    return d.shards[i].Put(key, value, wo)
+}
+
+func (d *shardedDB) Delete(key []byte, wo *opt.WriteOptions) error {
+    i := d.shardOf(key)
+    if i < 0 {
+        return nil
+    }
+    return d.shards[i].Delete(key, wo)
+}
+
+// Write splits batch by shard. Each shard's part is written atomically,
+// but not the batch as a whole.
+func (d *shardedDB) Write(batch *leveldb.Batch, wo *opt.WriteOptions) error {
+    split := &shardBatches{d: d, batches: make([]leveldb.Batch, len(d.shards))}
+    if err := batch.Replay(split); err != nil {
+        return err
+    }
+    if split.err != nil {
+        return split.err
+    }
+    for i := range d.shards {
+        if split.batches[i].Len() == 0 {
+            continue
+        }
+        if err := d.shards[i].Write(&split.batches[i], wo); err != nil {
+            return fmt.Errorf("shard %d: %w", i, err)
+        }
+    }
+    return nil
+}
+
+// NewIterator merges the keys of every shard in order.
+func (d *shardedDB) NewIterator(slice *util.Range, ro *opt.ReadOptions) iterator.Iterator {
+    iters := make([]iterator.Iterator, len(d.shards))
+    for i, shard := range d.shards {
+        iters[i] = shard.NewIterator(slice, ro)
+    }
+    return iterator.NewMergedIterator(iters, comparer.DefaultComparer, false)
+}
+
+func (d *shardedDB) CompactRange(r util.Range) error {
+    for i, shard := range d.shards {
+        if err := shard.CompactRange(r); err != nil {
+            return fmt.Errorf("shard %d: %w", i, err)
+        }
+    }
+    return nil
+}
+
+// Stats adds up the statistics of every shard.
+func (d *shardedDB) Stats(s *leveldb.DBStats) error {
+    *s = leveldb.DBStats{}
+    for i, shard := range d.shards {
+        var one leveldb.DBStats
+        if err := shard.Stats(&one); err != nil {
+            return fmt.Errorf("shard %d: %w", i, err)
+        }
+        s.WriteDelayCount += one.WriteDelayCount
+        s.WriteDelayDuration += one.WriteDelayDuration
+        s.WritePaused = s.WritePaused || one.WritePaused
+        s.AliveSnapshots += one.AliveSnapshots
+        s.AliveIterators += one.AliveIterators
+        s.IOWrite += one.IOWrite
+        s.IORead += one.IORead
+        s.BlockCacheSize += one.BlockCacheSize
+        s.OpenedTablesCount += one.OpenedTablesCount
+        s.LevelSizes = addLevels(s.LevelSizes, one.LevelSizes)
+        s.LevelTablesCounts = addLevels(s.LevelTablesCounts, one.LevelTablesCounts)
+        s.LevelRead = addLevels(s.LevelRead, one.LevelRead)
+        s.LevelWrite = addLevels(s.LevelWrite, one.LevelWrite)
+        s.LevelDurations = addLevels(s.LevelDurations, one.LevelDurations)
+    }
+    return nil
+}
+
+func addLevels[T ~int | ~int64](sum, levels []T) []T {
+    for len(sum) < len(levels) {
+        sum = append(sum, 0)
+    }
+    for i, v := range levels {
+        sum[i] += v
+    }
+    return sum
+}
+
+func (d *shardedDB) Close() error {
+    var first error
+    for _, shard := range d.shards {
+        if err := shard.Close(); err != nil && first == nil {
+            first = err
+        }
+    }
+    return first
+}
+
+// shardBatches splits the writes of a batch by shard, keeping the first
+// block past the last shard as err.
+type shardBatches struct {
+    d       *shardedDB
+    batches []leveldb.Batch
+    err     error
+}
+
+func (b *shardBatches) Put(key, value []byte) {
+    i := b.d.shardOf(key)
+    if i < 0 {
+        if b.err == nil {
+            b.err = b.d.pastLastShard(key)
+        }
+        return
+    }
+    b.batches[i].Put(key, value)
+}
+
+func (b *shardBatches) Delete(key []byte) {
+    if i := b.d.shardOf(key); i >= 0 {
+        b.batches[i].Delete(key)
+    }
+}
@@ -1,67 +1,307 @@
 package db
 
 import (
-    "encoding/json"
+    "context"
+    "crypto/cipher"
     "fmt"
+    "strconv"
 
     "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/tracing"
     "github.com/syndtr/goleveldb/leveldb"
+    "github.com/syndtr/goleveldb/leveldb/opt"
+    "github.com/syndtr/goleveldb/leveldb/util"
 )
 
+// BlockReader is the read side of a chain needed to compare it with
+// another. Storage implements it, as do clients of remote agents.
+type BlockReader interface {
+    GetMaxHeight() int
+    LoadBlock(height int) (*blocks.Block, error)
+}
+
 type Storage struct {
-    db *leveldb.DB
+    // db is raw, or the namespace of raw the storage reads and writes.
+    db          keyValueStore
+    raw         keyValueStore
+    namespace   string
+    // ctx parents the trace spans of reads; see WithContext.
+    ctx         context.Context
+    // encoding is the format of block values; see WithEncoding.
+    encoding    Encoding
+    // compression applies to writes; reads detect it per value.
+    compression Compression
+    // aead seals block values when an encryption key is set.
+    aead        cipher.AEAD
+    // sealKey is the HMAC key of block seals; see SetDefaultSealKey.
+    sealKey     []byte
+    layout      Layout
+    // journal, when set, records the values writes replace; path names
+    // the database in its entries.
+    journal     *Journal
+    path        string
+    // audit, when set, collects block writes for the audit log.
+    audit       *auditTrail
+    // dryRun skips every write; see SetDefaultDryRun.
+    dryRun      bool
 }
 
+// NewStorage opens the database at dbPath with the default encoding,
+// compression, encryption and seal keys, layout, journal and auditor (see
+// SetDefaultEncoding, SetDefaultCompression, SetDefaultEncryptionKey,
+// SetDefaultSealKey, SetDefaultLayout, SetDefaultJournal,
+// SetDefaultAuditor, SetDefaultDryRun, SetDefaultNamespace,
+// SetDefaultShardSize and SetDefaultReadLimit).
 func NewStorage(dbPath string) (*Storage, error) {
-    database, err := leveldb.OpenFile(dbPath, nil)
+    if defaultNamespace != "" && defaultLayout != LayoutInspector {
+        return nil, fmt.Errorf("chain namespaces need the inspector layout, not %s", defaultLayout)
+    }
+    if defaultShardSize > 0 && defaultLayout != LayoutInspector {
+        return nil, fmt.Errorf("shards need the inspector layout, not %s", defaultLayout)
+    }
+    var options *opt.Options
+    if defaultLayout != LayoutInspector {
+        options = &opt.Options{ReadOnly: true, ErrorIfMissing: true}
+    }
+    var database keyValueStore
+    var err error
+    dryRun := false
+    switch {
+    case defaultShardSize > 0:
+        database, dryRun, err = openShards(dbPath, options, defaultDryRun)
+    case defaultDryRun && defaultLayout == LayoutInspector:
+        database, dryRun, err = openDryRun(dbPath)
+    default:
+        database, err = leveldb.OpenFile(dbPath, options)
+    }
     if err != nil {
         return nil, fmt.Errorf("failed to open database: %w", err)
     }
-    return &Storage{db: database}, nil
+    s := withDefaults(database, dbPath)
+    if dryRun {
+        // The skipped writes are collected as for the audit log, to be
+        // logged instead.
+        s.dryRun = true
+        s.journal = nil
+        s.audit = &auditTrail{auditor: &Auditor{}}
+    }
+    return s, nil
 }
 
+// withDefaults wraps database, opened from dbPath, in a Storage with the
+// package defaults.
+func withDefaults(database keyValueStore, dbPath string) *Storage {
+    if defaultReadLimiter != nil {
+        database = &throttledDB{keyValueStore: database, limiter: defaultReadLimiter}
+    }
+    s := &Storage{
+        db:          database,
+        raw:         database,
+        namespace:   defaultNamespace,
+        encoding:    defaultEncoding,
+        compression: defaultCompression,
+        aead:        defaultAEAD,
+        sealKey:     defaultSealKey,
+        layout:      defaultLayout,
+        journal:     defaultJournal,
+        path:        dbPath,
+    }
+    if defaultNamespace != "" {
+        s.db = newNamespacedDB(database, defaultNamespace)
+    }
+    if defaultAuditor != nil && defaultLayout == LayoutInspector {
+        s.audit = &auditTrail{auditor: defaultAuditor}
+    }
+    return s
+}
+
+// Close appends the audit entry of the blocks written since opening, if
+// audited, and closes the database.
 func (s *Storage) Close() error {
+    if s.dryRun {
+        s.logDryRun()
+        return s.db.Close()
+    }
+    if err := s.flushAudit(); err != nil {
+        s.db.Close()
+        return fmt.Errorf("audit log: %w", err)
+    }
     return s.db.Close()
 }
 
+// WithContext returns a view of the same database whose reads are traced
+// as children of the span in ctx. Closing either closes both.
+func (s *Storage) WithContext(ctx context.Context) *Storage {
+    view := *s
+    view.ctx = ctx
+    return &view
+}
+
+// WithEncoding returns a view of the same database that reads and writes
+// block values in encoding e. Closing either closes both.
+func (s *Storage) WithEncoding(e Encoding) *Storage {
+    view := *s
+    view.encoding = e
+    return &view
+}
+
+// Encoding returns the format block values are read and written in.
+func (s *Storage) Encoding() Encoding {
+    return s.encoding
+}
+
+// Compression returns the compression blocks are written with.
+func (s *Storage) Compression() Compression {
+    return s.compression
+}
+
+// Layout returns the key schema the storage reads.
+func (s *Storage) Layout() Layout {
+    return s.layout
+}
+
+// DecodeBlock decodes a stored block value, as returned by LoadBlockRaw,
+// and returns the encoding it was decoded with: the storage's, for
+// EncodingAuto the detected one, and EncodingRLP for LayoutGeth. Values
+// are unsealed (without verifying the seal; see VerifySeal), decrypted
+// and decompressed first. The error is the decoder's own.
+func (s *Storage) DecodeBlock(data []byte) (*blocks.Block, Encoding, error) {
+    switch s.layout {
+    case LayoutGeth:
+        block, err := decodeGethHeader(data)
+        return block, EncodingRLP, err
+    case LayoutCometBFT:
+        block, err := decodeCometMeta(data)
+        return block, EncodingProto, err
+    }
+    data, _ = splitSeal(data)
+    data, err := s.decrypt(data)
+    if err == nil {
+        data, _, err = decompress(data)
+    }
+    if err != nil {
+        return nil, s.encoding, err
+    }
+    return s.encoding.unmarshal(data)
+}
+
+// FirstHeight is the lowest height the database can hold a block at: 0,
+// except for geth chaindata, whose older blocks live in freezer files, and
+// CometBFT blockstores, which start at the initial height or prune base.
+func (s *Storage) FirstHeight() int {
+    switch s.layout {
+    case LayoutGeth:
+        return s.gethFirstHeight()
+    case LayoutCometBFT:
+        return s.cometFirstHeight()
+    }
+    return 0
+}
+
 func (s *Storage) LoadBlock(height int) (*blocks.Block, error) {
-    key := []byte(fmt.Sprintf("block-%d", height))
-    data, err := s.db.Get(key, nil)
+    _, span := tracing.Start(s.ctx, "Storage.LoadBlock", "height", height)
+    defer span.End()
+    return s.loadBlock(height)
+}
+
+func (s *Storage) loadBlock(height int) (*blocks.Block, error) {
+    data, err := s.loadBlockRaw(height)
     if err != nil {
         return nil, err
     }
 
-    var block blocks.Block
-    if err := json.Unmarshal(data, &block); err != nil {
-        return nil, err
+    block, _, err := s.DecodeBlock(data)
+    if err != nil {
+        return nil, fmt.Errorf("block %d: %w: %w", height, ErrCorruptJSON, err)
     }
-    return &block, nil
+    return block, nil
 }
 
 func (s *Storage) LoadBlockRaw(height int) ([]byte, error) {
-    key := []byte(fmt.Sprintf("block-%d", height))
-    return s.db.Get(key, nil)
+    _, span := tracing.Start(s.ctx, "Storage.LoadBlockRaw", "height", height)
+    defer span.End()
+    return s.loadBlockRaw(height)
 }
 
-func (s *Storage) SaveBlock(block *blocks.Block) error {
-    key := []byte(fmt.Sprintf("block-%d", block.Height))
-    data, err := json.Marshal(block)
+func (s *Storage) loadBlockRaw(height int) ([]byte, error) {
+    var data []byte
+    var err error
+    switch s.layout {
+    case LayoutGeth:
+        data, err = s.gethHeaderRaw(height)
+    case LayoutCometBFT:
+        data, err = s.db.Get(cometMetaKey(height), nil)
+    default:
+        data, err = s.db.Get([]byte(fmt.Sprintf("block-%d", height)), nil)
+    }
+    if err == leveldb.ErrNotFound {
+        return nil, fmt.Errorf("block %d: %w", height, ErrBlockMissing)
+    }
     if err != nil {
+        return nil, fmt.Errorf("block %d: %w", height, err)
+    }
+    return data, nil
+}
+
+// SaveBlockRaw stores data verbatim as the block at height, and moves the
+// hash index entry of the block it replaces to the one data decodes to.
+func (s *Storage) SaveBlockRaw(height int, data []byte) error {
+    batch := s.NewBatch()
+    if err := batch.SaveBlockRaw(height, data); err != nil {
         return err
     }
-    return s.db.Put(key, data, nil)
+    return batch.Write(false)
 }
 
+// SaveBlock stores block and its hash index entry in one write.
+func (s *Storage) SaveBlock(block *blocks.Block) error {
+    batch := s.NewBatch()
+    if err := batch.SaveBlock(block); err != nil {
+        return err
+    }
+    return batch.Write(false)
+}
+
+// EncodeBlock returns the value SaveBlock stores for block: encoded,
+// compressed, encrypted and sealed as the storage is configured.
+func (s *Storage) EncodeBlock(block *blocks.Block) ([]byte, error) {
+    data, err := s.encoding.marshal(block)
+    if err == nil {
+        data, err = s.compression.compress(data)
+    }
+    if err != nil {
+        return nil, err
+    }
+    return s.seal(block.Height, s.encrypt(data)), nil
+}
+
+// GetMaxHeight returns the highest height holding a block, or -1 if none
+// does. For the inspector layout it is the highest "block-<height>" key,
+// whether or not its value decodes and whatever gaps lie below it, so
+// that a scan reaches every stored block.
 func (s *Storage) GetMaxHeight() int {
-    height := 0
-    for {
-        _, err := s.LoadBlock(height)
-        if err != nil {
-            if height == 0 {
-                return -1
-            }
-            return height - 1
+    _, span := tracing.Start(s.ctx, "Storage.GetMaxHeight")
+    defer span.End()
+    switch s.layout {
+    case LayoutGeth:
+        return s.gethMaxHeight()
+    case LayoutCometBFT:
+        return s.cometMaxHeight()
+    }
+
+    // Keys sort as strings, so block-99 follows block-100: every key is
+    // visited, but no value is decoded.
+    tip := -1
+    iter := s.db.NewIterator(util.BytesPrefix([]byte("block-")), nil)
+    defer iter.Release()
+    for iter.Next() {
+        key := string(iter.Key())
+        if classifyKey(key) != "block" {
+            continue
+        }
+        if height, _ := strconv.Atoi(key[len("block-"):]); height > tip {
+            tip = height
         }
-        height++
     }
+    return tip
 }
@@ -0,0 +1,113 @@
+package db
+
+import (
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/syndtr/goleveldb/leveldb/iterator"
+    "github.com/syndtr/goleveldb/leveldb/opt"
+    "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// ReadLimit caps the rate of database reads, so that a scan on the disk
+// of a live node leaves it the I/O it needs. A zero field is no limit.
+type ReadLimit struct {
+    // BytesPerSec counts the keys and values read.
+    BytesPerSec float64
+    ReadsPerSec float64
+}
+
+// readBurst is how far ahead of its rate a limited reader may get after
+// reading nothing for a while.
+const readBurst = 100 * time.Millisecond
+
+var defaultReadLimiter *readLimiter
+
+// SetDefaultReadLimit makes storages opened afterwards share one budget of
+// reads per second and bytes per second, counting every Get and every
+// iterator step; the CLI calls it for --max-read-mbps and
+// --max-reads-per-sec. A zero limit turns throttling off.
+func SetDefaultReadLimit(limit ReadLimit) error {
+    if limit.BytesPerSec < 0 || limit.ReadsPerSec < 0 {
+        return fmt.Errorf("read limits must not be negative")
+    }
+    defaultReadLimiter = nil
+    if limit != (ReadLimit{}) {
+        defaultReadLimiter = &readLimiter{limit: limit}
+    }
+    return nil
+}
+
+// readLimiter paces reads to a ReadLimit. Each read moves the time the
+// next may start forward by its share of the budget; a read that finds
+// that time ahead of the clock sleeps until then.
+type readLimiter struct {
+    mu       sync.Mutex
+    limit    ReadLimit
+    nextRead time.Time
+    nextByte time.Time
+}
+
+// wait charges reads and bytes to the budget and sleeps off any debt.
+// Reads are charged once done, when the size of the value is known, so a
+// large value delays the reads after it rather than itself.
+func (l *readLimiter) wait(reads, bytes int) {
+    l.mu.Lock()
+    now := time.Now()
+    delay := max(advance(&l.nextRead, now, float64(reads), l.limit.ReadsPerSec),
+        advance(&l.nextByte, now, float64(bytes), l.limit.BytesPerSec))
+    l.mu.Unlock()
+    if delay > 0 {
+        time.Sleep(delay)
+    }
+}
+
+// advance moves *next forward by n units at rate per second and returns
+// how long until it is reached.
+func advance(next *time.Time, now time.Time, n, rate float64) time.Duration {
+    if rate <= 0 || n == 0 {
+        return 0
+    }
+    if earliest := now.Add(-readBurst); next.Before(earliest) {
+        *next = earliest
+    }
+    *next = next.Add(time.Duration(n / rate * float64(time.Second)))
+    return next.Sub(now)
+}
+
+// throttledDB paces the reads of the underlying database with a
+// readLimiter. Writes are not limited.
+type throttledDB struct {
+    keyValueStore
+    limiter *readLimiter
+}
+
+func (t *throttledDB) Get(key []byte, ro *opt.ReadOptions) ([]byte, error) {
+    value, err := t.keyValueStore.Get(key, ro)
+    t.limiter.wait(1, len(key)+len(value))
+    return value, err
+}
+
+func (t *throttledDB) NewIterator(slice *util.Range, ro *opt.ReadOptions) iterator.Iterator {
+    return &throttledIterator{Iterator: t.keyValueStore.NewIterator(slice, ro), limiter: t.limiter}
+}
+
+// throttledIterator charges each entry it moves to as one read.
+type throttledIterator struct {
+    iterator.Iterator
+    limiter *readLimiter
+}
+
+func (it *throttledIterator) charge(ok bool) bool {
+    if ok {
+        it.limiter.wait(1, len(it.Iterator.Key())+len(it.Iterator.Value()))
+    }
+    return ok
+}
+
+func (it *throttledIterator) First() bool          { return it.charge(it.Iterator.First()) }
+func (it *throttledIterator) Last() bool           { return it.charge(it.Iterator.Last()) }
+func (it *throttledIterator) Seek(key []byte) bool { return it.charge(it.Iterator.Seek(key)) }
+func (it *throttledIterator) Next() bool           { return it.charge(it.Iterator.Next()) }
+func (it *throttledIterator) Prev() bool           { return it.charge(it.Iterator.Prev()) }
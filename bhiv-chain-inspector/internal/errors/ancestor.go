@@ -0,0 +1,145 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// CommonAncestor is the highest block two nodes agree on: the block a
+// forked node can be restored to before syncing from the other.
+type CommonAncestor struct {
+    ScanTime    string `json:"scan_time"`
+    Node1Path   string `json:"node1_path"`
+    Node2Path   string `json:"node2_path"`
+    Node1Height int    `json:"node1_height"`
+    Node2Height int    `json:"node2_height"`
+    // Height is -1 when the nodes do not even agree on their first block.
+    Height      int    `json:"height"`
+    Hash        string `json:"hash,omitempty"`
+    Timestamp   int64  `json:"timestamp,omitempty"`
+    // Node1Blocks and Node2Blocks count the blocks each node holds above
+    // the ancestor, which a restore to it would drop.
+    Node1Blocks int    `json:"node1_blocks"`
+    Node2Blocks int    `json:"node2_blocks"`
+    // Identical is set when neither node holds a block the other lacks.
+    Identical   bool   `json:"identical"`
+    Probes      int    `json:"probes"`
+}
+
+// FindCommonAncestor bisects for the highest height holding the same block
+// on both chains. Both chains are fingerprinted first, so that only the
+// first differing segment is probed and so that identical chains are told
+// by their digests: FindDivergence relies on hash linkage, and a chain
+// that breaks it can pass the bisect without being identical. When the
+// bisect finds no difference but the digests disagree, or fingerprints are
+// off (a negative segmentSize) or fail, the heights are walked instead, so
+// Identical is never set on the bisect alone.
+func FindCommonAncestor(r1, r2 db.BlockReader, db1Path, db2Path string, segmentSize int) *CommonAncestor {
+    result := &CommonAncestor{
+        ScanTime:  FormatTime(time.Now()),
+        Node1Path: db1Path,
+        Node2Path: db2Path,
+        Height:    -1,
+    }
+
+    var fp1, fp2 *Fingerprint
+    if segmentSize >= 0 {
+        var err1, err2 error
+        fp1, err1 = FingerprintOf(r1, segmentSize)
+        fp2, err2 = FingerprintOf(r2, segmentSize)
+        if err1 != nil || err2 != nil {
+            fp1, fp2 = nil, nil
+        }
+    }
+    if fp1 != nil {
+        result.Node1Height, result.Node2Height = fp1.Height, fp2.Height
+    } else {
+        result.Node1Height, result.Node2Height = r1.GetMaxHeight(), r2.GetMaxHeight()
+    }
+
+    divergence, probes := FindDivergence(r1, r2, fp1, fp2)
+    if divergence < 0 && (fp1 == nil || fp1.Digest != fp2.Digest) {
+        var walked int
+        divergence, walked = walkDivergence(r1, r2, firstDifferingHeight(fp1, fp2), result.Node1Height)
+        probes += walked
+    }
+    result.Probes = probes
+    result.Height = divergence - 1
+    if divergence < 0 {
+        result.Identical = true
+        result.Height = result.Node1Height
+    }
+    if result.Height >= max(firstHeight(r1), firstHeight(r2)) {
+        if block, err := r1.LoadBlock(result.Height); err == nil {
+            result.Hash, result.Timestamp = block.Hash, block.Timestamp
+        }
+    } else {
+        result.Height = -1
+    }
+    result.Node1Blocks = max(result.Node1Height-result.Height, 0)
+    result.Node2Blocks = max(result.Node2Height-result.Height, 0)
+    return result
+}
+
+// firstDifferingHeight is the start of the first segment whose digests
+// differ, 0 without fingerprints.
+func firstDifferingHeight(fp1, fp2 *Fingerprint) int {
+    if fp1 == nil || fp2 == nil {
+        return 0
+    }
+    for k := 0; k < len(fp1.Segments) && k < len(fp2.Segments); k++ {
+        if fp1.Segments[k].Digest != fp2.Segments[k].Digest {
+            return fp1.Segments[k].From
+        }
+    }
+    return 0
+}
+
+// OutputCommonAncestor prints the common ancestor and what each node holds
+// above it; quiet output is its height and hash alone, for scripts.
+func OutputCommonAncestor(result *CommonAncestor, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, result, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "%d %s\n", result.Height, result.Hash)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "COMMON ANCESTOR")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Node1:         %s (Height: %d)\n", result.Node1Path, result.Node1Height)
+    fmt.Fprintf(w, "  Node2:         %s (Height: %d)\n", result.Node2Path, result.Node2Height)
+    fmt.Fprintf(w, "  Blocks Probed: %d\n", result.Probes)
+
+    fmt.Fprintln(w)
+    if result.Height < 0 {
+        fmt.Fprintf(w, "%s  %s\n", sym.Warn, colorize(opts, ansiRed, "NO COMMON ANCESTOR - THE NODES DIFFER FROM THEIR FIRST BLOCK"))
+        fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+        return
+    }
+    fmt.Fprintf(w, "%s%s\n", sym.Diverge, colorize(opts, ansiGreen, fmt.Sprintf("Common Ancestor: Block %d", result.Height)))
+    fmt.Fprintf(w, "  Hash:          %s\n", result.Hash)
+    fmt.Fprintf(w, "  Timestamp:     %s\n", FormatUnix(result.Timestamp))
+    switch {
+    case result.Identical:
+        fmt.Fprintf(w, "\n%s%s\n", sym.Healthy, colorize(opts, ansiGreen, "CHAINS ARE IDENTICAL - THE ANCESTOR IS THEIR TIP"))
+    case result.Node1Blocks == 0 || result.Node2Blocks == 0:
+        behind, ahead := "Node1", "Node2"
+        if result.Node2Blocks == 0 {
+            behind, ahead = ahead, behind
+        }
+        fmt.Fprintf(w, "\n  %s is %d blocks behind %s but has not forked; sync it.\n",
+            behind, max(result.Node1Blocks, result.Node2Blocks), ahead)
+    default:
+        fmt.Fprintf(w, "\n  Above it, Node1 holds %d blocks and Node2 holds %d.\n", result.Node1Blocks, result.Node2Blocks)
+        fmt.Fprintf(w, "  Roll a forked node back to block %d, then sync it from the other.\n", result.Height)
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
@@ -0,0 +1,122 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// AuditLog is the output of the audit-log command: the entries that
+// passed its filters, and the number of broken links in the whole log.
+type AuditLog struct {
+    DatabasePath string          `json:"database_path"`
+    Entries      []db.AuditEntry `json:"entries"`
+    Total        int             `json:"total"`
+    Broken       int             `json:"broken"`
+}
+
+// AuditFilter selects audit entries; zero fields select everything.
+type AuditFilter struct {
+    Operation string
+    Since     time.Time
+    // Height selects entries that changed the block at Height; negative
+    // is unset.
+    Height    int
+    Last      int
+}
+
+// FilterAuditLog applies f to entries, which are oldest first, counting
+// broken links over all of them.
+func FilterAuditLog(entries []db.AuditEntry, dbPath string, f AuditFilter) *AuditLog {
+    log := &AuditLog{DatabasePath: dbPath, Entries: []db.AuditEntry{}, Total: len(entries)}
+    for _, e := range entries {
+        if e.Broken {
+            log.Broken++
+        }
+        if f.Operation != "" && e.Operation != f.Operation || e.Time < f.Since.UnixNano() {
+            continue
+        }
+        if f.Height >= 0 && !changesHeight(e, f.Height) {
+            continue
+        }
+        log.Entries = append(log.Entries, e)
+    }
+    if f.Last > 0 && len(log.Entries) > f.Last {
+        log.Entries = log.Entries[len(log.Entries)-f.Last:]
+    }
+    return log
+}
+
+// changesHeight reports whether e lists a change to height. Entries list
+// at most db.AuditChangesKept changes, so later ones are not matched.
+func changesHeight(e db.AuditEntry, height int) bool {
+    for _, c := range e.Changes {
+        if c.Height == height {
+            return true
+        }
+    }
+    return false
+}
+
+// OutputAuditLog prints one line per entry, and with -v the blocks each
+// changed with their hashes before and after.
+func OutputAuditLog(log *AuditLog, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, log, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Entries: %d | Shown: %d | Broken: %d\n", log.Total, len(log.Entries), log.Broken)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "AUDIT LOG")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "Database: %s\n\n", log.DatabasePath)
+    if len(log.Entries) == 0 {
+        fmt.Fprintln(w, "  No matching entries.")
+    } else {
+        fmt.Fprintf(w, "  %-*s  %-24s  %-10s  %8s\n", timeWidth(), "Time", "Who", "Operation", "Blocks")
+    }
+    for _, e := range log.Entries {
+        line := fmt.Sprintf("  %-*s  %-24s  %-10s  %8d", timeWidth(), FormatTime(time.Unix(0, e.Time)),
+            e.User+"@"+e.Host, e.Operation, e.Blocks)
+        if e.Broken {
+            line = colorize(opts, ansiRed, line+"  broken link: entries before were removed or edited")
+        }
+        fmt.Fprintln(w, line)
+        if opts.Verbosity < VerbosityVerbose {
+            continue
+        }
+        if len(e.Args) > 0 {
+            fmt.Fprintf(w, "      args: %s\n", strings.Join(e.Args, " "))
+        }
+        for _, c := range e.Changes {
+            fmt.Fprintf(w, "      block %-8d %s -> %s\n", c.Height, orNone(c.BeforeHash), orNone(c.AfterHash))
+        }
+        if more := e.Blocks - len(e.Changes); more > 0 {
+            fmt.Fprintf(w, "      ... and %d more block(s)\n", more)
+        }
+    }
+
+    fmt.Fprintln(w)
+    if log.Broken > 0 {
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiRed, sym.Fail),
+            colorize(opts, ansiRed, fmt.Sprintf("AUDIT LOG TAMPERED: %d broken link(s) in %d entries", log.Broken, log.Total)))
+    } else {
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiGreen, sym.OK),
+            colorize(opts, ansiGreen, fmt.Sprintf("Audit log intact: %d entries", log.Total)))
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
+
+func orNone(hash string) string {
+    if hash == "" {
+        return "(none)"
+    }
+    return hash
+}
@@ -0,0 +1,63 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+
+    "bhiv-chain-inspector/internal/backup"
+)
+
+// BackupReport is the output of the backup and restore commands.
+type BackupReport struct {
+    Operation    string           `json:"operation"`
+    DatabasePath string           `json:"database_path"`
+    Archive      string           `json:"archive"`
+    Size         int64            `json:"archive_size"`
+    Manifest     *backup.Manifest `json:"manifest"`
+    // Verified is set once the archive (backup) or the restored chain
+    // (restore) was checked against the manifest.
+    Verified     bool             `json:"verified"`
+    // Problems are why a restored chain failed verification.
+    Problems     []string         `json:"problems,omitempty"`
+    DurationMS   int64            `json:"duration_ms"`
+}
+
+// OutputBackup prints the manifest of the archive written or restored.
+func OutputBackup(report *BackupReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    m := report.Manifest
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "%s: %s | Blocks: %d | Tip: %d | Size: %s | Verified: %t\n", strings.ToUpper(report.Operation[:1])+report.Operation[1:],
+            report.Archive, m.Blocks, m.Tip, formatBytes(report.Size), report.Verified)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "CHAIN %s\n", strings.ToUpper(report.Operation))
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:         %s\n", report.DatabasePath)
+    fmt.Fprintf(w, "  Archive:          %s (%s)\n", report.Archive, formatBytes(report.Size))
+    fmt.Fprintf(w, "  Created:          %s\n", m.Created)
+    if m.ChainID != "" {
+        fmt.Fprintf(w, "  Chain ID:         %s\n", m.ChainID)
+    }
+    fmt.Fprintf(w, "  Blocks:           %d\n", m.Blocks)
+    fmt.Fprintf(w, "  Tip:              %d (%s)\n", m.Tip, m.TipHash)
+    fmt.Fprintf(w, "  Hash Algorithm:   %s\n", m.HashAlgorithm)
+    fmt.Fprintf(w, "  Fingerprint:      %s\n", m.Fingerprint)
+    fmt.Fprintf(w, "  Duration:         %d ms\n", report.DurationMS)
+    fmt.Fprintln(w)
+    for _, problem := range report.Problems {
+        fmt.Fprintf(w, "  %s %s\n", colorize(opts, ansiRed, sym.Fail), problem)
+    }
+    if report.Verified {
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiGreen, sym.OK), colorize(opts, ansiGreen, strings.ToUpper(report.Operation)+" VERIFIED"))
+    } else {
+        fmt.Fprintf(w, "%s  %s\n", sym.Warn, colorize(opts, ansiRed, strings.ToUpper(report.Operation)+" NOT VERIFIED"))
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
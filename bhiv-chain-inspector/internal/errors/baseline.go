@@ -0,0 +1,90 @@
+package errors
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+)
+
+// BaselineDiff compares a scan against an earlier scan of the same chain.
+// Findings are matched on class and message.
+type BaselineDiff struct {
+    BaselinePath     string  `json:"baseline_path"`
+    BaselineScanTime string  `json:"baseline_scan_time"`
+    New              []Issue `json:"new"`
+    Fixed            []Issue `json:"fixed"`
+    Unchanged        []Issue `json:"unchanged"`
+}
+
+// NewErrors counts new findings with error severity, i.e. regressions.
+func (d *BaselineDiff) NewErrors() int {
+    n := 0
+    for _, issue := range d.New {
+        if issue.Severity == SeverityError {
+            n++
+        }
+    }
+    return n
+}
+
+// LoadScanResult reads a scan previously written with --json.
+func LoadScanResult(path string) (*ErrorScanResult, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var result ErrorScanResult
+    if err := json.Unmarshal(data, &result); err != nil {
+        return nil, fmt.Errorf("%s is not a scan JSON report: %w", path, err)
+    }
+    // Reports of plain message strings name the class by list only.
+    fillCode := func(class string, entries []ErrorEntry) {
+        for i := range entries {
+            if entries[i].Code == "" {
+                entries[i].Code = CodeOf(class)
+            }
+        }
+    }
+    for _, class := range ScanClasses {
+        if list := result.list(class); list != nil {
+            fillCode(class, *list)
+        }
+    }
+    for class, entries := range result.Custom {
+        fillCode(class, entries)
+    }
+    return &result, nil
+}
+
+// DiffAgainstBaseline classifies every finding of current and baseline as
+// new, fixed or unchanged.
+func DiffAgainstBaseline(current, baseline *ErrorScanResult, baselinePath string) *BaselineDiff {
+    diff := &BaselineDiff{
+        BaselinePath:     baselinePath,
+        BaselineScanTime: baseline.ScanTime,
+    }
+
+    key := func(issue Issue) string { return issue.Class + "\x00" + issue.Message }
+
+    before := make(map[string]bool)
+    for _, issue := range baseline.Issues() {
+        before[key(issue)] = true
+    }
+
+    now := make(map[string]bool)
+    for _, issue := range current.Issues() {
+        now[key(issue)] = true
+        if before[key(issue)] {
+            diff.Unchanged = append(diff.Unchanged, issue)
+        } else {
+            diff.New = append(diff.New, issue)
+        }
+    }
+
+    for _, issue := range baseline.Issues() {
+        if !now[key(issue)] {
+            diff.Fixed = append(diff.Fixed, issue)
+        }
+    }
+    return diff
+}
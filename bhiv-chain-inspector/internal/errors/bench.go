@@ -0,0 +1,73 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+    "time"
+)
+
+// BenchReport is the output of the bench command: the throughput of each
+// phase over a scratch chain of Blocks blocks.
+type BenchReport struct {
+    Backend     string       `json:"backend"`
+    Encoding    string       `json:"encoding"`
+    Compression string       `json:"compression"`
+    Encrypted   bool         `json:"encrypted"`
+    Sealed      bool         `json:"sealed"`
+    Blocks      int          `json:"blocks"`
+    Phases      []BenchPhase `json:"phases"`
+}
+
+// BenchPhase is the timing of one phase: write, read, scan or compare.
+type BenchPhase struct {
+    Name         string  `json:"name"`
+    DurationMS   int64   `json:"duration_ms"`
+    BlocksPerSec float64 `json:"blocks_per_second"`
+}
+
+// Add records that phase name processed every block in d.
+func (r *BenchReport) Add(name string, d time.Duration) {
+    phase := BenchPhase{Name: name, DurationMS: d.Milliseconds()}
+    if s := d.Seconds(); s > 0 {
+        phase.BlocksPerSec = float64(r.Blocks) / s
+    }
+    r.Phases = append(r.Phases, phase)
+}
+
+// label is the name of the phase as printed.
+func (p BenchPhase) label() string {
+    return strings.ToUpper(p.Name[:1]) + p.Name[1:]
+}
+
+// OutputBenchReport prints one row per phase.
+func OutputBenchReport(report *BenchReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        parts := []string{fmt.Sprintf("Blocks: %d", report.Blocks)}
+        for _, p := range report.Phases {
+            parts = append(parts, fmt.Sprintf("%s: %.0f blocks/s", p.label(), p.BlocksPerSec))
+        }
+        fmt.Fprintln(w, strings.Join(parts, " | "))
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "STORAGE BENCHMARK")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Backend:          %s\n", report.Backend)
+    fmt.Fprintf(w, "  Encoding:         %s\n", report.Encoding)
+    fmt.Fprintf(w, "  Compression:      %s\n", report.Compression)
+    fmt.Fprintf(w, "  Encrypted:        %t\n", report.Encrypted)
+    fmt.Fprintf(w, "  Sealed:           %t\n", report.Sealed)
+    fmt.Fprintf(w, "  Blocks:           %d\n", report.Blocks)
+
+    fmt.Fprintf(w, "\n%sTHROUGHPUT:\n", sym.Stats)
+    for _, p := range report.Phases {
+        fmt.Fprintf(w, "  %-18s%8d ms  %12.0f blocks/s\n", p.label()+":", p.DurationMS, p.BlocksPerSec)
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
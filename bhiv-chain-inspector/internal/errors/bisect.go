@@ -0,0 +1,89 @@
+package errors
+
+import (
+    "bhiv-chain-inspector/internal/db"
+)
+
+// FindDivergence returns the first height at which the two chains differ,
+// or -1 if they are identical, using O(log n) block loads.
+//
+// It relies on the chain being hash-linked: each block's hash commits to
+// its predecessor, so equal blocks at height h imply equal prefixes 0..h.
+// That makes "same block at h" monotonic and lets the search bisect the
+// common height range. A chain with broken linkage (see scan) can
+// mislead the search; compare without --bisect walks every height.
+//
+// fp1 and fp2 may be nil. When both are given, the leading segments with
+// equal digests are skipped without loading any block and only the first
+// differing segment onwards is bisected. loads counts the heights probed;
+// each probe reads one block from each chain.
+func FindDivergence(r1, r2 db.BlockReader, fp1, fp2 *Fingerprint) (height, loads int) {
+    var tip1, tip2 int
+    if fp1 != nil && fp2 != nil {
+        tip1, tip2 = fp1.Height, fp2.Height
+    } else {
+        tip1, tip2 = r1.GetMaxHeight(), r2.GetMaxHeight()
+    }
+    common := tip1
+    if tip2 < common {
+        common = tip2
+    }
+
+    lo := 0
+    for _, seg := range matchingSegments(fp1, fp2) {
+        if seg.From != lo {
+            break
+        }
+        lo = seg.To + 1
+    }
+
+    same := func(h int) bool {
+        loads++
+        return sameBlockAt(r1, r2, h)
+    }
+
+    // Invariant: heights below lo are the same; heights from hi on differ
+    // (hi = common+1 means no difference has been seen yet).
+    hi := common + 1
+    for lo < hi {
+        mid := lo + (hi-lo)/2
+        if same(mid) {
+            lo = mid + 1
+        } else {
+            hi = mid
+        }
+    }
+
+    if lo <= common {
+        return lo, loads
+    }
+    if tip1 != tip2 {
+        // One chain is a prefix of the other.
+        return common + 1, loads
+    }
+    return -1, loads
+}
+
+// walkDivergence returns the first height from from through to at which
+// the two chains differ, or -1, loading every height in turn. Unlike
+// FindDivergence it does not rely on hash linkage.
+func walkDivergence(r1, r2 db.BlockReader, from, to int) (height, loads int) {
+    for h := from; h <= to; h++ {
+        loads++
+        if !sameBlockAt(r1, r2, h) {
+            return h, loads
+        }
+    }
+    return -1, loads
+}
+
+// sameBlockAt reports whether both chains hold the same block at h; a
+// block either cannot load counts as different.
+func sameBlockAt(r1, r2 db.BlockReader, h int) bool {
+    b1, err1 := r1.LoadBlock(h)
+    b2, err2 := r2.LoadBlock(h)
+    if err1 != nil || err2 != nil {
+        return false
+    }
+    return b1.Hash == b2.Hash && b1.Data == b2.Data && b1.Timestamp == b2.Timestamp
+}
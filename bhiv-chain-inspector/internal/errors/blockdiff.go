@@ -0,0 +1,256 @@
+package errors
+
+import (
+    "encoding/json"
+    "fmt"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// Diff styles of OutputOptions.DiffStyle.
+const (
+    DiffUnified    = "unified"
+    DiffSideBySide = "side-by-side"
+)
+
+// DiffStyles lists the accepted diff styles, the default first.
+var DiffStyles = []string{DiffUnified, DiffSideBySide}
+
+// sideBySideWidth is the width of each column of a side-by-side diff;
+// longer fields are cut short.
+const sideBySideWidth = 48
+
+// BlockDiff is a unified diff of the canonical JSON of the two versions of
+// a mismatched block, recorded by compare with CompareOptions.ShowDiff.
+type BlockDiff struct {
+    Height int      `json:"height"`
+    // Fields are the block fields whose values differ.
+    Fields []string `json:"fields"`
+    Diff   string   `json:"diff"`
+    lines  []diffLine
+}
+
+// diffBlocks returns the diff of two versions of the block at height, or
+// nil if their JSON is identical.
+func diffBlocks(height int, block1, block2 *blocks.Block) *BlockDiff {
+    json1, json2 := canonicalJSON(block1), canonicalJSON(block2)
+    if json1 == json2 {
+        return nil
+    }
+    var fields1, fields2 map[string]any
+    json.Unmarshal([]byte(json1), &fields1)
+    json.Unmarshal([]byte(json2), &fields2)
+    lines := diffLines(strings.Split(json1, "\n"), strings.Split(json2, "\n"))
+    name := fmt.Sprintf("block-%d", height)
+    return &BlockDiff{
+        Height: height,
+        Fields: changedFields(fields1, fields2),
+        Diff:   unifiedDiff("node1/"+name, "node2/"+name, lines),
+        lines:  lines,
+    }
+}
+
+// canonicalJSON is the indented JSON of a block, one field per line in
+// struct order.
+func canonicalJSON(block *blocks.Block) string {
+    data, _ := json.MarshalIndent(block, "", "  ")
+    return string(data)
+}
+
+// diffLine is one line of a diff: ' ' kept, '-' only in the first text,
+// '+' only in the second.
+type diffLine struct {
+    op   byte
+    text string
+}
+
+// diffLines diffs two texts line by line through their longest common
+// subsequence. Blocks are a dozen lines, so the quadratic table is cheap.
+func diffLines(a, b []string) []diffLine {
+    lcs := make([][]int, len(a)+1)
+    for i := range lcs {
+        lcs[i] = make([]int, len(b)+1)
+    }
+    for i := len(a) - 1; i >= 0; i-- {
+        for j := len(b) - 1; j >= 0; j-- {
+            if a[i] == b[j] {
+                lcs[i][j] = lcs[i+1][j+1] + 1
+            } else {
+                lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+            }
+        }
+    }
+    var lines []diffLine
+    i, j := 0, 0
+    for i < len(a) || j < len(b) {
+        switch {
+        case i < len(a) && j < len(b) && a[i] == b[j]:
+            lines = append(lines, diffLine{' ', a[i]})
+            i++
+            j++
+        case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+            lines = append(lines, diffLine{'-', a[i]})
+            i++
+        default:
+            lines = append(lines, diffLine{'+', b[j]})
+            j++
+        }
+    }
+    return lines
+}
+
+// unifiedDiff formats lines as a unified diff with diffContext lines of
+// context, merging changes closer than twice that into one hunk.
+func unifiedDiff(name1, name2 string, lines []diffLine) string {
+    var sb strings.Builder
+    fmt.Fprintf(&sb, "--- %s\n+++ %s\n", name1, name2)
+    for start := 0; start < len(lines); {
+        first := start
+        for first < len(lines) && lines[first].op == ' ' {
+            first++
+        }
+        if first == len(lines) {
+            break
+        }
+        // Extend the hunk while the next change is within reach.
+        last, kept := first, 0
+        for k := first; k < len(lines) && kept <= 2*diffContext; k++ {
+            if lines[k].op == ' ' {
+                kept++
+            } else {
+                last, kept = k, 0
+            }
+        }
+        from, to := max(first-diffContext, 0), min(last+diffContext+1, len(lines))
+
+        // Line numbers of the hunk in each text, counted from 1.
+        line1, line2 := 1, 1
+        for _, l := range lines[:from] {
+            if l.op != '+' {
+                line1++
+            }
+            if l.op != '-' {
+                line2++
+            }
+        }
+        count1, count2 := 0, 0
+        for _, l := range lines[from:to] {
+            if l.op != '+' {
+                count1++
+            }
+            if l.op != '-' {
+                count2++
+            }
+        }
+        fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", line1, count1, line2, count2)
+        for _, l := range lines[from:to] {
+            fmt.Fprintf(&sb, "%c%s\n", l.op, l.text)
+        }
+        start = to
+    }
+    return sb.String()
+}
+
+// printBlockDiffs prints the diff of every mismatched block in the
+// style of opts.DiffStyle.
+func printBlockDiffs(diffs []BlockDiff, opts OutputOptions) {
+    w := opts.Writer()
+    fmt.Fprintf(w, "\n%sBLOCK DIFFS:\n", symbolsFor(opts).Details)
+    for _, d := range diffs {
+        fmt.Fprintf(w, "\n  Block %d (%s):\n", d.Height, strings.Join(d.Fields, ", "))
+        if opts.DiffStyle == DiffSideBySide {
+            printSideBySide(d.lines, opts)
+            continue
+        }
+        for _, line := range strings.Split(strings.TrimSuffix(d.Diff, "\n"), "\n") {
+            switch {
+            case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+                // File headers stay uncolored.
+            case strings.HasPrefix(line, "@@"):
+                line = colorize(opts, ansiYellow, line)
+            case strings.HasPrefix(line, "-"):
+                line = colorize(opts, ansiRed, line)
+            case strings.HasPrefix(line, "+"):
+                line = colorize(opts, ansiGreen, line)
+            }
+            fmt.Fprintf(w, "    %s\n", line)
+        }
+    }
+}
+
+// printSideBySide prints the fields of both versions of a block in two
+// columns, diff -y style: "|" marks a field that differs, "<" and ">" one
+// only Node1 or Node2 has.
+func printSideBySide(lines []diffLine, opts OutputOptions) {
+    w := opts.Writer()
+    row := func(left, mark, right string) {
+        left, right = fieldText(left, right), fieldText(right, left)
+        pad := strings.Repeat(" ", sideBySideWidth-len(left))
+        switch mark {
+        case "|":
+            left, right = colorize(opts, ansiRed, left), colorize(opts, ansiGreen, right)
+        case "<":
+            left = colorize(opts, ansiRed, left)
+        case ">":
+            right = colorize(opts, ansiGreen, right)
+        }
+        fmt.Fprintf(w, "    %s%s %s %s\n", left, pad, mark, right)
+    }
+    fmt.Fprintf(w, "    %-*s   %s\n", sideBySideWidth, "Node1", "Node2")
+    for i := 0; i < len(lines); {
+        if lines[i].op == ' ' {
+            if text := lines[i].text; text != "{" && text != "}" {
+                row(text, " ", text)
+            }
+            i++
+            continue
+        }
+        // Pair a run of removed lines with the added lines after it.
+        var removed, added []string
+        for ; i < len(lines) && lines[i].op == '-'; i++ {
+            removed = append(removed, lines[i].text)
+        }
+        for ; i < len(lines) && lines[i].op == '+'; i++ {
+            added = append(added, lines[i].text)
+        }
+        for k := range max(len(removed), len(added)) {
+            switch {
+            case k < len(removed) && k < len(added):
+                row(removed[k], "|", added[k])
+            case k < len(removed):
+                row(removed[k], "<", "")
+            default:
+                row("", ">", added[k])
+            }
+        }
+    }
+}
+
+// fieldText is a line of a block's JSON without its indentation and
+// trailing comma, cut to sideBySideWidth. A line longer than that is cut
+// around its first difference from other, so that the difference shows.
+func fieldText(line, other string) string {
+    line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+    if len(line) <= sideBySideWidth {
+        return line
+    }
+    other = strings.TrimSuffix(strings.TrimSpace(other), ",")
+    at := 0
+    for at < len(line) && at < len(other) && line[at] == other[at] {
+        at++
+    }
+    if line == other || other == "" || at < sideBySideWidth-3 {
+        return line[:sideBySideWidth-3] + "..."
+    }
+    // Keep the field name, then a window starting shortly before the
+    // difference.
+    key, _, _ := strings.Cut(line, ":")
+    key += ": ..."
+    room := sideBySideWidth - len(key) - 3
+    from := min(at-8, len(line)-room)
+    return key + line[from:from+room] + "..."
+}
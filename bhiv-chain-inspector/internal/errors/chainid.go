@@ -0,0 +1,32 @@
+package errors
+
+import (
+    "fmt"
+
+    "bhiv-chain-inspector/internal/blocks"
+)
+
+// ClassChainIDErrors is reported for blocks that belong to another chain
+// than the database declares, such as blocks restored from a backup of a
+// different environment.
+const ClassChainIDErrors = "chain_id_errors"
+
+// chainIDCheck compares each block's ChainID with CheckContext.ChainID.
+// Databases that declare no chain are not checked.
+type chainIDCheck struct{}
+
+func (chainIDCheck) Name() string { return "chain-id" }
+
+func (chainIDCheck) Classes() []string { return []string{ClassChainIDErrors} }
+
+func (chainIDCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    switch {
+    case ctx.ChainID == "" || block.ChainID == ctx.ChainID:
+        return nil
+    case block.ChainID == "":
+        return []Finding{{Class: ClassChainIDErrors, Message: fmt.Sprintf("Block %d: No chain ID (chain is %s)", ctx.Height, ctx.ChainID),
+            Expected: ctx.ChainID}}
+    }
+    return []Finding{{Class: ClassChainIDErrors, Message: fmt.Sprintf("Block %d: Chain ID %s, chain is %s", ctx.Height, block.ChainID, ctx.ChainID),
+        Expected: ctx.ChainID, Actual: block.ChainID}}
+}
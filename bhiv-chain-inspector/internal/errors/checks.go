@@ -0,0 +1,288 @@
+package errors
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// Finding is a problem reported by a Check for one block. Findings about
+// a value that should have been another also give both, as text.
+type Finding struct {
+    Class    string
+    Message  string
+    Expected string
+    Actual   string
+}
+
+// CheckContext carries the chain state a check may need. It is owned by
+// the scanner and must not be modified by checks.
+type CheckContext struct {
+    // Height is the key height the block was loaded from.
+    Height int
+    // ExpectedHeight is the height the block should declare.
+    ExpectedHeight int
+    // Prev is the block at the previous height, nil for the first one and
+    // when that height is missing or cannot be decoded.
+    Prev *blocks.Block
+    // Now is the scan start time (Unix seconds).
+    Now int64
+    // SeenHashes maps every hash scanned so far to its first height.
+    SeenHashes map[string]int
+    // SeenData indexes the Data of every block scanned so far.
+    SeenData *DataIndex
+    // Chain reads other blocks of the chain being scanned, for checks
+    // that look further back than Prev.
+    Chain db.BlockReader
+    // ChainID is the chain the blocks must belong to: ScanOptions.ChainID,
+    // or else the ID the database declares; empty if neither is set.
+    ChainID string
+    // HashAlgorithm is the algorithm block hashes are checked with.
+    HashAlgorithm blocks.HashAlgorithm
+}
+
+// Check validates a single decoded block. Missing and undecodable blocks
+// are detected by the scanner itself, before any check runs.
+type Check interface {
+    Name() string
+    Validate(block *blocks.Block, ctx *CheckContext) []Finding
+}
+
+// ClassDeclarer may be implemented by checks that report classes beyond
+// the built-in ones, so that severities, suppressions and health weights
+// can refer to them.
+type ClassDeclarer interface {
+    Classes() []string
+}
+
+// StoreCheck may be implemented by checks that inspect the database as a
+// whole rather than block by block. ValidateStore runs once per scan,
+// before the first height. Its findings about a scanned height weigh on
+// that height's health penalty; the others are about the database as a
+// whole, which then weighs on the score as one more height. Validate is
+// still called for every block and may return nil.
+type StoreCheck interface {
+    ValidateStore(storage *db.Storage) []StoreFinding
+}
+
+// StoreFinding is a finding of a StoreCheck and the height it is about,
+// -1 for the database as a whole.
+type StoreFinding struct {
+    Height int
+    Finding
+}
+
+// RawCheck may be implemented by checks that inspect the stored value of
+// each block rather than the decoded block. ValidateRaw runs for every
+// height with a value, before it is decoded, so it also sees values that
+// do not decode.
+type RawCheck interface {
+    ValidateRaw(storage *db.Storage, height int, raw []byte) []Finding
+}
+
+var registry []Check
+
+// RegisterCheck adds c to the checks run by every scan. It panics if a
+// check with the same name is already registered.
+func RegisterCheck(c Check) {
+    for _, existing := range registry {
+        if existing.Name() == c.Name() {
+            panic(fmt.Sprintf("errors: check %q registered twice", c.Name()))
+        }
+    }
+    registry = append(registry, c)
+}
+
+// Checks returns all registered checks in registration order.
+func Checks() []Check {
+    return append([]Check(nil), registry...)
+}
+
+// SelectChecks returns the registered checks with the given names, in
+// registration order. An empty list selects every check.
+func SelectChecks(names []string) ([]Check, error) {
+    if len(names) == 0 {
+        return Checks(), nil
+    }
+    wanted := make(map[string]bool, len(names))
+    for _, name := range names {
+        wanted[strings.TrimSpace(name)] = true
+    }
+
+    var selected []Check
+    for _, c := range registry {
+        if wanted[c.Name()] {
+            selected = append(selected, c)
+            delete(wanted, c.Name())
+        }
+    }
+    if len(wanted) > 0 {
+        var unknown []string
+        for name := range wanted {
+            unknown = append(unknown, name)
+        }
+        sort.Strings(unknown)
+        return nil, fmt.Errorf("unknown checks %s (available: %s)",
+            strings.Join(unknown, ", "), strings.Join(CheckNames(), ", "))
+    }
+    return selected, nil
+}
+
+// CheckNames lists the names of all registered checks.
+func CheckNames() []string {
+    names := make([]string, len(registry))
+    for i, c := range registry {
+        names[i] = c.Name()
+    }
+    return names
+}
+
+// KnownClasses lists the built-in classes plus those declared by
+// registered checks.
+func KnownClasses() []string {
+    classes := append([]string(nil), ScanClasses...)
+    seen := make(map[string]bool, len(classes))
+    for _, class := range classes {
+        seen[class] = true
+    }
+    for _, c := range registry {
+        if d, ok := c.(ClassDeclarer); ok {
+            for _, class := range d.Classes() {
+                if !seen[class] {
+                    seen[class] = true
+                    classes = append(classes, class)
+                }
+            }
+        }
+    }
+    return classes
+}
+
+func isKnownClass(class string) bool {
+    for _, known := range KnownClasses() {
+        if known == class {
+            return true
+        }
+    }
+    return false
+}
+
+func init() {
+    RegisterCheck(hashCheck{})
+    RegisterCheck(duplicateCheck{})
+    RegisterCheck(dataCheck{})
+    RegisterCheck(timestampCheck{})
+    RegisterCheck(emptyCheck{})
+    RegisterCheck(prevHashCheck{})
+    RegisterCheck(heightCheck{})
+    RegisterCheck(merkleCheck{})
+    RegisterCheck(powCheck{})
+    RegisterCheck(chainIDCheck{})
+    RegisterCheck(keysCheck{})
+    RegisterCheck(indexCheck{})
+    RegisterCheck(orphansCheck{})
+    RegisterCheck(sealCheck{})
+}
+
+type hashCheck struct{}
+
+func (hashCheck) Name() string { return "hash" }
+
+func (hashCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    if block.VerifyHashWith(ctx.HashAlgorithm) != nil {
+        return []Finding{{Class: ClassBadHash, Message: fmt.Sprintf("Block %d: Bad hash", ctx.Height),
+            Expected: block.ComputedHashWith(ctx.HashAlgorithm), Actual: block.Hash}}
+    }
+    return nil
+}
+
+type duplicateCheck struct{}
+
+func (duplicateCheck) Name() string { return "duplicates" }
+
+func (duplicateCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    if firstHeight, exists := ctx.SeenHashes[block.Hash]; exists {
+        return []Finding{{Class: ClassDuplicateHashes,
+            Message: fmt.Sprintf("Block %d duplicates hash from Block %d", ctx.Height, firstHeight), Actual: block.Hash}}
+    }
+    return nil
+}
+
+type timestampCheck struct{}
+
+func (timestampCheck) Name() string { return "timestamps" }
+
+func (timestampCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    var findings []Finding
+
+    // Allow 5 minutes of clock drift
+    if block.Timestamp > ctx.Now+300 {
+        findings = append(findings, Finding{Class: ClassTimestampFuture,
+            Message:  fmt.Sprintf("Block %d: Timestamp in future", ctx.Height),
+            Expected: fmt.Sprintf("<= %d", ctx.Now+300), Actual: strconv.FormatInt(block.Timestamp, 10)})
+    }
+
+    tenYearsAgo := ctx.Now - (10 * 365 * 24 * 60 * 60)
+    if block.Timestamp < tenYearsAgo {
+        findings = append(findings, Finding{Class: ClassTimestampPast,
+            Message:  fmt.Sprintf("Block %d: Timestamp too old", ctx.Height),
+            Expected: fmt.Sprintf(">= %d", tenYearsAgo), Actual: strconv.FormatInt(block.Timestamp, 10)})
+    }
+
+    if ctx.Prev != nil && block.Timestamp <= ctx.Prev.Timestamp {
+        findings = append(findings, Finding{Class: ClassTimestampNotIncreasing,
+            Message:  fmt.Sprintf("Block %d: Timestamp not increasing", ctx.Height),
+            Expected: fmt.Sprintf("> %d", ctx.Prev.Timestamp), Actual: strconv.FormatInt(block.Timestamp, 10)})
+    }
+    return findings
+}
+
+type emptyCheck struct{}
+
+func (emptyCheck) Name() string { return "empty" }
+
+func (emptyCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    if strings.TrimSpace(block.Data) == "" {
+        return []Finding{{Class: ClassEmptyBlocks, Message: fmt.Sprintf("Block %d: Empty block", ctx.Height)}}
+    }
+    return nil
+}
+
+type prevHashCheck struct{}
+
+func (prevHashCheck) Name() string { return "prevhash" }
+
+func (prevHashCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    if ctx.Height == 0 {
+        if block.PrevHash != "0" {
+            return []Finding{{Class: ClassPrevHashErrors, Message: "Block 0: Invalid genesis prevHash",
+                Expected: "0", Actual: block.PrevHash}}
+        }
+    } else if ctx.Prev != nil && block.PrevHash != ctx.Prev.Hash {
+        return []Finding{{Class: ClassPrevHashErrors, Message: fmt.Sprintf("Block %d: PrevHash linkage broken", ctx.Height),
+            Expected: ctx.Prev.Hash, Actual: block.PrevHash}}
+    }
+    return nil
+}
+
+type heightCheck struct{}
+
+func (heightCheck) Name() string { return "height" }
+
+func (heightCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    var findings []Finding
+    expected, actual := strconv.Itoa(ctx.ExpectedHeight), strconv.Itoa(block.Height)
+    if block.Height != ctx.ExpectedHeight {
+        findings = append(findings, Finding{Class: ClassHeightErrors,
+            Message: fmt.Sprintf("Block %d: Height mismatch", ctx.Height), Expected: expected, Actual: actual})
+    }
+    if block.Height < ctx.ExpectedHeight {
+        findings = append(findings, Finding{Class: ClassOutOfOrderBlocks,
+            Message: fmt.Sprintf("Block %d: Out of order", ctx.Height), Expected: expected, Actual: actual})
+    }
+    return findings
+}
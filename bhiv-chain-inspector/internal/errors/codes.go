@@ -0,0 +1,123 @@
+package errors
+
+import (
+    "regexp"
+    "strings"
+)
+
+// Code is the stable identifier of a class of finding, such as
+// E_HASH_MISMATCH. Reports carry it next to the class and message so that
+// tooling and suppression rules need not match on English text; codes
+// are never renamed or reused.
+type Code string
+
+// Codes of the scan classes.
+const (
+    CodeCorruptBlock      Code = "E_CORRUPT_BLOCK"
+    CodeHashMismatch      Code = "E_HASH_MISMATCH"
+    CodeTSFuture          Code = "E_TS_FUTURE"
+    CodeTSPast            Code = "E_TS_PAST"
+    CodeTSNotIncreasing   Code = "E_TS_NOT_INCREASING"
+    CodeDuplicateHash     Code = "E_DUPLICATE_HASH"
+    CodeEmptyBlock        Code = "E_EMPTY_BLOCK"
+    CodePrevHashBroken    Code = "E_PREVHASH_BROKEN"
+    CodeHeightMismatch    Code = "E_HEIGHT_MISMATCH"
+    CodeMissingBlock      Code = "E_MISSING_BLOCK"
+    CodeOutOfOrder        Code = "E_OUT_OF_ORDER"
+    CodeMerkleRoot        Code = "E_MERKLE_ROOT"
+    CodeChainID           Code = "E_CHAIN_ID"
+    CodePoW               Code = "E_POW"
+    CodeDifficulty        Code = "E_DIFFICULTY"
+    CodeSignature         Code = "E_SIGNATURE"
+    CodeValidator         Code = "E_VALIDATOR"
+    CodeTamperedSeal      Code = "E_SEAL_TAMPERED"
+    CodeUnknownKey        Code = "E_UNKNOWN_KEY"
+    CodeOrphanBlock       Code = "E_ORPHAN_BLOCK"
+    CodeIndex             Code = "E_INDEX"
+    CodeDuplicateData     Code = "E_DUPLICATE_DATA"
+    CodeNearDuplicateData Code = "E_NEAR_DUPLICATE_DATA"
+)
+
+// Codes of the per-block verdicts of compare.
+const (
+    CodeNode1Only         Code = "E_NODE1_ONLY"
+    CodeNode2Only         Code = "E_NODE2_ONLY"
+    CodeNodeHashDiffers   Code = "E_NODE_HASH_DIFFERS"
+    CodeNodeDataDiffers   Code = "E_NODE_DATA_DIFFERS"
+    CodeNodeTimeDiffers   Code = "E_NODE_TIMESTAMP_DIFFERS"
+)
+
+// Codes of the per-height verdicts of compare3.
+const (
+    CodeLeftDiverged      Code = "E_LEFT_DIVERGED"
+    CodeRightDiverged     Code = "E_RIGHT_DIVERGED"
+    CodeBothDiverged      Code = "E_BOTH_DIVERGED"
+    CodeBothChangedAlike  Code = "E_BOTH_CHANGED_ALIKE"
+)
+
+var classCodes = map[string]Code{
+    ClassCorruptedJSON:          CodeCorruptBlock,
+    ClassBadHash:                CodeHashMismatch,
+    ClassTimestampFuture:        CodeTSFuture,
+    ClassTimestampPast:          CodeTSPast,
+    ClassTimestampNotIncreasing: CodeTSNotIncreasing,
+    ClassDuplicateHashes:        CodeDuplicateHash,
+    ClassEmptyBlocks:            CodeEmptyBlock,
+    ClassPrevHashErrors:         CodePrevHashBroken,
+    ClassHeightErrors:           CodeHeightMismatch,
+    ClassMissingBlocks:          CodeMissingBlock,
+    ClassOutOfOrderBlocks:       CodeOutOfOrder,
+    ClassMerkleRootErrors:       CodeMerkleRoot,
+    ClassChainIDErrors:          CodeChainID,
+    ClassPoWErrors:              CodePoW,
+    ClassDifficultyErrors:       CodeDifficulty,
+    ClassSignatureErrors:        CodeSignature,
+    ClassValidatorErrors:        CodeValidator,
+    ClassTamperedSeals:          CodeTamperedSeal,
+    ClassUnknownKeys:            CodeUnknownKey,
+    ClassOrphanBlocks:           CodeOrphanBlock,
+    ClassIndexErrors:            CodeIndex,
+    ClassDuplicateData:          CodeDuplicateData,
+    ClassNearDuplicateData:      CodeNearDuplicateData,
+
+    "node1_only_blocks":    CodeNode1Only,
+    "node2_only_blocks":    CodeNode2Only,
+    "hash_mismatches":      CodeNodeHashDiffers,
+    "data_mismatches":      CodeNodeDataDiffers,
+    "timestamp_mismatches": CodeNodeTimeDiffers,
+
+    ClassLeftDiverged:     CodeLeftDiverged,
+    ClassRightDiverged:    CodeRightDiverged,
+    ClassBothDiverged:     CodeBothDiverged,
+    ClassBothChangedAlike: CodeBothChangedAlike,
+}
+
+var codeUnsafe = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// CodeOf returns the code of class. Classes of plugins and expression
+// rules have none assigned; theirs is E_ and the class name in upper
+// case, e.g. E_PAYLOAD_SIZE for payload-size.
+func CodeOf(class string) Code {
+    if code, ok := classCodes[class]; ok {
+        return code
+    }
+    return Code("E_" + strings.Trim(codeUnsafe.ReplaceAllString(strings.ToUpper(class), "_"), "_"))
+}
+
+// KnownCodes lists the codes of KnownClasses, in the same order.
+func KnownCodes() []Code {
+    var codes []Code
+    for _, class := range KnownClasses() {
+        codes = append(codes, CodeOf(class))
+    }
+    return codes
+}
+
+func isKnownCode(code Code) bool {
+    for _, known := range KnownCodes() {
+        if known == code {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,42 @@
+package errors
+
+import "os"
+
+const (
+    ansiReset  = "\033[0m"
+    ansiRed    = "\033[31m"
+    ansiYellow = "\033[33m"
+    ansiGreen  = "\033[32m"
+)
+
+// ColorSupported reports whether stdout looks like a terminal that can show
+// ANSI colors. NO_COLOR (https://no-color.org) and TERM=dumb disable it.
+func ColorSupported() bool {
+    if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+        return false
+    }
+    info, err := os.Stdout.Stat()
+    if err != nil {
+        return false
+    }
+    return info.Mode()&os.ModeCharDevice != 0
+}
+
+// severityColor shows errors in red, warnings in yellow and info uncolored.
+func severityColor(sev Severity) string {
+    switch sev {
+    case SeverityWarning:
+        return ansiYellow
+    case SeverityInfo:
+        return ""
+    default:
+        return ansiRed
+    }
+}
+
+func colorize(opts OutputOptions, color, s string) string {
+    if !opts.Color || color == "" {
+        return s
+    }
+    return color + s + ansiReset
+}
@@ -1,10 +1,15 @@
 package errors
 
 import (
+    "cmp"
+    "context"
     "fmt"
+    "slices"
+    "strconv"
     "time"
 
     "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/tracing"
 )
 
 type ComparisonResult struct {
@@ -23,25 +28,113 @@ type ComparisonResult struct {
     TimestampMismatches []string `json:"timestamp_mismatches"`
     SyncPercentage      float64  `json:"sync_percentage"`
     Recommendations     []string `json:"recommendations"`
+    // SegmentsSkipped counts fingerprint segments found identical.
+    SegmentsSkipped     int      `json:"segments_skipped"`
+    // BisectProbes counts the heights probed to find the divergence point
+    // when bisecting; heights below it were not compared block by block.
+    BisectProbes        int      `json:"bisect_probes,omitempty"`
+    // TxDiffs break down DataMismatches by transaction; see TxDiff.
+    TxDiffs             []TxDiff `json:"tx_diffs,omitempty"`
+    // BlockDiffs are set with CompareOptions.ShowDiff.
+    BlockDiffs          []BlockDiff `json:"block_diffs,omitempty"`
+    // TimestampSkew is set when any height was compared block by block.
+    TimestampSkew       *TimestampSkew `json:"timestamp_skew,omitempty"`
 }
 
-func CompareNodes(storage1, storage2 *db.Storage, db1Path, db2Path string) *ComparisonResult {
+// CompareOptions tunes a comparison without changing what is detected.
+type CompareOptions struct {
+    // OnBlock, when set, is called once for every height present on either node.
+    OnBlock func(BlockVerdict)
+    // Context parents the comparison's trace spans; nil starts a new trace.
+    Context context.Context
+    // SegmentSize is the fingerprint segment size; 0 means
+    // DefaultSegmentSize. A negative value compares every block.
+    SegmentSize int
+    // Bisect finds the divergence point by binary search (see
+    // FindDivergence) and only compares blocks from there on. Local chains
+    // are then not fingerprinted, since that would read every block.
+    Bisect bool
+    // ShowDiff records a unified diff of every block the nodes hold in
+    // different versions; see BlockDiff.
+    ShowDiff bool
+}
+
+// CompareNodes compares two chains block by block. Either side may be a
+// local database or a remote agent. Both chains are fingerprinted first
+// and segments with equal digests are skipped; their heights count as
+// matching but are not passed to OnBlock.
+func CompareNodes(storage1, storage2 db.BlockReader, db1Path, db2Path string, opts CompareOptions) *ComparisonResult {
     result := &ComparisonResult{
-        ScanTime:        time.Now().Format("2006-01-02 15:04:05"),
+        ScanTime:        FormatTime(time.Now()),
         Node1Path:       db1Path,
         Node2Path:       db2Path,
         DivergencePoint: -1,
     }
 
-    result.Node1Height = storage1.GetMaxHeight()
-    result.Node2Height = storage2.GetMaxHeight()
+    traceCtx, span := tracing.Start(opts.Context, "CompareNodes", "node1", db1Path, "node2", db2Path)
+    defer func() {
+        span.SetAttributes("matching_blocks", result.MatchingBlocks,
+            "mismatched_blocks", len(result.MismatchedBlocks), "divergence_point", result.DivergencePoint)
+        span.End()
+    }()
+    storage1 = traced(storage1, traceCtx)
+    storage2 = traced(storage2, traceCtx)
+
+    // A fingerprint that cannot be obtained just means comparing every block.
+    var fp1, fp2 *Fingerprint
+    if opts.SegmentSize >= 0 && (!opts.Bisect || remote(storage1) && remote(storage2)) {
+        var err1, err2 error
+        fp1, err1 = FingerprintOf(storage1, opts.SegmentSize)
+        fp2, err2 = FingerprintOf(storage2, opts.SegmentSize)
+        if err1 != nil || err2 != nil {
+            fp1, fp2 = nil, nil
+        }
+    }
+    skip := make(map[int]Segment)
+    if fp1 != nil {
+        for _, seg := range matchingSegments(fp1, fp2) {
+            skip[seg.From] = seg
+        }
+        result.Node1Height = fp1.Height
+        result.Node2Height = fp2.Height
+    } else {
+        result.Node1Height = storage1.GetMaxHeight()
+        result.Node2Height = storage2.GetMaxHeight()
+    }
 
     maxHeight := result.Node1Height
     if result.Node2Height > maxHeight {
         maxHeight = result.Node2Height
     }
 
-    for i := 0; i <= maxHeight; i++ {
+    report := func(i int, issues ...Issue) {
+        if opts.OnBlock != nil {
+            opts.OnBlock(BlockVerdict{Height: i, Issues: issues})
+        }
+    }
+
+    first := min(firstHeight(storage1), firstHeight(storage2))
+    start := first
+    if opts.Bisect {
+        start, result.BisectProbes = FindDivergence(storage1, storage2, fp1, fp2)
+        if start < 0 {
+            start = maxHeight + 1
+        }
+        // Every height from first below start held the same block on
+        // both nodes.
+        result.MatchingBlocks += max(start-first, 0)
+        skip = make(map[int]Segment)
+    }
+
+    var skew []skewSample
+    for i := start; i <= maxHeight; i++ {
+        if seg, ok := skip[i]; ok {
+            result.MatchingBlocks += seg.Blocks
+            result.SegmentsSkipped++
+            i = seg.To
+            continue
+        }
+
         block1, err1 := storage1.LoadBlock(i)
         block2, err2 := storage2.LoadBlock(i)
 
@@ -54,6 +147,7 @@ func CompareNodes(storage1, storage2 *db.Storage, db1Path, db2Path string) *Comp
             if result.DivergencePoint == -1 {
                 result.DivergencePoint = i
             }
+            report(i, Issue{Class: "node2_only_blocks", Code: CodeNode2Only, Severity: SeverityError, Message: fmt.Sprintf("Block %d: Missing on Node1", i)})
             continue
         }
 
@@ -62,9 +156,13 @@ func CompareNodes(storage1, storage2 *db.Storage, db1Path, db2Path string) *Comp
             if result.DivergencePoint == -1 {
                 result.DivergencePoint = i
             }
+            report(i, Issue{Class: "node1_only_blocks", Code: CodeNode1Only, Severity: SeverityError, Message: fmt.Sprintf("Block %d: Missing on Node2", i)})
             continue
         }
 
+        var issues []Issue
+        skew = append(skew, skewSample{i, block2.Timestamp - block1.Timestamp})
+
         if block1.Hash != block2.Hash {
             result.MismatchedBlocks = append(result.MismatchedBlocks, i)
             if result.DivergencePoint == -1 {
@@ -72,30 +170,69 @@ func CompareNodes(storage1, storage2 *db.Storage, db1Path, db2Path string) *Comp
             }
             errMsg := fmt.Sprintf("Block %d: Hash mismatch", i)
             result.HashMismatches = append(result.HashMismatches, errMsg)
+            issues = append(issues, Issue{Class: "hash_mismatches", Code: CodeNodeHashDiffers, Severity: SeverityError, Message: errMsg})
         } else {
             result.MatchingBlocks++
         }
 
         if block1.Data != block2.Data {
             errMsg := fmt.Sprintf("Block %d: Data differs", i)
+            if diff := diffTransactions(i, block1, block2); diff != nil {
+                result.TxDiffs = append(result.TxDiffs, *diff)
+                errMsg += " (transactions: " + diff.Summary() + ")"
+            }
             result.DataMismatches = append(result.DataMismatches, errMsg)
+            issues = append(issues, Issue{Class: "data_mismatches", Code: CodeNodeDataDiffers, Severity: SeverityWarning, Message: errMsg})
         }
 
         if block1.Timestamp != block2.Timestamp {
             errMsg := fmt.Sprintf("Block %d: Timestamp differs", i)
             result.TimestampMismatches = append(result.TimestampMismatches, errMsg)
+            issues = append(issues, Issue{Class: "timestamp_mismatches", Code: CodeNodeTimeDiffers, Severity: SeverityWarning, Message: errMsg})
+        }
+
+        if opts.ShowDiff {
+            if diff := diffBlocks(i, block1, block2); diff != nil {
+                result.BlockDiffs = append(result.BlockDiffs, *diff)
+            }
         }
+
+        report(i, issues...)
     }
 
     if maxHeight >= 0 {
-        result.SyncPercentage = (float64(result.MatchingBlocks) / float64(maxHeight+1)) * 100
+        result.SyncPercentage = (float64(result.MatchingBlocks) / float64(maxHeight+1-first)) * 100
     }
 
+    result.TimestampSkew = newTimestampSkew(skew)
     result.Recommendations = generateRecommendations(result)
 
     return result
 }
 
+// remote reports whether r fingerprints itself rather than being read.
+func remote(r db.BlockReader) bool {
+    _, ok := r.(Fingerprinter)
+    return ok
+}
+
+// firstHeight is the lowest height r can hold: 0 unless r is a storage
+// whose layout starts higher, like a CometBFT blockstore.
+func firstHeight(r db.BlockReader) int {
+    if s, ok := r.(*db.Storage); ok {
+        return s.FirstHeight()
+    }
+    return 0
+}
+
+// traced parents the reads of local storage on ctx's span.
+func traced(r db.BlockReader, ctx context.Context) db.BlockReader {
+    if s, ok := r.(*db.Storage); ok {
+        return s.WithContext(ctx)
+    }
+    return r
+}
+
 func generateRecommendations(result *ComparisonResult) []string {
     recs := []string{}
 
@@ -109,6 +246,11 @@ func generateRecommendations(result *ComparisonResult) []string {
     if result.DivergencePoint >= 0 {
         recs = append(recs, fmt.Sprintf("Chains diverge at block %d", result.DivergencePoint))
     }
+    if skew := result.TimestampSkew; skew != nil {
+        if rec := skew.recommendation(); rec != "" {
+            recs = append(recs, rec)
+        }
+    }
 
     if len(recs) == 0 {
         recs = append(recs, "Nodes are perfectly synchronized")
@@ -116,3 +258,36 @@ func generateRecommendations(result *ComparisonResult) []string {
 
     return recs
 }
+
+// Columns and Rows make the differences of a comparison a Table, one row
+// per height and class, by height.
+func (r *ComparisonResult) Columns() []string {
+    return []string{"height", "class", "code", "severity", "message"}
+}
+
+func (r *ComparisonResult) Rows() [][]any {
+    rows := [][]any{}
+    heights := func(class string, list []int, message string) {
+        for _, h := range list {
+            rows = append(rows, []any{h, class, CodeOf(class), SeverityError, fmt.Sprintf("Block %d: %s", h, message)})
+        }
+    }
+    messages := func(class string, list []string, severity Severity) {
+        for _, msg := range list {
+            height := -1
+            if m := blockMessage.FindStringSubmatch(msg); m != nil {
+                height, _ = strconv.Atoi(m[1])
+            }
+            rows = append(rows, []any{height, class, CodeOf(class), severity, msg})
+        }
+    }
+    heights("node1_only_blocks", r.Node1OnlyBlocks, "Missing on Node2")
+    heights("node2_only_blocks", r.Node2OnlyBlocks, "Missing on Node1")
+    messages("hash_mismatches", r.HashMismatches, SeverityError)
+    messages("data_mismatches", r.DataMismatches, SeverityWarning)
+    messages("timestamp_mismatches", r.TimestampMismatches, SeverityWarning)
+    slices.SortStableFunc(rows, func(a, b []any) int {
+        return cmp.Compare(a[0].(int), b[0].(int))
+    })
+    return rows
+}
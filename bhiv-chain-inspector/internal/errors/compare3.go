@@ -0,0 +1,323 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/tracing"
+)
+
+// Classes of the three-way comparison: which of two nodes holds a version
+// of a height other than the one of their common ancestor, the base.
+const (
+    ClassLeftDiverged     = "left_diverged"
+    ClassRightDiverged    = "right_diverged"
+    ClassBothDiverged     = "both_diverged"
+    ClassBothChangedAlike = "both_changed_alike"
+)
+
+// Changes of one side of a height against the base.
+const (
+    ChangeNone    = ""
+    ChangeChanged = "changed"
+    ChangeAdded   = "added"
+    ChangeRemoved = "removed"
+)
+
+// ThreeWayHeight is a height where left or right differs from the base.
+// Left and Right say how each side differs: changed (another block),
+// added (a block the base lacks), removed (no block where the base has
+// one), or empty for a side that matches the base.
+type ThreeWayHeight struct {
+    Height   int      `json:"height"`
+    Class    string   `json:"class"`
+    Code     Code     `json:"code"`
+    Severity Severity `json:"severity"`
+    Left     string   `json:"left,omitempty"`
+    Right    string   `json:"right,omitempty"`
+    Message  string   `json:"message"`
+}
+
+// ThreeWayResult is the output of CompareThreeWay. Blocks are compared as
+// compare does, by presence, hash, data and timestamp.
+type ThreeWayResult struct {
+    ScanTime        string           `json:"scan_time"`
+    BasePath        string           `json:"base_path"`
+    LeftPath        string           `json:"left_path"`
+    RightPath       string           `json:"right_path"`
+    BaseHeight      int              `json:"base_height"`
+    LeftHeight      int              `json:"left_height"`
+    RightHeight     int              `json:"right_height"`
+    // Unchanged counts the heights where both sides hold the base's block.
+    Unchanged       int              `json:"unchanged"`
+    SegmentsSkipped int              `json:"segments_skipped"`
+    LeftDiverged    int              `json:"left_diverged"`
+    RightDiverged   int              `json:"right_diverged"`
+    BothDiverged    int              `json:"both_diverged"`
+    BothAlike       int              `json:"both_changed_alike"`
+    // FirstDivergence is the lowest height where a side left the base,
+    // -1 if neither did.
+    FirstDivergence int              `json:"first_divergence"`
+    Heights         []ThreeWayHeight `json:"heights"`
+    Recommendations []string         `json:"recommendations"`
+}
+
+// CompareThreeWay compares left and right with their common ancestor base
+// height by height, to tell which side diverged, not just that the two
+// differ. A side that only added blocks above the base's is ahead rather
+// than wrong, so heights where one side did so are warnings; heights
+// where both made the same change only mean the base is out of date.
+// Segments whose fingerprints match on all three chains are skipped as
+// by CompareNodes; opts.Bisect and opts.ShowDiff do not apply.
+func CompareThreeWay(base, left, right db.BlockReader, basePath, leftPath, rightPath string, opts CompareOptions) *ThreeWayResult {
+    result := &ThreeWayResult{
+        ScanTime:        FormatTime(time.Now()),
+        BasePath:        basePath,
+        LeftPath:        leftPath,
+        RightPath:       rightPath,
+        FirstDivergence: -1,
+        Heights:         []ThreeWayHeight{},
+    }
+
+    traceCtx, span := tracing.Start(opts.Context, "CompareThreeWay", "base", basePath, "left", leftPath, "right", rightPath)
+    defer func() {
+        span.SetAttributes("unchanged", result.Unchanged, "diverged", len(result.Heights),
+            "first_divergence", result.FirstDivergence)
+        span.End()
+    }()
+    readers := []db.BlockReader{traced(base, traceCtx), traced(left, traceCtx), traced(right, traceCtx)}
+
+    skip := make(map[int]Segment)
+    var fps []*Fingerprint
+    if opts.SegmentSize >= 0 {
+        for _, r := range readers {
+            fp, err := FingerprintOf(r, opts.SegmentSize)
+            if err != nil {
+                fps = nil
+                break
+            }
+            fps = append(fps, fp)
+        }
+    }
+    if fps != nil {
+        withRight := make(map[int]bool)
+        for _, seg := range matchingSegments(fps[0], fps[2]) {
+            withRight[seg.From] = true
+        }
+        for _, seg := range matchingSegments(fps[0], fps[1]) {
+            if withRight[seg.From] {
+                skip[seg.From] = seg
+            }
+        }
+        result.BaseHeight, result.LeftHeight, result.RightHeight = fps[0].Height, fps[1].Height, fps[2].Height
+    } else {
+        result.BaseHeight = readers[0].GetMaxHeight()
+        result.LeftHeight = readers[1].GetMaxHeight()
+        result.RightHeight = readers[2].GetMaxHeight()
+    }
+
+    maxHeight := max(result.BaseHeight, result.LeftHeight, result.RightHeight)
+    first := min(firstHeight(base), firstHeight(left), firstHeight(right))
+    for i := first; i <= maxHeight; i++ {
+        if seg, ok := skip[i]; ok {
+            result.Unchanged += seg.Blocks
+            result.SegmentsSkipped++
+            i = seg.To
+            continue
+        }
+
+        var versions [3]*blocks.Block
+        for k, r := range readers {
+            if block, err := r.LoadBlock(i); err == nil {
+                versions[k] = block
+            }
+        }
+        baseBlock, leftBlock, rightBlock := versions[0], versions[1], versions[2]
+        leftChange, rightChange := changeOf(baseBlock, leftBlock), changeOf(baseBlock, rightBlock)
+        if leftChange == ChangeNone && rightChange == ChangeNone {
+            if baseBlock != nil {
+                result.Unchanged++
+                if opts.OnBlock != nil {
+                    opts.OnBlock(BlockVerdict{Height: i})
+                }
+            }
+            continue
+        }
+
+        h := ThreeWayHeight{Height: i, Left: leftChange, Right: rightChange, Severity: SeverityError}
+        switch {
+        case rightChange == ChangeNone:
+            h.Class = ClassLeftDiverged
+            h.Message = fmt.Sprintf("Block %d: Left %s, Right as base", i, leftChange)
+            result.LeftDiverged++
+        case leftChange == ChangeNone:
+            h.Class = ClassRightDiverged
+            h.Message = fmt.Sprintf("Block %d: Right %s, Left as base", i, rightChange)
+            result.RightDiverged++
+        case sameVersion(leftBlock, rightBlock):
+            h.Class = ClassBothChangedAlike
+            h.Message = fmt.Sprintf("Block %d: Left and Right %s alike", i, leftChange)
+            h.Severity = SeverityInfo
+            result.BothAlike++
+        default:
+            h.Class = ClassBothDiverged
+            h.Message = fmt.Sprintf("Block %d: Left %s, Right %s, and they differ", i, leftChange, rightChange)
+            result.BothDiverged++
+        }
+        if leftChange == ChangeNone && rightChange == ChangeAdded || rightChange == ChangeNone && leftChange == ChangeAdded {
+            // One side is only ahead of the base and the other.
+            h.Severity = SeverityWarning
+        }
+        h.Code = CodeOf(h.Class)
+        if result.FirstDivergence == -1 && h.Class != ClassBothChangedAlike {
+            result.FirstDivergence = i
+        }
+        result.Heights = append(result.Heights, h)
+        if opts.OnBlock != nil {
+            opts.OnBlock(BlockVerdict{Height: i, Issues: []Issue{{Class: h.Class, Code: h.Code, Severity: h.Severity, Message: h.Message}}})
+        }
+    }
+
+    result.Recommendations = threeWayRecommendations(result)
+    return result
+}
+
+// changeOf describes side against base.
+func changeOf(base, side *blocks.Block) string {
+    switch {
+    case base == nil && side == nil:
+        return ChangeNone
+    case base == nil:
+        return ChangeAdded
+    case side == nil:
+        return ChangeRemoved
+    case !sameVersion(base, side):
+        return ChangeChanged
+    }
+    return ChangeNone
+}
+
+// sameVersion reports whether a and b, either nil if missing, are the same
+// version of a block for CompareNodes: same hash, data and timestamp.
+func sameVersion(a, b *blocks.Block) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    return a.Hash == b.Hash && a.Data == b.Data && a.Timestamp == b.Timestamp
+}
+
+func threeWayRecommendations(result *ThreeWayResult) []string {
+    recs := []string{}
+    errorsOn := func(class string) (n int, from int) {
+        from = -1
+        for _, h := range result.Heights {
+            if h.Class == class && h.Severity == SeverityError {
+                if from < 0 {
+                    from = h.Height
+                }
+                n++
+            }
+        }
+        return n, from
+    }
+    if n, from := errorsOn(ClassLeftDiverged); n > 0 {
+        recs = append(recs, fmt.Sprintf("Left diverged from the base at %d heights from block %d - restore them from Right or the base", n, from))
+    }
+    if n, from := errorsOn(ClassRightDiverged); n > 0 {
+        recs = append(recs, fmt.Sprintf("Right diverged from the base at %d heights from block %d - restore them from Left or the base", n, from))
+    }
+    if n, from := errorsOn(ClassBothDiverged); n > 0 {
+        recs = append(recs, fmt.Sprintf("Left and Right both diverged, differently, at %d heights from block %d - the base cannot tell which is right; compare --heal with a third node can", n, from))
+    }
+    switch {
+    case result.LeftHeight > result.RightHeight:
+        recs = append(recs, fmt.Sprintf("Right is %d blocks behind Left", result.LeftHeight-result.RightHeight))
+    case result.RightHeight > result.LeftHeight:
+        recs = append(recs, fmt.Sprintf("Left is %d blocks behind Right", result.RightHeight-result.LeftHeight))
+    }
+    if result.BothAlike > 0 {
+        recs = append(recs, fmt.Sprintf("Left and Right changed %d heights alike since the base - refresh the base", result.BothAlike))
+    }
+    if len(recs) == 0 {
+        recs = append(recs, "Left and Right both match the base")
+    }
+    return recs
+}
+
+// Columns and Rows make the diverged heights of a three-way comparison a
+// Table, one row each.
+func (r *ThreeWayResult) Columns() []string {
+    return []string{"height", "class", "code", "severity", "left", "right", "message"}
+}
+
+func (r *ThreeWayResult) Rows() [][]any {
+    rows := [][]any{}
+    for _, h := range r.Heights {
+        rows = append(rows, []any{h.Height, h.Class, h.Code, h.Severity, h.Left, h.Right, h.Message})
+    }
+    return rows
+}
+
+// OutputThreeWayResult prints the heights each side diverged at, grouped
+// into ranges, and what to do about them.
+func OutputThreeWayResult(result *ThreeWayResult, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, result, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Unchanged: %d | Left: %d | Right: %d | Both: %d | Alike: %d | First Divergence: %d\n",
+            result.Unchanged, result.LeftDiverged, result.RightDiverged, result.BothDiverged, result.BothAlike, result.FirstDivergence)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "THREE-WAY COMPARISON")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n%sNODE INFO:\n", sym.Stats)
+    fmt.Fprintf(w, "  Base:  %s (Height: %d)\n", result.BasePath, result.BaseHeight)
+    fmt.Fprintf(w, "  Left:  %s (Height: %d)\n", result.LeftPath, result.LeftHeight)
+    fmt.Fprintf(w, "  Right: %s (Height: %d)\n", result.RightPath, result.RightHeight)
+
+    fmt.Fprintf(w, "\n%sRESULTS:\n", sym.Search)
+    fmt.Fprintf(w, "  Unchanged:          %d\n", result.Unchanged)
+    if result.SegmentsSkipped > 0 {
+        fmt.Fprintf(w, "  Identical Segments: %d (skipped by fingerprint)\n", result.SegmentsSkipped)
+    }
+    count := func(label string, n int, color string) {
+        line := fmt.Sprintf("  %-20s%d", label+":", n)
+        if n > 0 {
+            line = colorize(opts, color, line)
+        }
+        fmt.Fprintln(w, line)
+    }
+    count("Left Diverged", result.LeftDiverged, ansiRed)
+    count("Right Diverged", result.RightDiverged, ansiRed)
+    count("Both Diverged", result.BothDiverged, ansiRed)
+    count("Both Changed Alike", result.BothAlike, ansiYellow)
+
+    if len(result.Heights) > 0 {
+        fmt.Fprintf(w, "\n%sDIVERGED HEIGHTS:\n", sym.Details)
+        issues := make([]Issue, len(result.Heights))
+        for i, h := range result.Heights {
+            issues[i] = Issue{Class: h.Class, Code: h.Code, Severity: h.Severity, Message: h.Message}
+        }
+        for _, g := range GroupIssues(issues) {
+            fmt.Fprintf(w, "  - %s\n", colorize(opts, severityColor(g.Severity), g.Message))
+        }
+    }
+
+    if result.FirstDivergence >= 0 {
+        fmt.Fprintf(w, "\n%s%s\n", sym.Diverge, colorize(opts, ansiRed, fmt.Sprintf("First Divergence: Block %d", result.FirstDivergence)))
+    }
+
+    fmt.Fprintf(w, "\n%sRECOMMENDATIONS:\n", sym.Recs)
+    for i, rec := range result.Recommendations {
+        fmt.Fprintf(w, "  %d. %s\n", i+1, rec)
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
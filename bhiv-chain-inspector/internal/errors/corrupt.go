@@ -0,0 +1,44 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+)
+
+// CorruptionReport is the output of the corrupt command.
+type CorruptionReport struct {
+    DatabasePath    string   `json:"database_path"`
+    Height          int      `json:"height"`
+    Type            string   `json:"type"`
+    Before          string   `json:"before"`
+    After           string   `json:"after"`
+    // ExpectedClasses are the classes a scan should now report for the
+    // block; the next block may be flagged as well.
+    ExpectedClasses []string `json:"expected_classes"`
+}
+
+// OutputCorruption prints what was changed and what a scan should find.
+func OutputCorruption(report *CorruptionReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    expected := strings.Join(report.ExpectedClasses, ", ")
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Corrupted: block %d | Type: %s | Expect: %s\n", report.Height, report.Type, expected)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "FAULT INJECTED")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:         %s\n", report.DatabasePath)
+    fmt.Fprintf(w, "  Block:            %d\n", report.Height)
+    fmt.Fprintf(w, "  Type:             %s\n", report.Type)
+    fmt.Fprintf(w, "  Before:           %s\n", report.Before)
+    fmt.Fprintf(w, "  After:            %s\n", report.After)
+    fmt.Fprintf(w, "\n%s  %s\n", sym.Warn, colorize(opts, ansiYellow,
+        fmt.Sprintf("A scan should now report %s for block %d", expected, report.Height)))
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
@@ -0,0 +1,85 @@
+package errors
+
+import (
+    "fmt"
+    "io"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// StorageStatsReport is the output of the db-stats command.
+type StorageStatsReport struct {
+    DatabasePath string `json:"database_path"`
+    *db.StorageStats
+}
+
+// OutputStorageStats prints key and value totals, block size percentiles,
+// the largest blocks and a table of LevelDB levels.
+func OutputStorageStats(report *StorageStatsReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Keys: %d | Values: %s | Blocks: %d | Block p50/p99: %s/%s\n",
+            report.TotalKeys, formatBytes(report.TotalValueBytes), report.Blocks,
+            formatBytes(int64(report.BlockSizes.P50)), formatBytes(int64(report.BlockSizes.P99)))
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "STORAGE STATS")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:         %s\n", report.DatabasePath)
+    fmt.Fprintf(w, "  Keys:             %d (%s)\n", report.TotalKeys, formatBytes(report.TotalKeyBytes))
+    fmt.Fprintf(w, "  Values:           %s\n", formatBytes(report.TotalValueBytes))
+
+    printBlockSizes(w, &db.BlockSizes{
+        Blocks:  report.Blocks,
+        Bytes:   report.BlockBytes,
+        Sizes:   report.BlockSizes,
+        Largest: report.Largest,
+    }, sym)
+
+    fmt.Fprintf(w, "\n%sLEVELDB:\n", sym.Search)
+    fmt.Fprintf(w, "  %-7s %7s %12s %14s %14s\n", "Level", "Tables", "Size", "Compact Read", "Compact Write")
+    for _, level := range report.Levels {
+        fmt.Fprintf(w, "  %-7d %7d %12s %14s %14s\n", level.Level, level.Tables,
+            formatBytes(level.Size), formatBytes(level.Read), formatBytes(level.Write))
+    }
+    fmt.Fprintf(w, "  Opened tables: %d | Block cache: %s\n", report.OpenedTables, formatBytes(int64(report.BlockCacheBytes)))
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
+
+// printBlockSizes prints the BLOCK SIZES and LARGEST BLOCKS sections.
+func printBlockSizes(w io.Writer, summary *db.BlockSizes, sym symbolSet) {
+    sizes := summary.Sizes
+    fmt.Fprintf(w, "\n%sBLOCK SIZES:\n", sym.Stats)
+    fmt.Fprintf(w, "  Blocks:           %d (%s)\n", summary.Blocks, formatBytes(summary.Bytes))
+    fmt.Fprintf(w, "  Mean:             %s\n", formatBytes(int64(sizes.Mean)))
+    fmt.Fprintf(w, "  p50 / p90 / p99:  %s / %s / %s\n",
+        formatBytes(int64(sizes.P50)), formatBytes(int64(sizes.P90)), formatBytes(int64(sizes.P99)))
+    fmt.Fprintf(w, "  Max:              %s\n", formatBytes(int64(sizes.Max)))
+    if len(summary.Largest) > 0 {
+        fmt.Fprintf(w, "\n%sLARGEST BLOCKS:\n", sym.Details)
+        for _, b := range summary.Largest {
+            fmt.Fprintf(w, "  Block %-10d %s\n", b.Height, formatBytes(int64(b.Size)))
+        }
+    }
+}
+
+// formatBytes renders n with a binary unit, e.g. "1.5 KiB".
+func formatBytes(n int64) string {
+    const unit = 1024
+    if n < unit {
+        return fmt.Sprintf("%d B", n)
+    }
+    value, exp := float64(n)/unit, 0
+    for value >= unit && exp < 4 {
+        value /= unit
+        exp++
+    }
+    return fmt.Sprintf("%.1f %ciB", value, "KMGTP"[exp])
+}
@@ -0,0 +1,120 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// dotBranchBlocks is how many blocks of each fork are drawn individually;
+// the rest of a branch is collapsed into one node ending at its tip.
+const dotBranchBlocks = 8
+
+// OutputForkDOT prints the fork topology of a comparison as a Graphviz
+// graph: the common chain collapsed into one node, the last common block,
+// and both branches from the divergence point on. Render it with e.g.
+// "dot -Tsvg". Block hashes are read again from r1 and r2.
+func OutputForkDOT(result *ComparisonResult, r1, r2 db.BlockReader, opts OutputOptions) {
+    w := opts.Writer()
+    var b strings.Builder
+    b.WriteString("digraph fork {\n")
+    b.WriteString("  rankdir=LR;\n")
+    b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"monospace\", fillcolor=white];\n")
+    fmt.Fprintf(&b, "  label=%s;\n  labelloc=t;\n",
+        dotQuote(fmt.Sprintf("%s vs %s  (%s)", result.Node1Path, result.Node2Path, result.ScanTime)))
+
+    d := result.DivergencePoint
+    if d < 0 {
+        fmt.Fprintf(&b, "  common [label=%s, fillcolor=lightgray];\n",
+            dotQuote(fmt.Sprintf("Blocks 0-%d\nno divergence", result.Node1Height)))
+        b.WriteString("}\n")
+        fmt.Fprint(w, b.String())
+        return
+    }
+
+    // fork is the node both branches grow from.
+    fork := "root"
+    switch {
+    case d == 0:
+        b.WriteString("  root [label=\"no common block\", shape=point];\n")
+    default:
+        if d >= 2 {
+            fmt.Fprintf(&b, "  common [label=%s, fillcolor=lightgray];\n",
+                dotQuote(fmt.Sprintf("Blocks 0-%d\ncommon chain", d-2)))
+        }
+        label := fmt.Sprintf("Block %d\nlast common block", d-1)
+        if block, err := r1.LoadBlock(d - 1); err == nil {
+            label = fmt.Sprintf("Block %d\n%s\nlast common block", d-1, shortHash(block.Hash))
+        }
+        fmt.Fprintf(&b, "  fork [label=%s, fillcolor=gold];\n", dotQuote(label))
+        if d >= 2 {
+            b.WriteString("  common -> fork;\n")
+        }
+        fork = "fork"
+    }
+
+    writeBranch(&b, "n1", "Node1: "+result.Node1Path, "lightblue", fork, r1, d, result.Node1Height)
+    writeBranch(&b, "n2", "Node2: "+result.Node2Path, "palegreen", fork, r2, d, result.Node2Height)
+    b.WriteString("}\n")
+    fmt.Fprint(w, b.String())
+}
+
+// writeBranch draws heights from..tip of one node as a cluster hanging off
+// the fork node. The first block is outlined as the divergence point.
+func writeBranch(b *strings.Builder, id, title, color, fork string, r db.BlockReader, from, tip int) {
+    fmt.Fprintf(b, "  subgraph cluster_%s {\n    label=%s;\n    color=%s;\n", id, dotQuote(title), color)
+    prev := fork
+    if tip < from {
+        fmt.Fprintf(b, "    %s_end [label=%s, style=dashed];\n", id, dotQuote(fmt.Sprintf("ends at block %d", tip)))
+        b.WriteString("  }\n")
+        fmt.Fprintf(b, "  %s -> %s_end [style=dashed];\n", fork, id)
+        return
+    }
+
+    var edges []string
+    last := tip
+    if tip-from+1 > dotBranchBlocks {
+        last = from + dotBranchBlocks - 1
+    }
+    for h := from; h <= last; h++ {
+        node := fmt.Sprintf("%s_%d", id, h)
+        label := fmt.Sprintf("Block %d\nmissing", h)
+        attrs := ", style=dashed"
+        if block, err := r.LoadBlock(h); err == nil {
+            label = fmt.Sprintf("Block %d\n%s", h, shortHash(block.Hash))
+            attrs = ", fillcolor=" + color
+        }
+        if h == from {
+            attrs += ", color=red, penwidth=2"
+        }
+        fmt.Fprintf(b, "    %s [label=%s%s];\n", node, dotQuote(label), attrs)
+        edges = append(edges, fmt.Sprintf("  %s -> %s;\n", prev, node))
+        prev = node
+    }
+    if last < tip {
+        node := id + "_more"
+        label := fmt.Sprintf("... %d more blocks\ntip: block %d", tip-last, tip)
+        fmt.Fprintf(b, "    %s [label=%s, fillcolor=%s];\n", node, dotQuote(label), color)
+        edges = append(edges, fmt.Sprintf("  %s -> %s [style=dotted];\n", prev, node))
+    }
+    b.WriteString("  }\n")
+    for _, e := range edges {
+        b.WriteString(e)
+    }
+}
+
+func shortHash(hash string) string {
+    if len(hash) > 12 {
+        return hash[:12] + "…"
+    }
+    return hash
+}
+
+// dotQuote returns s as a DOT string; newlines become centered line breaks.
+func dotQuote(s string) string {
+    s = strings.ReplaceAll(s, `\`, `\\`)
+    s = strings.ReplaceAll(s, `"`, `\"`)
+    s = strings.ReplaceAll(s, "\n", `\n`)
+    return `"` + s + `"`
+}
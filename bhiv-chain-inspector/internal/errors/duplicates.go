@@ -0,0 +1,195 @@
+package errors
+
+import (
+    "crypto/sha256"
+    "encoding/binary"
+    "fmt"
+    "hash/fnv"
+
+    "bhiv-chain-inspector/internal/blocks"
+)
+
+// Classes of the data check: a block whose Data repeats an earlier
+// block's exactly, or (with ScanOptions.NearDuplicates) nearly. Repeated
+// payloads usually mean the producer replayed transactions.
+const (
+    ClassDuplicateData     = "duplicate_data"
+    ClassNearDuplicateData = "near_duplicate_data"
+)
+
+// dataCheck compares each block's Data with the blocks scanned before it.
+// Empty Data is left to the empty check.
+type dataCheck struct{}
+
+func (dataCheck) Name() string { return "data" }
+
+func (dataCheck) Classes() []string { return []string{ClassDuplicateData, ClassNearDuplicateData} }
+
+func (dataCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    if block.Data == "" || ctx.SeenData == nil {
+        return nil
+    }
+    if first, ok := ctx.SeenData.First(block.Data); ok {
+        return []Finding{{Class: ClassDuplicateData,
+            Message: fmt.Sprintf("Block %d repeats the data of Block %d", ctx.Height, first)}}
+    }
+    if height, similarity, ok := ctx.SeenData.Similar(block.Data); ok {
+        return []Finding{{Class: ClassNearDuplicateData,
+            Message: fmt.Sprintf("Block %d data is %.0f%% similar to Block %d", ctx.Height, similarity*100, height)}}
+    }
+    return nil
+}
+
+// MinHash parameters of near-duplicate detection: Data is cut into
+// shingleSize-byte shingles, summarised by minHashes minimums, and
+// candidates found by locality-sensitive hashing over minHashBands bands.
+// Each indexed block costs minHashes*8 bytes.
+const (
+    shingleSize  = 5
+    minHashes    = 64
+    minHashBands = 16
+    // bucketKept bounds the heights kept per band bucket, so payloads
+    // that all look alike do not make the scan quadratic.
+    bucketKept   = 64
+)
+
+// DataIndex remembers the Data of scanned blocks for the data check. The
+// scanner adds each block after the checks have run on it.
+type DataIndex struct {
+    first map[[sha256.Size]byte]int
+    // threshold is the Jaccard similarity of near duplicates; 0 turns
+    // the MinHash index off.
+    threshold  float64
+    signatures map[int]*[minHashes]uint64
+    buckets    [minHashBands]map[uint64][]int
+}
+
+func newDataIndex(threshold float64) *DataIndex {
+    index := &DataIndex{first: make(map[[sha256.Size]byte]int), threshold: threshold}
+    if threshold > 0 {
+        index.signatures = make(map[int]*[minHashes]uint64)
+        for i := range index.buckets {
+            index.buckets[i] = make(map[uint64][]int)
+        }
+    }
+    return index
+}
+
+// First returns the first height whose Data equals data.
+func (d *DataIndex) First(data string) (int, bool) {
+    height, ok := d.first[sha256.Sum256([]byte(data))]
+    return height, ok
+}
+
+// Similar returns the most similar earlier block whose Data is at least
+// as similar to data as the threshold, by estimated Jaccard similarity of
+// their shingles.
+func (d *DataIndex) Similar(data string) (int, float64, bool) {
+    if d.threshold <= 0 {
+        return 0, 0, false
+    }
+    sig := minHash(data)
+    best, bestSim := -1, 0.0
+    seen := make(map[int]bool)
+    for band := range d.buckets {
+        for _, height := range d.buckets[band][bandKey(sig, band)] {
+            if seen[height] {
+                continue
+            }
+            seen[height] = true
+            sim := signatureSimilarity(sig, d.signatures[height])
+            if sim >= d.threshold && (sim > bestSim || sim == bestSim && height < best) {
+                best, bestSim = height, sim
+            }
+        }
+    }
+    return best, bestSim, best >= 0
+}
+
+func (d *DataIndex) add(height int, data string) {
+    sum := sha256.Sum256([]byte(data))
+    if _, ok := d.first[sum]; ok {
+        return
+    }
+    d.first[sum] = height
+    if d.threshold <= 0 {
+        return
+    }
+    sig := minHash(data)
+    d.signatures[height] = &sig
+    for band := range d.buckets {
+        key := bandKey(sig, band)
+        bucket := append(d.buckets[band][key], height)
+        if len(bucket) > bucketKept {
+            bucket = bucket[len(bucket)-bucketKept:]
+        }
+        d.buckets[band][key] = bucket
+    }
+}
+
+// minHash returns the MinHash signature of the shingles of data.
+func minHash(data string) [minHashes]uint64 {
+    var sig [minHashes]uint64
+    for i := range sig {
+        sig[i] = ^uint64(0)
+    }
+    shingle := func(s string) {
+        h := fnv.New64a()
+        h.Write([]byte(s))
+        x := h.Sum64()
+        for i := range sig {
+            if v := mix64(x ^ minHashSeeds[i]); v < sig[i] {
+                sig[i] = v
+            }
+        }
+    }
+    if len(data) <= shingleSize {
+        shingle(data)
+        return sig
+    }
+    for i := 0; i+shingleSize <= len(data); i++ {
+        shingle(data[i : i+shingleSize])
+    }
+    return sig
+}
+
+// minHashSeeds pick the hash function of each signature slot.
+var minHashSeeds = func() [minHashes]uint64 {
+    var seeds [minHashes]uint64
+    x := uint64(0x9e3779b97f4a7c15)
+    for i := range seeds {
+        x += 0x9e3779b97f4a7c15
+        seeds[i] = mix64(x)
+    }
+    return seeds
+}()
+
+// mix64 is the splitmix64 finaliser.
+func mix64(x uint64) uint64 {
+    x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+    x = (x ^ x>>27) * 0x94d049bb133111eb
+    return x ^ x>>31
+}
+
+// bandKey hashes the rows of one LSH band of sig.
+func bandKey(sig [minHashes]uint64, band int) uint64 {
+    rows := minHashes / minHashBands
+    h := fnv.New64a()
+    var buf [8]byte
+    for _, v := range sig[band*rows : (band+1)*rows] {
+        binary.LittleEndian.PutUint64(buf[:], v)
+        h.Write(buf[:])
+    }
+    return h.Sum64()
+}
+
+// signatureSimilarity estimates the Jaccard similarity of two signatures.
+func signatureSimilarity(a [minHashes]uint64, b *[minHashes]uint64) float64 {
+    same := 0
+    for i := range a {
+        if a[i] == b[i] {
+            same++
+        }
+    }
+    return float64(same) / minHashes
+}
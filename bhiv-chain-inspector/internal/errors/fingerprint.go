@@ -0,0 +1,150 @@
+package errors
+
+import (
+    "crypto/sha256"
+    "encoding/binary"
+    "encoding/hex"
+    "fmt"
+    "hash"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// DefaultSegmentSize is the number of heights per fingerprint segment.
+const DefaultSegmentSize = 10000
+
+// Fingerprint is a digest of a whole chain plus one digest per segment of
+// SegmentSize heights. Segment digests cover exactly what CompareNodes
+// compares (presence, hash, data and timestamp of each block), so two
+// chains with equal segment digests have no mismatches in that segment.
+type Fingerprint struct {
+    Height      int       `json:"height"`
+    SegmentSize int       `json:"segment_size"`
+    Digest      string    `json:"digest"`
+    Segments    []Segment `json:"segments"`
+}
+
+// Segment is the digest of heights From..To inclusive.
+type Segment struct {
+    From   int    `json:"from"`
+    To     int    `json:"to"`
+    Blocks int    `json:"blocks"`
+    Digest string `json:"digest"`
+}
+
+// Fingerprinter is implemented by block readers that can compute their
+// fingerprint themselves, such as remote agents, so that only digests
+// cross the network.
+type Fingerprinter interface {
+    Fingerprint(segmentSize int) (*Fingerprint, error)
+}
+
+// FingerprintOf asks r for its fingerprint if it can compute one itself,
+// and otherwise computes it by reading every block.
+func FingerprintOf(r db.BlockReader, segmentSize int) (*Fingerprint, error) {
+    if f, ok := r.(Fingerprinter); ok {
+        return f.Fingerprint(segmentSize)
+    }
+    return ComputeFingerprint(r, segmentSize), nil
+}
+
+// ComputeFingerprint reads heights 0 through the chain height of r.
+func ComputeFingerprint(r db.BlockReader, segmentSize int) *Fingerprint {
+    if segmentSize <= 0 {
+        segmentSize = DefaultSegmentSize
+    }
+    fp := &Fingerprint{Height: r.GetMaxHeight(), SegmentSize: segmentSize}
+
+    chain := sha256.New()
+    for from := 0; from <= fp.Height; from += segmentSize {
+        to := from + segmentSize - 1
+        if to > fp.Height {
+            to = fp.Height
+        }
+        seg := Segment{From: from, To: to}
+        h := sha256.New()
+        for i := from; i <= to; i++ {
+            block, err := r.LoadBlock(i)
+            if err != nil {
+                writeInt(h, int64(i))
+                writeInt(h, -1) // absent
+                continue
+            }
+            seg.Blocks++
+            writeInt(h, int64(i))
+            writeString(h, block.Hash)
+            writeString(h, block.Data)
+            writeInt(h, block.Timestamp)
+        }
+        seg.Digest = hex.EncodeToString(h.Sum(nil))
+        chain.Write([]byte(seg.Digest))
+        fp.Segments = append(fp.Segments, seg)
+    }
+    fp.Digest = hex.EncodeToString(chain.Sum(nil))
+    return fp
+}
+
+func writeInt(h hash.Hash, v int64) {
+    var buf [8]byte
+    binary.BigEndian.PutUint64(buf[:], uint64(v))
+    h.Write(buf[:])
+}
+
+// writeString length-prefixes s so that field boundaries are unambiguous.
+func writeString(h hash.Hash, s string) {
+    writeInt(h, int64(len(s)))
+    h.Write([]byte(s))
+}
+
+// matchingSegments returns the segments both chains have in common: equal
+// digests over a range both fingerprints fully cover.
+func matchingSegments(a, b *Fingerprint) []Segment {
+    if a == nil || b == nil || a.SegmentSize != b.SegmentSize {
+        return nil
+    }
+    // Heights past the lower tip are only read by the comparison if the
+    // tips differ, so a segment reaching past it cannot be trusted.
+    limit := a.Height
+    if b.Height < limit {
+        limit = b.Height
+    }
+    var same []Segment
+    for k := 0; k < len(a.Segments) && k < len(b.Segments); k++ {
+        sa, sb := a.Segments[k], b.Segments[k]
+        if sa.Digest != sb.Digest || sa.From != sb.From || sa.To != sb.To {
+            continue
+        }
+        if a.Height != b.Height && sa.To > limit {
+            continue
+        }
+        same = append(same, sa)
+    }
+    return same
+}
+
+// OutputFingerprint prints a fingerprint.
+func OutputFingerprint(fp *Fingerprint, dbPath string, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, fp, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintln(w, fp.Digest)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "CHAIN FINGERPRINT")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:      %s\n", dbPath)
+    fmt.Fprintf(w, "  Height:        %d\n", fp.Height)
+    fmt.Fprintf(w, "  Digest:        %s\n", fp.Digest)
+    fmt.Fprintf(w, "  Segment Size:  %d\n", fp.SegmentSize)
+    fmt.Fprintf(w, "\n%sSEGMENTS:\n", sym.Stats)
+    for _, seg := range fp.Segments {
+        fmt.Fprintf(w, "  %8d-%-8d  %s  (%d blocks)\n", seg.From, seg.To, seg.Digest, seg.Blocks)
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
@@ -0,0 +1,175 @@
+package errors
+
+import (
+    "cmp"
+    "fmt"
+    "slices"
+    "strings"
+    "time"
+)
+
+// FleetReport is the output of scan-fleet: one scan per database, ranked
+// worst first, and the nodes each class of finding affects.
+type FleetReport struct {
+    ScanTime   string       `json:"scan_time"`
+    Nodes      []FleetNode  `json:"nodes"`
+    Classes    []FleetClass `json:"classes"`
+    Healthy    int          `json:"healthy"`
+    Unhealthy  int          `json:"unhealthy"`
+    // Unreadable counts databases that could not be opened.
+    Unreadable int          `json:"unreadable"`
+}
+
+// FleetNode is the scan of one database. Error is set, and the counts are
+// zero, when it could not be opened.
+type FleetNode struct {
+    Path        string         `json:"path"`
+    Status      string         `json:"status"`
+    HealthScore int            `json:"health_score"`
+    Blocks      int            `json:"blocks"`
+    Errors      int            `json:"errors"`
+    Warnings    int            `json:"warnings"`
+    Classes     map[string]int `json:"classes,omitempty"`
+    Error       string         `json:"error,omitempty"`
+}
+
+// FleetClass is one class of finding across the fleet.
+type FleetClass struct {
+    Class    string   `json:"class"`
+    Findings int      `json:"findings"`
+    Nodes    []string `json:"nodes"`
+}
+
+// NewFleetReport ranks the scans of a fleet and the databases that could
+// not be opened, keyed by path, by health score, worst first.
+func NewFleetReport(results []*ErrorScanResult, unreadable map[string]error) *FleetReport {
+    report := &FleetReport{ScanTime: FormatTime(time.Now()), Nodes: []FleetNode{}, Classes: []FleetClass{}}
+    classes := make(map[string]*FleetClass)
+    for _, r := range results {
+        node := FleetNode{
+            Path:        r.DatabasePath,
+            Status:      r.Status,
+            HealthScore: r.HealthScore,
+            Blocks:      r.BlocksScanned,
+            Errors:      r.TotalErrors,
+            Warnings:    r.TotalWarnings,
+            Classes:     make(map[string]int),
+        }
+        for _, issue := range r.Issues() {
+            node.Classes[issue.Class]++
+        }
+        for class, n := range node.Classes {
+            c := classes[class]
+            if c == nil {
+                c = &FleetClass{Class: class}
+                classes[class] = c
+            }
+            c.Findings += n
+            c.Nodes = append(c.Nodes, node.Path)
+        }
+        if node.Status == "HEALTHY" {
+            report.Healthy++
+        } else {
+            report.Unhealthy++
+        }
+        report.Nodes = append(report.Nodes, node)
+    }
+    for _, path := range sortedKeys(unreadable) {
+        report.Nodes = append(report.Nodes, FleetNode{Path: path, Status: "UNREADABLE", Error: unreadable[path].Error()})
+        report.Unreadable++
+    }
+
+    slices.SortStableFunc(report.Nodes, func(a, b FleetNode) int {
+        return cmp.Or(cmp.Compare(a.HealthScore, b.HealthScore), cmp.Compare(b.Errors, a.Errors),
+            strings.Compare(a.Path, b.Path))
+    })
+    for _, class := range sortedKeys(classes) {
+        c := classes[class]
+        slices.Sort(c.Nodes)
+        report.Classes = append(report.Classes, *c)
+    }
+    slices.SortStableFunc(report.Classes, func(a, b FleetClass) int {
+        return cmp.Compare(len(b.Nodes), len(a.Nodes))
+    })
+    return report
+}
+
+// Failed reports whether any node is unhealthy or unreadable.
+func (r *FleetReport) Failed() bool {
+    return r.Unhealthy > 0 || r.Unreadable > 0
+}
+
+// Columns and Rows make the ranked nodes of a fleet scan a Table.
+func (r *FleetReport) Columns() []string {
+    return []string{"rank", "path", "status", "health_score", "blocks", "errors", "warnings", "error"}
+}
+
+func (r *FleetReport) Rows() [][]any {
+    rows := make([][]any, len(r.Nodes))
+    for i, node := range r.Nodes {
+        rows[i] = []any{i + 1, node.Path, node.Status, node.HealthScore, node.Blocks, node.Errors, node.Warnings, node.Error}
+    }
+    return rows
+}
+
+// OutputFleetReport prints the ranked nodes and, for each class of
+// finding, the nodes it affects (all of them with -v).
+func OutputFleetReport(report *FleetReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Nodes: %d | Healthy: %d | Unhealthy: %d | Unreadable: %d\n",
+            len(report.Nodes), report.Healthy, report.Unhealthy, report.Unreadable)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "FLEET SCAN")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Scan Time:        %s\n", report.ScanTime)
+    fmt.Fprintf(w, "  Nodes:            %d\n", len(report.Nodes))
+    fmt.Fprintf(w, "  Healthy:          %d\n", report.Healthy)
+    fmt.Fprintf(w, "  Unhealthy:        %d\n", report.Unhealthy)
+    if report.Unreadable > 0 {
+        fmt.Fprintf(w, "  Unreadable:       %d\n", report.Unreadable)
+    }
+
+    fmt.Fprintf(w, "\n%sNODES (worst first):\n", sym.Stats)
+    fmt.Fprintf(w, "  %4s  %-28s %6s %8s %8s %8s\n", "Rank", "Database", "Health", "Blocks", "Errors", "Warnings")
+    for i, node := range report.Nodes {
+        if node.Error != "" {
+            fmt.Fprintf(w, "  %4d  %-28s %s\n", i+1, node.Path, colorize(opts, ansiRed, "unreadable: "+node.Error))
+            continue
+        }
+        health := colorize(opts, statusColor(node.Errors), fmt.Sprintf("%5d%%", node.HealthScore))
+        fmt.Fprintf(w, "  %4d  %-28s %s %8d %8d %8d\n", i+1, node.Path, health, node.Blocks, node.Errors, node.Warnings)
+    }
+
+    if len(report.Classes) > 0 {
+        fmt.Fprintf(w, "\n%sFINDINGS BY CLASS:\n", sym.Details)
+        fmt.Fprintf(w, "  %-26s %8s %6s  %s\n", "Class", "Findings", "Nodes", "Affected")
+        for _, c := range report.Classes {
+            nodes := c.Nodes
+            if opts.Verbosity < VerbosityVerbose && len(nodes) > snapshotListed {
+                nodes = nodes[:snapshotListed]
+            }
+            list := strings.Join(nodes, ", ")
+            if more := len(c.Nodes) - len(nodes); more > 0 {
+                list += fmt.Sprintf(" and %d more", more)
+            }
+            fmt.Fprintf(w, "  %-26s %8d %6d  %s\n", c.Class, c.Findings, len(c.Nodes), list)
+        }
+    }
+
+    fmt.Fprintln(w)
+    if report.Failed() {
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiRed, sym.Fail),
+            colorize(opts, ansiRed, fmt.Sprintf("%d OF %d NODES NEED ATTENTION", report.Unhealthy+report.Unreadable, len(report.Nodes))))
+    } else {
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiGreen, sym.OK), colorize(opts, ansiGreen, "ALL NODES HEALTHY"))
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
@@ -0,0 +1,296 @@
+package errors
+
+import (
+    "bytes"
+    "encoding/csv"
+    "encoding/json"
+    stderrors "errors"
+    "fmt"
+    "html/template"
+    "io"
+    "reflect"
+    "strings"
+)
+
+// Output formats. Text is the layout each report prints itself; the others
+// are registered Formatters.
+const (
+    FormatText   = "text"
+    FormatJSON   = "json"
+    FormatNDJSON = "ndjson"
+    FormatCSV    = "csv"
+    FormatHTML   = "html"
+)
+
+// Formatter writes reports in one output format. Report is one of the
+// report types of this package, such as *ErrorScanResult or *FleetReport.
+type Formatter interface {
+    // Format writes report to w. A formatter that has no rendering of a
+    // kind of report returns an error wrapping ErrUnsupportedReport.
+    Format(w io.Writer, report any, opts OutputOptions) error
+}
+
+// Table is implemented by reports with a natural table, such as the
+// findings of a scan, which the csv, ndjson and html formats print row by
+// row.
+type Table interface {
+    Columns() []string
+    Rows() [][]any
+}
+
+// ErrUnsupportedReport is returned by formatters for reports they cannot
+// render, e.g. csv for a report that is not a Table.
+var ErrUnsupportedReport = stderrors.New("report not supported by this format")
+
+var (
+    formatters     = make(map[string]Formatter)
+    formatterNames []string
+)
+
+// RegisterFormatter makes f the formatter of --format name. It panics if
+// the name is taken; text is reserved for the reports' own layouts.
+func RegisterFormatter(name string, f Formatter) {
+    if _, ok := formatters[name]; ok || name == FormatText {
+        panic(fmt.Sprintf("errors: formatter %q registered twice", name))
+    }
+    formatters[name] = f
+    formatterNames = append(formatterNames, name)
+}
+
+// LookupFormatter returns the formatter registered as name, or nil for
+// text.
+func LookupFormatter(name string) (Formatter, error) {
+    if name == FormatText {
+        return nil, nil
+    }
+    f, ok := formatters[name]
+    if !ok {
+        return nil, fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(FormatNames(), ", "))
+    }
+    return f, nil
+}
+
+// FormatNames lists text followed by the registered formats in
+// registration order.
+func FormatNames() []string {
+    return append([]string{FormatText}, formatterNames...)
+}
+
+// FormatName returns the output format of o: Format, or json when only
+// JSON is set, or text.
+func (o OutputOptions) FormatName() string {
+    switch {
+    case o.Format != "":
+        return o.Format
+    case o.JSON:
+        return FormatJSON
+    }
+    return FormatText
+}
+
+// formatReport writes report with the formatter of opts and reports
+// whether it did. It returns false for text, which the caller prints.
+func formatReport(w io.Writer, report any, opts OutputOptions) bool {
+    name := opts.FormatName()
+    f, err := LookupFormatter(name)
+    if err == nil && f == nil {
+        return false
+    }
+    if err == nil {
+        err = f.Format(w, report, opts)
+    }
+    if stderrors.Is(err, ErrUnsupportedReport) {
+        err = fmt.Errorf("%s output is not available for this report", name)
+    }
+    if err != nil {
+        fmt.Fprintf(opts.DiagWriter(), "--format %s: %v\n", name, err)
+    }
+    return true
+}
+
+func init() {
+    RegisterFormatter(FormatJSON, jsonFormatter{})
+    RegisterFormatter(FormatNDJSON, ndjsonFormatter{})
+    RegisterFormatter(FormatCSV, csvFormatter{})
+    RegisterFormatter(FormatHTML, htmlFormatter{})
+}
+
+// jsonFormatter prints the report as one indented JSON document.
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(w io.Writer, report any, opts OutputOptions) error {
+    outputJSON(w, report)
+    return nil
+}
+
+// ndjsonFormatter prints one JSON object per line: per row of a Table,
+// per element of a report that is a list, or else the whole report.
+type ndjsonFormatter struct{}
+
+func (ndjsonFormatter) Format(w io.Writer, report any, opts OutputOptions) error {
+    enc := json.NewEncoder(w)
+    if t, ok := report.(Table); ok {
+        // Objects keep the order of the columns, which a map would not.
+        columns := t.Columns()
+        for _, row := range t.Rows() {
+            var line bytes.Buffer
+            line.WriteByte('{')
+            for i, column := range columns {
+                key, _ := json.Marshal(column)
+                value, err := json.Marshal(row[i])
+                if err != nil {
+                    return err
+                }
+                if i > 0 {
+                    line.WriteByte(',')
+                }
+                line.Write(key)
+                line.WriteByte(':')
+                line.Write(value)
+            }
+            line.WriteString("}\n")
+            if _, err := w.Write(line.Bytes()); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+    if v := reflect.ValueOf(report); v.Kind() == reflect.Slice {
+        for i := range v.Len() {
+            if err := enc.Encode(v.Index(i).Interface()); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+    return enc.Encode(report)
+}
+
+// csvFormatter prints a Table with a header row.
+type csvFormatter struct{}
+
+func (csvFormatter) Format(w io.Writer, report any, opts OutputOptions) error {
+    t, ok := report.(Table)
+    if !ok {
+        return ErrUnsupportedReport
+    }
+    cw := csv.NewWriter(w)
+    cw.Write(t.Columns())
+    for _, row := range t.Rows() {
+        cw.Write(cellTexts(row))
+    }
+    cw.Flush()
+    return cw.Error()
+}
+
+// htmlFormatter prints a standalone page: the scalar fields of the report
+// as a summary, then its Table, or its remaining fields as JSON.
+type htmlFormatter struct{}
+
+var htmlPage = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+pre { margin: 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+{{range .Summary}}<tr><th>{{.Name}}</th><td>{{if .Block}}<pre>{{.Value}}</pre>{{else}}{{.Value}}{{end}}</td></tr>
+{{end}}</table>
+{{with .Columns}}<table>
+<tr>{{range .}}<th>{{.}}</th>{{end}}</tr>
+{{range $.Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// htmlField is one row of the summary of an HTML report; Block values are
+// indented JSON.
+type htmlField struct {
+    Name  string
+    Value string
+    Block bool
+}
+
+func (htmlFormatter) Format(w io.Writer, report any, opts OutputOptions) error {
+    data, err := json.Marshal(report)
+    if err != nil {
+        return err
+    }
+    var fields map[string]json.RawMessage
+    if json.Unmarshal(data, &fields) != nil {
+        // Not an object: show the report whole.
+        fields = map[string]json.RawMessage{"report": data}
+    }
+    page := struct {
+        Title   string
+        Summary []htmlField
+        Columns []string
+        Rows    [][]string
+    }{Title: reportTitle(report)}
+    t, isTable := report.(Table)
+    for _, name := range sortedKeys(fields) {
+        raw := fields[name]
+        switch raw[0] {
+        case 'n':
+            // null
+        case '[', '{':
+            if isTable {
+                continue
+            }
+            var indented bytes.Buffer
+            json.Indent(&indented, raw, "", "  ")
+            page.Summary = append(page.Summary, htmlField{Name: name, Value: indented.String(), Block: true})
+        case '"':
+            var value string
+            json.Unmarshal(raw, &value)
+            page.Summary = append(page.Summary, htmlField{Name: name, Value: value})
+        default:
+            page.Summary = append(page.Summary, htmlField{Name: name, Value: string(raw)})
+        }
+    }
+    if isTable {
+        page.Columns = t.Columns()
+        for _, row := range t.Rows() {
+            page.Rows = append(page.Rows, cellTexts(row))
+        }
+    }
+    return htmlPage.Execute(w, page)
+}
+
+// reportTitle names a report after its type, e.g. "Error Scan Result".
+func reportTitle(report any) string {
+    t := reflect.TypeOf(report)
+    for t != nil && t.Kind() == reflect.Pointer {
+        t = t.Elem()
+    }
+    if t == nil || t.Name() == "" {
+        return "Report"
+    }
+    var title strings.Builder
+    for i, r := range t.Name() {
+        if i > 0 && r >= 'A' && r <= 'Z' {
+            title.WriteByte(' ')
+        }
+        title.WriteRune(r)
+    }
+    return title.String()
+}
+
+// cellTexts formats the cells of a table row for csv and html.
+func cellTexts(row []any) []string {
+    texts := make([]string, len(row))
+    for i, cell := range row {
+        texts[i] = fmt.Sprint(cell)
+    }
+    return texts
+}
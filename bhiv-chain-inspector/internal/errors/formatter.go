@@ -3,81 +3,342 @@ package errors
 import (
     "encoding/json"
     "fmt"
+    "io"
+    "os"
+    "sort"
     "strings"
+    "text/template"
 )
 
-func OutputScanResult(result *ErrorScanResult, jsonMode bool) {
-    if jsonMode {
-        outputJSON(result)
-    } else {
-        outputScanText(result)
+// Verbosity controls how much text output is produced.
+type Verbosity int
+
+const (
+    VerbosityQuiet   Verbosity = -1 // summary line only
+    VerbosityNormal  Verbosity = 0  // summary and classification
+    VerbosityVerbose Verbosity = 1  // per-block lines and full error details
+)
+
+type OutputOptions struct {
+    // Format names the output format, text or a registered Formatter;
+    // when empty, JSON selects json. See FormatName.
+    Format    string
+    JSON      bool
+    Verbosity Verbosity
+    ASCII     bool
+    Color     bool
+    // Out receives reports; nil means os.Stdout.
+    Out       io.Writer
+    // Diag receives diagnostics (logs, warnings); nil means os.Stderr.
+    Diag      io.Writer
+    // DiffStyle is how compare prints block diffs: DiffUnified (also
+    // when empty) or DiffSideBySide.
+    DiffStyle string
+    // Template, when set, replaces the scan and compare reports: it is
+    // executed with the ErrorScanResult or ComparisonResult. See
+    // ParseReportTemplate.
+    Template  *template.Template
+}
+
+// Writer returns the writer reports are printed to.
+func (o OutputOptions) Writer() io.Writer {
+    if o.Out == nil {
+        return os.Stdout
     }
+    return o.Out
 }
 
-func OutputComparisonResult(result *ComparisonResult, jsonMode bool) {
-    if jsonMode {
-        outputJSON(result)
-    } else {
-        outputComparisonText(result)
+// DiagWriter returns the writer diagnostics are printed to.
+func (o OutputOptions) DiagWriter() io.Writer {
+    if o.Diag == nil {
+        return os.Stderr
+    }
+    return o.Diag
+}
+
+func OutputScanResult(result *ErrorScanResult, opts OutputOptions) {
+    if opts.Template != nil {
+        outputTemplate(opts.Template, result, opts)
+    } else if !formatReport(opts.Writer(), result, opts) {
+        outputScanText(result, opts)
     }
 }
 
-func outputJSON(data interface{}) {
+func OutputComparisonResult(result *ComparisonResult, opts OutputOptions) {
+    if opts.Template != nil {
+        outputTemplate(opts.Template, result, opts)
+    } else if !formatReport(opts.Writer(), result, opts) {
+        outputComparisonText(result, opts)
+    }
+}
+
+// PrintBlockVerdict prints a per-block line, used in verbose mode while a
+// scan or comparison is running and by follow. With JSON or NDJSON output
+// it prints the verdict as a single-line object.
+func PrintBlockVerdict(v BlockVerdict, opts OutputOptions) {
+    w := opts.Writer()
+    if f := opts.FormatName(); f == FormatJSON || f == FormatNDJSON {
+        line, _ := json.Marshal(v)
+        fmt.Fprintln(w, string(line))
+        return
+    }
+    sym := symbolsFor(opts)
+    if len(v.Issues) == 0 {
+        fmt.Fprintf(w, "%s Block %d: OK\n", colorize(opts, ansiGreen, sym.OK), v.Height)
+        return
+    }
+    for _, issue := range v.Issues {
+        color := severityColor(issue.Severity)
+        mark := sym.Fail
+        if issue.Severity != SeverityError {
+            mark = sym.Warn
+        }
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, color, mark), colorize(opts, color, issue.Message))
+    }
+}
+
+func outputJSON(w io.Writer, data interface{}) {
     jsonData, _ := json.MarshalIndent(data, "", "  ")
-    fmt.Println(string(jsonData))
+    fmt.Fprintln(w, string(jsonData))
 }
 
-func outputScanText(result *ErrorScanResult) {
-    fmt.Println("\n" + strings.Repeat("═", 66))
-    fmt.Println("BLOCKCHAIN ERROR SCAN SUMMARY")
-    fmt.Println(strings.Repeat("═", 66))
-    fmt.Printf("\n📊 STATISTICS:\n")
-    fmt.Printf("  Blocks Scanned:   %d\n", result.BlocksScanned)
-    fmt.Printf("  Total Errors:     %d\n", result.TotalErrors)
-    fmt.Printf("  Health Score:     %d%%\n", result.HealthScore)
-    fmt.Printf("  Status:           %s\n", result.Status)
+func outputScanText(result *ErrorScanResult, opts OutputOptions) {
+    w := opts.Writer()
+    sym := symbolsFor(opts)
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Status: %s | Blocks: %d | Errors: %d | Warnings: %d | Health: %d%%\n",
+            result.Status, result.BlocksScanned, result.TotalErrors, result.TotalWarnings, result.HealthScore)
+        return
+    }
+
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "BLOCKCHAIN ERROR SCAN SUMMARY")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n%sSTATISTICS:\n", sym.Stats)
+    fmt.Fprintf(w, "  Blocks Scanned:   %d\n", result.BlocksScanned)
+    if result.CacheHits > 0 {
+        fmt.Fprintf(w, "  Verified Before:  %d (checks skipped, see --verify-cache)\n", result.CacheHits)
+    }
+    fmt.Fprintf(w, "  Total Errors:     %d\n", result.TotalErrors)
+    if result.TotalWarnings > 0 || result.TotalInfo > 0 {
+        fmt.Fprintf(w, "  Warnings / Info:  %d / %d\n", result.TotalWarnings, result.TotalInfo)
+    }
+    if result.Suppressed > 0 {
+        fmt.Fprintf(w, "  Suppressed:       %d\n", result.Suppressed)
+    }
+    if len(result.Encodings) > 0 {
+        var parts []string
+        for _, e := range sortedKeys(result.Encodings) {
+            parts = append(parts, fmt.Sprintf("%s %d", e, result.Encodings[e]))
+        }
+        fmt.Fprintf(w, "  Encodings:        %s\n", strings.Join(parts, ", "))
+    }
+    if result.HashAlgorithm != "" {
+        fmt.Fprintf(w, "  Hash Algorithm:   %s%s\n", result.HashAlgorithm, detectionNote(result.HashDetection))
+    }
+    if sizes := result.BlockSizes; sizes != nil && sizes.Blocks > 0 {
+        fmt.Fprintf(w, "  Block Sizes:      %s total, %s mean, %s max\n", formatBytes(sizes.Bytes),
+            formatBytes(int64(sizes.Sizes.Mean)), formatBytes(int64(sizes.Sizes.Max)))
+    }
+    fmt.Fprintf(w, "  Health Score:     %d%%\n", result.HealthScore)
+    fmt.Fprintf(w, "  Status:           %s\n", colorize(opts, statusColor(result.TotalErrors), result.Status))
     
-    fmt.Println("\n🔍 ERROR CLASSIFICATION:")
-    fmt.Printf("  Corrupted JSON:           %d\n", len(result.CorruptedJSON))
-    fmt.Printf("  Bad Hash:                 %d\n", len(result.BadHash))
-    fmt.Printf("  Timestamp Future:         %d\n", len(result.TimestampFuture))
-    fmt.Printf("  Timestamp Past:           %d\n", len(result.TimestampPast))
-    fmt.Printf("  Timestamp Not Increasing: %d\n", len(result.TimestampNotIncreasing))
-    fmt.Printf("  Duplicate Hashes:         %d\n", len(result.DuplicateHashes))
-    fmt.Printf("  Empty Blocks:             %d\n", len(result.EmptyBlocks))
-    fmt.Printf("  PrevHash Errors:          %d\n", len(result.PrevHashErrors))
-    fmt.Printf("  Height Errors:            %d\n", len(result.HeightErrors))
-    fmt.Printf("  Missing Blocks:           %d\n", len(result.MissingBlocks))
-    fmt.Printf("  Out of Order:             %d\n", len(result.OutOfOrderBlocks))
+    if result.Reorg != nil {
+        printReorg(result.Reorg, opts)
+    }
+    
+    fmt.Fprintf(w, "\n%sERROR CLASSIFICATION:\n", sym.Search)
+    printClassCount(opts, result, "Corrupted JSON", ClassCorruptedJSON)
+    printClassCount(opts, result, "Bad Hash", ClassBadHash)
+    printClassCount(opts, result, "Timestamp Future", ClassTimestampFuture)
+    printClassCount(opts, result, "Timestamp Past", ClassTimestampPast)
+    printClassCount(opts, result, "Timestamp Not Increasing", ClassTimestampNotIncreasing)
+    printClassCount(opts, result, "Duplicate Hashes", ClassDuplicateHashes)
+    printClassCount(opts, result, "Empty Blocks", ClassEmptyBlocks)
+    printClassCount(opts, result, "PrevHash Errors", ClassPrevHashErrors)
+    printClassCount(opts, result, "Height Errors", ClassHeightErrors)
+    printClassCount(opts, result, "Missing Blocks", ClassMissingBlocks)
+    printClassCount(opts, result, "Out of Order", ClassOutOfOrderBlocks)
+    for _, class := range result.customClasses() {
+        printClassCount(opts, result, class, class)
+    }
+
+    if opts.Verbosity >= VerbosityVerbose && result.BlockSizes != nil && result.BlockSizes.Blocks > 0 {
+        printBlockSizes(w, result.BlockSizes, sym)
+    }
+
+    if opts.Verbosity >= VerbosityVerbose && result.TotalErrors > 0 {
+        printScanDetails(result, opts)
+    }
+
+    if result.Spilled != nil {
+        fmt.Fprintf(w, "\n  %s findings beyond the memory limit were written to %s\n",
+            formatCount(result.Spilled.Findings), result.Spilled.Path)
+    }
+
+    if result.Baseline != nil {
+        printBaselineDiff(result.Baseline, opts)
+    }
     
     if result.TotalErrors == 0 {
-        fmt.Println("\n🎉 No errors found! Blockchain is healthy.")
+        fmt.Fprintf(w, "\n%s%s\n", sym.Healthy, colorize(opts, ansiGreen, "No errors found! Blockchain is healthy."))
     } else {
-        fmt.Println("\n⚠️  Errors detected.")
+        fmt.Fprintf(w, "\n%s  %s\n", sym.Warn, colorize(opts, ansiRed, "Errors detected."))
     }
-    fmt.Println(strings.Repeat("═", 66))
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
 }
 
-func outputComparisonText(result *ComparisonResult) {
-    fmt.Println("\n" + strings.Repeat("═", 66))
-    fmt.Println("NODE COMPARISON SUMMARY")
-    fmt.Println(strings.Repeat("═", 66))
-    fmt.Printf("\n📊 NODE INFO:\n")
-    fmt.Printf("  Node1: %s (Height: %d)\n", result.Node1Path, result.Node1Height)
-    fmt.Printf("  Node2: %s (Height: %d)\n", result.Node2Path, result.Node2Height)
+func sortedKeys[V any](m map[string]V) []string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+func statusColor(errorCount int) string {
+    if errorCount == 0 {
+        return ansiGreen
+    }
+    return ansiRed
+}
+
+// printClassCount prints one classification row, colored by the class
+// severity when the count is non-zero. The count includes the findings
+// left out of the class list.
+func printClassCount(opts OutputOptions, result *ErrorScanResult, label, class string) {
+    w := opts.Writer()
+    sev := result.SeverityOf(class)
+    count := result.listed(class) + result.Omitted[class]
+    line := fmt.Sprintf("  %-26s%d", label+":", count)
+    if sev != SeverityError {
+        line += fmt.Sprintf(" (%s)", sev)
+    }
+    if count > 0 {
+        line = colorize(opts, severityColor(sev), line)
+    }
+    fmt.Fprintln(w, line)
+}
+
+// printScanDetails prints the findings grouped into ranges of heights,
+// each class followed by the count Summarize left out of it.
+func printScanDetails(result *ErrorScanResult, opts OutputOptions) {
+    w := opts.Writer()
+    fmt.Fprintf(w, "\n%sERROR DETAILS:\n", symbolsFor(opts).Details)
+    groups := result.Groups
+    if groups == nil {
+        groups = GroupIssues(result.Issues())
+    }
+    shown := make(map[string]bool)
+    for i, g := range groups {
+        fmt.Fprintf(w, "  - %s\n", colorize(opts, severityColor(g.Severity), g.Message))
+        last := i == len(groups)-1 || groups[i+1].Class != g.Class
+        if n := result.Omitted[g.Class]; last && n > 0 {
+            fmt.Fprintf(w, "    ... and %s more %s\n", formatCount(n), g.Class)
+        }
+        shown[g.Class] = true
+    }
+    // Classes whose findings were all spilled have no groups.
+    for _, class := range sortedKeys(result.Omitted) {
+        if !shown[class] {
+            fmt.Fprintf(w, "  - %s %s not kept in memory\n", formatCount(result.Omitted[class]), class)
+        }
+    }
+}
+
+func printBaselineDiff(diff *BaselineDiff, opts OutputOptions) {
+    w := opts.Writer()
+    fmt.Fprintf(w, "\n%sBASELINE COMPARISON:\n", symbolsFor(opts).Search)
+    fmt.Fprintf(w, "  Baseline:   %s (%s)\n", diff.BaselinePath, diff.BaselineScanTime)
+    fmt.Fprintf(w, "  New:        %d\n", len(diff.New))
+    fmt.Fprintf(w, "  Fixed:      %d\n", len(diff.Fixed))
+    fmt.Fprintf(w, "  Unchanged:  %d\n", len(diff.Unchanged))
+
+    for _, issue := range diff.New {
+        fmt.Fprintf(w, "  + %s\n", colorize(opts, severityColor(issue.Severity), issue.Message))
+    }
+    for _, issue := range diff.Fixed {
+        fmt.Fprintf(w, "  - %s\n", colorize(opts, ansiGreen, issue.Message))
+    }
+    if opts.Verbosity >= VerbosityVerbose {
+        for _, issue := range diff.Unchanged {
+            fmt.Fprintf(w, "  = %s\n", issue.Message)
+        }
+    }
+}
+
+func outputComparisonText(result *ComparisonResult, opts OutputOptions) {
+    w := opts.Writer()
+    sym := symbolsFor(opts)
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Matching: %d | Mismatched: %d | Sync: %.1f%% | Divergence: %d\n",
+            result.MatchingBlocks, len(result.MismatchedBlocks), result.SyncPercentage, result.DivergencePoint)
+        return
+    }
+
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "NODE COMPARISON SUMMARY")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n%sNODE INFO:\n", sym.Stats)
+    fmt.Fprintf(w, "  Node1: %s (Height: %d)\n", result.Node1Path, result.Node1Height)
+    fmt.Fprintf(w, "  Node2: %s (Height: %d)\n", result.Node2Path, result.Node2Height)
     
-    fmt.Println("\n🔍 RESULTS:")
-    fmt.Printf("  Matching Blocks:    %d\n", result.MatchingBlocks)
-    fmt.Printf("  Mismatched Blocks:  %d\n", len(result.MismatchedBlocks))
-    fmt.Printf("  Sync Percentage:    %.1f%%\n", result.SyncPercentage)
+    fmt.Fprintf(w, "\n%sRESULTS:\n", sym.Search)
+    fmt.Fprintf(w, "  Matching Blocks:    %d\n", result.MatchingBlocks)
+    if result.SegmentsSkipped > 0 {
+        fmt.Fprintf(w, "  Identical Segments: %d (skipped by fingerprint)\n", result.SegmentsSkipped)
+    }
+    if result.BisectProbes > 0 {
+        fmt.Fprintf(w, "  Bisect Probes:      %d (common prefix not walked)\n", result.BisectProbes)
+    }
+    mismatched := fmt.Sprintf("  Mismatched Blocks:  %d", len(result.MismatchedBlocks))
+    if len(result.MismatchedBlocks) > 0 {
+        mismatched = colorize(opts, ansiRed, mismatched)
+    }
+    fmt.Fprintln(w, mismatched)
+    fmt.Fprintf(w, "  Sync Percentage:    %.1f%%\n", result.SyncPercentage)
+
+    if result.TimestampSkew != nil && result.TimestampSkew.Skewed > 0 {
+        printTimestampSkew(result.TimestampSkew, opts)
+    }
+
+    if len(result.TxDiffs) > 0 {
+        printTxDiffs(result.TxDiffs, opts)
+    }
+
+    if len(result.BlockDiffs) > 0 {
+        printBlockDiffs(result.BlockDiffs, opts)
+    }
+
+    if opts.Verbosity >= VerbosityVerbose {
+        printMismatchDetails(result, opts)
+    }
     
     if result.DivergencePoint >= 0 {
-        fmt.Printf("\n🔀 Divergence Point: Block %d\n", result.DivergencePoint)
+        fmt.Fprintf(w, "\n%s%s\n", sym.Diverge, colorize(opts, ansiRed, fmt.Sprintf("Divergence Point: Block %d", result.DivergencePoint)))
     }
     
-    fmt.Println("\n🔧 RECOMMENDATIONS:")
+    fmt.Fprintf(w, "\n%sRECOMMENDATIONS:\n", sym.Recs)
     for i, rec := range result.Recommendations {
-        fmt.Printf("  %d. %s\n", i+1, rec)
+        fmt.Fprintf(w, "  %d. %s\n", i+1, rec)
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
+
+func printMismatchDetails(result *ComparisonResult, opts OutputOptions) {
+    w := opts.Writer()
+    if len(result.HashMismatches)+len(result.DataMismatches)+len(result.TimestampMismatches) == 0 {
+        return
+    }
+    fmt.Fprintf(w, "\n%sMISMATCH DETAILS:\n", symbolsFor(opts).Details)
+    for _, msg := range result.HashMismatches {
+        fmt.Fprintf(w, "  - %s\n", colorize(opts, severityColor(SeverityError), msg))
+    }
+    for _, msg := range result.DataMismatches {
+        fmt.Fprintf(w, "  - %s\n", colorize(opts, severityColor(SeverityWarning), msg))
+    }
+    for _, msg := range result.TimestampMismatches {
+        fmt.Fprintf(w, "  - %s\n", colorize(opts, severityColor(SeverityWarning), msg))
     }
-    fmt.Println(strings.Repeat("═", 66))
 }
@@ -0,0 +1,103 @@
+package errors
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+)
+
+// IssueGroup is a run of findings of one class at consecutive heights
+// whose messages differ only in the height, such as "Blocks 1000-1999:
+// Missing". Findings whose message does not start with "Block <height>"
+// form groups of their own.
+type IssueGroup struct {
+    Class      string   `json:"class"`
+    Code       Code     `json:"code"`
+    Severity   Severity `json:"severity"`
+    Message    string   `json:"message"`
+    FromHeight int      `json:"from_height"`
+    ToHeight   int      `json:"to_height"`
+    Count      int      `json:"count"`
+}
+
+var blockMessage = regexp.MustCompile(`^Block (\d+)(.*)$`)
+
+// GroupIssues merges findings of one class at consecutive heights into
+// ranges, keeping the order of issues.
+func GroupIssues(issues []Issue) []IssueGroup {
+    var groups []IssueGroup
+    lastRest := ""
+    for _, issue := range issues {
+        m := blockMessage.FindStringSubmatch(issue.Message)
+        if m == nil {
+            groups = append(groups, IssueGroup{Class: issue.Class, Code: issue.Code, Severity: issue.Severity,
+                Message: issue.Message, FromHeight: -1, ToHeight: -1, Count: 1})
+            lastRest = ""
+            continue
+        }
+        height, _ := strconv.Atoi(m[1])
+        rest := m[2]
+        if n := len(groups); n > 0 && lastRest == rest {
+            g := &groups[n-1]
+            if g.Class == issue.Class && g.FromHeight >= 0 && g.ToHeight+1 == height {
+                g.ToHeight = height
+                g.Count++
+                g.Message = fmt.Sprintf("Blocks %d-%d%s", g.FromHeight, height, rest)
+                continue
+            }
+        }
+        groups = append(groups, IssueGroup{Class: issue.Class, Code: issue.Code, Severity: issue.Severity,
+            Message: issue.Message, FromHeight: height, ToHeight: height, Count: 1})
+        lastRest = rest
+    }
+    return groups
+}
+
+// Summarize groups the findings of the scan into Groups. With maxPerType
+// above zero it keeps only the first maxPerType groups of each class,
+// cuts the class lists down to the findings those groups cover, and
+// counts the findings left out in Omitted, along with those spilled to a
+// file. Totals and the health score still count every finding.
+func (r *ErrorScanResult) Summarize(maxPerType int) {
+    r.Groups = nil
+    if r.Spilled != nil {
+        r.Omitted = make(map[string]int)
+        for class, n := range r.Spilled.Classes {
+            r.Omitted[class] = n
+        }
+    }
+    kept := make(map[string]int)
+    shown := make(map[string]int)
+    for _, g := range GroupIssues(r.Issues()) {
+        if maxPerType > 0 && shown[g.Class] >= maxPerType {
+            if r.Omitted == nil {
+                r.Omitted = make(map[string]int)
+            }
+            r.Omitted[g.Class] += g.Count
+            continue
+        }
+        shown[g.Class]++
+        kept[g.Class] += g.Count
+        r.Groups = append(r.Groups, g)
+    }
+    for class := range r.Omitted {
+        switch list := r.list(class); {
+        case list != nil:
+            *list = (*list)[:kept[class]]
+        case class == ClassMissingBlocks:
+            r.MissingBlocks = r.MissingBlocks[:kept[class]]
+        // A custom class whose findings were all spilled has no list.
+        case r.Custom[class] != nil:
+            r.Custom[class] = r.Custom[class][:kept[class]]
+        }
+    }
+}
+
+// formatCount writes n with thousands separators, e.g. 9,412.
+func formatCount(n int) string {
+    s := strconv.Itoa(n)
+    for i := len(s) - 3; i > 0 && s[i-1] != '-'; i -= 3 {
+        s = s[:i] + "," + s[i:]
+    }
+    return s
+}
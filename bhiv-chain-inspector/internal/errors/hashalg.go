@@ -0,0 +1,66 @@
+package errors
+
+import (
+    "fmt"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// HashSampleSize is the number of blocks DetectHashAlgorithm hashes.
+const HashSampleSize = 16
+
+// HashDetection reports which hash algorithm a chain appears to use.
+type HashDetection struct {
+    // Algorithm matched the most sampled blocks; empty if none matched.
+    Algorithm blocks.HashAlgorithm `json:"algorithm"`
+    Sampled   int                  `json:"sampled"`
+    // Matches counts the sampled blocks each algorithm verifies.
+    Matches   map[string]int       `json:"matches"`
+}
+
+// DetectHashAlgorithm hashes the genesis block and up to sample-1 more
+// blocks spread evenly over r with every supported algorithm. Ties go to
+// the algorithm listed first in blocks.HashAlgorithms.
+func DetectHashAlgorithm(r db.BlockReader, sample int) *HashDetection {
+    d := &HashDetection{Matches: make(map[string]int)}
+    tip := r.GetMaxHeight()
+    if tip < 0 || sample < 1 {
+        return d
+    }
+    points := min(sample, tip+1)
+    for i := 0; i < points; i++ {
+        height := 0
+        if points > 1 {
+            height = i * tip / (points - 1)
+        }
+        block, err := r.LoadBlock(height)
+        if err != nil {
+            continue
+        }
+        d.Sampled++
+        for _, alg := range blocks.HashAlgorithms {
+            if block.VerifyHashWith(alg) == nil {
+                d.Matches[string(alg)]++
+            }
+        }
+    }
+    best := 0
+    for _, alg := range blocks.HashAlgorithms {
+        if n := d.Matches[string(alg)]; n > best {
+            d.Algorithm, best = alg, n
+        }
+    }
+    return d
+}
+
+// detectionNote describes how the scan's hash algorithm was chosen.
+func detectionNote(d *HashDetection) string {
+    switch {
+    case d == nil:
+        return ""
+    case d.Algorithm == "":
+        return fmt.Sprintf(" (none of %d sampled blocks matched any algorithm)", d.Sampled)
+    }
+    return fmt.Sprintf(" (detected: %d/%d sampled blocks match)", d.Matches[string(d.Algorithm)], d.Sampled)
+}
@@ -0,0 +1,223 @@
+package errors
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// HealAction is one block rewrite decided by HealNodes. It is also the
+// line format of the heal audit log.
+type HealAction struct {
+    Time    string `json:"time"`
+    Height  int    `json:"height"`
+    Node    string `json:"node"`
+    OldHash string `json:"old_hash,omitempty"` // empty if the block was missing or unreadable
+    NewHash string `json:"new_hash"`
+    Sources int    `json:"sources"` // nodes holding the majority version
+    DryRun  bool   `json:"dry_run"`
+    Error   string `json:"error,omitempty"`
+}
+
+// HealResult summarizes a heal run.
+type HealResult struct {
+    ScanTime        string       `json:"scan_time"`
+    Nodes           []string     `json:"nodes"`
+    HeightsChecked  int          `json:"heights_checked"`
+    DivergentBlocks []int        `json:"divergent_blocks"`
+    NoMajority      []int        `json:"no_majority"`
+    Actions         []HealAction `json:"actions"`
+    Failed          int          `json:"failed"`
+    DryRun          bool         `json:"dry_run"`
+}
+
+// HealOptions tunes HealNodes.
+type HealOptions struct {
+    // DryRun decides actions without writing anything.
+    DryRun bool
+    // OnAction, when set, is called for every action after it is applied.
+    OnAction func(HealAction)
+}
+
+// HealNodes compares the decoded block at every height across nodes and,
+// where they differ, writes the version held by a strict majority into
+// the nodes that disagree, re-encoded as each of them stores blocks.
+// Votes are cast on decoded blocks, not stored bytes, so nodes that
+// compress, encrypt or encode differently still agree on the same block.
+// Heights without a majority are only reported. A block that is missing
+// or does not decode casts no vote, and a majority of such nodes never
+// deletes a block from the others.
+func HealNodes(storages []*db.Storage, paths []string, opts HealOptions) *HealResult {
+    result := &HealResult{
+        ScanTime: FormatTime(time.Now()),
+        Nodes:    paths,
+        DryRun:   opts.DryRun,
+    }
+
+    maxHeight := -1
+    for _, s := range storages {
+        if h := s.GetMaxHeight(); h > maxHeight {
+            maxHeight = h
+        }
+    }
+
+    for i := 0; i <= maxHeight; i++ {
+        result.HeightsChecked++
+        stored := make([]*blocks.Block, len(storages))
+        versions := make([][]byte, len(storages))
+        oldHashes := make([]string, len(storages))
+        for n, s := range storages {
+            raw, err := s.LoadBlockRaw(i)
+            if err != nil {
+                continue
+            }
+            oldHashes[n] = "(corrupted)"
+            if block, _, err := s.DecodeBlock(raw); err == nil {
+                stored[n], versions[n], oldHashes[n] = block, blockVersion(block), block.Hash
+            }
+        }
+
+        majority, votes, agreed := majorityVersion(versions)
+        if agreed {
+            continue
+        }
+        result.DivergentBlocks = append(result.DivergentBlocks, i)
+        if majority == nil {
+            result.NoMajority = append(result.NoMajority, i)
+            continue
+        }
+        var winner *blocks.Block
+        for n, version := range versions {
+            if version != nil && bytes.Equal(version, majority) {
+                winner = stored[n]
+                break
+            }
+        }
+
+        for n, version := range versions {
+            if version != nil && bytes.Equal(version, majority) {
+                continue
+            }
+            action := HealAction{
+                Time:    time.Now().Format(time.RFC3339),
+                Height:  i,
+                Node:    paths[n],
+                OldHash: oldHashes[n],
+                NewHash: winner.Hash,
+                Sources: votes,
+                DryRun:  opts.DryRun,
+            }
+            if !opts.DryRun {
+                if err := saveHealed(storages[n], i, winner); err != nil {
+                    action.Error = err.Error()
+                    result.Failed++
+                }
+            }
+            result.Actions = append(result.Actions, action)
+            if opts.OnAction != nil {
+                opts.OnAction(action)
+            }
+        }
+    }
+    return result
+}
+
+// majorityVersion returns the present version held by more than half of
+// the nodes and its vote count. agreed is true when every node holds the
+// same version (or none holds one).
+func majorityVersion(versions [][]byte) (majority []byte, votes int, agreed bool) {
+    agreed = true
+    for _, v := range versions[1:] {
+        if !bytes.Equal(v, versions[0]) || (v == nil) != (versions[0] == nil) {
+            agreed = false
+            break
+        }
+    }
+    if agreed {
+        return nil, len(versions), true
+    }
+    for _, candidate := range versions {
+        if candidate == nil {
+            continue
+        }
+        count := 0
+        for _, v := range versions {
+            if v != nil && bytes.Equal(v, candidate) {
+                count++
+            }
+        }
+        if count*2 > len(versions) {
+            return candidate, count, false
+        }
+    }
+    return nil, 0, false
+}
+
+// blockVersion is what a node votes with for block: its fields as JSON,
+// whatever encoding, compression or encryption stored them.
+func blockVersion(block *blocks.Block) []byte {
+    data, _ := json.Marshal(block)
+    return data
+}
+
+// saveHealed writes block at height into s, encoded as s stores blocks.
+func saveHealed(s *db.Storage, height int, block *blocks.Block) error {
+    data, err := s.EncodeBlock(block)
+    if err != nil {
+        return err
+    }
+    return s.SaveBlockRaw(height, data)
+}
+
+// OutputHealResult prints a heal summary.
+func OutputHealResult(result *HealResult, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, result, opts) {
+        return
+    }
+    written := len(result.Actions) - result.Failed
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Divergent: %d | Healed: %d | No majority: %d | Failed: %d | Dry run: %v\n",
+            len(result.DivergentBlocks), written, len(result.NoMajority), result.Failed, result.DryRun)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "MAJORITY HEAL SUMMARY")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n%sNODES:\n", sym.Stats)
+    for _, node := range result.Nodes {
+        fmt.Fprintf(w, "  %s\n", node)
+    }
+    fmt.Fprintf(w, "\n%sRESULTS:\n", sym.Search)
+    fmt.Fprintf(w, "  Heights Checked:    %d\n", result.HeightsChecked)
+    fmt.Fprintf(w, "  Divergent Blocks:   %d\n", len(result.DivergentBlocks))
+    fmt.Fprintf(w, "  Without Majority:   %d\n", len(result.NoMajority))
+    if result.DryRun {
+        fmt.Fprintf(w, "  Would Rewrite:      %d\n", len(result.Actions))
+    } else {
+        fmt.Fprintf(w, "  Blocks Rewritten:   %d\n", written)
+        fmt.Fprintf(w, "  Failed Writes:      %d\n", result.Failed)
+    }
+
+    if len(result.Actions) > 0 {
+        fmt.Fprintf(w, "\n%sACTIONS:\n", sym.Details)
+        for _, a := range result.Actions {
+            status := colorize(opts, ansiGreen, sym.OK)
+            if a.Error != "" {
+                status = colorize(opts, ansiRed, sym.Fail)
+            }
+            fmt.Fprintf(w, "  %s Block %d -> %s (%d nodes agree)\n", status, a.Height, a.Node, a.Sources)
+        }
+    }
+    for _, h := range result.NoMajority {
+        fmt.Fprintf(w, "  %s Block %d: no majority version, left unchanged\n", colorize(opts, ansiYellow, sym.Warn), h)
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
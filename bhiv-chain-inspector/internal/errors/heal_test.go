@@ -0,0 +1,185 @@
+package errors
+
+import (
+    "bytes"
+    "fmt"
+    "path/filepath"
+    "slices"
+    "strings"
+    "testing"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+func TestMajorityVersion(t *testing.T) {
+    a, b, c := []byte("a"), []byte("b"), []byte("c")
+    tests := []struct {
+        name     string
+        versions [][]byte
+        majority []byte
+        votes    int
+        agreed   bool
+    }{
+        {"all agree", [][]byte{a, a, a}, nil, 3, true},
+        {"single node", [][]byte{a}, nil, 1, true},
+        {"all missing", [][]byte{nil, nil, nil}, nil, 3, true},
+        {"one differs", [][]byte{a, b, a}, a, 2, false},
+        {"one missing", [][]byte{a, nil, a}, a, 2, false},
+        {"tie", [][]byte{a, b}, nil, 0, false},
+        {"tie of four", [][]byte{a, b, b, a}, nil, 0, false},
+        {"no two agree", [][]byte{a, b, c}, nil, 0, false},
+        {"half is not a majority", [][]byte{a, a, b, c}, nil, 0, false},
+        {"missing casts no vote", [][]byte{a, nil, nil}, nil, 0, false},
+        {"missing counts against", [][]byte{a, a, nil, nil}, nil, 0, false},
+    }
+    for _, tt := range tests {
+        majority, votes, agreed := majorityVersion(tt.versions)
+        if !bytes.Equal(majority, tt.majority) || votes != tt.votes || agreed != tt.agreed {
+            t.Errorf("%s: got (%q, %d, %v), want (%q, %d, %v)",
+                tt.name, majority, votes, agreed, tt.majority, tt.votes, tt.agreed)
+        }
+    }
+}
+
+// healKey encrypts the nodes a HealNodes test marks encrypted.
+var healKey = []byte("0123456789abcdef")
+
+// healBlock is version v of the block at height.
+func healBlock(height int, v byte) *blocks.Block {
+    block := &blocks.Block{
+        Height:    height,
+        Data:      fmt.Sprintf("version %c", v),
+        Timestamp: 1704067200 + int64(height),
+        ChainID:   "heal",
+    }
+    block.MineWith(blocks.SHA256)
+    return block
+}
+
+// openHealNode creates a node in dir holding one block per byte of
+// chain: a version letter, '-' for none, or '!' for a value that does not
+// decode.
+func openHealNode(t *testing.T, dir, chain string, encrypted bool) *db.Storage {
+    t.Helper()
+    key := healKey
+    if !encrypted {
+        key = nil
+    }
+    if err := db.SetDefaultEncryptionKey(key); err != nil {
+        t.Fatal(err)
+    }
+    defer db.SetDefaultEncryptionKey(nil)
+    s, err := db.NewStorage(dir)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { s.Close() })
+    for height, v := range []byte(chain) {
+        switch v {
+        case '-':
+        case '!':
+            err = s.SaveBlockRaw(height, []byte("not a block"))
+        default:
+            err = s.SaveBlock(healBlock(height, v))
+        }
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+    return s
+}
+
+func TestHealNodes(t *testing.T) {
+    tests := []struct {
+        name       string
+        nodes      []string
+        encrypted  []bool
+        divergent  []int
+        noMajority []int
+        // actions are "<height>:<node index>:<version written>".
+        actions    []string
+    }{
+        {name: "all agree", nodes: []string{"aaa", "aaa", "aaa"}},
+        {name: "all missing at a height", nodes: []string{"a-a", "a-a"}},
+        {name: "one differs", nodes: []string{"aaa", "aba", "aaa"},
+            divergent: []int{1}, actions: []string{"1:1:a"}},
+        {name: "missing block", nodes: []string{"aaa", "a-a", "aaa"},
+            divergent: []int{1}, actions: []string{"1:1:a"}},
+        {name: "missing tip", nodes: []string{"aaa", "aa", "aaa"},
+            divergent: []int{2}, actions: []string{"2:1:a"}},
+        {name: "corrupted block", nodes: []string{"aaa", "a!a", "aaa"},
+            divergent: []int{1}, actions: []string{"1:1:a"}},
+        {name: "tie", nodes: []string{"aa", "ab"},
+            divergent: []int{1}, noMajority: []int{1}},
+        {name: "tie of four", nodes: []string{"aab", "aab", "aaa", "aaa"},
+            divergent: []int{2}, noMajority: []int{2}},
+        {name: "missing majority deletes nothing", nodes: []string{"aaa", "aa-", "aa-"},
+            divergent: []int{2}, noMajority: []int{2}},
+        {name: "corrupted majority overwrites nothing", nodes: []string{"a!", "a!", "aa"},
+            divergent: []int{1}, noMajority: []int{1}},
+        {name: "several heights", nodes: []string{"abab", "bbaa", "bbba"},
+            divergent: []int{0, 2, 3}, actions: []string{"0:0:b", "2:2:a", "3:0:a"}},
+        {name: "encrypted node agrees", nodes: []string{"aaa", "aaa", "aaa"},
+            encrypted: []bool{false, true, false}},
+        {name: "encrypted node healed", nodes: []string{"aaa", "aba", "aaa"},
+            encrypted: []bool{false, true, false},
+            divergent: []int{1}, actions: []string{"1:1:a"}},
+        {name: "encrypted majority", nodes: []string{"aaa", "aba", "aaa"},
+            encrypted: []bool{true, false, true},
+            divergent: []int{1}, actions: []string{"1:1:a"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            storages := make([]*db.Storage, len(tt.nodes))
+            paths := make([]string, len(tt.nodes))
+            for n, chain := range tt.nodes {
+                paths[n] = filepath.Join(t.TempDir(), fmt.Sprint(n))
+                storages[n] = openHealNode(t, paths[n], chain, tt.encrypted != nil && tt.encrypted[n])
+            }
+            check := func(result *HealResult) {
+                t.Helper()
+                var actions []string
+                for _, a := range result.Actions {
+                    actions = append(actions, fmt.Sprintf("%d:%d", a.Height, slices.Index(paths, a.Node)))
+                    if a.Error != "" {
+                        t.Errorf("block %d of %s: %s", a.Height, a.Node, a.Error)
+                    }
+                }
+                if !slices.Equal(result.DivergentBlocks, tt.divergent) {
+                    t.Errorf("divergent blocks %v, want %v", result.DivergentBlocks, tt.divergent)
+                }
+                if !slices.Equal(result.NoMajority, tt.noMajority) {
+                    t.Errorf("no majority at %v, want %v", result.NoMajority, tt.noMajority)
+                }
+                var want []string
+                for _, a := range tt.actions {
+                    want = append(want, a[:strings.LastIndex(a, ":")])
+                }
+                if !slices.Equal(actions, want) {
+                    t.Errorf("actions %v, want %v", actions, want)
+                }
+            }
+
+            // A dry run decides the same actions and writes none of them.
+            check(HealNodes(storages, paths, HealOptions{DryRun: true}))
+            check(HealNodes(storages, paths, HealOptions{}))
+
+            for _, a := range tt.actions {
+                var height, n int
+                var v byte
+                fmt.Sscanf(a, "%d:%d:%c", &height, &n, &v)
+                want := healBlock(height, v)
+                got, err := storages[n].LoadBlock(height)
+                if err != nil || got.Hash != want.Hash {
+                    t.Errorf("block %d of node %d after healing: %+v, %v; want hash %s", height, n, got, err, want.Hash)
+                }
+            }
+            again := HealNodes(storages, paths, HealOptions{DryRun: true})
+            if !slices.Equal(again.DivergentBlocks, tt.noMajority) || len(again.Actions) != 0 {
+                t.Errorf("after healing: divergent %v with %d action(s), want only %v",
+                    again.DivergentBlocks, len(again.Actions), tt.noMajority)
+            }
+        })
+    }
+}
@@ -0,0 +1,84 @@
+package errors
+
+import (
+    "fmt"
+    "math"
+)
+
+// HealthModel turns findings into a 0-100 health score. Each checked
+// height accumulates the weights of its error-severity findings, capped at
+// MaxPenaltyPerBlock, so a single badly broken block cannot outweigh the
+// rest of the chain. The score is the share of the maximum possible
+// penalty that was not incurred.
+//
+//  health:
+//    weights:
+//      bad_hash: 1
+//      timestamp_not_increasing: 0.25
+//    max_penalty_per_block: 1
+type HealthModel struct {
+    Weights            map[string]float64 `json:"weights,omitempty"`
+    MaxPenaltyPerBlock float64            `json:"max_penalty_per_block"`
+}
+
+// DefaultHealthModel weighs every error class 1 and caps each block at 1,
+// so the score is the percentage of heights without errors.
+func DefaultHealthModel() HealthModel {
+    return HealthModel{MaxPenaltyPerBlock: 1}
+}
+
+// NewHealthModel validates configured weights; a nil cap keeps the default.
+func NewHealthModel(weights map[string]float64, maxPenaltyPerBlock *float64) (HealthModel, error) {
+    model := DefaultHealthModel()
+    for class, w := range weights {
+        if !isKnownClass(class) {
+            return model, fmt.Errorf("unknown error class %q in health weights", class)
+        }
+        if w < 0 {
+            return model, fmt.Errorf("negative health weight %v for %s", w, class)
+        }
+    }
+    model.Weights = weights
+    if maxPenaltyPerBlock != nil {
+        if *maxPenaltyPerBlock <= 0 {
+            return model, fmt.Errorf("max_penalty_per_block must be positive, got %v", *maxPenaltyPerBlock)
+        }
+        model.MaxPenaltyPerBlock = *maxPenaltyPerBlock
+    }
+    return model, nil
+}
+
+func (m HealthModel) weight(class string) float64 {
+    if w, ok := m.Weights[class]; ok {
+        return w
+    }
+    return 1
+}
+
+// blockPenalty is the capped penalty for the findings at one height.
+// Warnings and info findings never count.
+func (m HealthModel) blockPenalty(issues []Issue) float64 {
+    penalty := 0.0
+    for _, issue := range issues {
+        if issue.Severity == SeverityError {
+            penalty += m.weight(issue.Class)
+        }
+    }
+    if m.MaxPenaltyPerBlock > 0 {
+        penalty = math.Min(penalty, m.MaxPenaltyPerBlock)
+    }
+    return penalty
+}
+
+// score converts the accumulated penalty over heights into 0-100.
+func (m HealthModel) score(penalty float64, heights int) int {
+    if heights == 0 {
+        return 0
+    }
+    maxPenalty := m.MaxPenaltyPerBlock
+    if maxPenalty <= 0 {
+        maxPenalty = 1
+    }
+    score := 100 * (1 - penalty/(maxPenalty*float64(heights)))
+    return int(math.Round(math.Max(0, math.Min(100, score))))
+}
@@ -0,0 +1,126 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// HistoryEntry summarizes result for the scan history stored in the database.
+func (r *ErrorScanResult) HistoryEntry(at time.Time) *db.ScanHistoryEntry {
+    counts := make(map[string]int)
+    for _, issue := range r.Issues() {
+        counts[issue.Class]++
+    }
+    if r.Spilled != nil {
+        for class, n := range r.Spilled.Classes {
+            counts[class] += n
+        }
+    }
+    return &db.ScanHistoryEntry{
+        Time:          at.UnixNano(),
+        HealthScore:   r.HealthScore,
+        BlocksScanned: r.BlocksScanned,
+        TotalErrors:   r.TotalErrors,
+        TotalWarnings: r.TotalWarnings,
+        Status:        r.Status,
+        ErrorCounts:   counts,
+    }
+}
+
+// splitHistory separates scan entries from backup entries and returns the
+// newest verified backup, if any.
+func splitHistory(entries []db.ScanHistoryEntry) (scans []db.ScanHistoryEntry, lastGood *db.ScanHistoryEntry) {
+    for i, e := range entries {
+        switch {
+        case e.Backup == nil:
+            scans = append(scans, e)
+        case e.Backup.Verified:
+            lastGood = &entries[i]
+        }
+    }
+    return scans, lastGood
+}
+
+// LastScans drops all but the last n scan entries of history, keeping the
+// backup entries.
+func LastScans(entries []db.ScanHistoryEntry, n int) []db.ScanHistoryEntry {
+    scans, _ := splitHistory(entries)
+    if len(scans) <= n {
+        return entries
+    }
+    cutoff := scans[len(scans)-n].Time
+    var kept []db.ScanHistoryEntry
+    for _, e := range entries {
+        if e.Backup != nil || e.Time >= cutoff {
+            kept = append(kept, e)
+        }
+    }
+    return kept
+}
+
+// OutputTrend prints stored scan history, oldest first, and when the last
+// good backup was taken.
+func OutputTrend(history []db.ScanHistoryEntry, dbPath string, opts OutputOptions) {
+    w := opts.Writer()
+    if history == nil {
+        history = []db.ScanHistoryEntry{}
+    }
+    if formatReport(w, history, opts) {
+        return
+    }
+
+    sym := symbolsFor(opts)
+    entries, lastGood := splitHistory(history)
+    backupLine := "none recorded"
+    if lastGood != nil {
+        backupLine = fmt.Sprintf("%s (block %d, %s)", FormatTime(time.Unix(0, lastGood.Time)),
+            lastGood.Backup.Tip, lastGood.Backup.Archive)
+    }
+    if len(entries) == 0 {
+        fmt.Fprintf(w, "No scan history recorded in %s yet; run scan first. Last good backup: %s\n", dbPath, backupLine)
+        return
+    }
+
+    first, last := entries[0], entries[len(entries)-1]
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Scans: %d | Health: %d%% -> %d%% | Errors: %d -> %d",
+            len(entries), first.HealthScore, last.HealthScore, first.TotalErrors, last.TotalErrors)
+        if lastGood != nil {
+            fmt.Fprintf(w, " | Last Backup: %s", FormatTime(time.Unix(0, lastGood.Time)))
+        }
+        fmt.Fprintln(w)
+        return
+    }
+
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "SCAN HISTORY TREND")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "Database: %s\n\n", dbPath)
+    tw := timeWidth()
+    fmt.Fprintf(w, "  %-*s  %6s  %6s  %8s  %8s  %s\n", tw, "Time", "Health", "Errors", "Warnings", "Blocks", "Trend")
+
+    bar := "█"
+    if opts.ASCII {
+        bar = "#"
+    }
+    for _, e := range entries {
+        line := fmt.Sprintf("  %-*s  %5d%%  %6d  %8d  %8d  %s",
+            tw, FormatTime(time.Unix(0, e.Time)),
+            e.HealthScore, e.TotalErrors, e.TotalWarnings, e.BlocksScanned,
+            strings.Repeat(bar, e.HealthScore/10))
+        fmt.Fprintln(w, colorize(opts, statusColor(e.TotalErrors), line))
+    }
+
+    fmt.Fprintf(w, "\n%sCHANGE SINCE FIRST SCAN:\n", sym.Stats)
+    fmt.Fprintf(w, "  Health Score:  %+d%%\n", last.HealthScore-first.HealthScore)
+    fmt.Fprintf(w, "  Errors:        %+d\n", last.TotalErrors-first.TotalErrors)
+    fmt.Fprintf(w, "  Blocks:        %+d\n", last.BlocksScanned-first.BlocksScanned)
+    if last.HealthScore < first.HealthScore {
+        fmt.Fprintf(w, "\n%s  %s\n", sym.Warn, colorize(opts, ansiYellow, "Health is degrading over time."))
+    }
+    fmt.Fprintf(w, "\n  Last Good Backup: %s\n", backupLine)
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
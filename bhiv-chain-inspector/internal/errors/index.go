@@ -0,0 +1,83 @@
+package errors
+
+import (
+    "fmt"
+    "log/slog"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// ClassIndexErrors is reported for hash index entries that name the wrong
+// block, and for blocks a built index lacks; see db.Storage.AuditHashIndex.
+const ClassIndexErrors = "index_errors"
+
+// indexCheck audits the hash index once per scan.
+type indexCheck struct{}
+
+func (indexCheck) Name() string { return "index" }
+
+func (indexCheck) Classes() []string { return []string{ClassIndexErrors} }
+
+func (indexCheck) Validate(*blocks.Block, *CheckContext) []Finding { return nil }
+
+func (indexCheck) ValidateStore(storage *db.Storage) []StoreFinding {
+    problems, err := storage.AuditHashIndex()
+    if err != nil {
+        slog.Warn("cannot audit hash index", "err", err)
+    }
+    var findings []StoreFinding
+    for _, p := range problems {
+        findings = append(findings, StoreFinding{p.Height, Finding{Class: ClassIndexErrors,
+            Message: fmt.Sprintf("Hash index %.16s... (block %d): %s", p.Hash, p.Height, p.Reason)}})
+    }
+    return findings
+}
+
+// IndexReport is the output of the index and reindex commands.
+type IndexReport struct {
+    DatabasePath string `json:"database_path"`
+    // Rebuilt is set by reindex, which first dropped Dropped entries.
+    Rebuilt      bool   `json:"rebuilt"`
+    Dropped      int    `json:"dropped,omitempty"`
+    Indexed      int    `json:"indexed"`
+    // Skipped values do not decode, or decode to a block without a hash.
+    Skipped      int    `json:"skipped"`
+    DurationMS   int64  `json:"duration_ms"`
+}
+
+// OutputIndex prints how many block hashes were indexed.
+func OutputIndex(report *IndexReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Dropped: %d | Indexed: %d | Skipped: %d\n", report.Dropped, report.Indexed, report.Skipped)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    if report.Rebuilt {
+        fmt.Fprintln(w, "HASH INDEX REBUILT")
+    } else {
+        fmt.Fprintln(w, "HASH INDEX")
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:         %s\n", report.DatabasePath)
+    if report.Rebuilt {
+        fmt.Fprintf(w, "  Entries Dropped:  %d\n", report.Dropped)
+    }
+    fmt.Fprintf(w, "  Blocks Indexed:   %d\n", report.Indexed)
+    fmt.Fprintf(w, "  Skipped:          %d\n", report.Skipped)
+    fmt.Fprintf(w, "  Duration:         %d ms\n", report.DurationMS)
+    if report.Skipped > 0 {
+        fmt.Fprintf(w, "\n%s %s\n", colorize(opts, ansiYellow, sym.Warn),
+            colorize(opts, ansiYellow, "Some values do not decode; run scan to find them"))
+    } else {
+        fmt.Fprintf(w, "\n%s %s\n", colorize(opts, ansiGreen, sym.OK), colorize(opts, ansiGreen, "EVERY BLOCK INDEXED"))
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+    "fmt"
+    "log/slog"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// ClassUnknownKeys is reported for keys that are neither blocks nor the
+// inspector's metadata; see db.Storage.AuditKeys.
+const ClassUnknownKeys = "unknown_keys"
+
+// keysCheck audits the keyspace once per scan.
+type keysCheck struct{}
+
+func (keysCheck) Name() string { return "keys" }
+
+func (keysCheck) Classes() []string { return []string{ClassUnknownKeys} }
+
+func (keysCheck) Validate(*blocks.Block, *CheckContext) []Finding { return nil }
+
+func (keysCheck) ValidateStore(storage *db.Storage) []StoreFinding {
+    audit, err := storage.AuditKeys()
+    if err != nil {
+        slog.Warn("cannot audit keys", "err", err)
+    }
+    var findings []StoreFinding
+    for _, key := range audit.Unknown {
+        findings = append(findings, StoreFinding{-1, Finding{Class: ClassUnknownKeys, Message: fmt.Sprintf("Key %s: %s", key.Key, key.Reason)}})
+    }
+    return findings
+}
+
+// KeyAuditReport is the output of the keys command.
+type KeyAuditReport struct {
+    DatabasePath string `json:"database_path"`
+    *db.KeyAudit
+}
+
+// OutputKeyAudit prints the key counts and every unknown key.
+func OutputKeyAudit(report *KeyAuditReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Keys: %d | Blocks: %d | Metadata: %d | Unknown: %d\n",
+            report.TotalKeys, report.BlockKeys, report.MetadataKeys, len(report.Unknown))
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "KEYSPACE AUDIT")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:         %s\n", report.DatabasePath)
+    fmt.Fprintf(w, "  Total Keys:       %d\n", report.TotalKeys)
+    fmt.Fprintf(w, "  Block Keys:       %d\n", report.BlockKeys)
+    fmt.Fprintf(w, "  Metadata Keys:    %d\n", report.MetadataKeys)
+    if report.NamespaceKeys > 0 {
+        fmt.Fprintf(w, "  Other Chains:     %d keys\n", report.NamespaceKeys)
+    }
+
+    fmt.Fprintln(w)
+    if len(report.Unknown) == 0 {
+        fmt.Fprintf(w, "%s%s\n", sym.Healthy, colorize(opts, ansiGreen, "No unknown keys"))
+    } else {
+        fmt.Fprintf(w, "%sUNKNOWN KEYS (%d):\n", sym.Details, len(report.Unknown))
+        for _, key := range report.Unknown {
+            fmt.Fprintf(w, "  %s %s %s\n", colorize(opts, ansiRed, sym.Fail), key.Key,
+                colorize(opts, ansiYellow, fmt.Sprintf("(%s, %d bytes)", key.Reason, key.Size)))
+        }
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// BlockList is the output of the list command: one row per height of a
+// range.
+type BlockList struct {
+    DatabasePath string     `json:"database_path"`
+    From         int        `json:"from"`
+    To           int        `json:"to"`
+    Blocks       []BlockRow `json:"blocks"`
+}
+
+// BlockRow summarises the block at Height. Size is the stored value's;
+// Error says why the block could not be loaded or decoded, and the other
+// fields are then zero.
+type BlockRow struct {
+    Height    int    `json:"height"`
+    Hash      string `json:"hash,omitempty"`
+    Timestamp int64  `json:"timestamp,omitempty"`
+    Size      int    `json:"size"`
+    Error     string `json:"error,omitempty"`
+}
+
+// ListBlocks reads the heights from to to, inclusive.
+func ListBlocks(storage *db.Storage, dbPath string, from, to int) *BlockList {
+    list := &BlockList{DatabasePath: dbPath, From: from, To: to, Blocks: []BlockRow{}}
+    for height := from; height <= to; height++ {
+        row := BlockRow{Height: height}
+        raw, err := storage.LoadBlockRaw(height)
+        if err == nil {
+            row.Size = len(raw)
+            block, encoding, decodeErr := storage.DecodeBlock(raw)
+            if decodeErr != nil {
+                err = fmt.Errorf("corrupted %s: %v", encoding.Label(), decodeErr)
+            } else {
+                row.Hash, row.Timestamp = block.Hash, block.Timestamp
+            }
+        }
+        if err != nil {
+            row.Error = err.Error()
+        }
+        list.Blocks = append(list.Blocks, row)
+    }
+    return list
+}
+
+// Columns and Rows make a block list a Table. Times are Unix seconds.
+func (l *BlockList) Columns() []string {
+    return []string{"height", "hash", "timestamp", "size", "error"}
+}
+
+func (l *BlockList) Rows() [][]any {
+    rows := make([][]any, len(l.Blocks))
+    for i, row := range l.Blocks {
+        rows[i] = []any{row.Height, row.Hash, row.Timestamp, row.Size, row.Error}
+    }
+    return rows
+}
+
+// OutputBlockList prints a table of the rows, hashes cut to 16 characters.
+func OutputBlockList(list *BlockList, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, list, opts) {
+        return
+    }
+    failed := 0
+    for _, row := range list.Blocks {
+        if row.Error != "" {
+            failed++
+        }
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Heights: %d-%d | Blocks: %d | Unreadable: %d\n", list.From, list.To, len(list.Blocks)-failed, failed)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "BLOCKS %d-%d\n", list.From, list.To)
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "Database: %s\n\n", list.DatabasePath)
+    tw := timeWidth()
+    fmt.Fprintf(w, "  %8s  %-16s  %-*s  %10s\n", "Height", "Hash", tw, "Time", "Size")
+    for _, row := range list.Blocks {
+        if row.Error != "" {
+            line := fmt.Sprintf("  %8d  %s", row.Height, row.Error)
+            if row.Size > 0 {
+                line += fmt.Sprintf(" (%s)", formatBytes(int64(row.Size)))
+            }
+            fmt.Fprintln(w, colorize(opts, ansiRed, line))
+            continue
+        }
+        fmt.Fprintf(w, "  %8d  %-16.16s  %-*s  %10s\n", row.Height, row.Hash,
+            tw, FormatUnix(row.Timestamp), formatBytes(int64(row.Size)))
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
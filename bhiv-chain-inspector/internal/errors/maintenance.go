@@ -0,0 +1,52 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// MaintenanceReport is the output of the compact and recover commands.
+// Before is unset for recover, which runs when the database cannot be
+// opened normally.
+type MaintenanceReport struct {
+    DatabasePath string         `json:"database_path"`
+    Operation    string         `json:"operation"`
+    Before       *db.TableStats `json:"before,omitempty"`
+    After        db.TableStats  `json:"after"`
+    MaxHeight    int            `json:"max_height"`
+    DurationMS   int64          `json:"duration_ms"`
+}
+
+// OutputMaintenance prints the table counts and sizes before and after a
+// compaction or recovery, and the chain height it left.
+func OutputMaintenance(report *MaintenanceReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "LevelDB %s: done | Tables: %d | Size: %s | Height: %d\n", report.Operation,
+            report.After.Tables, formatBytes(report.After.Size), report.MaxHeight)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "LEVELDB %s\n", strings.ToUpper(report.Operation))
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:         %s\n", report.DatabasePath)
+    if b := report.Before; b != nil {
+        fmt.Fprintf(w, "  Before:           %d table(s), %s\n", b.Tables, formatBytes(b.Size))
+    }
+    fmt.Fprintf(w, "  After:            %d table(s), %s\n", report.After.Tables, formatBytes(report.After.Size))
+    if b := report.Before; b != nil && b.Size > report.After.Size {
+        fmt.Fprintf(w, "  Reclaimed:        %s\n", formatBytes(b.Size-report.After.Size))
+    }
+    fmt.Fprintf(w, "  Chain Height:     %d\n", report.MaxHeight)
+    fmt.Fprintf(w, "  Duration:         %d ms\n", report.DurationMS)
+    fmt.Fprintf(w, "\n%s %s\n", colorize(opts, ansiGreen, sym.OK),
+        colorize(opts, ansiGreen, strings.ToUpper(report.Operation) + " COMPLETE"))
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
@@ -0,0 +1,30 @@
+package errors
+
+import (
+    "fmt"
+
+    "bhiv-chain-inspector/internal/blocks"
+)
+
+// ClassMerkleRootErrors is reported for blocks whose stored Merkle root
+// does not match the tree recomputed from their transactions.
+const ClassMerkleRootErrors = "merkle_root_errors"
+
+// merkleCheck verifies stored Merkle roots. Blocks without one predate
+// the field and are not checked.
+type merkleCheck struct{}
+
+func (merkleCheck) Name() string { return "merkle" }
+
+func (merkleCheck) Classes() []string { return []string{ClassMerkleRootErrors} }
+
+func (merkleCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    if block.MerkleRoot == "" {
+        return nil
+    }
+    if root := block.ComputeMerkleRoot(); root != block.MerkleRoot {
+        return []Finding{{Class: ClassMerkleRootErrors, Message: fmt.Sprintf("Block %d: Merkle root mismatch", ctx.Height),
+            Expected: root, Actual: block.MerkleRoot}}
+    }
+    return nil
+}
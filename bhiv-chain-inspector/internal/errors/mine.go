@@ -0,0 +1,78 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/blocks"
+)
+
+// MineReport is the output of the mine command.
+type MineReport struct {
+    DatabasePath  string       `json:"database_path"`
+    Difficulty    int          `json:"difficulty"`
+    HashAlgorithm string       `json:"hash_algorithm"`
+    Blocks        []MinedBlock `json:"blocks"`
+    // Attempts is the number of hashes computed over all blocks.
+    Attempts      uint64       `json:"attempts"`
+    DurationMS    int64        `json:"duration_ms"`
+    HashesPerSec  float64      `json:"hashes_per_second"`
+}
+
+// MinedBlock is one block found by the mine command.
+type MinedBlock struct {
+    Height     int    `json:"height"`
+    Hash       string `json:"hash"`
+    Nonce      uint64 `json:"nonce"`
+    DurationMS int64  `json:"duration_ms"`
+}
+
+// Add records block, mined in d. Mining tries nonces from zero, so the
+// block took Nonce+1 attempts.
+func (r *MineReport) Add(block *blocks.Block, d time.Duration) {
+    r.Blocks = append(r.Blocks, MinedBlock{block.Height, block.Hash, block.Nonce, d.Milliseconds()})
+    r.Attempts += block.Nonce + 1
+}
+
+// Finish sets the total duration and hash rate.
+func (r *MineReport) Finish(d time.Duration) {
+    r.DurationMS = d.Milliseconds()
+    if s := d.Seconds(); s > 0 {
+        r.HashesPerSec = float64(r.Attempts) / s
+    }
+}
+
+// OutputMineReport prints one row per mined block and the hash rate.
+func OutputMineReport(report *MineReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Mined: %d | Difficulty: %d | Attempts: %d | Rate: %.0f H/s\n",
+            len(report.Blocks), report.Difficulty, report.Attempts, report.HashesPerSec)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "PROOF OF WORK MINING")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:         %s\n", report.DatabasePath)
+    fmt.Fprintf(w, "  Difficulty:       %d bits\n", report.Difficulty)
+    fmt.Fprintf(w, "  Hash Algorithm:   %s\n", report.HashAlgorithm)
+
+    fmt.Fprintf(w, "\n%sBLOCKS:\n", sym.Details)
+    for _, b := range report.Blocks {
+        fmt.Fprintf(w, "  %s Block %-8d nonce %-10d %6d ms  %s\n",
+            colorize(opts, ansiGreen, sym.OK), b.Height, b.Nonce, b.DurationMS, b.Hash)
+    }
+
+    fmt.Fprintf(w, "\n%sTOTAL:\n", sym.Stats)
+    fmt.Fprintf(w, "  Blocks Mined:     %d\n", len(report.Blocks))
+    fmt.Fprintf(w, "  Attempts:         %d\n", report.Attempts)
+    fmt.Fprintf(w, "  Duration:         %d ms\n", report.DurationMS)
+    fmt.Fprintf(w, "  Hash Rate:        %.0f H/s\n", report.HashesPerSec)
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
@@ -0,0 +1,61 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// NamespaceReport is the output of the namespaces command.
+type NamespaceReport struct {
+    DatabasePath string             `json:"database_path"`
+    Namespaces   []db.NamespaceInfo `json:"namespaces"`
+}
+
+// OutputNamespaces prints one row per chain of the database.
+func OutputNamespaces(report *NamespaceReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        names := make([]string, len(report.Namespaces))
+        for i, ns := range report.Namespaces {
+            names[i] = chainName(ns.Name)
+        }
+        fmt.Fprintf(w, "Chains: %d | %s\n", len(names), strings.Join(names, ", "))
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "CHAIN NAMESPACES")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:         %s\n", report.DatabasePath)
+    fmt.Fprintf(w, "  Chains:           %d\n", len(report.Namespaces))
+    if len(report.Namespaces) == 0 {
+        fmt.Fprintf(w, "\n%s No blocks or metadata stored\n", colorize(opts, ansiYellow, sym.Warn))
+        fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+        return
+    }
+
+    fmt.Fprintf(w, "\n%sCHAINS:\n", sym.Stats)
+    fmt.Fprintf(w, "  %-20s %10s %10s %10s\n", "Chain", "Blocks", "Tip", "Metadata")
+    for _, ns := range report.Namespaces {
+        tip := "-"
+        if ns.MaxHeight >= 0 {
+            tip = fmt.Sprint(ns.MaxHeight)
+        }
+        fmt.Fprintf(w, "  %-20s %10d %10s %10d\n", chainName(ns.Name), ns.Blocks, tip, ns.MetadataKeys)
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
+
+// chainName shows the default chain, stored without a namespace.
+func chainName(name string) string {
+    if name == "" {
+        return "(default)"
+    }
+    return name
+}
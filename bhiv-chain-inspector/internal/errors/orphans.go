@@ -0,0 +1,35 @@
+package errors
+
+import (
+    "fmt"
+    "log/slog"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// ClassOrphanBlocks is reported for blocks stored off the canonical
+// chain; see db.Storage.FindOrphans.
+const ClassOrphanBlocks = "orphan_blocks"
+
+// orphansCheck looks for side-chain blocks once per scan.
+type orphansCheck struct{}
+
+func (orphansCheck) Name() string { return "orphans" }
+
+func (orphansCheck) Classes() []string { return []string{ClassOrphanBlocks} }
+
+func (orphansCheck) Validate(*blocks.Block, *CheckContext) []Finding { return nil }
+
+func (orphansCheck) ValidateStore(storage *db.Storage) []StoreFinding {
+    orphans, err := storage.FindOrphans()
+    if err != nil {
+        slog.Warn("cannot look for orphan blocks", "err", err)
+    }
+    var findings []StoreFinding
+    for _, o := range orphans {
+        findings = append(findings, StoreFinding{o.Height, Finding{Class: ClassOrphanBlocks,
+            Message: fmt.Sprintf("Key %s: block %d (hash %.16s, parent %.16s): %s", o.Key, o.Height, o.Hash, o.PrevHash, o.Reason)}})
+    }
+    return findings
+}
@@ -0,0 +1,35 @@
+package errors
+
+import (
+    "fmt"
+    "strconv"
+
+    "bhiv-chain-inspector/internal/blocks"
+)
+
+// ClassPoWErrors is reported for blocks whose hash does not meet their
+// declared difficulty.
+const ClassPoWErrors = "pow_errors"
+
+// powCheck verifies proof of work. Blocks with no difficulty are not
+// mined and are not checked.
+type powCheck struct{}
+
+func (powCheck) Name() string { return "pow" }
+
+func (powCheck) Classes() []string { return []string{ClassPoWErrors} }
+
+func (powCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    switch {
+    case block.Difficulty == 0:
+        return nil
+    case block.Difficulty < 0 || block.Difficulty > 256:
+        return []Finding{{Class: ClassPoWErrors, Message: fmt.Sprintf("Block %d: Invalid difficulty %d", ctx.Height, block.Difficulty),
+            Expected: "0-256", Actual: strconv.Itoa(block.Difficulty)}}
+    case !block.MeetsDifficulty():
+        bits := blocks.LeadingZeroBits(block.Hash)
+        return []Finding{{Class: ClassPoWErrors, Message: fmt.Sprintf("Block %d: Hash has %d leading zero bits, difficulty is %d",
+            ctx.Height, bits, block.Difficulty), Expected: fmt.Sprintf(">= %d", block.Difficulty), Actual: strconv.Itoa(bits)}}
+    }
+    return nil
+}
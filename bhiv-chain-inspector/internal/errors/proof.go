@@ -0,0 +1,163 @@
+package errors
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// InclusionProof shows that a transaction is part of a block without the
+// rest of the block's transactions: hashing the leaf of TxID up Path
+// yields MerkleRoot.
+type InclusionProof struct {
+    Height     int                `json:"height"`
+    BlockHash  string             `json:"block_hash"`
+    MerkleRoot string             `json:"merkle_root"`
+    TxID       string             `json:"tx_id"`
+    TxIndex    int                `json:"tx_index"`
+    Path       []blocks.ProofStep `json:"path"`
+}
+
+// BuildInclusionProof proves the transaction tx of the block at height.
+// tx is either the transaction's index in the block or its TxID.
+func BuildInclusionProof(r db.BlockReader, height int, tx string) (*InclusionProof, error) {
+    block, err := r.LoadBlock(height)
+    if err != nil {
+        return nil, err
+    }
+    txs := block.Transactions()
+    index := -1
+    if n, err := strconv.Atoi(tx); err == nil && n >= 0 && n < len(txs) {
+        index = n
+    } else {
+        for i, t := range txs {
+            if blocks.TxID(t) == strings.ToLower(tx) {
+                index = i
+                break
+            }
+        }
+    }
+    if index < 0 {
+        return nil, fmt.Errorf("block %d has no transaction %q (%d transactions)", height, tx, len(txs))
+    }
+    root := block.ComputeMerkleRoot()
+    if block.MerkleRoot != "" && block.MerkleRoot != root {
+        return nil, fmt.Errorf("block %d: stored Merkle root does not match its transactions", height)
+    }
+    return &InclusionProof{
+        Height:     height,
+        BlockHash:  block.Hash,
+        MerkleRoot: root,
+        TxID:       blocks.TxID(txs[index]),
+        TxIndex:    index,
+        Path:       blocks.MerkleProof(txs, index),
+    }, nil
+}
+
+// LoadInclusionProof reads a proof written by prove --json; "-" is stdin.
+func LoadInclusionProof(path string) (*InclusionProof, error) {
+    f := os.Stdin
+    if path != "-" {
+        var err error
+        if f, err = os.Open(path); err != nil {
+            return nil, err
+        }
+        defer f.Close()
+    }
+    var proof InclusionProof
+    if err := json.NewDecoder(f).Decode(&proof); err != nil {
+        return nil, fmt.Errorf("bad proof %s: %w", path, err)
+    }
+    return &proof, nil
+}
+
+// ProofVerification is the outcome of checking an InclusionProof.
+type ProofVerification struct {
+    Valid  bool   `json:"valid"`
+    Height int    `json:"height"`
+    TxID   string `json:"tx_id"`
+    Root   string `json:"merkle_root"`
+    Reason string `json:"reason,omitempty"`
+}
+
+// VerifyInclusionProof checks proof against a trusted block header: the
+// header must be the proven block, and the proof path must lead to the
+// header's Merkle root. With a nil header, the proof is checked against
+// its own root only, which shows it is well formed but not that the
+// block is genuine.
+func VerifyInclusionProof(proof *InclusionProof, header *blocks.Block) *ProofVerification {
+    v := &ProofVerification{Height: proof.Height, TxID: proof.TxID, Root: proof.MerkleRoot}
+    if header != nil {
+        root := header.MerkleRoot
+        if root == "" {
+            root = header.ComputeMerkleRoot()
+        }
+        switch {
+        case header.Hash != proof.BlockHash:
+            v.Reason = "block hash differs from the header"
+            return v
+        case root != proof.MerkleRoot:
+            v.Reason = "Merkle root differs from the header"
+            return v
+        }
+    }
+    if !blocks.VerifyMerkleProof(proof.TxID, proof.Path, proof.MerkleRoot) {
+        v.Reason = "path does not lead to the Merkle root"
+        return v
+    }
+    v.Valid = true
+    return v
+}
+
+// OutputInclusionProof prints a proof. The JSON form is the input of
+// verify-proof.
+func OutputInclusionProof(proof *InclusionProof, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, proof, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Height: %d | Tx: %s | Root: %s | Steps: %d\n", proof.Height, proof.TxID, proof.MerkleRoot, len(proof.Path))
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "MERKLE INCLUSION PROOF")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Height:       %d\n", proof.Height)
+    fmt.Fprintf(w, "  Block Hash:   %s\n", proof.BlockHash)
+    fmt.Fprintf(w, "  Merkle Root:  %s\n", proof.MerkleRoot)
+    fmt.Fprintf(w, "  Tx ID:        %s\n", proof.TxID)
+    fmt.Fprintf(w, "  Tx Index:     %d\n", proof.TxIndex)
+    fmt.Fprintf(w, "\n%sPATH:\n", sym.Details)
+    for i, step := range proof.Path {
+        side := "right"
+        if step.Left {
+            side = "left"
+        }
+        fmt.Fprintf(w, "  %2d  %-5s  %s\n", i, side, step.Hash)
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
+
+// OutputProofVerification prints the outcome of verify-proof.
+func OutputProofVerification(v *ProofVerification, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, v, opts) {
+        return
+    }
+    sym := symbolsFor(opts)
+    if v.Valid {
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiGreen, sym.OK),
+            colorize(opts, ansiGreen, fmt.Sprintf("Proof valid: tx %s is in block %d", v.TxID, v.Height)))
+        return
+    }
+    fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiRed, sym.Fail),
+        colorize(opts, ansiRed, fmt.Sprintf("Proof invalid: %s", v.Reason)))
+}
@@ -0,0 +1,152 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// ReorgEvent reports that blocks verified by an earlier scan or watch
+// pass, as recorded in the checkpoint, have since been rewritten.
+type ReorgEvent struct {
+    CheckpointTime int64  `json:"checkpoint_time"`
+    OldTip         ChainTip `json:"old_tip"`
+    NewTip         ChainTip `json:"new_tip"`
+    // ForkHeight is the last height whose block is unchanged, -1 when the
+    // genesis block changed or the oldest recorded one did; in that case
+    // Depth is only a lower bound.
+    ForkHeight     int    `json:"fork_height"`
+    Depth          int    `json:"depth"`
+    DepthAtLeast   bool   `json:"depth_at_least,omitempty"`
+}
+
+// ChainTip is the height and hash of a chain's last block; Hash is empty
+// for a chain that holds none.
+type ChainTip struct {
+    Height int    `json:"height"`
+    Hash   string `json:"hash"`
+}
+
+// NewCheckpoint records the chain up to height, or returns nil when that
+// block cannot be loaded.
+func NewCheckpoint(r db.BlockReader, height int) *db.Checkpoint {
+    tip, err := r.LoadBlock(height)
+    if err != nil {
+        return nil
+    }
+    cp := &db.Checkpoint{Height: height, Hash: tip.Hash, Time: time.Now().Unix()}
+    for h := max(height-db.CheckpointHashes+1, 0); h < height; h++ {
+        hash := ""
+        if block, err := r.LoadBlock(h); err == nil {
+            hash = block.Hash
+        }
+        cp.Recent = append(cp.Recent, hash)
+    }
+    cp.Recent = append(cp.Recent, tip.Hash)
+    return cp
+}
+
+// DetectReorg compares the chain with the hashes cp recorded. It reports
+// a reorg when the block at cp.Height no longer has the recorded hash, or
+// when a block below it links to a predecessor other than the recorded
+// one. A block whose hash changed but that the chain still links through
+// is tampering, not a reorg; the bad_hash and prevhash checks report it.
+// It returns nil when there is no checkpoint or no reorg.
+func DetectReorg(r db.BlockReader, cp *db.Checkpoint) *ReorgEvent {
+    if cp == nil {
+        return nil
+    }
+    hashes := cp.Recent
+    if len(hashes) == 0 {
+        hashes = []string{cp.Hash}
+    }
+    // hashes[i] is the hash of height first+i.
+    first := cp.Height - len(hashes) + 1
+    // lastUnchanged is the highest height below height whose block still
+    // has its recorded hash, or first-1 if none does.
+    lastUnchanged := func(height int) int {
+        for h := height - 1; h >= first; h-- {
+            if hash := hashes[h-first]; hash != "" {
+                if block, err := r.LoadBlock(h); err == nil && block.Hash == hash {
+                    return h
+                }
+            }
+        }
+        return first - 1
+    }
+
+    fork := -2
+    if tip, err := r.LoadBlock(cp.Height); err != nil || tip.Hash != cp.Hash {
+        fork = lastUnchanged(cp.Height)
+    } else {
+        for h := first + 1; h <= cp.Height; h++ {
+            prev := hashes[h-1-first]
+            if block, err := r.LoadBlock(h); err == nil && prev != "" && block.PrevHash != prev {
+                fork = lastUnchanged(h - 1)
+                break
+            }
+        }
+    }
+    if fork < -1 {
+        return nil
+    }
+
+    event := &ReorgEvent{
+        CheckpointTime: cp.Time,
+        OldTip:         ChainTip{Height: cp.Height, Hash: cp.Hash},
+        NewTip:         ChainTip{Height: r.GetMaxHeight()},
+        ForkHeight:     fork,
+        Depth:          cp.Height - fork,
+    }
+    if block, err := r.LoadBlock(event.NewTip.Height); err == nil {
+        event.NewTip.Hash = block.Hash
+    }
+    if fork < first && first > 0 {
+        // Blocks older than the checkpoint recorded may have changed too.
+        event.ForkHeight, event.DepthAtLeast = -1, true
+    }
+    return event
+}
+
+// describe is the one-line summary of the event.
+func (e *ReorgEvent) describe() string {
+    depth := fmt.Sprintf("%d", e.Depth)
+    if e.DepthAtLeast {
+        depth = "at least " + depth
+    }
+    fork := "fork point older than the checkpoint"
+    if e.ForkHeight < 0 && !e.DepthAtLeast {
+        fork = "genesis rewritten"
+    }
+    if e.ForkHeight >= 0 {
+        fork = fmt.Sprintf("fork after block %d", e.ForkHeight)
+    }
+    return fmt.Sprintf("%s block(s) rewritten, %s", depth, fork)
+}
+
+// printReorg prints the REORG DETECTED section.
+func printReorg(e *ReorgEvent, opts OutputOptions) {
+    w := opts.Writer()
+    sym := symbolsFor(opts)
+    fmt.Fprintf(w, "\n%s %s\n", colorize(opts, ansiYellow, sym.Warn), colorize(opts, ansiYellow, "REORG DETECTED:"))
+    fmt.Fprintf(w, "  Depth:            %s\n", e.describe())
+    fmt.Fprintf(w, "  Old Tip:          %d (%.16s), verified %s\n", e.OldTip.Height, e.OldTip.Hash,
+        FormatUnix(e.CheckpointTime))
+    fmt.Fprintf(w, "  New Tip:          %d (%.16s)\n", e.NewTip.Height, e.NewTip.Hash)
+}
+
+// OutputReorg prints a reorg found between watch passes.
+func OutputReorg(e *ReorgEvent, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, e, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Reorg: %s | Old Tip: %d | New Tip: %d\n", e.describe(), e.OldTip.Height, e.NewTip.Height)
+        return
+    }
+    printReorg(e, opts)
+    fmt.Fprintln(w, strings.Repeat(symbolsFor(opts).Rule, 66))
+}
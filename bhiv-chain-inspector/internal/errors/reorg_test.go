@@ -0,0 +1,138 @@
+package errors
+
+import (
+    "fmt"
+    "testing"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// memChain is a BlockReader over blocks held in memory.
+type memChain map[int]*blocks.Block
+
+func (c memChain) GetMaxHeight() int {
+    tip := -1
+    for h := range c {
+        tip = max(tip, h)
+    }
+    return tip
+}
+
+func (c memChain) LoadBlock(height int) (*blocks.Block, error) {
+    if block, ok := c[height]; ok {
+        copied := *block
+        return &copied, nil
+    }
+    return nil, db.ErrBlockMissing
+}
+
+// extend mines blocks from height through tip onto c, linked to the
+// block below, with data tagged by branch.
+func (c memChain) extend(height, tip int, branch string) memChain {
+    for h := height; h <= tip; h++ {
+        prev := "0"
+        if below, ok := c[h-1]; ok {
+            prev = below.Hash
+        }
+        block := &blocks.Block{Height: h, PrevHash: prev, Data: fmt.Sprintf("%s %d", branch, h), Timestamp: 1704067200 + int64(h)}
+        block.MineWith(blocks.SHA256)
+        c[h] = block
+    }
+    return c
+}
+
+func (c memChain) clone() memChain {
+    copied := make(memChain, len(c))
+    for h, block := range c {
+        b := *block
+        copied[h] = &b
+    }
+    return copied
+}
+
+func TestDetectReorg(t *testing.T) {
+    chain := memChain{}.extend(0, 29, "main")
+    tests := []struct {
+        name    string
+        cp      *db.Checkpoint
+        chain   func() memChain
+        // fork is -2 when no reorg is expected.
+        fork    int
+        depth   int
+        atLeast bool
+    }{
+        {name: "unchanged", chain: chain.clone, fork: -2},
+        {name: "extended", chain: func() memChain { return chain.clone().extend(30, 34, "main") }, fork: -2},
+        {name: "stored hash tampered below the tip", fork: -2, chain: func() memChain {
+            c := chain.clone()
+            c[5].Hash = "ffff"
+            return c
+        }},
+        {name: "block data tampered below the tip", fork: -2, chain: func() memChain {
+            c := chain.clone()
+            c[5].Data = "forged"
+            return c
+        }},
+        {name: "block deleted below the tip", fork: -2, chain: func() memChain {
+            c := chain.clone()
+            delete(c, 12)
+            return c
+        }},
+        {name: "tip replaced", fork: 28, depth: 1, chain: func() memChain {
+            return chain.clone().extend(29, 29, "fork")
+        }},
+        {name: "rebuilt from block 20", fork: 19, depth: 10, chain: func() memChain {
+            return chain.clone().extend(20, 32, "fork")
+        }},
+        {name: "rebuilt from block 20 over a tampered block", fork: 19, depth: 10, chain: func() memChain {
+            c := chain.clone().extend(20, 29, "fork")
+            c[5].Hash = "ffff"
+            return c
+        }},
+        {name: "tip truncated", fork: 24, depth: 5, chain: func() memChain {
+            c := chain.clone()
+            for h := 25; h <= 29; h++ {
+                delete(c, h)
+            }
+            return c
+        }},
+        {name: "relinked below an intact tip", fork: 9, depth: 20, chain: func() memChain {
+            c := chain.clone().extend(10, 10, "fork")
+            c[11].PrevHash = c[10].Hash
+            return c
+        }},
+        {name: "genesis rewritten", fork: -1, depth: 30, chain: func() memChain {
+            return memChain{}.extend(0, 29, "fork")
+        }},
+        {name: "fork older than the checkpoint", fork: -1, depth: 10, atLeast: true,
+            cp: checkpointOfLast(chain, 29, 10), chain: func() memChain {
+                return chain.clone().extend(10, 29, "fork")
+            }},
+    }
+    for _, tt := range tests {
+        cp := tt.cp
+        if cp == nil {
+            cp = NewCheckpoint(chain, 29)
+        }
+        event := DetectReorg(tt.chain(), cp)
+        switch {
+        case tt.fork == -2 && event != nil:
+            t.Errorf("%s: reorg reported: %s", tt.name, event.describe())
+        case tt.fork == -2:
+        case event == nil:
+            t.Errorf("%s: no reorg reported", tt.name)
+        case event.ForkHeight != tt.fork || event.Depth != tt.depth || event.DepthAtLeast != tt.atLeast:
+            t.Errorf("%s: fork %d, depth %d (at least: %v), want fork %d, depth %d (at least: %v)",
+                tt.name, event.ForkHeight, event.Depth, event.DepthAtLeast, tt.fork, tt.depth, tt.atLeast)
+        }
+    }
+}
+
+// checkpointOfLast is NewCheckpoint keeping only the last n hashes,
+// as a checkpoint of a chain longer than db.CheckpointHashes does.
+func checkpointOfLast(r db.BlockReader, height, n int) *db.Checkpoint {
+    cp := NewCheckpoint(r, height)
+    cp.Recent = cp.Recent[len(cp.Recent)-n:]
+    return cp
+}
@@ -0,0 +1,131 @@
+package errors
+
+import (
+    "bytes"
+    "crypto/ed25519"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    stderrors "errors"
+    "fmt"
+    "os"
+    "strings"
+)
+
+// ReportSignature is the detached signature of a JSON report: an ed25519
+// signature of the report's exact bytes, kept in a file beside it.
+type ReportSignature struct {
+    Algorithm    string `json:"algorithm"`
+    PublicKey    string `json:"public_key"`
+    ReportSHA256 string `json:"report_sha256"`
+    Signature    string `json:"signature"`
+}
+
+// SignReport signs report with key.
+func SignReport(report []byte, key ed25519.PrivateKey) ReportSignature {
+    sum := sha256.Sum256(report)
+    return ReportSignature{
+        Algorithm:    "ed25519",
+        PublicKey:    hex.EncodeToString(key.Public().(ed25519.PublicKey)),
+        ReportSHA256: hex.EncodeToString(sum[:]),
+        Signature:    hex.EncodeToString(ed25519.Sign(key, report)),
+    }
+}
+
+// WriteReportSignature writes sig to path as indented JSON.
+func WriteReportSignature(path string, sig ReportSignature) error {
+    data, _ := json.MarshalIndent(sig, "", "  ")
+    return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadReportSignature reads a signature file written by
+// WriteReportSignature.
+func LoadReportSignature(path string) (ReportSignature, error) {
+    var sig ReportSignature
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return sig, err
+    }
+    if err := json.Unmarshal(data, &sig); err != nil {
+        return sig, fmt.Errorf("%s: %w", path, err)
+    }
+    return sig, nil
+}
+
+// ReportVerification is the output of verify-report. Trusted is set when
+// the signing key was checked against a key the auditor supplied; without
+// one, a valid signature only shows the report matches the key named in
+// the signature file.
+type ReportVerification struct {
+    Report    string `json:"report"`
+    Signature string `json:"signature"`
+    PublicKey string `json:"public_key"`
+    Trusted   bool   `json:"trusted"`
+    Valid     bool   `json:"valid"`
+    Error     string `json:"error,omitempty"`
+}
+
+// CheckReportSignature checks sig against the bytes of report. A non-nil
+// trusted key must be the one that signed.
+func CheckReportSignature(report []byte, sig ReportSignature, trusted ed25519.PublicKey) error {
+    if sig.Algorithm != "ed25519" {
+        return fmt.Errorf("unsupported signature algorithm %q", sig.Algorithm)
+    }
+    key, err := hex.DecodeString(sig.PublicKey)
+    if err != nil || len(key) != ed25519.PublicKeySize {
+        return stderrors.New("public_key is not a hex-encoded ed25519 key")
+    }
+    if trusted != nil && !bytes.Equal(key, trusted) {
+        return fmt.Errorf("signed by %s, not the trusted key", sig.PublicKey)
+    }
+    signature, err := hex.DecodeString(sig.Signature)
+    if err != nil || len(signature) != ed25519.SignatureSize {
+        return stderrors.New("signature is not a hex-encoded ed25519 signature")
+    }
+    if !ed25519.Verify(key, report, signature) {
+        if sum := sha256.Sum256(report); !strings.EqualFold(hex.EncodeToString(sum[:]), sig.ReportSHA256) {
+            return stderrors.New("the report was altered after it was signed")
+        }
+        return stderrors.New("bad signature")
+    }
+    return nil
+}
+
+// OutputReportVerification prints the verdict of verify-report.
+func OutputReportVerification(v *ReportVerification, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, v, opts) {
+        return
+    }
+    verdict := "VALID"
+    if !v.Valid {
+        verdict = "INVALID"
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Report: %s | Signature: %s | Trusted key: %t\n", v.Report, verdict, v.Trusted)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "REPORT SIGNATURE")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Report:           %s\n", v.Report)
+    fmt.Fprintf(w, "  Signature:        %s\n", v.Signature)
+    fmt.Fprintf(w, "  Signed by:        %s\n", v.PublicKey)
+    if v.Error != "" {
+        fmt.Fprintf(w, "  Error:            %s\n", v.Error)
+    }
+
+    fmt.Fprintln(w)
+    switch {
+    case !v.Valid:
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiRed, sym.Fail), colorize(opts, ansiRed, "SIGNATURE INVALID"))
+    case !v.Trusted:
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiYellow, sym.Warn),
+            colorize(opts, ansiYellow, "SIGNATURE VALID, signer not checked (pass --public-key)"))
+    default:
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiGreen, sym.OK), colorize(opts, ansiGreen, "SIGNATURE VALID"))
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
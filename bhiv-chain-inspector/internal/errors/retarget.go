@@ -0,0 +1,101 @@
+package errors
+
+import (
+    "fmt"
+    "math"
+    "strconv"
+
+    "bhiv-chain-inspector/internal/blocks"
+)
+
+// ClassDifficultyErrors is reported for blocks whose difficulty does not
+// follow the configured retarget rule.
+const ClassDifficultyErrors = "difficulty_errors"
+
+// RetargetRule is the difficulty adjustment rule of a proof-of-work chain.
+// Difficulty stays constant within each window of Interval blocks. The
+// first block of a window (heights that are multiples of Interval) moves
+// it by log2(expected / actual) bits, rounded, where actual is the time
+// the previous window's blocks took, from its first block to its last,
+// and expected is (Interval-1) * TargetBlockTime. The step is clamped to
+// MaxStep bits, and difficulty never drops below 1.
+type RetargetRule struct {
+    Interval        int
+    TargetBlockTime int64 // seconds
+    MaxStep         int
+}
+
+// DefaultRetargetMaxStep is the MaxStep used when a rule leaves it zero.
+const DefaultRetargetMaxStep = 2
+
+// NewRetargetCheck returns a check that verifies rule. Register it with
+// RegisterCheck.
+func NewRetargetCheck(rule RetargetRule) (Check, error) {
+    if rule.Interval < 2 {
+        return nil, fmt.Errorf("retarget interval must be at least 2, got %d", rule.Interval)
+    }
+    if rule.TargetBlockTime <= 0 {
+        return nil, fmt.Errorf("retarget target block time must be positive, got %d", rule.TargetBlockTime)
+    }
+    if rule.MaxStep == 0 {
+        rule.MaxStep = DefaultRetargetMaxStep
+    }
+    if rule.MaxStep < 0 {
+        return nil, fmt.Errorf("retarget max step must be positive, got %d", rule.MaxStep)
+    }
+    return retargetCheck{rule}, nil
+}
+
+type retargetCheck struct {
+    rule RetargetRule
+}
+
+func (retargetCheck) Name() string { return "retarget" }
+
+func (retargetCheck) Classes() []string { return []string{ClassDifficultyErrors} }
+
+func (c retargetCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    // Chains without proof of work, and blocks after a gap, are skipped.
+    prev := ctx.Prev
+    if prev == nil || prev.Difficulty == 0 || ctx.Height == 0 {
+        return nil
+    }
+    want, err := c.expected(prev, ctx)
+    if err != nil {
+        return []Finding{{Class: ClassDifficultyErrors, Message: fmt.Sprintf("Block %d: Cannot check retarget: %v", ctx.Height, err)}}
+    }
+    if block.Difficulty != want {
+        return []Finding{{Class: ClassDifficultyErrors, Message: fmt.Sprintf("Block %d: Difficulty %d, retarget rule requires %d",
+            ctx.Height, block.Difficulty, want), Expected: strconv.Itoa(want), Actual: strconv.Itoa(block.Difficulty)}}
+    }
+    return nil
+}
+
+// expected returns the difficulty the block at ctx.Height must declare.
+func (c retargetCheck) expected(prev *blocks.Block, ctx *CheckContext) (int, error) {
+    n := c.rule.Interval
+    if ctx.Height%n != 0 {
+        return prev.Difficulty, nil
+    }
+    first, err := ctx.Chain.LoadBlock(ctx.Height - n)
+    if err != nil {
+        return 0, err
+    }
+    actual := prev.Timestamp - first.Timestamp
+    expected := int64(n-1) * c.rule.TargetBlockTime
+
+    step := c.rule.MaxStep
+    if actual > 0 {
+        step = int(math.Round(math.Log2(float64(expected) / float64(actual))))
+    }
+    if step > c.rule.MaxStep {
+        step = c.rule.MaxStep
+    }
+    if step < -c.rule.MaxStep {
+        step = -c.rule.MaxStep
+    }
+    if d := prev.Difficulty + step; d > 1 {
+        return d, nil
+    }
+    return 1, nil
+}
@@ -1,13 +1,18 @@
 package errors
 
 import (
+    "context"
     "encoding/json"
     "fmt"
-    "strings"
+    "log/slog"
+    "maps"
+    "slices"
+    "strconv"
     "time"
 
     "bhiv-chain-inspector/internal/blocks"
     "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/tracing"
 )
 
 type ErrorScanResult struct {
@@ -15,29 +20,297 @@ type ErrorScanResult struct {
     DatabasePath            string   `json:"database_path"`
     TotalBlocks             int      `json:"total_blocks"`
     BlocksScanned           int      `json:"blocks_scanned"`
+    HeightsChecked          int      `json:"heights_checked"`
     TotalErrors             int      `json:"total_errors"`
-    CorruptedJSON           []string `json:"corrupted_json"`
-    BadHash                 []string `json:"bad_hash"`
-    TimestampFuture         []string `json:"timestamp_future"`
-    TimestampPast           []string `json:"timestamp_past"`
-    TimestampNotIncreasing  []string `json:"timestamp_not_increasing"`
-    DuplicateHashes         []string `json:"duplicate_hashes"`
-    EmptyBlocks             []string `json:"empty_blocks"`
-    PrevHashErrors          []string `json:"prevhash_errors"`
-    HeightErrors            []string `json:"height_errors"`
+    TotalWarnings           int      `json:"total_warnings"`
+    TotalInfo               int      `json:"total_info"`
+    Suppressed              int      `json:"suppressed"`
+    CorruptedJSON           []ErrorEntry `json:"corrupted_json"`
+    BadHash                 []ErrorEntry `json:"bad_hash"`
+    TimestampFuture         []ErrorEntry `json:"timestamp_future"`
+    TimestampPast           []ErrorEntry `json:"timestamp_past"`
+    TimestampNotIncreasing  []ErrorEntry `json:"timestamp_not_increasing"`
+    DuplicateHashes         []ErrorEntry `json:"duplicate_hashes"`
+    EmptyBlocks             []ErrorEntry `json:"empty_blocks"`
+    PrevHashErrors          []ErrorEntry `json:"prevhash_errors"`
+    HeightErrors            []ErrorEntry `json:"height_errors"`
     MissingBlocks           []int    `json:"missing_blocks"`
-    OutOfOrderBlocks        []string `json:"out_of_order_blocks"`
+    OutOfOrderBlocks        []ErrorEntry `json:"out_of_order_blocks"`
     HealthScore             int      `json:"health_score"`
     Status                  string   `json:"status"`
+    // Severities lists classes that were downgraded from error.
+    Severities              map[string]string `json:"severities,omitempty"`
+    // Custom holds findings of classes declared by registered checks.
+    Custom                  map[string][]ErrorEntry `json:"custom,omitempty"`
+    // Checks lists the checks that ran.
+    Checks                  []string          `json:"checks,omitempty"`
+    // HashAlgorithm is the algorithm block hashes were checked with.
+    HashAlgorithm           string            `json:"hash_algorithm,omitempty"`
+    // HashDetection is set when HashAlgorithm was detected, not given.
+    HashDetection           *HashDetection    `json:"hash_detection,omitempty"`
+    // Encodings counts the blocks decoded in each detected encoding; it
+    // is only set when the storage sniffs encodings (db.EncodingAuto).
+    Encodings               map[string]int    `json:"encodings,omitempty"`
+    // BlockSizes summarises the stored size of every block value read.
+    BlockSizes              *db.BlockSizes    `json:"block_sizes,omitempty"`
+    // Reorg is set when blocks verified by an earlier scan were rewritten;
+    // see DetectReorg.
+    Reorg                   *ReorgEvent       `json:"reorg,omitempty"`
+    // Baseline is set when the scan was diffed against a previous report.
+    Baseline                *BaselineDiff     `json:"baseline,omitempty"`
+    // CacheHits counts the blocks whose checks ScanOptions.Cache skipped.
+    CacheHits               int               `json:"cache_hits,omitempty"`
+    // Spilled is set when findings beyond the memory limit of
+    // ScanOptions.Spill were written to its file instead of the lists.
+    Spilled                 *SpillSummary     `json:"spilled,omitempty"`
+    // Groups and Omitted are set by Summarize.
+    Groups                  []IssueGroup      `json:"groups,omitempty"`
+    Omitted                 map[string]int    `json:"omitted,omitempty"`
 }
 
-func ScanErrors(storage *db.Storage, dbPath string) *ErrorScanResult {
+// SeverityOf returns the severity the scan applied to class.
+func (r *ErrorScanResult) SeverityOf(class string) Severity {
+    if sev, ok := r.Severities[class]; ok {
+        return Severity(sev)
+    }
+    return SeverityError
+}
+
+// Issues flattens the per-class lists into a single list, in the order of
+// the classification table.
+func (r *ErrorScanResult) Issues() []Issue {
+    var issues []Issue
+    r.eachEntry(func(class string, e ErrorEntry) {
+        issues = append(issues, Issue{Class: class, Code: CodeOf(class), Severity: r.SeverityOf(class), Message: e.Message})
+    })
+    return issues
+}
+
+// eachEntry calls fn with every finding, in the order of the
+// classification table.
+func (r *ErrorScanResult) eachEntry(fn func(class string, e ErrorEntry)) {
+    add := func(class string, entries []ErrorEntry) {
+        for _, e := range entries {
+            fn(class, e)
+        }
+    }
+    add(ClassCorruptedJSON, r.CorruptedJSON)
+    add(ClassBadHash, r.BadHash)
+    add(ClassTimestampFuture, r.TimestampFuture)
+    add(ClassTimestampPast, r.TimestampPast)
+    add(ClassTimestampNotIncreasing, r.TimestampNotIncreasing)
+    add(ClassDuplicateHashes, r.DuplicateHashes)
+    add(ClassEmptyBlocks, r.EmptyBlocks)
+    add(ClassPrevHashErrors, r.PrevHashErrors)
+    add(ClassHeightErrors, r.HeightErrors)
+    for _, h := range r.MissingBlocks {
+        add(ClassMissingBlocks, []ErrorEntry{{Height: h, Code: CodeOf(ClassMissingBlocks), Message: fmt.Sprintf("Block %d: Missing", h)}})
+    }
+    add(ClassOutOfOrderBlocks, r.OutOfOrderBlocks)
+
+    for _, class := range sortedKeys(r.Custom) {
+        add(class, r.Custom[class])
+    }
+}
+
+// Columns and Rows make the findings of a scan a Table, one row each.
+func (r *ErrorScanResult) Columns() []string {
+    return []string{"height", "class", "code", "severity", "expected", "actual", "message"}
+}
+
+func (r *ErrorScanResult) Rows() [][]any {
+    rows := [][]any{}
+    r.eachEntry(func(class string, e ErrorEntry) {
+        rows = append(rows, []any{e.Height, class, CodeOf(class), r.SeverityOf(class), e.Expected, e.Actual, e.Message})
+    })
+    return rows
+}
+
+// add appends e to the list for class.
+func (r *ErrorScanResult) add(class string, e ErrorEntry) {
+    if list := r.list(class); list != nil {
+        *list = append(*list, e)
+        return
+    }
+    if r.Custom == nil {
+        r.Custom = make(map[string][]ErrorEntry)
+    }
+    r.Custom[class] = append(r.Custom[class], e)
+}
+
+// listed returns the number of findings of class in its list.
+func (r *ErrorScanResult) listed(class string) int {
+    if list := r.list(class); list != nil {
+        return len(*list)
+    }
+    if class == ClassMissingBlocks {
+        return len(r.MissingBlocks)
+    }
+    return len(r.Custom[class])
+}
+
+// customClasses lists the classes of registered checks that have
+// findings, whether listed or only counted in Omitted.
+func (r *ErrorScanResult) customClasses() []string {
+    classes := sortedKeys(r.Custom)
+    for _, class := range sortedKeys(r.Omitted) {
+        if r.list(class) == nil && class != ClassMissingBlocks && r.Custom[class] == nil {
+            classes = append(classes, class)
+        }
+    }
+    slices.Sort(classes)
+    return classes
+}
+
+// list returns the entry list of a built-in class, or nil for missing
+// blocks (kept as heights) and custom classes.
+func (r *ErrorScanResult) list(class string) *[]ErrorEntry {
+    switch class {
+    case ClassCorruptedJSON:
+        return &r.CorruptedJSON
+    case ClassBadHash:
+        return &r.BadHash
+    case ClassTimestampFuture:
+        return &r.TimestampFuture
+    case ClassTimestampPast:
+        return &r.TimestampPast
+    case ClassTimestampNotIncreasing:
+        return &r.TimestampNotIncreasing
+    case ClassDuplicateHashes:
+        return &r.DuplicateHashes
+    case ClassEmptyBlocks:
+        return &r.EmptyBlocks
+    case ClassPrevHashErrors:
+        return &r.PrevHashErrors
+    case ClassHeightErrors:
+        return &r.HeightErrors
+    case ClassOutOfOrderBlocks:
+        return &r.OutOfOrderBlocks
+    }
+    return nil
+}
+
+// Error classes, named after the JSON keys of the lists they populate.
+const (
+    ClassCorruptedJSON          = "corrupted_json"
+    ClassBadHash                = "bad_hash"
+    ClassTimestampFuture        = "timestamp_future"
+    ClassTimestampPast          = "timestamp_past"
+    ClassTimestampNotIncreasing = "timestamp_not_increasing"
+    ClassDuplicateHashes        = "duplicate_hashes"
+    ClassEmptyBlocks            = "empty_blocks"
+    ClassPrevHashErrors         = "prevhash_errors"
+    ClassHeightErrors           = "height_errors"
+    ClassMissingBlocks          = "missing_blocks"
+    ClassOutOfOrderBlocks       = "out_of_order_blocks"
+)
+
+// Issue is a single problem found at one height.
+type Issue struct {
+    Class    string   `json:"class"`
+    Code     Code     `json:"code"`
+    Severity Severity `json:"severity"`
+    Message  string   `json:"message"`
+}
+
+// ErrorEntry is a finding as a scan reports it: the height it was found
+// at (-1 for findings about the database as a whole), the code of its
+// class, and the expected and actual values when the check gives them.
+// Message is the line text output prints.
+type ErrorEntry struct {
+    Height   int    `json:"height"`
+    Code     Code   `json:"code"`
+    Expected string `json:"expected,omitempty"`
+    Actual   string `json:"actual,omitempty"`
+    Message  string `json:"message"`
+}
+
+// UnmarshalJSON also reads the plain message strings of reports written
+// before entries were structured, taking the height from the message.
+func (e *ErrorEntry) UnmarshalJSON(data []byte) error {
+    var msg string
+    if json.Unmarshal(data, &msg) == nil {
+        *e = ErrorEntry{Height: -1, Message: msg}
+        if m := blockMessage.FindStringSubmatch(msg); m != nil {
+            e.Height, _ = strconv.Atoi(m[1])
+        }
+        return nil
+    }
+    type entry ErrorEntry
+    return json.Unmarshal(data, (*entry)(e))
+}
+
+// BlockVerdict describes the outcome of checking a single height.
+type BlockVerdict struct {
+    Height int     `json:"height"`
+    Issues []Issue `json:"issues"`
+}
+
+// ScanOptions tunes a scan without changing what is detected.
+type ScanOptions struct {
+    // OnBlock, when set, is called once for every height visited.
+    OnBlock func(BlockVerdict)
+    // Severity downgrades error classes to warnings or info.
+    Severity SeverityMap
+    // Suppress drops accepted findings entirely; they are only counted.
+    Suppress Suppressions
+    // Health weighs findings into the health score; nil means the default.
+    Health *HealthModel
+    // Checks selects the checks to run; nil runs every registered check.
+    Checks []Check
+    // Context parents the scan's trace spans; nil starts a new trace. Once
+    // it is done the scan stops before the next height, with status
+    // CANCELLED.
+    Context context.Context
+    // FromHeight starts the scan at this height instead of genesis, e.g. to
+    // check only the tip region. Duplicate hashes are then only detected
+    // within the scanned range.
+    FromHeight int
+    // Heights, when positive, scans only this many heights from FromHeight
+    // instead of running to the tip.
+    Heights int
+    // Tip, when positive, is the chain's highest height as the caller
+    // already found it; ScanErrors then does not walk the keys again.
+    Tip int
+    // SkipStoreChecks leaves out the checks of the whole database (keys,
+    // index, orphans), whose cost does not depend on the heights scanned.
+    SkipStoreChecks bool
+    // ChainID, when set, overrides the chain ID the database declares.
+    ChainID string
+    // HashAlgorithm checks block hashes with this algorithm; empty
+    // detects it from a sample of blocks (see DetectHashAlgorithm).
+    HashAlgorithm blocks.HashAlgorithm
+    // NearDuplicates, when positive, is the similarity (0-1) at which the
+    // data check reports a block's Data as a near duplicate of an earlier
+    // block's; see DataIndex.
+    NearDuplicates float64
+    // Spill, when set, bounds the memory spent on findings; those beyond
+    // its limit go to its file. The caller closes it after the scan.
+    Spill *FindingSpill
+    // Cache, when set, skips the checks of blocks a previous scan found
+    // clean. ScanErrors only uses it for scans of the whole chain.
+    Cache *VerifyCache
+}
+
+func ScanErrors(storage *db.Storage, dbPath string, opts ScanOptions) *ErrorScanResult {
     result := &ErrorScanResult{
-        ScanTime:     time.Now().Format("2006-01-02 15:04:05"),
+        ScanTime:     FormatTime(time.Now()),
         DatabasePath: dbPath,
+        Severities:   opts.Severity.Overrides(),
     }
 
-    height := storage.GetMaxHeight()
+    traceCtx, span := tracing.Start(opts.Context, "ScanErrors", "db", dbPath)
+    defer func() {
+        span.SetAttributes("blocks", result.BlocksScanned, "errors", result.TotalErrors,
+            "health_score", result.HealthScore, "status", result.Status)
+        span.End()
+    }()
+    storage = storage.WithContext(traceCtx)
+
+    height := opts.Tip
+    if height <= 0 {
+        height = storage.GetMaxHeight()
+    }
     if height < 0 {
         result.Status = "ERROR: Empty database"
         result.HealthScore = 0
@@ -45,126 +318,319 @@ func ScanErrors(storage *db.Storage, dbPath string) *ErrorScanResult {
     }
 
     result.TotalBlocks = height + 1
-    seenHashes := make(map[string]int)
-    var prevBlock *blocks.Block
-    expectedHeight := 0
-    currentTime := time.Now().Unix()
+    opts.FromHeight = max(opts.FromHeight, storage.FirstHeight())
+    scan := newChainScan(opts)
+    if opts.FromHeight > 0 && opts.FromHeight <= height {
+        scan.next = opts.FromHeight
+        scan.ctx.ExpectedHeight = opts.FromHeight
+        if prev, err := storage.LoadBlock(opts.FromHeight - 1); err == nil {
+            scan.ctx.Prev = prev
+        }
+    }
+    if opts.Heights > 0 && opts.FromHeight+opts.Heights-1 < height {
+        height = opts.FromHeight + opts.Heights - 1
+    }
+    if opts.Cache != nil {
+        if opts.FromHeight > storage.FirstHeight() || opts.Heights > 0 {
+            slog.Debug("verification cache not used for a partial scan", "path", opts.Cache.Path)
+        } else {
+            scan.cache = opts.Cache
+        }
+    }
+    scan.run(storage, result, height)
+    return result
+}
 
-    for i := 0; i <= height+10; i++ {
-        rawData, rawErr := storage.LoadBlockRaw(i)
-        
-        if rawErr != nil {
-            if i <= height {
-                result.MissingBlocks = append(result.MissingBlocks, i)
-                result.TotalErrors++
-            }
-            if i > height {
-                break
-            }
-            continue
+// chainScan is the state carried from one height to the next. ScanErrors
+// uses it for a single pass; a Watcher keeps it between passes so each
+// pass only visits heights appended since the last one.
+type chainScan struct {
+    opts   ScanOptions
+    checks []Check
+    health HealthModel
+    ctx    *CheckContext
+    // next is the first height the next run visits.
+    next   int
+    // detection is the hash algorithm detection of the first run.
+    detection *HashDetection
+    // storeChecked is set once the StoreChecks have run.
+    storeChecked bool
+    // sizes are the stored sizes of the values read so far.
+    sizes  []db.BlockSize
+    // cache, when set, is used and rewritten by the next run.
+    cache  *VerifyCache
+}
+
+func newChainScan(opts ScanOptions) *chainScan {
+    s := &chainScan{
+        opts:   opts,
+        checks: opts.Checks,
+        health: DefaultHealthModel(),
+        ctx: &CheckContext{
+            SeenHashes: make(map[string]int),
+            SeenData:   newDataIndex(opts.NearDuplicates),
+        },
+    }
+    if s.checks == nil {
+        s.checks = Checks()
+    }
+    if opts.Health != nil {
+        s.health = *opts.Health
+    }
+    return s
+}
+
+// layoutSkips names the checks that do not apply to blocks read through a
+// foreign layout, which fills in only some Block fields: geth and CometBFT
+// headers carry no Data, and are hashed over fields the Block does not
+// keep. Their keyspaces are not the inspector's either.
+var layoutSkips = map[db.Layout][]string{
+    db.LayoutGeth:     {"hash", "empty", "keys", "index", "orphans", "seals"},
+    db.LayoutCometBFT: {"hash", "empty", "keys", "index", "orphans", "seals"},
+}
+
+// skipForLayout drops the checks of layoutSkips[layout] from checks.
+func skipForLayout(checks []Check, layout db.Layout) []Check {
+    skip := layoutSkips[layout]
+    if len(skip) == 0 {
+        return checks
+    }
+    var kept []Check
+    for _, check := range checks {
+        if !slices.Contains(skip, check.Name()) {
+            kept = append(kept, check)
         }
+    }
+    return kept
+}
+
+// runs reports whether the scan runs the check called name.
+func (s *chainScan) runs(name string) bool {
+    for _, check := range s.checks {
+        if check.Name() == name {
+            return true
+        }
+    }
+    return false
+}
 
-        var block blocks.Block
-        err := json.Unmarshal(rawData, &block)
+// run visits heights from s.next up to tip. It fills in result's findings,
+// counters, health score and status.
+func (s *chainScan) run(storage *db.Storage, result *ErrorScanResult, tip int) {
+    opts, ctx := s.opts, s.ctx
+    if opts.Checks == nil {
+        s.checks = skipForLayout(s.checks, storage.Layout())
+    }
+    if opts.SkipStoreChecks {
+        s.checks = slices.DeleteFunc(slices.Clone(s.checks), func(check Check) bool {
+            _, ok := check.(StoreCheck)
+            return ok
+        })
+    }
+    for _, check := range s.checks {
+        result.Checks = append(result.Checks, check.Name())
+    }
+    ctx.Now = time.Now().Unix()
+    ctx.Chain = storage
+    if ctx.ChainID = opts.ChainID; ctx.ChainID == "" {
+        id, err := storage.ChainID()
         if err != nil {
-            errMsg := fmt.Sprintf("Block %d: Corrupted JSON - %v", i, err)
-            result.CorruptedJSON = append(result.CorruptedJSON, errMsg)
-            result.TotalErrors++
-            continue
+            slog.Warn("cannot read declared chain ID", "err", err)
+        }
+        ctx.ChainID = id
+    }
+    if ctx.HashAlgorithm == "" && s.runs("hash") {
+        ctx.HashAlgorithm = opts.HashAlgorithm
+        if ctx.HashAlgorithm == "" {
+            s.detection = DetectHashAlgorithm(storage, HashSampleSize)
+            ctx.HashAlgorithm = s.detection.Algorithm
+            if ctx.HashAlgorithm == "" {
+                slog.Warn("no hash algorithm matches the sampled blocks, checking with sha256",
+                    "sampled", s.detection.Sampled)
+                ctx.HashAlgorithm = blocks.SHA256
+            }
         }
+    }
+    if ctx.HashAlgorithm != "" {
+        result.HashAlgorithm = ctx.HashAlgorithm.String()
+    }
+    result.HashDetection = s.detection
 
-        result.BlocksScanned++
+    var cache *cacheRun
+    if s.cache != nil {
+        cache = s.cache.start(s.cacheSettings(storage, s.cache.Settings), s.next)
+        defer func() {
+            cache.finish()
+            result.CacheHits = cache.hits
+        }()
+    }
 
-        // Hash validation
-        computedHash := blocks.ComputeHash(block.Height, block.PrevHash, block.Data, block.Timestamp)
-        if block.Hash != computedHash {
-            errMsg := fmt.Sprintf("Block %d: Bad hash", i)
-            result.BadHash = append(result.BadHash, errMsg)
+    var issues []Issue
+    // found is set once the height being visited has a finding, even a
+    // suppressed one; only heights without are cached as clean.
+    found := false
+    count := func(class string) Severity {
+        sev := opts.Severity.Of(class)
+        switch sev {
+        case SeverityWarning:
+            result.TotalWarnings++
+        case SeverityInfo:
+            result.TotalInfo++
+        default:
             result.TotalErrors++
         }
-
-        // Duplicate detection
-        if firstHeight, exists := seenHashes[block.Hash]; exists {
-            errMsg := fmt.Sprintf("Block %d duplicates hash from Block %d", i, firstHeight)
-            result.DuplicateHashes = append(result.DuplicateHashes, errMsg)
-            result.TotalErrors++
-        } else {
-            seenHashes[block.Hash] = i
+        return sev
+    }
+    record := func(height int, f Finding) {
+        found = true
+        if opts.Suppress.Matches(height, f.Class) {
+            result.Suppressed++
+            return
+        }
+        code, sev := CodeOf(f.Class), count(f.Class)
+        entry := ErrorEntry{Height: height, Code: code, Expected: f.Expected, Actual: f.Actual, Message: f.Message}
+        if opts.Spill == nil || opts.Spill.keep(result, f.Class, sev, entry) {
+            result.add(f.Class, entry)
         }
+        issues = append(issues, Issue{Class: f.Class, Code: code, Severity: sev, Message: f.Message})
+    }
+    penalty := 0.0
+    // storeIssues are the issues store checks found at each height; they
+    // weigh on that height's penalty when it is visited.
+    storeIssues := map[int][]Issue{}
+    report := func(i int) {
+        result.HeightsChecked++
+        issues = append(issues, storeIssues[i]...)
+        delete(storeIssues, i)
+        penalty += s.health.blockPenalty(issues)
+        if opts.OnBlock != nil {
+            opts.OnBlock(BlockVerdict{Height: i, Issues: issues})
+        }
+        if cache != nil {
+            cache.record(!found)
+        }
+        issues, found = nil, false
+    }
 
-        // Timestamp future
-        if block.Timestamp > currentTime+300 {
-            errMsg := fmt.Sprintf("Block %d: Timestamp in future", i)
-            result.TimestampFuture = append(result.TimestampFuture, errMsg)
-            result.TotalErrors++
+    if !s.storeChecked {
+        s.storeChecked = true
+        for _, check := range s.checks {
+            if sc, ok := check.(StoreCheck); ok {
+                for _, f := range sc.ValidateStore(storage) {
+                    record(f.Height, f.Finding)
+                    storeIssues[f.Height] = append(storeIssues[f.Height], issues...)
+                    issues = nil
+                }
+            }
         }
+        found = false
+    }
 
-        // Timestamp past
-        tenYearsAgo := currentTime - (10 * 365 * 24 * 60 * 60)
-        if block.Timestamp < tenYearsAgo {
-            errMsg := fmt.Sprintf("Block %d: Timestamp too old", i)
-            result.TimestampPast = append(result.TimestampPast, errMsg)
-            result.TotalErrors++
+    cancelled := false
+    i := s.next
+    for ; i <= tip; i++ {
+        if opts.Context != nil && opts.Context.Err() != nil {
+            cancelled = true
+            break
         }
+        rawData, rawErr := storage.LoadBlockRaw(i)
+        
+        if rawErr != nil {
+            if cache != nil {
+                cache.next(i, nil)
+            }
+            found = true
+            if opts.Suppress.Matches(i, ClassMissingBlocks) {
+                result.Suppressed++
+            } else {
+                sev, msg := count(ClassMissingBlocks), fmt.Sprintf("Block %d: Missing", i)
+                entry := ErrorEntry{Height: i, Code: CodeMissingBlock, Message: msg}
+                if opts.Spill == nil || opts.Spill.keep(result, ClassMissingBlocks, sev, entry) {
+                    result.MissingBlocks = append(result.MissingBlocks, i)
+                }
+                issues = append(issues, Issue{
+                    Class:    ClassMissingBlocks,
+                    Code:     CodeMissingBlock,
+                    Severity: sev,
+                    Message:  msg,
+                })
+            }
+            report(i)
+            ctx.Prev = nil
+            ctx.ExpectedHeight++
+            continue
+        }
+        s.sizes = append(s.sizes, db.BlockSize{Height: i, Size: len(rawData)})
+        // A cached block is still decoded, for the state later checks use.
+        cached := cache != nil && cache.next(i, rawData)
 
-        // Timestamp not increasing
-        if prevBlock != nil && block.Timestamp <= prevBlock.Timestamp {
-            errMsg := fmt.Sprintf("Block %d: Timestamp not increasing", i)
-            result.TimestampNotIncreasing = append(result.TimestampNotIncreasing, errMsg)
-            result.TotalErrors++
+        for _, check := range s.checks {
+            if rc, ok := check.(RawCheck); ok && !cached {
+                for _, f := range rc.ValidateRaw(storage, i, rawData) {
+                    record(i, f)
+                }
+            }
         }
 
-        // Empty blocks
-        if block.Data == "" || len(strings.TrimSpace(block.Data)) == 0 {
-            errMsg := fmt.Sprintf("Block %d: Empty block", i)
-            result.EmptyBlocks = append(result.EmptyBlocks, errMsg)
-            result.TotalErrors++
+        block, encoding, err := storage.DecodeBlock(rawData)
+        if err != nil {
+            errMsg := fmt.Sprintf("Block %d: Corrupted %s - %v", i, encoding.Label(), err)
+            record(i, Finding{Class: ClassCorruptedJSON, Message: errMsg})
+            report(i)
+            ctx.Prev = nil
+            ctx.ExpectedHeight++
+            continue
         }
 
-        // PrevHash validation
-        if i == 0 {
-            if block.PrevHash != "0" {
-                errMsg := fmt.Sprintf("Block 0: Invalid genesis prevHash")
-                result.PrevHashErrors = append(result.PrevHashErrors, errMsg)
-                result.TotalErrors++
+        result.BlocksScanned++
+        if storage.Encoding() == db.EncodingAuto {
+            if result.Encodings == nil {
+                result.Encodings = make(map[string]int)
             }
-        } else if prevBlock != nil && block.PrevHash != prevBlock.Hash {
-            errMsg := fmt.Sprintf("Block %d: PrevHash linkage broken", i)
-            result.PrevHashErrors = append(result.PrevHashErrors, errMsg)
-            result.TotalErrors++
+            result.Encodings[string(encoding)]++
         }
 
-        // Height validation
-        if block.Height != expectedHeight {
-            errMsg := fmt.Sprintf("Block %d: Height mismatch", i)
-            result.HeightErrors = append(result.HeightErrors, errMsg)
-            result.TotalErrors++
+        ctx.Height = i
+        if !cached {
+            for _, check := range s.checks {
+                for _, f := range check.Validate(block, ctx) {
+                    record(i, f)
+                }
+            }
         }
 
-        // Out of order
-        if block.Height < expectedHeight {
-            errMsg := fmt.Sprintf("Block %d: Out of order", i)
-            result.OutOfOrderBlocks = append(result.OutOfOrderBlocks, errMsg)
-            result.TotalErrors++
+        report(i)
+        if _, exists := ctx.SeenHashes[block.Hash]; !exists {
+            ctx.SeenHashes[block.Hash] = i
         }
-
-        prevBlock = &block
-        expectedHeight++
+        if block.Data != "" && s.runs("data") {
+            ctx.SeenData.add(i, block.Data)
+        }
+        ctx.Prev = block
+        ctx.ExpectedHeight++
     }
+    s.next = i
+    result.BlockSizes = db.SummarizeBlockSizes(s.sizes, largestBlocksReported)
 
-    // Calculate health score
-    if result.BlocksScanned > 0 {
-        result.HealthScore = ((result.BlocksScanned - result.TotalErrors) * 100) / result.BlocksScanned
-        if result.HealthScore < 0 {
-            result.HealthScore = 0
-        }
+    // Store issues about the database as a whole, or about heights this
+    // run did not visit, weigh as one more height.
+    var storeWide []Issue
+    for _, h := range slices.Sorted(maps.Keys(storeIssues)) {
+        storeWide = append(storeWide, storeIssues[h]...)
     }
+    heights := result.HeightsChecked
+    if len(storeWide) > 0 {
+        penalty += s.health.blockPenalty(storeWide)
+        heights++
+    }
+    result.HealthScore = s.health.score(penalty, heights)
 
-    if result.TotalErrors == 0 {
+    switch {
+    case cancelled:
+        result.Status = "CANCELLED"
+    case result.TotalErrors == 0:
         result.Status = "HEALTHY"
-    } else {
+    default:
         result.Status = "ERRORS_FOUND"
     }
-
-    return result
 }
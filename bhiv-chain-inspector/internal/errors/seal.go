@@ -0,0 +1,31 @@
+package errors
+
+import (
+    "fmt"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// ClassTamperedSeals is reported for stored values whose HMAC seal is
+// missing or wrong: they were changed by something other than the
+// inspector, such as an edit of the LevelDB files. Other classes on a
+// block with a valid seal are logical errors of whatever wrote it.
+const ClassTamperedSeals = "tampered_seals"
+
+// sealCheck verifies block seals when the storage has a seal key. It runs
+// on the stored value, so undecodable blocks are checked too.
+type sealCheck struct{}
+
+func (sealCheck) Name() string { return "seals" }
+
+func (sealCheck) Classes() []string { return []string{ClassTamperedSeals} }
+
+func (sealCheck) Validate(*blocks.Block, *CheckContext) []Finding { return nil }
+
+func (sealCheck) ValidateRaw(storage *db.Storage, height int, raw []byte) []Finding {
+    if err := storage.VerifySeal(height, raw); err != nil {
+        return []Finding{{Class: ClassTamperedSeals, Message: fmt.Sprintf("Block %d: Tampered - %v", height, err)}}
+    }
+    return nil
+}
@@ -0,0 +1,143 @@
+package errors
+
+import (
+    "fmt"
+    "maps"
+    "regexp"
+    "slices"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// SearchResult lists the transactions whose text matched a pattern.
+type SearchResult struct {
+    DatabasePath   string        `json:"database_path"`
+    Pattern        string        `json:"pattern"`
+    BlocksSearched int           `json:"blocks_searched"`
+    Matches        []SearchMatch `json:"matches"`
+    // Truncated is set when the search stopped at its match limit.
+    Truncated      bool          `json:"truncated,omitempty"`
+}
+
+// SearchMatch is one matching transaction, with up to the requested number
+// of transactions around it from the same block.
+type SearchMatch struct {
+    Height int      `json:"height"`
+    Tx     int      `json:"tx"`
+    Text   string   `json:"text"`
+    Before []string `json:"before,omitempty"`
+    After  []string `json:"after,omitempty"`
+}
+
+// SearchBlocks matches re against every transaction of the chain, skipping
+// unloadable heights as ComputeStats does. Data without newlines is a
+// single transaction, so payloads of any shape are searched. It stops after
+// limit matches when limit is positive.
+func SearchBlocks(r db.BlockReader, dbPath string, re *regexp.Regexp, context, limit int) *SearchResult {
+    result := &SearchResult{DatabasePath: dbPath, Pattern: re.String(), Matches: []SearchMatch{}}
+    tip := r.GetMaxHeight()
+    for height := 0; height <= tip; height++ {
+        block, err := r.LoadBlock(height)
+        if err != nil {
+            continue
+        }
+        result.BlocksSearched++
+        txs := block.Transactions()
+        for i, tx := range txs {
+            if !re.MatchString(tx) {
+                continue
+            }
+            if limit > 0 && len(result.Matches) == limit {
+                result.Truncated = true
+                return result
+            }
+            result.Matches = append(result.Matches, SearchMatch{
+                Height: height,
+                Tx:     i,
+                Text:   tx,
+                Before: txs[max(i-context, 0):i],
+                After:  txs[i+1 : min(i+1+context, len(txs))],
+            })
+        }
+    }
+    return result
+}
+
+// OutputSearch prints each match under its block with its transaction
+// index, marked ">" with the matched text highlighted; context is marked
+// "-".
+func OutputSearch(result *SearchResult, re *regexp.Regexp, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, result, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Pattern: %s | Matches: %d | Blocks: %d\n", result.Pattern, len(result.Matches), result.matchedBlocks())
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "BLOCK SEARCH")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "Database: %s\n", result.DatabasePath)
+    fmt.Fprintf(w, "Pattern:  %s\n", result.Pattern)
+
+    // Lines shared by the context of several matches print once, and a
+    // gap in the transaction indexes of a block is marked "...".
+    for i := 0; i < len(result.Matches); {
+        height := result.Matches[i].Height
+        lines := make(map[int]string)
+        matched := make(map[int]bool)
+        for ; i < len(result.Matches) && result.Matches[i].Height == height; i++ {
+            m := result.Matches[i]
+            for j, tx := range m.Before {
+                lines[m.Tx-len(m.Before)+j] = tx
+            }
+            for j, tx := range m.After {
+                lines[m.Tx+1+j] = tx
+            }
+            lines[m.Tx] = m.Text
+            matched[m.Tx] = true
+        }
+        fmt.Fprintf(w, "\n%sBlock %d:\n", sym.Search, height)
+        prev := -1
+        for _, tx := range slices.Sorted(maps.Keys(lines)) {
+            if prev >= 0 && tx > prev+1 {
+                fmt.Fprintln(w, "  ...")
+            }
+            prev = tx
+            if matched[tx] {
+                highlighted := re.ReplaceAllStringFunc(lines[tx], func(s string) string { return colorize(opts, ansiYellow, s) })
+                fmt.Fprintf(w, "  > %5d  %s\n", tx, highlighted)
+            } else {
+                fmt.Fprintf(w, "  - %5d  %s\n", tx, lines[tx])
+            }
+        }
+    }
+
+    fmt.Fprintln(w)
+    switch {
+    case len(result.Matches) == 0:
+        fmt.Fprintf(w, "%s No matches in %d block(s)\n", sym.Warn, result.BlocksSearched)
+    case result.Truncated:
+        fmt.Fprintf(w, "%s Stopped after %d match(es) in %d block(s)\n", colorize(opts, ansiYellow, sym.Warn),
+            len(result.Matches), result.matchedBlocks())
+    default:
+        fmt.Fprintf(w, "%s %d match(es) in %d of %d block(s)\n", colorize(opts, ansiGreen, sym.OK),
+            len(result.Matches), result.matchedBlocks(), result.BlocksSearched)
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
+
+// matchedBlocks counts the blocks holding at least one match.
+func (r *SearchResult) matchedBlocks() int {
+    count, height := 0, -1
+    for _, m := range r.Matches {
+        if m.Height != height {
+            count, height = count+1, m.Height
+        }
+    }
+    return count
+}
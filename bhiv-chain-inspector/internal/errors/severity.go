@@ -0,0 +1,74 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+)
+
+// Severity classifies how much a finding matters. Only errors count
+// against the health score and the scan exit code.
+type Severity string
+
+const (
+    SeverityError   Severity = "error"
+    SeverityWarning Severity = "warning"
+    SeverityInfo    Severity = "info"
+)
+
+// SeverityMap assigns a severity to error classes; unlisted classes are errors.
+type SeverityMap map[string]Severity
+
+// ScanClasses lists the built-in classes reported by ScanErrors.
+var ScanClasses = []string{
+    ClassCorruptedJSON,
+    ClassBadHash,
+    ClassTimestampFuture,
+    ClassTimestampPast,
+    ClassTimestampNotIncreasing,
+    ClassDuplicateHashes,
+    ClassEmptyBlocks,
+    ClassPrevHashErrors,
+    ClassHeightErrors,
+    ClassMissingBlocks,
+    ClassOutOfOrderBlocks,
+}
+
+// ParseSeverityMap validates a class -> severity table from the config file.
+func ParseSeverityMap(raw map[string]string) (SeverityMap, error) {
+    severities := SeverityMap{}
+    for class, level := range raw {
+        if !isKnownClass(class) {
+            return nil, fmt.Errorf("unknown error class %q (known: %s)", class, strings.Join(KnownClasses(), ", "))
+        }
+        switch sev := Severity(strings.ToLower(level)); sev {
+        case SeverityError, SeverityWarning, SeverityInfo:
+            severities[class] = sev
+        default:
+            return nil, fmt.Errorf("invalid severity %q for %s: use error, warning or info", level, class)
+        }
+    }
+    return severities, nil
+}
+
+// Of returns the severity of class.
+func (m SeverityMap) Of(class string) Severity {
+    if sev, ok := m[class]; ok {
+        return sev
+    }
+    return SeverityError
+}
+
+// Overrides returns the non-default entries as plain strings, for reports.
+func (m SeverityMap) Overrides() map[string]string {
+    var out map[string]string
+    for class, sev := range m {
+        if sev == SeverityError {
+            continue
+        }
+        if out == nil {
+            out = make(map[string]string)
+        }
+        out[class] = string(sev)
+    }
+    return out
+}
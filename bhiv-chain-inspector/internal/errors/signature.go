@@ -0,0 +1,143 @@
+package errors
+
+import (
+    "crypto/ed25519"
+    "encoding/hex"
+    stderrors "errors"
+    "fmt"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/config"
+)
+
+// Error classes of the signature check.
+const (
+    // ClassSignatureErrors is reported for blocks that are unsigned,
+    // signed by a key outside the validator set, or carry a bad signature.
+    ClassSignatureErrors = "signature_errors"
+    // ClassValidatorErrors is reported for blocks signed by a validator
+    // outside the heights it was authorized for.
+    ClassValidatorErrors = "validator_errors"
+)
+
+// Validator is a key trusted to sign blocks, optionally only in its epoch,
+// the inclusive From..To height range (either end may be open). A key
+// that rotates out and back in is listed once per epoch.
+//
+//  validators:
+//    - name: node-a
+//      public_key: 3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c
+//      to: 9999
+//    - name: node-b
+//      public_key: 5fe0b1c3f3c2d1c5e3a8e1a0c4d6a9b2f7e8d9c0b1a2f3e4d5c6b7a8f9e0d1c2
+//      from: 10000
+type Validator struct {
+    Name      string `json:"name"`
+    PublicKey string `json:"public_key"`
+    From      *int   `json:"from,omitempty"`
+    To        *int   `json:"to,omitempty"`
+}
+
+// covers reports whether height is within the validator's epoch.
+func (v Validator) covers(height int) bool {
+    return (v.From == nil || height >= *v.From) && (v.To == nil || height <= *v.To)
+}
+
+func (v Validator) epoch() string {
+    from, to := "genesis", "open"
+    if v.From != nil {
+        from = fmt.Sprint(*v.From)
+    }
+    if v.To != nil {
+        to = fmt.Sprint(*v.To)
+    }
+    return from + ".." + to
+}
+
+// ValidatorSet lists the keys whose signatures the signature check
+// accepts, with their epochs.
+type ValidatorSet []Validator
+
+// LoadValidators reads and validates a validator file.
+func LoadValidators(path string) (ValidatorSet, error) {
+    var file struct {
+        Validators ValidatorSet `json:"validators"`
+    }
+    if err := config.DecodeFile(path, &file); err != nil {
+        return nil, err
+    }
+    if len(file.Validators) == 0 {
+        return nil, fmt.Errorf("%s: no validators", path)
+    }
+    for i := range file.Validators {
+        v := &file.Validators[i]
+        v.PublicKey = strings.ToLower(v.PublicKey)
+        if key, err := hex.DecodeString(v.PublicKey); err != nil || len(key) != ed25519.PublicKeySize {
+            return nil, fmt.Errorf("%s: validator %d: public_key must be %d hex-encoded bytes", path, i+1, ed25519.PublicKeySize)
+        }
+        if v.Name == "" {
+            v.Name = v.PublicKey[:12]
+        }
+        if v.From != nil && v.To != nil && *v.From > *v.To {
+            return nil, fmt.Errorf("%s: validator %d: from (%d) is after to (%d)", path, i+1, *v.From, *v.To)
+        }
+    }
+    return file.Validators, nil
+}
+
+// authorize finds the entries of key. It returns the one whose epoch
+// covers height, or else the first entry of key and false; known is false
+// when key is not in the set at all.
+func (s ValidatorSet) authorize(key string, height int) (v Validator, known, ok bool) {
+    key = strings.ToLower(key)
+    for _, candidate := range s {
+        if candidate.PublicKey != key {
+            continue
+        }
+        if candidate.covers(height) {
+            return candidate, true, true
+        }
+        if !known {
+            v, known = candidate, true
+        }
+    }
+    return v, known, false
+}
+
+// NewSignatureCheck returns a check requiring every block to be signed
+// by a member of validators whose epoch covers the block's height.
+// Register it with RegisterCheck.
+func NewSignatureCheck(validators ValidatorSet) Check {
+    return signatureCheck{validators}
+}
+
+type signatureCheck struct {
+    validators ValidatorSet
+}
+
+func (signatureCheck) Name() string { return "signature" }
+
+func (signatureCheck) Classes() []string {
+    return []string{ClassSignatureErrors, ClassValidatorErrors}
+}
+
+func (c signatureCheck) Validate(block *blocks.Block, ctx *CheckContext) []Finding {
+    err := block.VerifySignature()
+    switch {
+    case stderrors.Is(err, blocks.ErrUnsigned):
+        return []Finding{{Class: ClassSignatureErrors, Message: fmt.Sprintf("Block %d: Unsigned", ctx.Height)}}
+    case err != nil:
+        return []Finding{{Class: ClassSignatureErrors, Message: fmt.Sprintf("Block %d: Bad signature", ctx.Height)}}
+    }
+    v, known, ok := c.validators.authorize(block.Signer, ctx.Height)
+    switch {
+    case !known:
+        return []Finding{{Class: ClassSignatureErrors, Message: fmt.Sprintf("Block %d: Signed by unknown key %s", ctx.Height, block.Signer),
+            Actual: block.Signer}}
+    case !ok:
+        return []Finding{{Class: ClassValidatorErrors, Message: fmt.Sprintf("Block %d: Signed by %s outside its epoch (%s)", ctx.Height, v.Name, v.epoch()),
+            Expected: v.epoch()}}
+    }
+    return nil
+}
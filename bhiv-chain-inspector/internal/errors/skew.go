@@ -0,0 +1,146 @@
+package errors
+
+import (
+    "fmt"
+    "math"
+)
+
+// Verdicts of a TimestampSkew.
+const (
+    SkewNone         = "in_sync"
+    SkewClock        = "clock_skew"
+    SkewDrift        = "clock_drift"
+    SkewInconsistent = "inconsistent"
+)
+
+// skewDriftSeconds is how far the fitted trend must move the skew across
+// the compared heights, with a strong correlation, to call it drift.
+const skewDriftSeconds = 2
+
+// TimestampSkew is the distribution of Node2's block timestamps minus
+// Node1's over the heights both nodes hold and compare block by block;
+// segments skipped as identical have no skew and are left out. A steady
+// skew of one sign points at a node's clock (NTP) rather than a fork, as
+// does one that grows steadily with height (drift).
+type TimestampSkew struct {
+    Heights   int     `json:"heights"`
+    Skewed    int     `json:"skewed"`
+    Mean      float64 `json:"mean_seconds"`
+    StdDev    float64 `json:"stddev_seconds"`
+    // Max is the skew of largest magnitude, at MaxHeight.
+    Max       int64   `json:"max_seconds"`
+    MaxHeight int     `json:"max_height"`
+    // Trend is the least-squares change of the skew per 1000 heights.
+    Trend     float64 `json:"trend_seconds_per_1000"`
+    Verdict   string  `json:"verdict"`
+}
+
+// skewSample is the timestamp delta at one height.
+type skewSample struct {
+    height int
+    delta  int64
+}
+
+// newTimestampSkew summarises samples, or returns nil if there are none.
+func newTimestampSkew(samples []skewSample) *TimestampSkew {
+    if len(samples) == 0 {
+        return nil
+    }
+    skew := &TimestampSkew{Heights: len(samples), MaxHeight: -1}
+    n := float64(len(samples))
+    var sumH, sumD float64
+    positive, negative := 0, 0
+    for _, s := range samples {
+        sumH += float64(s.height)
+        sumD += float64(s.delta)
+        switch {
+        case s.delta > 0:
+            positive++
+        case s.delta < 0:
+            negative++
+        }
+        if s.delta != 0 && (skew.MaxHeight < 0 || abs64(s.delta) > abs64(skew.Max)) {
+            skew.Max, skew.MaxHeight = s.delta, s.height
+        }
+    }
+    skew.Skewed = positive + negative
+    skew.Mean = sumD / n
+    if skew.Skewed == 0 {
+        skew.Verdict = SkewNone
+        return skew
+    }
+
+    meanH := sumH / n
+    var varH, varD, cov float64
+    minH, maxH := samples[0].height, samples[0].height
+    for _, s := range samples {
+        dh, dd := float64(s.height)-meanH, float64(s.delta)-skew.Mean
+        varH += dh * dh
+        varD += dd * dd
+        cov += dh * dd
+        minH, maxH = min(minH, s.height), max(maxH, s.height)
+    }
+    skew.StdDev = math.Sqrt(varD / n)
+    var slope, r float64
+    if varH > 0 && varD > 0 {
+        slope = cov / varH
+        r = cov / math.Sqrt(varH*varD)
+    }
+    skew.Trend = slope * 1000
+
+    // Ninety per cent of the heights skewed the same way.
+    steady := float64(max(positive, negative)) >= 0.9*n
+    switch {
+    case math.Abs(r) >= 0.8 && math.Abs(slope*float64(maxH-minH)) >= skewDriftSeconds:
+        skew.Verdict = SkewDrift
+    case steady && skew.StdDev <= max(1, math.Abs(skew.Mean)/2):
+        skew.Verdict = SkewClock
+    default:
+        skew.Verdict = SkewInconsistent
+    }
+    return skew
+}
+
+func abs64(v int64) int64 {
+    if v < 0 {
+        return -v
+    }
+    return v
+}
+
+// recommendation explains the verdict, or returns "" when the nodes agree.
+func (s *TimestampSkew) recommendation() string {
+    ahead := "ahead of"
+    if s.Mean < 0 {
+        ahead = "behind"
+    }
+    switch s.Verdict {
+    case SkewClock:
+        return fmt.Sprintf("Node2's timestamps are a steady %.1fs %s Node1's at %d of %d heights - "+
+            "check NTP on both nodes; consistent skew is a clock problem, not a fork",
+            math.Abs(s.Mean), ahead, s.Skewed, s.Heights)
+    case SkewDrift:
+        return fmt.Sprintf("Timestamp skew drifts by %+.1fs per 1000 blocks (max %+ds at block %d) - "+
+            "a node's clock is running fast or slow; check NTP", s.Trend, s.Max, s.MaxHeight)
+    case SkewInconsistent:
+        return fmt.Sprintf("Timestamps differ at %d heights without a steady skew (max %+ds at block %d) - "+
+            "the nodes hold different blocks there, not a clock problem", s.Skewed, s.Max, s.MaxHeight)
+    }
+    return ""
+}
+
+// printTimestampSkew prints the skew section of a comparison.
+func printTimestampSkew(s *TimestampSkew, opts OutputOptions) {
+    w := opts.Writer()
+    fmt.Fprintf(w, "\n%sTIMESTAMP SKEW (Node2 - Node1):\n", symbolsFor(opts).Stats)
+    fmt.Fprintf(w, "  Heights:            %d (%d skewed)\n", s.Heights, s.Skewed)
+    fmt.Fprintf(w, "  Mean Skew:          %+.1fs\n", s.Mean)
+    fmt.Fprintf(w, "  Max Skew:           %+ds at block %d\n", s.Max, s.MaxHeight)
+    fmt.Fprintf(w, "  Std Deviation:      %.1fs\n", s.StdDev)
+    fmt.Fprintf(w, "  Trend:              %+.2fs per 1000 blocks\n", s.Trend)
+    color := ansiYellow
+    if s.Verdict == SkewInconsistent {
+        color = ansiRed
+    }
+    fmt.Fprintf(w, "  Verdict:            %s\n", colorize(opts, color, s.Verdict))
+}
@@ -0,0 +1,173 @@
+package errors
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "slices"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// snapshotListed is the number of rewritten blocks listed without -v.
+const snapshotListed = 10
+
+// SnapshotDiff is the history of one node between two snapshots of its
+// database: the heights added, changed and removed from Before to After.
+type SnapshotDiff struct {
+    Before    string           `json:"before"`
+    After     string           `json:"after"`
+    Unchanged int              `json:"unchanged"`
+    Appended  []int            `json:"appended"`
+    Rewritten []RewrittenBlock `json:"rewritten"`
+    Deleted   []int            `json:"deleted"`
+}
+
+// RewrittenBlock is a height whose block differs between the snapshots.
+// The hashes are the blocks' own, so a block edited without updating its
+// hash shows the same hash twice; values that do not decode show as
+// "value:" and the SHA-256 of the stored bytes.
+type RewrittenBlock struct {
+    Height     int    `json:"height"`
+    BeforeHash string `json:"before_hash"`
+    AfterHash  string `json:"after_hash"`
+}
+
+// Changed reports whether any block stored before was rewritten or
+// deleted, rather than only new blocks appended.
+func (d *SnapshotDiff) Changed() bool {
+    return len(d.Rewritten) > 0 || len(d.Deleted) > 0
+}
+
+// snapshotBlock identifies the block stored at a height: Digest covers
+// every decoded field, so it does not depend on the encoding or
+// compression the value was written with.
+type snapshotBlock struct {
+    Hash   string
+    Digest [sha256.Size]byte
+}
+
+func snapshotBlocks(storage *db.Storage) (map[int]snapshotBlock, error) {
+    stored := make(map[int]snapshotBlock)
+    err := storage.BlockValues(func(height int, value []byte) error {
+        if block, _, err := storage.DecodeBlock(value); err == nil {
+            fields, _ := json.Marshal(block)
+            stored[height] = snapshotBlock{block.Hash, sha256.Sum256(fields)}
+        } else {
+            sum := sha256.Sum256(value)
+            stored[height] = snapshotBlock{"value:" + hex.EncodeToString(sum[:]), sum}
+        }
+        return nil
+    })
+    return stored, err
+}
+
+// DiffSnapshots compares every stored block of two snapshots by content,
+// so a snapshot re-encoded or recompressed in between shows no change.
+func DiffSnapshots(before, after *db.Storage, beforePath, afterPath string) (*SnapshotDiff, error) {
+    old, err := snapshotBlocks(before)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", beforePath, err)
+    }
+    current, err := snapshotBlocks(after)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", afterPath, err)
+    }
+
+    diff := &SnapshotDiff{Before: beforePath, After: afterPath,
+        Appended: []int{}, Rewritten: []RewrittenBlock{}, Deleted: []int{}}
+    for height, block := range current {
+        switch was, ok := old[height]; {
+        case !ok:
+            diff.Appended = append(diff.Appended, height)
+        case was.Digest != block.Digest:
+            diff.Rewritten = append(diff.Rewritten, RewrittenBlock{height, was.Hash, block.Hash})
+        default:
+            diff.Unchanged++
+        }
+    }
+    for height := range old {
+        if _, ok := current[height]; !ok {
+            diff.Deleted = append(diff.Deleted, height)
+        }
+    }
+    slices.Sort(diff.Appended)
+    slices.Sort(diff.Deleted)
+    slices.SortFunc(diff.Rewritten, func(a, b RewrittenBlock) int { return a.Height - b.Height })
+    return diff, nil
+}
+
+// heightRanges renders sorted heights as "0-99, 120, 130-131".
+func heightRanges(heights []int) string {
+    var parts []string
+    for i := 0; i < len(heights); {
+        j := i
+        for j+1 < len(heights) && heights[j+1] == heights[j]+1 {
+            j++
+        }
+        if i == j {
+            parts = append(parts, fmt.Sprint(heights[i]))
+        } else {
+            parts = append(parts, fmt.Sprintf("%d-%d", heights[i], heights[j]))
+        }
+        i = j + 1
+    }
+    return strings.Join(parts, ", ")
+}
+
+// OutputSnapshotDiff prints the counts and heights of each kind of change,
+// and the rewritten blocks with their hashes (all of them with -v).
+func OutputSnapshotDiff(diff *SnapshotDiff, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, diff, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Appended: %d | Rewritten: %d | Deleted: %d | Unchanged: %d\n",
+            len(diff.Appended), len(diff.Rewritten), len(diff.Deleted), diff.Unchanged)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "SNAPSHOT DIFF")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Before:           %s\n", diff.Before)
+    fmt.Fprintf(w, "  After:            %s\n", diff.After)
+    fmt.Fprintf(w, "  Unchanged:        %d\n", diff.Unchanged)
+    fmt.Fprintf(w, "  Appended:         %d\n", len(diff.Appended))
+    fmt.Fprintf(w, "  Rewritten:        %d\n", len(diff.Rewritten))
+    fmt.Fprintf(w, "  Deleted:          %d\n", len(diff.Deleted))
+
+    if len(diff.Appended) > 0 {
+        fmt.Fprintf(w, "\n%sAPPENDED:\n  %s\n", sym.Stats, heightRanges(diff.Appended))
+    }
+    if len(diff.Deleted) > 0 {
+        fmt.Fprintf(w, "\n%s\n  %s\n", colorize(opts, ansiRed, sym.Details+"DELETED:"), heightRanges(diff.Deleted))
+    }
+    if len(diff.Rewritten) > 0 {
+        fmt.Fprintf(w, "\n%s\n", colorize(opts, ansiRed, sym.Details+"REWRITTEN:"))
+        shown := diff.Rewritten
+        if opts.Verbosity < VerbosityVerbose && len(shown) > snapshotListed {
+            shown = shown[:snapshotListed]
+        }
+        for _, b := range shown {
+            fmt.Fprintf(w, "  Block %d:\n", b.Height)
+            fmt.Fprintf(w, "    Before:         %s\n", b.BeforeHash)
+            fmt.Fprintf(w, "    After:          %s\n", b.AfterHash)
+        }
+        if more := len(diff.Rewritten) - len(shown); more > 0 {
+            fmt.Fprintf(w, "  ... and %d more (-v lists them all)\n", more)
+        }
+    }
+
+    fmt.Fprintln(w)
+    if diff.Changed() {
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiRed, sym.Fail), colorize(opts, ansiRed, "HISTORY CHANGED"))
+    } else {
+        fmt.Fprintf(w, "%s %s\n", colorize(opts, ansiGreen, sym.OK), colorize(opts, ansiGreen, "HISTORY PRESERVED"))
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
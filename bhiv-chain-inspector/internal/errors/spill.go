@@ -0,0 +1,109 @@
+package errors
+
+import (
+    "bufio"
+    "encoding/json"
+    "os"
+)
+
+// entryOverhead approximates the memory an ErrorEntry takes besides its
+// strings: the struct in its list and the list's spare capacity.
+const entryOverhead = 120
+
+// FindingSpill bounds the memory a scan spends on its findings. Findings
+// are kept in the class lists of the result while they fit in the limit;
+// the rest are appended to a file as NDJSON, one object per finding with
+// the columns of the scan's Table, and only counted in the result. The
+// memory a scan needs then stays flat however many findings it makes.
+type FindingSpill struct {
+    limit int64
+    path  string
+    used  int64
+    file  *os.File
+    w     *bufio.Writer
+    err   error
+}
+
+// NewFindingSpill keeps up to limit bytes of findings in memory and
+// spills the rest to path, or to a temporary file when path is empty. The
+// file is only created once a finding is spilled.
+func NewFindingSpill(limit int64, path string) *FindingSpill {
+    return &FindingSpill{limit: limit, path: path}
+}
+
+// SpillSummary describes the findings a scan wrote to its spill file.
+type SpillSummary struct {
+    Path     string         `json:"path"`
+    Findings int            `json:"findings"`
+    Classes  map[string]int `json:"classes"`
+}
+
+// spilledFinding is a line of the spill file.
+type spilledFinding struct {
+    Height   int      `json:"height"`
+    Class    string   `json:"class"`
+    Code     Code     `json:"code"`
+    Severity Severity `json:"severity"`
+    Expected string   `json:"expected,omitempty"`
+    Actual   string   `json:"actual,omitempty"`
+    Message  string   `json:"message"`
+}
+
+// keep charges e, of class, to the memory budget and reports whether it
+// fits. A finding that does not is written to the file and counted in
+// result.Spilled instead.
+func (s *FindingSpill) keep(result *ErrorScanResult, class string, sev Severity, e ErrorEntry) bool {
+    size := int64(entryOverhead + len(e.Message) + len(e.Expected) + len(e.Actual))
+    if class == ClassMissingBlocks {
+        // Missing blocks are kept as heights alone.
+        size = 8
+    }
+    if s.used+size <= s.limit {
+        s.used += size
+        return true
+    }
+    if s.file == nil && s.err == nil {
+        s.open()
+    }
+    if s.err == nil {
+        line, _ := json.Marshal(spilledFinding{Height: e.Height, Class: class, Code: e.Code, Severity: sev,
+            Expected: e.Expected, Actual: e.Actual, Message: e.Message})
+        s.w.Write(line)
+        s.err = s.w.WriteByte('\n')
+    }
+    if result.Spilled == nil {
+        result.Spilled = &SpillSummary{Classes: make(map[string]int)}
+        if s.file != nil {
+            result.Spilled.Path = s.file.Name()
+        }
+    }
+    result.Spilled.Findings++
+    result.Spilled.Classes[class]++
+    return false
+}
+
+func (s *FindingSpill) open() {
+    if s.path == "" {
+        s.file, s.err = os.CreateTemp("", "inspector-findings-*.ndjson")
+    } else {
+        s.file, s.err = os.Create(s.path)
+    }
+    if s.err == nil {
+        s.w = bufio.NewWriter(s.file)
+    }
+}
+
+// Close flushes and closes the spill file, if one was created, and
+// returns the first error met writing it.
+func (s *FindingSpill) Close() error {
+    if s.file == nil {
+        return s.err
+    }
+    if err := s.w.Flush(); s.err == nil {
+        s.err = err
+    }
+    if err := s.file.Close(); s.err == nil {
+        s.err = err
+    }
+    return s.err
+}
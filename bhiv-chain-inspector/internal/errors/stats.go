@@ -0,0 +1,265 @@
+package errors
+
+import (
+    "fmt"
+    "io"
+    "slices"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// ChainStats are the summary metrics of the original Day-1 stats tool,
+// plus the distributions of block times and sizes.
+type ChainStats struct {
+    Height           int             `json:"height"`
+    TotalBlocks      int             `json:"total_blocks"`
+    AverageBlockTime float64         `json:"average_block_time_seconds"`
+    Gaps             []int           `json:"gaps"`
+    DuplicateHashes  []string        `json:"duplicate_hashes"`
+    // BlockTimes is nil for chains of fewer than two blocks.
+    BlockTimes       *BlockTimeStats `json:"block_times,omitempty"`
+    // BlockSizes is only set when the reader exposes stored values.
+    BlockSizes       *db.BlockSizes  `json:"block_sizes,omitempty"`
+}
+
+// largestBlocksReported is the number of blocks ChainStats.BlockSizes and
+// ErrorScanResult.BlockSizes list as the largest.
+const largestBlocksReported = 10
+
+// rawBlockReader is a BlockReader that also returns stored values, as
+// *db.Storage does.
+type rawBlockReader interface {
+    db.BlockReader
+    LoadBlockRaw(height int) ([]byte, error)
+    DecodeBlock(raw []byte) (*blocks.Block, db.Encoding, error)
+}
+
+// rollingWindow is the number of heights per BlockTimeStats.Rolling entry.
+const rollingWindow = 1000
+
+// histogramBuckets is the number of BlockTimeStats.Histogram buckets.
+const histogramBuckets = 10
+
+// BlockTimeStats is the distribution of the intervals, in seconds,
+// between the timestamps of consecutive loaded blocks. Percentiles are
+// nearest-rank. Intervals can be negative when timestamps go backwards.
+type BlockTimeStats struct {
+    Min       int64             `json:"min"`
+    Max       int64             `json:"max"`
+    Median    int64             `json:"median"`
+    P95       int64             `json:"p95"`
+    Histogram []IntervalBucket  `json:"histogram"`
+    // Rolling averages the intervals ending in each window of
+    // rollingWindow heights.
+    Rolling   []RollingInterval `json:"rolling"`
+}
+
+// IntervalBucket counts the intervals from From up to To, inclusive of To
+// for the last bucket only.
+type IntervalBucket struct {
+    From  int64 `json:"from"`
+    To    int64 `json:"to"`
+    Count int   `json:"count"`
+}
+
+// RollingInterval is the average interval of the blocks at heights From
+// to To.
+type RollingInterval struct {
+    From    int     `json:"from"`
+    To      int     `json:"to"`
+    Average float64 `json:"average"`
+}
+
+// blockInterval is the interval before the block at height.
+type blockInterval struct {
+    height  int
+    seconds int64
+}
+
+// blockTimeStats summarises intervals, in height order.
+func blockTimeStats(intervals []blockInterval) *BlockTimeStats {
+    if len(intervals) == 0 {
+        return nil
+    }
+    sorted := make([]int64, len(intervals))
+    for i, iv := range intervals {
+        sorted[i] = iv.seconds
+    }
+    slices.Sort(sorted)
+    n := len(sorted)
+    percentile := func(p int) int64 {
+        return sorted[max((p*n+99)/100, 1)-1]
+    }
+    bt := &BlockTimeStats{Min: sorted[0], Max: sorted[n-1], Median: percentile(50), P95: percentile(95)}
+
+    width := max((bt.Max-bt.Min+histogramBuckets)/histogramBuckets, 1)
+    for from := bt.Min; from <= bt.Max; from += width {
+        bt.Histogram = append(bt.Histogram, IntervalBucket{From: from, To: from + width})
+    }
+    for _, v := range sorted {
+        bt.Histogram[(v-bt.Min)/width].Count++
+    }
+
+    var window *RollingInterval
+    var sum int64
+    var count int
+    for _, iv := range intervals {
+        from := iv.height / rollingWindow * rollingWindow
+        if window == nil || window.From != from {
+            if window != nil {
+                window.Average = float64(sum) / float64(count)
+                bt.Rolling = append(bt.Rolling, *window)
+            }
+            window, sum, count = &RollingInterval{From: from, To: from + rollingWindow - 1}, 0, 0
+        }
+        sum += iv.seconds
+        count++
+    }
+    window.Average = float64(sum) / float64(count)
+    bt.Rolling = append(bt.Rolling, *window)
+    return bt
+}
+
+// ComputeStats reads r from height 0 to its tip. Heights below the tip
+// that cannot be loaded are reported as gaps.
+func ComputeStats(r db.BlockReader) *ChainStats {
+    stats := &ChainStats{Height: -1, Gaps: []int{}, DuplicateHashes: []string{}}
+    seen := make(map[string]int)
+    var first, last int64
+    var intervals []blockInterval
+    var sizes []db.BlockSize
+    raw, sized := r.(rawBlockReader)
+    load := r.LoadBlock
+    if sized {
+        load = func(height int) (*blocks.Block, error) {
+            value, err := raw.LoadBlockRaw(height)
+            if err != nil {
+                return nil, err
+            }
+            sizes = append(sizes, db.BlockSize{Height: height, Size: len(value)})
+            block, _, err := raw.DecodeBlock(value)
+            return block, err
+        }
+    }
+    stats.Height = r.GetMaxHeight()
+    for height := 0; height <= stats.Height; height++ {
+        block, err := load(height)
+        if err != nil {
+            stats.Gaps = append(stats.Gaps, height)
+            continue
+        }
+        if firstHeight, ok := seen[block.Hash]; ok {
+            stats.DuplicateHashes = append(stats.DuplicateHashes,
+                fmt.Sprintf("Block %d duplicates hash from Block %d", height, firstHeight))
+        } else {
+            seen[block.Hash] = height
+        }
+        if stats.TotalBlocks == 0 {
+            first = block.Timestamp
+        } else {
+            intervals = append(intervals, blockInterval{height, block.Timestamp - last})
+        }
+        last = block.Timestamp
+        stats.TotalBlocks++
+    }
+    if stats.TotalBlocks > 1 {
+        stats.AverageBlockTime = float64(last-first) / float64(stats.TotalBlocks-1)
+    }
+    stats.BlockTimes = blockTimeStats(intervals)
+    if sized {
+        stats.BlockSizes = db.SummarizeBlockSizes(sizes, largestBlocksReported)
+    }
+    return stats
+}
+
+// OutputStats prints chain statistics.
+func OutputStats(stats *ChainStats, dbPath string, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, stats, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Height: %d | Blocks: %d | Avg Block Time: %.2fs | Gaps: %d | Duplicates: %d\n",
+            stats.Height, stats.TotalBlocks, stats.AverageBlockTime, len(stats.Gaps), len(stats.DuplicateHashes))
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "BLOCKCHAIN STATS")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:            %s\n", dbPath)
+    fmt.Fprintf(w, "  Height:              %d\n", stats.Height)
+    fmt.Fprintf(w, "  Total Blocks:        %d\n", stats.TotalBlocks)
+    fmt.Fprintf(w, "  Average Block Time:  %.2f seconds\n", stats.AverageBlockTime)
+    if bt := stats.BlockTimes; bt != nil {
+        outputBlockTimes(w, bt, stats.AverageBlockTime, sym, opts)
+    }
+    if stats.BlockSizes != nil && stats.BlockSizes.Blocks > 0 {
+        printBlockSizes(w, stats.BlockSizes, sym)
+    }
+
+    fmt.Fprintf(w, "\n%sGAP DETECTION:\n", sym.Search)
+    if len(stats.Gaps) > 0 {
+        fmt.Fprintf(w, "  %s %s\n", colorize(opts, ansiYellow, sym.Warn), colorize(opts, ansiYellow, fmt.Sprintf("Gaps detected at heights: %v", stats.Gaps)))
+    } else {
+        fmt.Fprintf(w, "  %s No gaps detected\n", colorize(opts, ansiGreen, sym.OK))
+    }
+
+    fmt.Fprintf(w, "\n%sDUPLICATE HASH DETECTION:\n", sym.Search)
+    if len(stats.DuplicateHashes) > 0 {
+        for _, dup := range stats.DuplicateHashes {
+            fmt.Fprintf(w, "  %s %s\n", colorize(opts, ansiYellow, sym.Warn), colorize(opts, ansiYellow, dup))
+        }
+    } else {
+        fmt.Fprintf(w, "  %s No duplicate hashes\n", colorize(opts, ansiGreen, sym.OK))
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
+
+// outputBlockTimes prints the block time percentiles and histogram, and
+// the rolling windows whose average is off the chain's by half or more
+// (every window with -v).
+func outputBlockTimes(w io.Writer, bt *BlockTimeStats, average float64, sym symbolSet, opts OutputOptions) {
+    fmt.Fprintf(w, "\n%sBLOCK TIME DISTRIBUTION:\n", sym.Stats)
+    fmt.Fprintf(w, "  Min:                 %d seconds\n", bt.Min)
+    fmt.Fprintf(w, "  Median:              %d seconds\n", bt.Median)
+    fmt.Fprintf(w, "  p95:                 %d seconds\n", bt.P95)
+    fmt.Fprintf(w, "  Max:                 %d seconds\n\n", bt.Max)
+    most := 0
+    for _, b := range bt.Histogram {
+        most = max(most, b.Count)
+    }
+    bar := "█"
+    if opts.ASCII {
+        bar = "#"
+    }
+    for _, b := range bt.Histogram {
+        fmt.Fprintf(w, "  %12s - %-12s %8d  %s\n", fmt.Sprintf("%ds", b.From), fmt.Sprintf("%ds", b.To), b.Count, strings.Repeat(bar, (b.Count*40+most-1)/most))
+    }
+
+    fmt.Fprintf(w, "\n%sROLLING AVERAGE (per %d blocks):\n", sym.Search, rollingWindow)
+    unusual := 0
+    for _, r := range bt.Rolling {
+        off := average > 0 && (r.Average < average/2 || r.Average > average*1.5)
+        if !off && opts.Verbosity < VerbosityVerbose {
+            continue
+        }
+        line := fmt.Sprintf("Blocks %d-%d: %.2f seconds", r.From, r.To, r.Average)
+        if off {
+            unusual++
+            label := "bursty"
+            if r.Average > average {
+                label = "stalled"
+            }
+            fmt.Fprintf(w, "  %s %s\n", colorize(opts, ansiYellow, sym.Warn), colorize(opts, ansiYellow, line+" ("+label+")"))
+        } else {
+            fmt.Fprintf(w, "  %s %s\n", colorize(opts, ansiGreen, sym.OK), line)
+        }
+    }
+    if unusual == 0 {
+        fmt.Fprintf(w, "  %s All %d window(s) within half of the average\n", colorize(opts, ansiGreen, sym.OK), len(bt.Rolling))
+    }
+}
@@ -0,0 +1,113 @@
+package errors
+
+import (
+    "fmt"
+    "slices"
+
+    "bhiv-chain-inspector/internal/config"
+)
+
+// SuppressionRule excludes known, accepted findings from scan results.
+// A rule matches by height (Heights, or the inclusive From..To range) and
+// by class or code; omitted criteria match everything.
+//
+//  suppressions:
+//    - heights: [4, 17]
+//      classes: [bad_hash]
+//      reason: restored from backup in 2023
+//    - from: 1000
+//      to: 1999
+//      codes: [E_EMPTY_BLOCK]
+type SuppressionRule struct {
+    Heights []int    `json:"heights,omitempty"`
+    From    *int     `json:"from,omitempty"`
+    To      *int     `json:"to,omitempty"`
+    Classes []string `json:"classes,omitempty"`
+    Codes   []Code   `json:"codes,omitempty"`
+    Reason  string   `json:"reason,omitempty"`
+}
+
+// Suppressions is a set of rules; the zero value suppresses nothing.
+type Suppressions []SuppressionRule
+
+// LoadSuppressions reads and validates a suppression file.
+func LoadSuppressions(path string) (Suppressions, error) {
+    if path == "" {
+        return nil, nil
+    }
+    var file struct {
+        Suppressions Suppressions `json:"suppressions"`
+    }
+    if err := config.DecodeFile(path, &file); err != nil {
+        return nil, err
+    }
+    if err := file.Suppressions.validate(); err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    return file.Suppressions, nil
+}
+
+func (s Suppressions) validate() error {
+    for i, rule := range s {
+        for _, class := range rule.Classes {
+            if !isKnownClass(class) {
+                return fmt.Errorf("suppression %d: unknown error class %q", i+1, class)
+            }
+        }
+        for _, code := range rule.Codes {
+            if !isKnownCode(code) {
+                return fmt.Errorf("suppression %d: unknown error code %q", i+1, code)
+            }
+        }
+        if rule.From != nil && rule.To != nil && *rule.From > *rule.To {
+            return fmt.Errorf("suppression %d: from (%d) is after to (%d)", i+1, *rule.From, *rule.To)
+        }
+        if len(rule.Heights) == 0 && rule.From == nil && rule.To == nil && len(rule.Classes) == 0 && len(rule.Codes) == 0 {
+            return fmt.Errorf("suppression %d: matches everything; give heights, a range, classes or codes", i+1)
+        }
+    }
+    return nil
+}
+
+// Matches reports whether a finding of class at height is suppressed.
+func (s Suppressions) Matches(height int, class string) bool {
+    for _, rule := range s {
+        if rule.matches(height, class) {
+            return true
+        }
+    }
+    return false
+}
+
+func (r SuppressionRule) matches(height int, class string) bool {
+    if len(r.Classes) > 0 && !contains(r.Classes, class) {
+        return false
+    }
+    if len(r.Codes) > 0 && !slices.Contains(r.Codes, CodeOf(class)) {
+        return false
+    }
+    if r.From != nil && height < *r.From {
+        return false
+    }
+    if r.To != nil && height > *r.To {
+        return false
+    }
+    if len(r.Heights) > 0 {
+        for _, h := range r.Heights {
+            if h == height {
+                return true
+            }
+        }
+        return false
+    }
+    return true
+}
+
+func contains(list []string, s string) bool {
+    for _, item := range list {
+        if item == s {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,50 @@
+package errors
+
+// symbolSet holds the decorations used by the text formatter.
+type symbolSet struct {
+    OK      string
+    Fail    string
+    Warn    string
+    Rule    string
+    Stats   string
+    Search  string
+    Details string
+    Healthy string
+    Diverge string
+    Recs    string
+}
+
+var unicodeSymbols = symbolSet{
+    OK:      "✔",
+    Fail:    "✖",
+    Warn:    "⚠️",
+    Rule:    "═",
+    Stats:   "📊 ",
+    Search:  "🔍 ",
+    Details: "📋 ",
+    Healthy: "🎉 ",
+    Diverge: "🔀 ",
+    Recs:    "🔧 ",
+}
+
+// asciiSymbols is used with --ascii for terminals and log pipelines that
+// cannot render box drawing characters or emoji.
+var asciiSymbols = symbolSet{
+    OK:      "[OK]",
+    Fail:    "[X]",
+    Warn:    "[!]",
+    Rule:    "=",
+    Stats:   "",
+    Search:  "",
+    Details: "",
+    Healthy: "",
+    Diverge: "",
+    Recs:    "",
+}
+
+func symbolsFor(opts OutputOptions) symbolSet {
+    if opts.ASCII {
+        return asciiSymbols
+    }
+    return unicodeSymbols
+}
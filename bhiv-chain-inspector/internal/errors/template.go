@@ -0,0 +1,72 @@
+package errors
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "reflect"
+    "strings"
+    "text/template"
+)
+
+// reportFuncs are the functions report templates can call besides the
+// text/template builtins:
+//
+//  json V        V as indented JSON
+//  upper S       S in upper case
+//  lower S       S in lower case
+//  join LIST SEP the elements of LIST joined by SEP
+//  repeat S N    S repeated N times
+//  count N       N with thousands separators
+//  code CLASS    the error code of CLASS (see CodeOf)
+var reportFuncs = template.FuncMap{
+    "json": func(v any) (string, error) {
+        data, err := json.MarshalIndent(v, "", "  ")
+        return string(data), err
+    },
+    "upper":  strings.ToUpper,
+    "lower":  strings.ToLower,
+    "join":   joinAny,
+    "repeat": strings.Repeat,
+    "count":  formatCount,
+    "code":   CodeOf,
+}
+
+// ParseReportTemplate parses a text/template file that scan and compare
+// execute with their ErrorScanResult or ComparisonResult in place of the
+// built-in report; see OutputOptions.Template.
+func ParseReportTemplate(path string) (*template.Template, error) {
+    text, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    return template.New(path).Funcs(reportFuncs).Option("missingkey=error").Parse(string(text))
+}
+
+// outputTemplate executes tmpl with result. The report is rendered in full
+// before any of it is written, so a template that fails half way prints
+// the error rather than half a report.
+func outputTemplate(tmpl *template.Template, result any, opts OutputOptions) {
+    var report bytes.Buffer
+    if err := tmpl.Execute(&report, result); err != nil {
+        fmt.Fprintf(opts.DiagWriter(), "report template: %v\n", err)
+        return
+    }
+    io.Copy(opts.Writer(), &report)
+}
+
+// joinAny joins the elements of a slice of any type, each formatted as
+// by print.
+func joinAny(list any, sep string) string {
+    v := reflect.ValueOf(list)
+    if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+        return fmt.Sprint(list)
+    }
+    parts := make([]string, v.Len())
+    for i := range parts {
+        parts[i] = fmt.Sprint(v.Index(i).Interface())
+    }
+    return strings.Join(parts, sep)
+}
@@ -0,0 +1,80 @@
+package errors
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// DefaultTimeLayout is the layout of --time-format default.
+const DefaultTimeLayout = "2006-01-02 15:04:05"
+
+// timeLayouts are the layouts of --time-format; "unix" prints seconds.
+var timeLayouts = map[string]string{
+    "default": DefaultTimeLayout,
+    "rfc3339": time.RFC3339,
+    "unix":    "",
+}
+
+// TimeFormat is how reports print times: in Layout, or as Unix seconds
+// when Layout is empty, after converting them to Location.
+type TimeFormat struct {
+    Layout   string
+    Location *time.Location
+}
+
+var timeFormat = TimeFormat{Layout: DefaultTimeLayout, Location: time.Local}
+
+// ParseTimeFormat reads the --time-format and --tz flags: a format of
+// default, rfc3339 or unix, and a zone of UTC, local or an IANA name such
+// as Europe/Berlin.
+func ParseTimeFormat(format, tz string) (TimeFormat, error) {
+    layout, ok := timeLayouts[strings.ToLower(format)]
+    if !ok {
+        return TimeFormat{}, fmt.Errorf("unknown --time-format %q (want default, rfc3339 or unix)", format)
+    }
+    f := TimeFormat{Layout: layout, Location: time.Local}
+    if !strings.EqualFold(tz, "local") {
+        loc, err := time.LoadLocation(tz)
+        if err != nil {
+            return TimeFormat{}, fmt.Errorf("unknown --tz %q (want UTC, local or an IANA zone): %w", tz, err)
+        }
+        f.Location = loc
+    }
+    return f, nil
+}
+
+// SetTimeFormat makes every report print times as f.
+func SetTimeFormat(f TimeFormat) {
+    timeFormat = f
+}
+
+// FormatTime prints t as SetTimeFormat selected.
+func FormatTime(t time.Time) string {
+    if timeFormat.Layout == "" {
+        return strconv.FormatInt(t.Unix(), 10)
+    }
+    return t.In(timeFormat.Location).Format(timeFormat.Layout)
+}
+
+// FormatUnix prints a time given in Unix seconds, as block timestamps are.
+func FormatUnix(sec int64) string {
+    return FormatTime(time.Unix(sec, 0))
+}
+
+// timeWidth is the width of a time column.
+func timeWidth() int {
+    return len(FormatTime(time.Date(2006, 1, 2, 15, 4, 5, 0, timeFormat.Location)))
+}
+
+// periodStart is the start of the hour or day holding sec, in the zone
+// times are printed in.
+func periodStart(sec int64, period string) int64 {
+    t := time.Unix(sec, 0).In(timeFormat.Location)
+    hour := t.Hour()
+    if period == "daily" {
+        hour = 0
+    }
+    return time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, timeFormat.Location).Unix()
+}
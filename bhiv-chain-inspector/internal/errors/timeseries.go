@@ -0,0 +1,138 @@
+package errors
+
+import (
+    "fmt"
+    "maps"
+    "slices"
+    "strconv"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// timeSeriesPeriods are the periods of "stats --timeseries".
+var timeSeriesPeriods = map[string]bool{
+    "hourly": true,
+    "daily":  true,
+}
+
+// TimeSeriesPeriods lists the periods ComputeTimeSeries accepts.
+func TimeSeriesPeriods() string {
+    return "hourly, daily"
+}
+
+// TimeSeries is the block production of a chain per hour or day of the
+// zone times are printed in (see SetTimeFormat), from block timestamps.
+// Only periods holding at least one block are listed.
+type TimeSeries struct {
+    DatabasePath string       `json:"database_path"`
+    Period       string       `json:"period"`
+    Buckets      []TimeBucket `json:"buckets"`
+}
+
+// TimeBucket is one period. AverageInterval averages the intervals from
+// each of its blocks' predecessors, so a period whose first block follows
+// a stall shows it; it is 0 when no block in the period has one.
+type TimeBucket struct {
+    Start           int64   `json:"start"`
+    Blocks          int     `json:"blocks"`
+    FirstHeight     int     `json:"first_height"`
+    LastHeight      int     `json:"last_height"`
+    AverageInterval float64 `json:"average_interval_seconds"`
+
+    intervals int
+}
+
+// ComputeTimeSeries reads r from height 0 the way ComputeStats does and
+// counts the blocks of each period.
+func ComputeTimeSeries(r db.BlockReader, dbPath, period string) (*TimeSeries, error) {
+    if !timeSeriesPeriods[period] {
+        return nil, fmt.Errorf("unknown time series period %q (want %s)", period, TimeSeriesPeriods())
+    }
+    buckets := make(map[int64]*TimeBucket)
+    loaded := false
+    var last int64
+    for height, tip := 0, r.GetMaxHeight(); height <= tip; height++ {
+        block, err := r.LoadBlock(height)
+        if err != nil {
+            continue
+        }
+        start := periodStart(block.Timestamp, period)
+        b := buckets[start]
+        if b == nil {
+            b = &TimeBucket{Start: start, FirstHeight: height}
+            buckets[start] = b
+        }
+        b.Blocks++
+        b.LastHeight = height
+        if loaded {
+            b.AverageInterval += float64(block.Timestamp - last)
+            b.intervals++
+        }
+        last, loaded = block.Timestamp, true
+    }
+
+    series := &TimeSeries{DatabasePath: dbPath, Period: period, Buckets: []TimeBucket{}}
+    for _, start := range slices.Sorted(maps.Keys(buckets)) {
+        b := buckets[start]
+        if b.intervals > 0 {
+            b.AverageInterval /= float64(b.intervals)
+        }
+        series.Buckets = append(series.Buckets, *b)
+    }
+    return series, nil
+}
+
+// OutputTimeSeries prints one row per period.
+func OutputTimeSeries(series *TimeSeries, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, series, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        most := 0
+        for _, b := range series.Buckets {
+            most = max(most, b.Blocks)
+        }
+        fmt.Fprintf(w, "Period: %s | Buckets: %d | Most Blocks: %d\n", series.Period, len(series.Buckets), most)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "BLOCK PRODUCTION (%s)\n", strings.ToUpper(series.Period))
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "Database: %s\n\n", series.DatabasePath)
+    tw := timeWidth()
+    fmt.Fprintf(w, "  %-*s  %8s  %-17s  %s\n", tw, "Start", "Blocks", "Heights", "Avg Interval")
+    for _, b := range series.Buckets {
+        fmt.Fprintf(w, "  %-*s  %8d  %-17s  %.2f seconds\n", tw, FormatUnix(b.Start), b.Blocks,
+            fmt.Sprintf("%d-%d", b.FirstHeight, b.LastHeight), b.AverageInterval)
+    }
+    if len(series.Buckets) == 0 {
+        fmt.Fprintln(w, "  No blocks")
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
+
+// Columns and Rows make a time series a Table, one row per period, for
+// dashboards and spreadsheets (--format csv).
+func (s *TimeSeries) Columns() []string {
+    return []string{"start", "blocks", "first_height", "last_height", "average_interval_seconds"}
+}
+
+func (s *TimeSeries) Rows() [][]any {
+    rows := make([][]any, len(s.Buckets))
+    for i, b := range s.Buckets {
+        rows[i] = []any{timeSeriesStart(b.Start), b.Blocks, b.FirstHeight, b.LastHeight,
+            strconv.FormatFloat(b.AverageInterval, 'f', 2, 64)}
+    }
+    return rows
+}
+
+// timeSeriesStart prints the start of a period in a Table: RFC 3339 in the
+// zone times are printed in, whatever --time-format says.
+func timeSeriesStart(start int64) string {
+    return time.Unix(start, 0).In(timeFormat.Location).Format(time.RFC3339)
+}
@@ -0,0 +1,200 @@
+package errors
+
+import (
+    "encoding/json"
+    "fmt"
+    "reflect"
+    "slices"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+)
+
+// txDiffsListed is the number of blocks whose transaction diff compare
+// prints without -v.
+const txDiffsListed = 5
+
+// txKeyFields are the JSON fields that identify a transaction, in order
+// of preference, so that two versions of one transaction pair up as
+// changed rather than as one absent from each node.
+var txKeyFields = []string{"tx_id", "txid", "id", "hash", "nonce"}
+
+// TxDiff is the difference between the transactions (lines of Data, see
+// blocks.Block.Transactions) of one height on two nodes. Transactions are matched by content first,
+// then JSON transactions by their identifying field (see txKeyFields).
+type TxDiff struct {
+    Height    int        `json:"height"`
+    Common    int        `json:"common"`
+    Node1Only []string   `json:"node1_only"`
+    Node2Only []string   `json:"node2_only"`
+    Changed   []TxChange `json:"changed"`
+}
+
+// TxChange is a transaction both nodes hold in different versions.
+type TxChange struct {
+    // Key is the identifying field and its value, e.g. "nonce=42".
+    Key    string   `json:"key"`
+    Node1  string   `json:"node1"`
+    Node2  string   `json:"node2"`
+    // Fields are the top-level JSON fields whose values differ.
+    Fields []string `json:"fields"`
+}
+
+// Summary counts the differences, e.g. "2 only on Node1, 1 changed".
+func (d *TxDiff) Summary() string {
+    var parts []string
+    if n := len(d.Node1Only); n > 0 {
+        parts = append(parts, fmt.Sprintf("%d only on Node1", n))
+    }
+    if n := len(d.Node2Only); n > 0 {
+        parts = append(parts, fmt.Sprintf("%d only on Node2", n))
+    }
+    if n := len(d.Changed); n > 0 {
+        parts = append(parts, fmt.Sprintf("%d changed", n))
+    }
+    if len(parts) == 0 {
+        return "same transactions in another order"
+    }
+    return strings.Join(parts, ", ")
+}
+
+// diffTransactions compares the transactions of two versions of a block.
+// It returns nil when each holds a single transaction that is not a JSON
+// object, as there is nothing finer to show than the data differing.
+func diffTransactions(height int, block1, block2 *blocks.Block) *TxDiff {
+    txs1, txs2 := block1.Transactions(), block2.Transactions()
+    if len(txs1) <= 1 && len(txs2) <= 1 && !anyJSONObject(txs1) && !anyJSONObject(txs2) {
+        return nil
+    }
+    diff := &TxDiff{Height: height, Node1Only: []string{}, Node2Only: []string{}, Changed: []TxChange{}}
+
+    // Match identical transactions, counting repeats.
+    remaining := make(map[string]int)
+    for _, tx := range txs2 {
+        remaining[tx]++
+    }
+    var only1 []string
+    for _, tx := range txs1 {
+        if remaining[tx] > 0 {
+            remaining[tx]--
+            diff.Common++
+        } else {
+            only1 = append(only1, tx)
+        }
+    }
+    var only2 []string
+    for _, tx := range txs2 {
+        if remaining[tx] > 0 {
+            remaining[tx]--
+            only2 = append(only2, tx)
+        }
+    }
+
+    // Pair what is left by identifying field.
+    keyed2 := make(map[string]int)
+    for i, tx := range only2 {
+        if key, _ := txKey(tx); key != "" {
+            if _, dup := keyed2[key]; !dup {
+                keyed2[key] = i
+            }
+        }
+    }
+    paired := make(map[int]bool)
+    for _, tx := range only1 {
+        key, fields1 := txKey(tx)
+        if i, ok := keyed2[key]; ok && key != "" && !paired[i] {
+            paired[i] = true
+            _, fields2 := txKey(only2[i])
+            diff.Changed = append(diff.Changed, TxChange{Key: key, Node1: tx, Node2: only2[i], Fields: changedFields(fields1, fields2)})
+            continue
+        }
+        diff.Node1Only = append(diff.Node1Only, tx)
+    }
+    for i, tx := range only2 {
+        if !paired[i] {
+            diff.Node2Only = append(diff.Node2Only, tx)
+        }
+    }
+    return diff
+}
+
+func anyJSONObject(txs []string) bool {
+    for _, tx := range txs {
+        if _, fields := txKey(tx); fields != nil {
+            return true
+        }
+    }
+    return false
+}
+
+// txKey decodes tx as a JSON object and returns its identifying field as
+// "field=value", empty if it has none.
+func txKey(tx string) (string, map[string]any) {
+    var fields map[string]any
+    if json.Unmarshal([]byte(tx), &fields) != nil {
+        return "", nil
+    }
+    for _, name := range txKeyFields {
+        if v, ok := fields[name]; ok {
+            return fmt.Sprintf("%s=%v", name, v), fields
+        }
+    }
+    return "", fields
+}
+
+// changedFields lists the top-level fields present in either object whose
+// values differ.
+func changedFields(a, b map[string]any) []string {
+    var names []string
+    for name, v := range a {
+        if w, ok := b[name]; !ok || !reflect.DeepEqual(v, w) {
+            names = append(names, name)
+        }
+    }
+    for name := range b {
+        if _, ok := a[name]; !ok {
+            names = append(names, name)
+        }
+    }
+    slices.Sort(names)
+    return names
+}
+
+// shortTx shortens a transaction to fit one output line.
+func shortTx(tx string) string {
+    const width = 72
+    if len(tx) <= width {
+        return tx
+    }
+    return tx[:width-3] + "..."
+}
+
+// printTxDiffs prints the transaction diffs of a comparison, the first
+// txDiffsListed blocks unless verbose.
+func printTxDiffs(diffs []TxDiff, opts OutputOptions) {
+    w := opts.Writer()
+    fmt.Fprintf(w, "\n%sTRANSACTION DIFFS:\n", symbolsFor(opts).Details)
+    shown := diffs
+    if opts.Verbosity < VerbosityVerbose && len(shown) > txDiffsListed {
+        shown = shown[:txDiffsListed]
+    }
+    for _, d := range shown {
+        fmt.Fprintf(w, "  Block %d: %d common, %s\n", d.Height, d.Common, d.Summary())
+        for _, tx := range d.Node1Only {
+            fmt.Fprintf(w, "    %s %s\n", colorize(opts, ansiRed, "- Node1 only:"), shortTx(tx))
+        }
+        for _, tx := range d.Node2Only {
+            fmt.Fprintf(w, "    %s %s\n", colorize(opts, ansiGreen, "+ Node2 only:"), shortTx(tx))
+        }
+        for _, c := range d.Changed {
+            fmt.Fprintf(w, "    %s %s (fields: %s)\n", colorize(opts, ansiYellow, "~ Changed:"), c.Key, strings.Join(c.Fields, ", "))
+            if opts.Verbosity >= VerbosityVerbose {
+                fmt.Fprintf(w, "        Node1: %s\n", shortTx(c.Node1))
+                fmt.Fprintf(w, "        Node2: %s\n", shortTx(c.Node2))
+            }
+        }
+    }
+    if more := len(diffs) - len(shown); more > 0 {
+        fmt.Fprintf(w, "  ... and %d more blocks (-v lists them all)\n", more)
+    }
+}
@@ -0,0 +1,41 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+)
+
+// UndoReport is the output of the undo command.
+type UndoReport struct {
+    Journal   string   `json:"journal"`
+    Databases []string `json:"databases"`
+    // Restored keys got their old value back; Deleted keys did not exist
+    // before the journaled writes.
+    Restored  int      `json:"restored"`
+    Deleted   int      `json:"deleted"`
+}
+
+// OutputUndo prints what the undo command reverted.
+func OutputUndo(report *UndoReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Undone: %s | Restored: %d | Deleted: %d\n", report.Journal, report.Restored, report.Deleted)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "JOURNAL UNDONE")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Journal:          %s\n", report.Journal)
+    for _, path := range report.Databases {
+        fmt.Fprintf(w, "  Database:         %s\n", path)
+    }
+    fmt.Fprintf(w, "  Keys Restored:    %d\n", report.Restored)
+    fmt.Fprintf(w, "  Keys Deleted:     %d\n", report.Deleted)
+    fmt.Fprintf(w, "\n%s %s\n", colorize(opts, ansiGreen, sym.OK), colorize(opts, ansiGreen, "ORIGINAL VALUES RESTORED"))
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
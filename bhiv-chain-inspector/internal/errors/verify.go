@@ -0,0 +1,178 @@
+package errors
+
+import (
+    "fmt"
+    "strings"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+)
+
+// VerifyReport is a scan result regrouped as one pass/fail verdict per
+// error class, the report of the original Day-1 verify tool. Verification
+// and scanning share ScanErrors, so they cannot disagree.
+type VerifyReport struct {
+    DatabasePath   string        `json:"database_path"`
+    BlocksAnalyzed int           `json:"blocks_analyzed"`
+    Passed         bool          `json:"passed"`
+    TotalErrors    int           `json:"total_errors"`
+    Checks         []VerifyCheck `json:"checks"`
+}
+
+// VerifyCheck is the verdict for one error class. A class passes when it
+// has no error-severity findings; warnings are listed but do not fail it.
+type VerifyCheck struct {
+    Class    string   `json:"class"`
+    Passed   bool     `json:"passed"`
+    Findings []string `json:"findings,omitempty"`
+}
+
+// NewVerifyReport groups the findings of result by class.
+func NewVerifyReport(result *ErrorScanResult) *VerifyReport {
+    report := &VerifyReport{
+        DatabasePath:   result.DatabasePath,
+        BlocksAnalyzed: result.BlocksScanned,
+        Passed:         result.TotalErrors == 0,
+        TotalErrors:    result.TotalErrors,
+    }
+    byClass := make(map[string]*VerifyCheck)
+    classes := append(append([]string(nil), ScanClasses...), sortedKeys(result.Custom)...)
+    for _, class := range classes {
+        report.Checks = append(report.Checks, VerifyCheck{Class: class, Passed: true})
+    }
+    for i := range report.Checks {
+        byClass[report.Checks[i].Class] = &report.Checks[i]
+    }
+    for _, issue := range result.Issues() {
+        check := byClass[issue.Class]
+        check.Findings = append(check.Findings, issue.Message)
+        if issue.Severity == SeverityError {
+            check.Passed = false
+        }
+    }
+    return report
+}
+
+// OutputVerifyReport prints one PASSED/FAILED row per class followed by
+// the overall verdict.
+func OutputVerifyReport(report *VerifyReport, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, report, opts) {
+        return
+    }
+    verdict := "PASSED"
+    if !report.Passed {
+        verdict = "FAILED"
+    }
+    if opts.Verbosity <= VerbosityQuiet {
+        fmt.Fprintf(w, "Verification: %s | Blocks: %d | Errors: %d\n", verdict, report.BlocksAnalyzed, report.TotalErrors)
+        return
+    }
+
+    sym := symbolsFor(opts)
+    fmt.Fprintln(w, "\n" + strings.Repeat(sym.Rule, 66))
+    fmt.Fprintln(w, "COMPLETE END-TO-END BLOCKCHAIN VERIFICATION")
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+    fmt.Fprintf(w, "\n  Database:         %s\n", report.DatabasePath)
+    fmt.Fprintf(w, "  Blocks Analyzed:  %d\n", report.BlocksAnalyzed)
+
+    printVerifyChecks(report, opts)
+
+    fmt.Fprintln(w)
+    if report.Passed {
+        fmt.Fprintf(w, "%s%s\n", sym.Healthy, colorize(opts, ansiGreen, "BLOCKCHAIN VERIFICATION PASSED"))
+    } else {
+        fmt.Fprintf(w, "%s  %s\n", sym.Warn, colorize(opts, ansiRed,
+            fmt.Sprintf("BLOCKCHAIN VERIFICATION FAILED (%d errors)", report.TotalErrors)))
+    }
+    fmt.Fprintln(w, strings.Repeat(sym.Rule, 66))
+}
+
+// printVerifyChecks prints the CHECKS section, the findings of each class
+// too when verbose.
+func printVerifyChecks(report *VerifyReport, opts OutputOptions) {
+    w := opts.Writer()
+    sym := symbolsFor(opts)
+    fmt.Fprintf(w, "\n%sCHECKS:\n", sym.Search)
+    for _, check := range report.Checks {
+        mark, color, state := sym.OK, ansiGreen, "PASSED"
+        if !check.Passed {
+            mark, color, state = sym.Fail, ansiRed, "FAILED"
+        } else if len(check.Findings) > 0 {
+            mark, color, state = sym.Warn, ansiYellow, "PASSED"
+        }
+        line := fmt.Sprintf("%-26s%s", check.Class+":", state)
+        if n := len(check.Findings); n > 0 {
+            line += fmt.Sprintf(" (%d finding(s))", n)
+        }
+        fmt.Fprintf(w, "  %s %s\n", colorize(opts, color, mark), colorize(opts, color, line))
+        if opts.Verbosity >= VerbosityVerbose {
+            for _, finding := range check.Findings {
+                fmt.Fprintf(w, "      - %s\n", finding)
+            }
+        }
+    }
+}
+
+// OutputBlock prints a single block.
+func OutputBlock(block *blocks.Block, opts OutputOptions) {
+    w := opts.Writer()
+    if formatReport(w, block, opts) {
+        return
+    }
+    fmt.Fprintf(w, "\n=== Block %d ===\n", block.Height)
+    fmt.Fprintf(w, "Hash:      %s\n", block.Hash)
+    fmt.Fprintf(w, "PrevHash:  %s\n", block.PrevHash)
+    fmt.Fprintf(w, "Timestamp: %s (Unix: %d)\n", FormatUnix(block.Timestamp), block.Timestamp)
+    fmt.Fprintf(w, "Data:      %s\n\n", block.Data)
+}
+
+// BlockView is a block with its hash recomputed and its link to the block
+// before checked, the view command's JSON output.
+type BlockView struct {
+    *blocks.Block
+    HashAlgorithm string `json:"hash_algorithm"`
+    ComputedHash  string `json:"computed_hash"`
+    HashValid     bool   `json:"hash_valid"`
+    // LinkageValid is set when PrevHash is "0" for genesis, or else the
+    // hash of the block before; LinkageError says why that block could
+    // not be read.
+    LinkageValid  bool   `json:"linkage_valid"`
+    LinkageError  string `json:"linkage_error,omitempty"`
+}
+
+// NewBlockView checks block, read from r. An empty alg means the first
+// algorithm of blocks.HashAlgorithms the hash matches, or else SHA256.
+func NewBlockView(block *blocks.Block, r db.BlockReader, alg blocks.HashAlgorithm) *BlockView {
+    view := &BlockView{Block: block}
+    if alg == "" {
+        alg = blocks.SHA256
+        for _, candidate := range blocks.HashAlgorithms {
+            if block.VerifyHashWith(candidate) == nil {
+                alg = candidate
+                break
+            }
+        }
+    }
+    view.HashAlgorithm = alg.String()
+    view.ComputedHash = block.ComputedHashWith(alg)
+    view.HashValid = view.ComputedHash == block.Hash
+
+    if block.Height == 0 {
+        view.LinkageValid = block.PrevHash == "0"
+    } else if prev, err := r.LoadBlock(block.Height - 1); err != nil {
+        view.LinkageError = err.Error()
+    } else {
+        view.LinkageValid = block.PrevHash == prev.Hash
+    }
+    return view
+}
+
+// OutputBlockView prints the block as OutputBlock does, and the checks
+// with it in JSON.
+func OutputBlockView(view *BlockView, opts OutputOptions) {
+    if formatReport(opts.Writer(), view, opts) {
+        return
+    }
+    OutputBlock(view.Block, opts)
+}
@@ -0,0 +1,191 @@
+package errors
+
+import (
+    "bufio"
+    "bytes"
+    "crypto/sha256"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "log/slog"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// VerifyCache remembers, in a file beside the database, which blocks a
+// full scan found clean, so the next scan can skip their checks. A block
+// is skipped only if it and every block below it are stored byte for byte
+// as when it was verified: each height is keyed by a running SHA-256 over
+// the stored values up to it, so a change to any block re-verifies it and
+// all blocks above it, whose checks may look back at it. Skipped blocks
+// are still read and decoded, for the state the checks of later blocks
+// need.
+//
+// The file is read and rewritten in height order during the scan, so it
+// costs no memory however long the chain. It is rebuilt when the scan
+// settings differ from the ones it was written with, and after MaxAge,
+// since some verdicts depend on the clock (timestamps too old, rules that
+// use now).
+type VerifyCache struct {
+    Path string
+    // Settings identifies inputs of the checks the scanner does not see,
+    // such as the contents of config, validator and key files.
+    Settings string
+    // MaxAge, when positive, is how long the file is used before it is
+    // rebuilt.
+    MaxAge time.Duration
+}
+
+// verifyCacheMagic starts every cache file; the version changes with the
+// layout. A header of the settings digest, creation time (Unix seconds)
+// and first height follows, then one record per height: the running
+// digest and whether the block was clean.
+const verifyCacheMagic = "BHIVVC1\n"
+
+const verifyCacheRecord = sha256.Size + 1
+
+// cacheRun is a VerifyCache during one scan: the file of the last scan,
+// if usable, and the file of this one, renamed over it at the end.
+type cacheRun struct {
+    path    string
+    old     *bufio.Reader
+    oldFile *os.File
+    // oldNext is the height of the next record of old.
+    oldNext int
+    tmp     *os.File
+    w       *bufio.Writer
+    digest  [sha256.Size]byte
+    hits    int
+    err     error
+}
+
+// start opens the cache for a scan from height first with the given
+// settings digest. Without a usable file every height misses.
+func (c *VerifyCache) start(settings [sha256.Size]byte, first int) *cacheRun {
+    run := &cacheRun{path: c.Path, oldNext: first}
+    now := time.Now()
+    var header bytes.Buffer
+    header.WriteString(verifyCacheMagic)
+    header.Write(settings[:])
+    binary.Write(&header, binary.BigEndian, now.Unix())
+    binary.Write(&header, binary.BigEndian, int64(first))
+
+    if f, err := os.Open(c.Path); err == nil {
+        r := bufio.NewReader(f)
+        old := make([]byte, header.Len())
+        _, err := io.ReadFull(r, old)
+        created := time.Unix(int64(binary.BigEndian.Uint64(old[len(verifyCacheMagic)+sha256.Size:])), 0)
+        switch {
+        case err != nil || !bytes.Equal(old[:len(verifyCacheMagic)+sha256.Size], header.Bytes()[:len(verifyCacheMagic)+sha256.Size]):
+            slog.Info("verification cache was written with other settings, rebuilding", "path", c.Path)
+            f.Close()
+        case int(binary.BigEndian.Uint64(old[len(old)-8:])) != first:
+            slog.Info("chain starts at another height than cached, rebuilding verification cache", "path", c.Path)
+            f.Close()
+        case c.MaxAge > 0 && now.Sub(created) > c.MaxAge:
+            slog.Info("verification cache expired, rebuilding", "path", c.Path, "created", created)
+            f.Close()
+        default:
+            run.old, run.oldFile = r, f
+            // Keep the creation time, so the age counts from the rebuild.
+            copy(header.Bytes()[len(verifyCacheMagic)+sha256.Size:], old[len(verifyCacheMagic)+sha256.Size:])
+        }
+    } else if !os.IsNotExist(err) {
+        slog.Warn("cannot read verification cache", "path", c.Path, "err", err)
+    }
+
+    run.tmp, run.err = os.CreateTemp(filepath.Dir(c.Path), filepath.Base(c.Path)+".*.tmp")
+    if run.err == nil {
+        run.w = bufio.NewWriter(run.tmp)
+        _, run.err = run.w.Write(header.Bytes())
+    }
+    return run
+}
+
+// next folds the stored value of height, nil if missing, into the
+// running digest and reports whether the cache found the block clean at
+// the same digest.
+func (r *cacheRun) next(height int, raw []byte) bool {
+    h := sha256.New()
+    h.Write(r.digest[:])
+    if raw == nil {
+        h.Write([]byte("missing"))
+    } else {
+        h.Write([]byte{0})
+        h.Write(raw)
+    }
+    h.Sum(r.digest[:0])
+
+    if r.old == nil || r.oldNext != height {
+        return false
+    }
+    var record [verifyCacheRecord]byte
+    if _, err := io.ReadFull(r.old, record[:]); err != nil {
+        r.closeOld()
+        return false
+    }
+    r.oldNext++
+    hit := record[sha256.Size] == 1 && bytes.Equal(record[:sha256.Size], r.digest[:])
+    if hit {
+        r.hits++
+    }
+    return hit
+}
+
+// record writes the verdict of the height last passed to next.
+func (r *cacheRun) record(clean bool) {
+    if r.err != nil {
+        return
+    }
+    r.w.Write(r.digest[:])
+    flag := byte(0)
+    if clean {
+        flag = 1
+    }
+    r.err = r.w.WriteByte(flag)
+}
+
+func (r *cacheRun) closeOld() {
+    if r.oldFile != nil {
+        r.oldFile.Close()
+        r.old, r.oldFile = nil, nil
+    }
+}
+
+// finish replaces the cache file with the one written during the scan.
+func (r *cacheRun) finish() {
+    r.closeOld()
+    if r.tmp == nil {
+        slog.Warn("cannot write verification cache", "path", r.path, "err", r.err)
+        return
+    }
+    if r.err == nil {
+        r.err = r.w.Flush()
+    }
+    if err := r.tmp.Close(); r.err == nil {
+        r.err = err
+    }
+    if r.err == nil {
+        r.err = os.Rename(r.tmp.Name(), r.path)
+    }
+    if r.err != nil {
+        os.Remove(r.tmp.Name())
+        slog.Warn("cannot write verification cache", "path", r.path, "err", r.err)
+    }
+}
+
+// cacheSettings digests what the verdicts of a scan depend on besides the
+// stored blocks.
+func (s *chainScan) cacheSettings(storage *db.Storage, extra string) [sha256.Size]byte {
+    var names []string
+    for _, check := range s.checks {
+        names = append(names, check.Name())
+    }
+    return sha256.Sum256(fmt.Appendf(nil, "checks=%s\nhash=%s\nchain=%s\nnear=%v\nencoding=%s\nlayout=%s\nnamespace=%s\n%s",
+        strings.Join(names, ","), s.ctx.HashAlgorithm, s.ctx.ChainID, s.opts.NearDuplicates,
+        storage.Encoding(), storage.Layout(), storage.Namespace(), extra))
+}
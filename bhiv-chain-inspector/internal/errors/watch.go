@@ -0,0 +1,70 @@
+package errors
+
+import (
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+)
+
+// Watcher validates a chain in passes over one database, normally kept open.
+// The first pass checks the whole chain; every later pass only checks the
+// heights appended since, against the chain state the previous passes
+// built up (previous block, expected height, seen hashes).
+type Watcher struct {
+    storage *db.Storage
+    dbPath  string
+    scan    *chainScan
+    started bool
+}
+
+// NewWatcher returns a watcher for storage; opts apply to every pass.
+func NewWatcher(storage *db.Storage, dbPath string, opts ScanOptions) *Watcher {
+    return &Watcher{storage: storage, dbPath: dbPath, scan: newChainScan(opts)}
+}
+
+// Pass validates the heights added since the previous pass. The result
+// covers only those heights; HeightsChecked is 0 when nothing was new.
+func (w *Watcher) Pass() *ErrorScanResult {
+    result := &ErrorScanResult{
+        ScanTime:     FormatTime(time.Now()),
+        DatabasePath: w.dbPath,
+        Severities:   w.scan.opts.Severity.Overrides(),
+    }
+
+    tip := w.storage.GetMaxHeight()
+    if !w.started {
+        if first := w.storage.FirstHeight(); first > w.scan.next {
+            w.scan.next = first
+            w.scan.ctx.ExpectedHeight = first
+        }
+    }
+    if tip < 0 {
+        result.Status = "ERROR: Empty database"
+        return result
+    }
+    w.started = true
+
+    result.TotalBlocks = tip + 1
+    w.scan.run(w.storage, result, tip)
+    return result
+}
+
+// SetStorage makes later passes read storage, the same database opened
+// again, e.g. by a caller that does not keep it open between passes.
+func (w *Watcher) SetStorage(storage *db.Storage) {
+    w.storage = storage
+}
+
+// OnBlock replaces the per-height callback for subsequent passes, e.g. to
+// report only blocks that arrive after the initial pass.
+func (w *Watcher) OnBlock(fn func(BlockVerdict)) {
+    w.scan.opts.OnBlock = fn
+}
+
+// Checkpoint describes the last block validated, or nil before any.
+func (w *Watcher) Checkpoint() *db.Checkpoint {
+    if !w.started {
+        return nil
+    }
+    return NewCheckpoint(w.storage, w.scan.ctx.Height)
+}
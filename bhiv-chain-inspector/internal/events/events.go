@@ -0,0 +1,124 @@
+// Package events publishes chain integrity events (one per finding, plus a
+// summary per scan) to a message bus for stream processing.
+//
+// Destinations are URLs:
+//
+//  nats://host:4222/chain.events        NATS subject, core protocol
+//  kafka+http://proxy:8082/chain-events  Kafka topic via a Confluent REST Proxy
+//
+// Kafka is reached through its REST proxy rather than the binary protocol,
+// which keeps the inspector free of a Kafka client dependency.
+package events
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/url"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// Publisher sends a batch of JSON messages; key may be used for
+// partitioning.
+type Publisher interface {
+    Publish(key string, messages [][]byte) error
+}
+
+// NewPublisher parses a destination URL.
+func NewPublisher(dest string) (Publisher, error) {
+    u, err := url.Parse(dest)
+    if err != nil {
+        return nil, fmt.Errorf("invalid events destination: %w", err)
+    }
+    if u.Scheme != "nats" && u.Scheme != "kafka+http" && u.Scheme != "kafka+https" {
+        return nil, fmt.Errorf("unsupported events destination %q (use nats:// or kafka+http://)", dest)
+    }
+    target := strings.TrimPrefix(u.Path, "/")
+    if target == "" {
+        return nil, fmt.Errorf("events destination %q names no subject or topic", dest)
+    }
+    if u.Scheme == "nats" {
+        return &natsPublisher{addr: u.Host, subject: target}, nil
+    }
+    scheme := strings.TrimPrefix(u.Scheme, "kafka+")
+    return &kafkaRESTPublisher{url: scheme + "://" + u.Host + "/topics/" + url.PathEscape(target)}, nil
+}
+
+// Finding is the event published for each finding.
+type Finding struct {
+    Type     string          `json:"type"` // "finding"
+    Database string          `json:"db"`
+    Time     int64           `json:"time"` // Unix nanoseconds, like Summary
+    Height   int             `json:"height"`
+    Class    string          `json:"class"`
+    Code     errors.Code     `json:"code"`
+    Severity errors.Severity `json:"severity"`
+    Message  string          `json:"message"`
+}
+
+// Summary is the event published once per scan.
+type Summary struct {
+    Type     string `json:"type"` // "scan_summary"
+    Database string `json:"db"`
+    *db.ScanHistoryEntry
+}
+
+// Emitter collects the findings of a scan through its OnBlock hook and
+// publishes them, followed by a summary, when the scan is done.
+type Emitter struct {
+    pub      Publisher
+    dbPath   string
+    findings []Finding
+}
+
+// NewEmitter returns an emitter for scans of dbPath.
+func NewEmitter(pub Publisher, dbPath string) *Emitter {
+    return &Emitter{pub: pub, dbPath: dbPath}
+}
+
+// OnBlock returns a ScanOptions.OnBlock hook recording findings and then
+// calling next, if any.
+func (e *Emitter) OnBlock(next func(errors.BlockVerdict)) func(errors.BlockVerdict) {
+    return func(v errors.BlockVerdict) {
+        now := time.Now().UnixNano()
+        for _, issue := range v.Issues {
+            e.findings = append(e.findings, Finding{
+                Type:     "finding",
+                Database: e.dbPath,
+                Time:     now,
+                Height:   v.Height,
+                Class:    issue.Class,
+                Code:     issue.Code,
+                Severity: issue.Severity,
+                Message:  issue.Message,
+            })
+        }
+        if next != nil {
+            next(v)
+        }
+    }
+}
+
+// Emit publishes the collected findings and a summary of result, then
+// forgets the findings so the emitter can be reused for the next scan.
+func (e *Emitter) Emit(result *errors.ErrorScanResult, start time.Time) error {
+    messages := make([][]byte, 0, len(e.findings)+1)
+    for _, f := range e.findings {
+        data, err := json.Marshal(f)
+        if err != nil {
+            return err
+        }
+        messages = append(messages, data)
+    }
+    e.findings = nil
+
+    data, err := json.Marshal(Summary{Type: "scan_summary", Database: e.dbPath, ScanHistoryEntry: result.HistoryEntry(start)})
+    if err != nil {
+        return err
+    }
+    messages = append(messages, data)
+    return e.pub.Publish(e.dbPath, messages)
+}
@@ -0,0 +1,46 @@
+package events
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "time"
+)
+
+// kafkaRESTPublisher produces to a topic through the Confluent REST Proxy
+// v2 API (POST /topics/<topic>, JSON embedded format).
+type kafkaRESTPublisher struct {
+    url string
+}
+
+var kafkaClient = &http.Client{Timeout: 30 * time.Second}
+
+func (p *kafkaRESTPublisher) Publish(key string, messages [][]byte) error {
+    type record struct {
+        Key   string          `json:"key,omitempty"`
+        Value json.RawMessage `json:"value"`
+    }
+    payload := struct {
+        Records []record `json:"records"`
+    }{}
+    for _, msg := range messages {
+        payload.Records = append(payload.Records, record{Key: key, Value: msg})
+    }
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return err
+    }
+
+    resp, err := kafkaClient.Post(p.url, "application/vnd.kafka.json.v2+json", bytes.NewReader(body))
+    if err != nil {
+        return fmt.Errorf("kafka: %w", err)
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode/100 != 2 {
+        detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+        return fmt.Errorf("kafka: %s: %s", resp.Status, bytes.TrimSpace(detail))
+    }
+    return nil
+}
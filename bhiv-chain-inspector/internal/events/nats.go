@@ -0,0 +1,63 @@
+package events
+
+import (
+    "bufio"
+    "fmt"
+    "net"
+    "strings"
+    "time"
+)
+
+// natsPublisher speaks the NATS core text protocol: it connects, sends
+// CONNECT and one PUB per message, and waits for the PONG to its PING so
+// that a server-side error is reported before returning.
+type natsPublisher struct {
+    addr    string
+    subject string
+}
+
+func (p *natsPublisher) Publish(key string, messages [][]byte) error {
+    conn, err := net.DialTimeout("tcp", p.addr, 5*time.Second)
+    if err != nil {
+        return fmt.Errorf("nats: %w", err)
+    }
+    defer conn.Close()
+    conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+    r := bufio.NewReader(conn)
+    info, err := r.ReadString('\n')
+    if err != nil {
+        return fmt.Errorf("nats: reading INFO: %w", err)
+    }
+    if !strings.HasPrefix(info, "INFO ") {
+        return fmt.Errorf("nats: unexpected greeting %q", strings.TrimSpace(info))
+    }
+
+    w := bufio.NewWriter(conn)
+    fmt.Fprint(w, `CONNECT {"verbose":false,"pedantic":false,"name":"bhiv-chain-inspector","lang":"go"}`+"\r\n")
+    for _, msg := range messages {
+        fmt.Fprintf(w, "PUB %s %d\r\n", p.subject, len(msg))
+        w.Write(msg)
+        w.WriteString("\r\n")
+    }
+    w.WriteString("PING\r\n")
+    if err := w.Flush(); err != nil {
+        return fmt.Errorf("nats: %w", err)
+    }
+
+    for {
+        line, err := r.ReadString('\n')
+        if err != nil {
+            return fmt.Errorf("nats: waiting for PONG: %w", err)
+        }
+        line = strings.TrimSpace(line)
+        switch {
+        case line == "PONG":
+            return nil
+        case line == "PING":
+            fmt.Fprint(conn, "PONG\r\n")
+        case strings.HasPrefix(line, "-ERR"):
+            return fmt.Errorf("nats: %s", line)
+        }
+    }
+}
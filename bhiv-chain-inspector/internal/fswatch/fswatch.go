@@ -0,0 +1,37 @@
+// Package fswatch reports writes to the files of a directory, so that
+// follow can pass over a database as soon as it is appended to instead of
+// at the next poll. It is implemented with inotify on Linux; elsewhere
+// Watch returns ErrNotSupported.
+package fswatch
+
+import "errors"
+
+// ErrNotSupported is returned by Watch on platforms without inotify.
+var ErrNotSupported = errors.New("watching directories is not supported on this platform")
+
+// Watcher signals changes to one directory. Changes that arrive while the
+// last signal is still unread are folded into it, so a reader that falls
+// behind sees one signal, not a backlog.
+type Watcher struct {
+    changes chan struct{}
+    close   func() error
+}
+
+// Changes receives a value after files in the directory are created,
+// written, renamed or removed.
+func (w *Watcher) Changes() <-chan struct{} {
+    return w.changes
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+    return w.close()
+}
+
+// signal marks a change, unless one is already waiting to be read.
+func (w *Watcher) signal() {
+    select {
+    case w.changes <- struct{}{}:
+    default:
+    }
+}
@@ -0,0 +1,41 @@
+//go:build linux
+
+package fswatch
+
+import (
+    "os"
+    "syscall"
+)
+
+// watchEvents are the inotify events that mean a file in the directory
+// changed. Opening and reading do not count, so readers of a database do
+// not wake its watchers.
+const watchEvents = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+    syscall.IN_MOVED_TO | syscall.IN_DELETE
+
+// Watch watches the files directly in dir.
+func Watch(dir string) (*Watcher, error) {
+    fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+    if err != nil {
+        return nil, os.NewSyscallError("inotify_init1", err)
+    }
+    if _, err := syscall.InotifyAddWatch(fd, dir, watchEvents); err != nil {
+        syscall.Close(fd)
+        return nil, &os.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+    }
+    // A non-blocking descriptor goes to the runtime poller, so Close
+    // wakes the pending Read.
+    events := os.NewFile(uintptr(fd), "inotify")
+    w := &Watcher{changes: make(chan struct{}, 1), close: events.Close}
+    go func() {
+        // The events themselves are not needed, only that some arrived.
+        buf := make([]byte, 16*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+        for {
+            if _, err := events.Read(buf); err != nil {
+                return
+            }
+            w.signal()
+        }
+    }()
+    return w, nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package fswatch
+
+// Watch is not supported on this platform.
+func Watch(dir string) (*Watcher, error) {
+    return nil, ErrNotSupported
+}
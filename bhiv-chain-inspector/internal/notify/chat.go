@@ -0,0 +1,87 @@
+package notify
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "sort"
+    "strings"
+    "time"
+
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// topClasses is how many error classes a chat summary lists.
+const topClasses = 3
+
+// Slack posts a summary to a Slack incoming webhook.
+type Slack struct {
+    URL     string
+    Retries int
+}
+
+func (s *Slack) Name() string { return "slack" }
+
+func (s *Slack) Notify(result *errors.ErrorScanResult) error {
+    return postChat(s.URL, map[string]string{"text": Summary(result, "*")}, s.Retries)
+}
+
+// Discord posts a summary to a Discord webhook.
+type Discord struct {
+    URL     string
+    Retries int
+}
+
+func (d *Discord) Name() string { return "discord" }
+
+func (d *Discord) Notify(result *errors.ErrorScanResult) error {
+    text := Summary(result, "**")
+    if runes := []rune(text); len(runes) > 2000 { // Discord's message limit
+        text = string(runes[:1997]) + "..."
+    }
+    return postChat(d.URL, map[string]string{"content": text}, d.Retries)
+}
+
+func postChat(url string, payload map[string]string, retries int) error {
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return err
+    }
+    header := http.Header{}
+    header.Set("Content-Type", "application/json")
+    return post(url, body, header, retryPolicy{Retries: retries, Backoff: time.Second})
+}
+
+// Summary is a short human-readable digest of result for chat: status,
+// health score and the most frequent error classes. bold is the markup
+// used for emphasis ("*" for Slack, "**" for Discord/Markdown).
+func Summary(result *errors.ErrorScanResult, bold string) string {
+    var sb strings.Builder
+    fmt.Fprintf(&sb, "%sChain inspector: %s%s on %s\n", bold, result.Status, bold, result.DatabasePath)
+    fmt.Fprintf(&sb, "Health %d%% | %d errors, %d warnings in %d blocks\n",
+        result.HealthScore, result.TotalErrors, result.TotalWarnings, result.BlocksScanned)
+
+    counts := make(map[string]int)
+    for _, issue := range result.Issues() {
+        if issue.Severity == errors.SeverityError {
+            counts[issue.Class]++
+        }
+    }
+    classes := make([]string, 0, len(counts))
+    for class := range counts {
+        classes = append(classes, class)
+    }
+    sort.Slice(classes, func(i, j int) bool {
+        if counts[classes[i]] != counts[classes[j]] {
+            return counts[classes[i]] > counts[classes[j]]
+        }
+        return classes[i] < classes[j]
+    })
+    if len(classes) > topClasses {
+        classes = classes[:topClasses]
+    }
+    for _, class := range classes {
+        fmt.Fprintf(&sb, "• %s: %d\n", class, counts[class])
+    }
+    return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,79 @@
+// Package notify delivers scan results to external systems when watch
+// finds problems.
+package notify
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "log/slog"
+    "net/http"
+    "time"
+
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// Notifier sends a scan result somewhere.
+type Notifier interface {
+    Name() string
+    Notify(result *errors.ErrorScanResult) error
+}
+
+// retryPolicy controls post; the zero value tries once.
+type retryPolicy struct {
+    Retries int
+    Backoff time.Duration
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// post sends body to url, retrying network errors, 429 and 5xx responses
+// with exponential backoff. Other 4xx responses are not retried.
+func post(url string, body []byte, header http.Header, policy retryPolicy) error {
+    backoff := policy.Backoff
+    var lastErr error
+    for attempt := 0; attempt <= policy.Retries; attempt++ {
+        if attempt > 0 {
+            slog.Debug("retrying notification", "url", url, "attempt", attempt+1, "err", lastErr)
+            time.Sleep(backoff)
+            backoff *= 2
+        }
+
+        req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+        if err != nil {
+            return err
+        }
+        for key, values := range header {
+            req.Header[key] = values
+        }
+        resp, err := client.Do(req)
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+        resp.Body.Close()
+
+        switch {
+        case resp.StatusCode/100 == 2:
+            return nil
+        case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+            lastErr = fmt.Errorf("%s", resp.Status)
+        default:
+            return fmt.Errorf("%s rejected notification: %s", url, resp.Status)
+        }
+    }
+    return fmt.Errorf("%s: giving up after %d attempts: %w", url, policy.Retries+1, lastErr)
+}
+
+// NotifyAll sends result through every notifier, logging failures; one
+// failing notifier does not stop the others.
+func NotifyAll(notifiers []Notifier, result *errors.ErrorScanResult) {
+    for _, n := range notifiers {
+        if err := n.Notify(result); err != nil {
+            slog.Error("notification failed", "notifier", n.Name(), "err", err)
+        } else {
+            slog.Debug("notification sent", "notifier", n.Name())
+        }
+    }
+}
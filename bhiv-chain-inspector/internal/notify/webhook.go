@@ -0,0 +1,54 @@
+package notify
+
+import (
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "net/http"
+    "strconv"
+    "time"
+
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// Webhook POSTs the scan result as JSON (the scan --json report).
+//
+// With a secret, each request carries
+//
+//  X-Inspector-Timestamp: <unix seconds>
+//  X-Inspector-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">
+//
+// so receivers can verify the sender and reject replays of old requests.
+type Webhook struct {
+    URL     string
+    Secret  string
+    Retries int
+}
+
+func (w *Webhook) Name() string { return "webhook" }
+
+func (w *Webhook) Notify(result *errors.ErrorScanResult) error {
+    body, err := json.Marshal(result)
+    if err != nil {
+        return err
+    }
+    header := http.Header{}
+    header.Set("Content-Type", "application/json")
+    header.Set("User-Agent", "bhiv-chain-inspector")
+    if w.Secret != "" {
+        timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+        header.Set("X-Inspector-Timestamp", timestamp)
+        header.Set("X-Inspector-Signature", "sha256="+Sign(w.Secret, timestamp, body))
+    }
+    return post(w.URL, body, header, retryPolicy{Retries: w.Retries, Backoff: time.Second})
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" under secret.
+func Sign(secret, timestamp string, body []byte) string {
+    mac := hmac.New(sha256.New, []byte(secret))
+    mac.Write([]byte(timestamp))
+    mac.Write([]byte("."))
+    mac.Write(body)
+    return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,57 @@
+// Package plugins loads chain-specific validation checks from Go plugins
+// (built with -buildmode=plugin) and registers them with the scanner.
+//
+// A plugin is a main package inside this module that exports
+//
+//  func InspectorChecks() []errors.Check
+//
+// Go plugins must be built with the same toolchain and the same versions
+// of every shared package as the inspector binary, so they are kept in the
+// plugins/ directory of this module and built alongside it.
+package plugins
+
+import (
+    "fmt"
+    "plugin"
+
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// Symbol is the name every plugin must export.
+const Symbol = "InspectorChecks"
+
+// Load opens the plugin at path and registers the checks it provides.
+// It returns the names of the registered checks.
+func Load(path string) ([]string, error) {
+    p, err := plugin.Open(path)
+    if err != nil {
+        return nil, fmt.Errorf("cannot open plugin %s: %w", path, err)
+    }
+    sym, err := p.Lookup(Symbol)
+    if err != nil {
+        return nil, fmt.Errorf("plugin %s does not export %s: %w", path, Symbol, err)
+    }
+    factory, ok := sym.(func() []errors.Check)
+    if !ok {
+        return nil, fmt.Errorf("plugin %s: %s has type %T, want func() []errors.Check", path, Symbol, sym)
+    }
+
+    var names []string
+    for _, check := range factory() {
+        if err := register(check); err != nil {
+            return names, fmt.Errorf("plugin %s: %w", path, err)
+        }
+        names = append(names, check.Name())
+    }
+    return names, nil
+}
+
+func register(check errors.Check) (err error) {
+    defer func() {
+        if r := recover(); r != nil {
+            err = fmt.Errorf("%v", r)
+        }
+    }()
+    errors.RegisterCheck(check)
+    return nil
+}
@@ -0,0 +1,109 @@
+package rules
+
+import (
+    "fmt"
+    "regexp"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/config"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+var ruleName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Check is a scan check backed by an expression. Its name doubles as the
+// error class it reports, so severities, suppressions and health weights
+// can refer to it like any built-in class.
+//
+// Expressions see:
+//
+//  block.height, block.hash, block.prev_hash, block.data, block.timestamp
+//  prev.*   the previous block; rules that use it are skipped at genesis
+//  height   the key height the block was loaded from
+//  now      the scan start time (Unix seconds)
+type Check struct {
+    name    string
+    expr    *Expr
+    message string
+}
+
+// NewCheck compiles a configured rule.
+func NewCheck(rule config.RuleConfig) (*Check, error) {
+    if !ruleName.MatchString(rule.Name) {
+        return nil, fmt.Errorf("rule name %q must be lowercase letters, digits and underscores", rule.Name)
+    }
+    for _, class := range errors.KnownClasses() {
+        if class == rule.Name {
+            return nil, fmt.Errorf("rule name %q clashes with an existing error class", rule.Name)
+        }
+    }
+    expr, err := Compile(rule.Expr)
+    if err != nil {
+        return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+    }
+    return &Check{name: rule.Name, expr: expr, message: rule.Message}, nil
+}
+
+// Register compiles every configured rule and registers it with the
+// scanner. It returns the names of the registered rules.
+func Register(rules []config.RuleConfig) ([]string, error) {
+    var names []string
+    for _, rule := range rules {
+        c, err := NewCheck(rule)
+        if err != nil {
+            return names, err
+        }
+        for _, existing := range errors.CheckNames() {
+            if existing == c.name {
+                return names, fmt.Errorf("rule %s: a check with that name already exists", c.name)
+            }
+        }
+        errors.RegisterCheck(c)
+        names = append(names, c.name)
+    }
+    return names, nil
+}
+
+func (c *Check) Name() string { return c.name }
+
+func (c *Check) Classes() []string { return []string{c.name} }
+
+func (c *Check) Validate(block *blocks.Block, ctx *errors.CheckContext) []errors.Finding {
+    env := Env{
+        "block":  blockEnv(block),
+        "height": float64(ctx.Height),
+        "now":    float64(ctx.Now),
+    }
+    if ctx.Prev != nil {
+        env["prev"] = blockEnv(ctx.Prev)
+    }
+
+    ok, err := c.expr.EvalBool(env)
+    if IsUndefined(err) {
+        return nil
+    }
+    if err != nil {
+        return []errors.Finding{{Class: c.name,
+            Message: fmt.Sprintf("Block %d: rule %s failed: %v", ctx.Height, c.name, err)}}
+    }
+    if ok {
+        return nil
+    }
+
+    message := c.message
+    if message == "" {
+        message = c.expr.String()
+    }
+    return []errors.Finding{{Class: c.name,
+        Message: fmt.Sprintf("Block %d: %s", ctx.Height, message)}}
+}
+
+func blockEnv(b *blocks.Block) map[string]interface{} {
+    return map[string]interface{}{
+        "height":    float64(b.Height),
+        "hash":      b.Hash,
+        "prev_hash": b.PrevHash,
+        "data":      b.Data,
+        "timestamp": float64(b.Timestamp),
+    }
+}
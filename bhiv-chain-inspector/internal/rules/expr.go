@@ -0,0 +1,568 @@
+// Package rules implements a small expression language for user-defined
+// block validation rules, e.g.
+//
+//  block.timestamp - prev.timestamp < 60
+//  len(block.data) <= 4096 && !contains(block.data, "DROP TABLE")
+//
+// Values are numbers (float64), strings and booleans. Supported operators,
+// from lowest to highest precedence: ||, &&, == != < <= > >=, + -, * / %,
+// unary ! and -. Functions: len, contains, startsWith, endsWith, matches.
+package rules
+
+import (
+    "fmt"
+    "math"
+    "regexp"
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+// Expr is a compiled expression.
+type Expr struct {
+    src  string
+    root node
+}
+
+// Env resolves identifiers such as "block" or "height". Objects are
+// map[string]interface{} so that "block.timestamp" can be looked up.
+type Env map[string]interface{}
+
+// errUndefined is returned when an expression refers to something that is
+// absent in the environment, such as prev on the genesis block.
+type errUndefined struct{ name string }
+
+func (e errUndefined) Error() string { return fmt.Sprintf("%s is undefined", e.name) }
+
+// IsUndefined reports whether err came from a reference to a missing value.
+func IsUndefined(err error) bool {
+    _, ok := err.(errUndefined)
+    return ok
+}
+
+// Compile parses src.
+func Compile(src string) (*Expr, error) {
+    p := &parser{lex: lexer{src: src}}
+    p.next()
+    root, err := p.parseExpr(0)
+    if err != nil {
+        return nil, err
+    }
+    if p.tok.kind != tokEOF {
+        return nil, fmt.Errorf("unexpected %q at offset %d", p.tok.text, p.tok.pos)
+    }
+    return &Expr{src: src, root: root}, nil
+}
+
+func (e *Expr) String() string { return e.src }
+
+// Eval evaluates the expression in env.
+func (e *Expr) Eval(env Env) (interface{}, error) {
+    return e.root.eval(env)
+}
+
+// EvalBool evaluates the expression and requires a boolean result.
+func (e *Expr) EvalBool(env Env) (bool, error) {
+    v, err := e.Eval(env)
+    if err != nil {
+        return false, err
+    }
+    b, ok := v.(bool)
+    if !ok {
+        return false, fmt.Errorf("expression %q is %s, not a boolean", e.src, typeName(v))
+    }
+    return b, nil
+}
+
+// ---- lexer ----
+
+type tokKind int
+
+const (
+    tokEOF tokKind = iota
+    tokNum
+    tokStr
+    tokIdent
+    tokOp
+)
+
+type token struct {
+    kind tokKind
+    text string
+    num  float64
+    pos  int
+}
+
+type lexer struct {
+    src string
+    pos int
+}
+
+var twoCharOps = []string{"&&", "||", "==", "!=", "<=", ">="}
+
+func (l *lexer) next() (token, error) {
+    for l.pos < len(l.src) && unicode.IsSpace(rune(l.src[l.pos])) {
+        l.pos++
+    }
+    start := l.pos
+    if l.pos >= len(l.src) {
+        return token{kind: tokEOF, pos: start}, nil
+    }
+
+    c := l.src[l.pos]
+    switch {
+    case c >= '0' && c <= '9':
+        for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+            l.pos++
+        }
+        text := l.src[start:l.pos]
+        n, err := strconv.ParseFloat(text, 64)
+        if err != nil {
+            return token{}, fmt.Errorf("invalid number %q at offset %d", text, start)
+        }
+        return token{kind: tokNum, text: text, num: n, pos: start}, nil
+
+    case c == '"' || c == '\'':
+        l.pos++
+        var sb strings.Builder
+        for l.pos < len(l.src) && l.src[l.pos] != c {
+            if l.src[l.pos] == '\\' && l.pos+1 < len(l.src) {
+                l.pos++
+            }
+            sb.WriteByte(l.src[l.pos])
+            l.pos++
+        }
+        if l.pos >= len(l.src) {
+            return token{}, fmt.Errorf("unterminated string at offset %d", start)
+        }
+        l.pos++
+        return token{kind: tokStr, text: sb.String(), pos: start}, nil
+
+    case c == '_' || unicode.IsLetter(rune(c)):
+        for l.pos < len(l.src) && (l.src[l.pos] == '_' || isDigit(l.src[l.pos]) || unicode.IsLetter(rune(l.src[l.pos]))) {
+            l.pos++
+        }
+        return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}, nil
+    }
+
+    for _, op := range twoCharOps {
+        if strings.HasPrefix(l.src[l.pos:], op) {
+            l.pos += 2
+            return token{kind: tokOp, text: op, pos: start}, nil
+        }
+    }
+    if strings.IndexByte("+-*/%<>!().,", c) >= 0 {
+        l.pos++
+        return token{kind: tokOp, text: string(c), pos: start}, nil
+    }
+    return token{}, fmt.Errorf("unexpected character %q at offset %d", c, start)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// ---- parser ----
+
+type parser struct {
+    lex lexer
+    tok token
+    err error
+}
+
+func (p *parser) next() {
+    if p.err != nil {
+        return
+    }
+    p.tok, p.err = p.lex.next()
+}
+
+var binaryPrecedence = map[string]int{
+    "||": 1,
+    "&&": 2,
+    "==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+    "+": 4, "-": 4,
+    "*": 5, "/": 5, "%": 5,
+}
+
+func (p *parser) parseExpr(minPrec int) (node, error) {
+    left, err := p.parseUnary()
+    if err != nil {
+        return nil, err
+    }
+    for {
+        if p.err != nil {
+            return nil, p.err
+        }
+        prec, ok := binaryPrecedence[p.tok.text]
+        if p.tok.kind != tokOp || !ok || prec <= minPrec {
+            return left, nil
+        }
+        op := p.tok.text
+        p.next()
+        right, err := p.parseExpr(prec)
+        if err != nil {
+            return nil, err
+        }
+        left = binaryNode{op: op, left: left, right: right}
+    }
+}
+
+func (p *parser) parseUnary() (node, error) {
+    if p.tok.kind == tokOp && (p.tok.text == "!" || p.tok.text == "-") {
+        op := p.tok.text
+        p.next()
+        operand, err := p.parseUnary()
+        if err != nil {
+            return nil, err
+        }
+        return unaryNode{op: op, operand: operand}, nil
+    }
+    return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+    if p.err != nil {
+        return nil, p.err
+    }
+    tok := p.tok
+    switch tok.kind {
+    case tokNum:
+        p.next()
+        return literalNode{value: tok.num}, nil
+    case tokStr:
+        p.next()
+        return literalNode{value: tok.text}, nil
+    case tokIdent:
+        p.next()
+        switch tok.text {
+        case "true":
+            return literalNode{value: true}, nil
+        case "false":
+            return literalNode{value: false}, nil
+        }
+        if p.tok.kind == tokOp && p.tok.text == "(" {
+            return p.parseCall(tok)
+        }
+        path := []string{tok.text}
+        for p.tok.kind == tokOp && p.tok.text == "." {
+            p.next()
+            if p.tok.kind != tokIdent {
+                return nil, fmt.Errorf("expected field name after '.' at offset %d", p.tok.pos)
+            }
+            path = append(path, p.tok.text)
+            p.next()
+        }
+        return identNode{path: path}, nil
+    case tokOp:
+        if tok.text == "(" {
+            p.next()
+            inner, err := p.parseExpr(0)
+            if err != nil {
+                return nil, err
+            }
+            if p.tok.text != ")" {
+                return nil, fmt.Errorf("expected ')' at offset %d", p.tok.pos)
+            }
+            p.next()
+            return inner, nil
+        }
+    case tokEOF:
+        return nil, fmt.Errorf("unexpected end of expression")
+    }
+    return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+    fn, ok := functions[name.text]
+    if !ok {
+        return nil, fmt.Errorf("unknown function %q at offset %d", name.text, name.pos)
+    }
+    p.next() // (
+    var args []node
+    for p.tok.text != ")" {
+        arg, err := p.parseExpr(0)
+        if err != nil {
+            return nil, err
+        }
+        args = append(args, arg)
+        if p.tok.text == "," {
+            p.next()
+        } else if p.tok.text != ")" {
+            return nil, fmt.Errorf("expected ',' or ')' at offset %d", p.tok.pos)
+        }
+    }
+    p.next()
+    if len(args) != fn.arity {
+        return nil, fmt.Errorf("%s takes %d arguments, got %d", name.text, fn.arity, len(args))
+    }
+    call := callNode{name: name.text, fn: fn, args: args}
+    if name.text == "matches" {
+        // Compile constant patterns once.
+        if lit, ok := args[1].(literalNode); ok {
+            pattern, isStr := lit.value.(string)
+            if !isStr {
+                return nil, fmt.Errorf("matches: pattern must be a string")
+            }
+            re, err := regexp.Compile(pattern)
+            if err != nil {
+                return nil, fmt.Errorf("matches: %w", err)
+            }
+            call.re = re
+        }
+    }
+    return call, nil
+}
+
+// ---- evaluation ----
+
+type node interface {
+    eval(env Env) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+func (n literalNode) eval(Env) (interface{}, error) { return n.value, nil }
+
+type identNode struct{ path []string }
+
+func (n identNode) eval(env Env) (interface{}, error) {
+    v, ok := env[n.path[0]]
+    if !ok || v == nil {
+        return nil, errUndefined{n.path[0]}
+    }
+    for i, field := range n.path[1:] {
+        obj, isObj := v.(map[string]interface{})
+        if !isObj {
+            return nil, fmt.Errorf("%s is not an object", strings.Join(n.path[:i+1], "."))
+        }
+        if v, ok = obj[field]; !ok {
+            return nil, fmt.Errorf("unknown field %s", strings.Join(n.path[:i+2], "."))
+        }
+    }
+    return v, nil
+}
+
+type unaryNode struct {
+    op      string
+    operand node
+}
+
+func (n unaryNode) eval(env Env) (interface{}, error) {
+    v, err := n.operand.eval(env)
+    if err != nil {
+        return nil, err
+    }
+    switch n.op {
+    case "!":
+        b, ok := v.(bool)
+        if !ok {
+            return nil, fmt.Errorf("! needs a boolean, got %s", typeName(v))
+        }
+        return !b, nil
+    default:
+        f, ok := v.(float64)
+        if !ok {
+            return nil, fmt.Errorf("- needs a number, got %s", typeName(v))
+        }
+        return -f, nil
+    }
+}
+
+type binaryNode struct {
+    op          string
+    left, right node
+}
+
+func (n binaryNode) eval(env Env) (interface{}, error) {
+    l, err := n.left.eval(env)
+    if err != nil {
+        return nil, err
+    }
+
+    // Short-circuit logical operators.
+    if n.op == "&&" || n.op == "||" {
+        lb, ok := l.(bool)
+        if !ok {
+            return nil, fmt.Errorf("%s needs booleans, got %s", n.op, typeName(l))
+        }
+        if (n.op == "&&" && !lb) || (n.op == "||" && lb) {
+            return lb, nil
+        }
+        r, err := n.right.eval(env)
+        if err != nil {
+            return nil, err
+        }
+        rb, ok := r.(bool)
+        if !ok {
+            return nil, fmt.Errorf("%s needs booleans, got %s", n.op, typeName(r))
+        }
+        return rb, nil
+    }
+
+    r, err := n.right.eval(env)
+    if err != nil {
+        return nil, err
+    }
+
+    switch n.op {
+    case "==":
+        return equal(l, r), nil
+    case "!=":
+        return !equal(l, r), nil
+    }
+
+    if ls, ok := l.(string); ok {
+        rs, ok := r.(string)
+        if !ok {
+            return nil, fmt.Errorf("cannot apply %s to string and %s", n.op, typeName(r))
+        }
+        switch n.op {
+        case "+":
+            return ls + rs, nil
+        case "<":
+            return ls < rs, nil
+        case "<=":
+            return ls <= rs, nil
+        case ">":
+            return ls > rs, nil
+        case ">=":
+            return ls >= rs, nil
+        }
+        return nil, fmt.Errorf("cannot apply %s to strings", n.op)
+    }
+
+    lf, lok := l.(float64)
+    rf, rok := r.(float64)
+    if !lok || !rok {
+        return nil, fmt.Errorf("cannot apply %s to %s and %s", n.op, typeName(l), typeName(r))
+    }
+    switch n.op {
+    case "+":
+        return lf + rf, nil
+    case "-":
+        return lf - rf, nil
+    case "*":
+        return lf * rf, nil
+    case "/":
+        if rf == 0 {
+            return nil, fmt.Errorf("division by zero")
+        }
+        return lf / rf, nil
+    case "%":
+        if rf == 0 {
+            return nil, fmt.Errorf("division by zero")
+        }
+        return math.Mod(lf, rf), nil
+    case "<":
+        return lf < rf, nil
+    case "<=":
+        return lf <= rf, nil
+    case ">":
+        return lf > rf, nil
+    case ">=":
+        return lf >= rf, nil
+    }
+    return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+func equal(a, b interface{}) bool {
+    return a == b
+}
+
+type function struct {
+    arity int
+    call  func(args []interface{}, re *regexp.Regexp) (interface{}, error)
+}
+
+type callNode struct {
+    name string
+    fn   function
+    args []node
+    re   *regexp.Regexp
+}
+
+func (n callNode) eval(env Env) (interface{}, error) {
+    args := make([]interface{}, len(n.args))
+    for i, arg := range n.args {
+        v, err := arg.eval(env)
+        if err != nil {
+            return nil, err
+        }
+        args[i] = v
+    }
+    v, err := n.fn.call(args, n.re)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", n.name, err)
+    }
+    return v, nil
+}
+
+func stringArgs(args []interface{}) ([]string, error) {
+    out := make([]string, len(args))
+    for i, a := range args {
+        s, ok := a.(string)
+        if !ok {
+            return nil, fmt.Errorf("argument %d must be a string, got %s", i+1, typeName(a))
+        }
+        out[i] = s
+    }
+    return out, nil
+}
+
+var functions = map[string]function{
+    "len": {1, func(args []interface{}, _ *regexp.Regexp) (interface{}, error) {
+        s, err := stringArgs(args)
+        if err != nil {
+            return nil, err
+        }
+        return float64(len(s[0])), nil
+    }},
+    "contains": {2, func(args []interface{}, _ *regexp.Regexp) (interface{}, error) {
+        s, err := stringArgs(args)
+        if err != nil {
+            return nil, err
+        }
+        return strings.Contains(s[0], s[1]), nil
+    }},
+    "startsWith": {2, func(args []interface{}, _ *regexp.Regexp) (interface{}, error) {
+        s, err := stringArgs(args)
+        if err != nil {
+            return nil, err
+        }
+        return strings.HasPrefix(s[0], s[1]), nil
+    }},
+    "endsWith": {2, func(args []interface{}, _ *regexp.Regexp) (interface{}, error) {
+        s, err := stringArgs(args)
+        if err != nil {
+            return nil, err
+        }
+        return strings.HasSuffix(s[0], s[1]), nil
+    }},
+    "matches": {2, func(args []interface{}, re *regexp.Regexp) (interface{}, error) {
+        s, err := stringArgs(args)
+        if err != nil {
+            return nil, err
+        }
+        if re == nil {
+            if re, err = regexp.Compile(s[1]); err != nil {
+                return nil, err
+            }
+        }
+        return re.MatchString(s[0]), nil
+    }},
+}
+
+func typeName(v interface{}) string {
+    switch v.(type) {
+    case float64:
+        return "number"
+    case string:
+        return "string"
+    case bool:
+        return "boolean"
+    case map[string]interface{}:
+        return "object"
+    case nil:
+        return "null"
+    }
+    return fmt.Sprintf("%T", v)
+}
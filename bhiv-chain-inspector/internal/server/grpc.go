@@ -0,0 +1,172 @@
+package server
+
+import (
+    "context"
+
+    inspectorv1 "bhiv-chain-inspector/api"
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/errors"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+// RegisterGRPC serves the ChainInspector service of s on g. The methods
+// are those of Handler, with responses converted to the messages of
+// api/inspector.proto.
+func (s *Server) RegisterGRPC(g *grpc.Server) {
+    inspectorv1.RegisterChainInspectorServer(g, grpcService{s: s})
+}
+
+type grpcService struct {
+    inspectorv1.UnimplementedChainInspectorServer
+    s *Server
+}
+
+func (g grpcService) ScanErrors(ctx context.Context, req *inspectorv1.ScanErrorsRequest) (*inspectorv1.ScanErrorsResponse, error) {
+    result, rerr := g.s.scan(req.DbPath)
+    if rerr != nil {
+        return nil, rerr.grpcStatus()
+    }
+    return scanResponse(result), nil
+}
+
+func (g grpcService) CompareNodes(ctx context.Context, req *inspectorv1.CompareNodesRequest) (*inspectorv1.CompareNodesResponse, error) {
+    result, rerr := g.s.compare(req.Db1Path, req.Db2Path)
+    if rerr != nil {
+        return nil, rerr.grpcStatus()
+    }
+    return &inspectorv1.CompareNodesResponse{
+        ScanTime:            result.ScanTime,
+        Node1Path:           result.Node1Path,
+        Node2Path:           result.Node2Path,
+        Node1Height:         int64(result.Node1Height),
+        Node2Height:         int64(result.Node2Height),
+        MatchingBlocks:      int64(result.MatchingBlocks),
+        MismatchedBlocks:    heights(result.MismatchedBlocks),
+        Node1OnlyBlocks:     heights(result.Node1OnlyBlocks),
+        Node2OnlyBlocks:     heights(result.Node2OnlyBlocks),
+        DivergencePoint:     int64(result.DivergencePoint),
+        HashMismatches:      result.HashMismatches,
+        DataMismatches:      result.DataMismatches,
+        TimestampMismatches: result.TimestampMismatches,
+        SyncPercentage:      result.SyncPercentage,
+        Recommendations:     result.Recommendations,
+    }, nil
+}
+
+func (g grpcService) GetBlock(ctx context.Context, req *inspectorv1.GetBlockRequest) (*inspectorv1.Block, error) {
+    block, rerr := g.s.block(req.DbPath, int(req.Height))
+    if rerr != nil {
+        return nil, rerr.grpcStatus()
+    }
+    return blockMessage(block), nil
+}
+
+func (g grpcService) StreamBlocks(req *inspectorv1.StreamBlocksRequest, stream grpc.ServerStreamingServer[inspectorv1.Block]) error {
+    var to *int
+    if req.ToHeight != nil {
+        h := int(*req.ToHeight)
+        to = &h
+    }
+    rerr := g.s.eachBlock(stream.Context(), req.DbPath, int(req.FromHeight), to, func(block *blocks.Block) error {
+        return stream.Send(blockMessage(block))
+    })
+    if rerr != nil {
+        return rerr.grpcStatus()
+    }
+    return stream.Context().Err()
+}
+
+// grpcCodes maps the Twirp codes of rpcError to gRPC status codes, which
+// Twirp's are named after.
+var grpcCodes = map[string]codes.Code{
+    "invalid_argument": codes.InvalidArgument,
+    "not_found":        codes.NotFound,
+    "data_loss":        codes.DataLoss,
+    "bad_route":        codes.Unimplemented,
+    "internal":         codes.Internal,
+}
+
+func (e *rpcError) grpcStatus() error {
+    code, ok := grpcCodes[e.Code]
+    if !ok {
+        code = codes.Unknown
+    }
+    return status.Error(code, e.Msg)
+}
+
+func blockMessage(b *blocks.Block) *inspectorv1.Block {
+    return &inspectorv1.Block{
+        Height:     int64(b.Height),
+        Hash:       b.Hash,
+        PrevHash:   b.PrevHash,
+        Data:       b.Data,
+        Timestamp:  b.Timestamp,
+        MerkleRoot: b.MerkleRoot,
+        Difficulty: int64(b.Difficulty),
+        Nonce:      b.Nonce,
+        Signer:     b.Signer,
+        Signature:  b.Signature,
+        ChainId:    b.ChainID,
+    }
+}
+
+func scanResponse(r *errors.ErrorScanResult) *inspectorv1.ScanErrorsResponse {
+    resp := &inspectorv1.ScanErrorsResponse{
+        ScanTime:               r.ScanTime,
+        DatabasePath:           r.DatabasePath,
+        TotalBlocks:            int64(r.TotalBlocks),
+        BlocksScanned:          int64(r.BlocksScanned),
+        HeightsChecked:         int64(r.HeightsChecked),
+        TotalErrors:            int64(r.TotalErrors),
+        TotalWarnings:          int64(r.TotalWarnings),
+        TotalInfo:              int64(r.TotalInfo),
+        Suppressed:             int64(r.Suppressed),
+        CorruptedJson:          entryMessages(r.CorruptedJSON),
+        BadHash:                entryMessages(r.BadHash),
+        TimestampFuture:        entryMessages(r.TimestampFuture),
+        TimestampPast:          entryMessages(r.TimestampPast),
+        TimestampNotIncreasing: entryMessages(r.TimestampNotIncreasing),
+        DuplicateHashes:        entryMessages(r.DuplicateHashes),
+        EmptyBlocks:            entryMessages(r.EmptyBlocks),
+        PrevhashErrors:         entryMessages(r.PrevHashErrors),
+        HeightErrors:           entryMessages(r.HeightErrors),
+        MissingBlocks:          heights(r.MissingBlocks),
+        OutOfOrderBlocks:       entryMessages(r.OutOfOrderBlocks),
+        HealthScore:            int64(r.HealthScore),
+        Status:                 r.Status,
+        Severities:             r.Severities,
+        Checks:                 r.Checks,
+        HashAlgorithm:          r.HashAlgorithm,
+    }
+    if len(r.Custom) > 0 {
+        resp.Custom = make(map[string]*inspectorv1.ErrorEntries, len(r.Custom))
+        for class, entries := range r.Custom {
+            resp.Custom[class] = &inspectorv1.ErrorEntries{Entries: entryMessages(entries)}
+        }
+    }
+    return resp
+}
+
+func entryMessages(entries []errors.ErrorEntry) []*inspectorv1.ErrorEntry {
+    var msgs []*inspectorv1.ErrorEntry
+    for _, e := range entries {
+        msgs = append(msgs, &inspectorv1.ErrorEntry{
+            Height:   int64(e.Height),
+            Code:     string(e.Code),
+            Expected: e.Expected,
+            Actual:   e.Actual,
+            Message:  e.Message,
+        })
+    }
+    return msgs
+}
+
+func heights(hs []int) []int64 {
+    var out []int64
+    for _, h := range hs {
+        out = append(out, int64(h))
+    }
+    return out
+}
@@ -0,0 +1,430 @@
+// Package server implements the ChainInspector service (api/inspector.proto)
+// over gRPC, and over HTTP/JSON following the Twirp conventions: every RPC
+// is a POST to /twirp/inspector.v1.ChainInspector/<Method> with a JSON body.
+package server
+
+import (
+    "context"
+    "encoding/json"
+    stderrors "errors"
+    "fmt"
+    "log/slog"
+    "net/http"
+    "sync"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// PathPrefix is the route prefix of the ChainInspector service.
+const PathPrefix = "/twirp/inspector.v1.ChainInspector/"
+
+// ScanEventsPath is the WebSocket endpoint streaming scan progress.
+const ScanEventsPath = "/ws/scan"
+
+// Server serves scans of a fixed set of databases. Requests may only name
+// databases listed in Databases; an empty db_path selects the first one.
+type Server struct {
+    Databases []string
+    Scan      errors.ScanOptions
+
+    // AllowedOrigins are the browser origins, besides the server's own,
+    // that may open ScanEventsPath; "*" allows any.
+    AllowedOrigins []string
+
+    // LevelDB allows one open handle per database, so requests that touch
+    // the same database are serialized.
+    mu    sync.Mutex
+    locks map[string]*sync.Mutex
+}
+
+// New returns a server for the given databases; empty paths are ignored.
+func New(databases []string, scan errors.ScanOptions) *Server {
+    s := &Server{Scan: scan, locks: make(map[string]*sync.Mutex)}
+    for _, path := range databases {
+        if path != "" {
+            s.Databases = append(s.Databases, path)
+        }
+    }
+    return s
+}
+
+// Handler returns the HTTP handler serving every RPC as JSON; see
+// RegisterGRPC for gRPC.
+func (s *Server) Handler() http.Handler {
+    mux := http.NewServeMux()
+    mux.HandleFunc(PathPrefix+"ScanErrors", s.rpc(s.scanErrors))
+    mux.HandleFunc(PathPrefix+"CompareNodes", s.rpc(s.compareNodes))
+    mux.HandleFunc(PathPrefix+"GetBlock", s.rpc(s.getBlock))
+    mux.HandleFunc(PathPrefix+"StreamBlocks", s.streamBlocks)
+    mux.HandleFunc(ScanEventsPath, s.scanEvents)
+    return mux
+}
+
+// rpcError is a Twirp error; code is one of the Twirp error codes.
+type rpcError struct {
+    status int
+    Code   string `json:"code"`
+    Msg    string `json:"msg"`
+}
+
+func (e *rpcError) Error() string { return e.Code + ": " + e.Msg }
+
+func invalidArgument(format string, args ...interface{}) *rpcError {
+    return &rpcError{http.StatusBadRequest, "invalid_argument", fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...interface{}) *rpcError {
+    return &rpcError{http.StatusNotFound, "not_found", fmt.Sprintf(format, args...)}
+}
+
+func internal(err error) *rpcError {
+    return &rpcError{http.StatusInternalServerError, "internal", err.Error()}
+}
+
+func writeError(w http.ResponseWriter, err *rpcError) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(err.status)
+    json.NewEncoder(w).Encode(err)
+}
+
+// decode checks the method and reads the JSON request into req.
+func decode(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+    if r.Method != http.MethodPost {
+        writeError(w, &rpcError{http.StatusMethodNotAllowed, "bad_route", "use POST"})
+        return false
+    }
+    if r.ContentLength != 0 {
+        if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+            writeError(w, invalidArgument("malformed request: %v", err))
+            return false
+        }
+    }
+    return true
+}
+
+// rpc adapts a unary method to an HTTP handler.
+func (s *Server) rpc(method func(r *http.Request, body json.RawMessage) (interface{}, *rpcError)) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        var body json.RawMessage
+        if !decode(w, r, &body) {
+            return
+        }
+        if len(body) == 0 {
+            body = json.RawMessage("{}")
+        }
+        resp, rerr := method(r, body)
+        if rerr != nil {
+            slog.Warn("rpc failed", "path", r.URL.Path, "code", rerr.Code, "msg", rerr.Msg)
+            writeError(w, rerr)
+            return
+        }
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(resp)
+    }
+}
+
+// resolve maps a requested db_path to a served database.
+func (s *Server) resolve(path string) (string, *rpcError) {
+    if len(s.Databases) == 0 {
+        return "", invalidArgument("server has no databases")
+    }
+    if path == "" {
+        return s.Databases[0], nil
+    }
+    for _, served := range s.Databases {
+        if served == path {
+            return path, nil
+        }
+    }
+    return "", notFound("database %q is not served", path)
+}
+
+// open locks and opens a served database; release closes and unlocks it.
+func (s *Server) open(path string) (storage *db.Storage, release func(), rerr *rpcError) {
+    s.mu.Lock()
+    lock, ok := s.locks[path]
+    if !ok {
+        lock = &sync.Mutex{}
+        s.locks[path] = lock
+    }
+    s.mu.Unlock()
+
+    lock.Lock()
+    storage, err := db.NewStorage(path)
+    if err != nil {
+        lock.Unlock()
+        return nil, nil, internal(err)
+    }
+    return storage, func() {
+        storage.Close()
+        lock.Unlock()
+    }, nil
+}
+
+type scanErrorsRequest struct {
+    DBPath string `json:"db_path"`
+}
+
+func (s *Server) scanErrors(r *http.Request, body json.RawMessage) (interface{}, *rpcError) {
+    var req scanErrorsRequest
+    if err := decodeRequest(body, &req); err != nil {
+        return nil, err
+    }
+    return s.scan(req.DBPath)
+}
+
+// scan runs a scan of the served database at dbPath.
+func (s *Server) scan(dbPath string) (*errors.ErrorScanResult, *rpcError) {
+    path, rerr := s.resolve(dbPath)
+    if rerr != nil {
+        return nil, rerr
+    }
+    storage, release, rerr := s.open(path)
+    if rerr != nil {
+        return nil, rerr
+    }
+    defer release()
+
+    return errors.ScanErrors(storage, path, s.Scan), nil
+}
+
+type compareNodesRequest struct {
+    DB1Path string `json:"db1_path"`
+    DB2Path string `json:"db2_path"`
+}
+
+func (s *Server) compareNodes(r *http.Request, body json.RawMessage) (interface{}, *rpcError) {
+    var req compareNodesRequest
+    if err := decodeRequest(body, &req); err != nil {
+        return nil, err
+    }
+    return s.compare(req.DB1Path, req.DB2Path)
+}
+
+// compare compares two served databases.
+func (s *Server) compare(db1Path, db2Path string) (*errors.ComparisonResult, *rpcError) {
+    if db1Path == "" || db2Path == "" {
+        return nil, invalidArgument("db1_path and db2_path are required")
+    }
+    if db1Path == db2Path {
+        return nil, invalidArgument("db1_path and db2_path must differ")
+    }
+    path1, rerr := s.resolve(db1Path)
+    if rerr != nil {
+        return nil, rerr
+    }
+    path2, rerr := s.resolve(db2Path)
+    if rerr != nil {
+        return nil, rerr
+    }
+
+    // Lock in a fixed order so concurrent comparisons cannot deadlock.
+    first, second := path1, path2
+    if second < first {
+        first, second = second, first
+    }
+    storageA, releaseA, rerr := s.open(first)
+    if rerr != nil {
+        return nil, rerr
+    }
+    defer releaseA()
+    storageB, releaseB, rerr := s.open(second)
+    if rerr != nil {
+        return nil, rerr
+    }
+    defer releaseB()
+
+    storage1, storage2 := storageA, storageB
+    if first != path1 {
+        storage1, storage2 = storageB, storageA
+    }
+    return errors.CompareNodes(storage1, storage2, path1, path2, errors.CompareOptions{}), nil
+}
+
+type getBlockRequest struct {
+    DBPath string `json:"db_path"`
+    Height int    `json:"height"`
+}
+
+func (s *Server) getBlock(r *http.Request, body json.RawMessage) (interface{}, *rpcError) {
+    var req getBlockRequest
+    if err := decodeRequest(body, &req); err != nil {
+        return nil, err
+    }
+    return s.block(req.DBPath, req.Height)
+}
+
+// block loads the block at height of a served database.
+func (s *Server) block(dbPath string, height int) (*blocks.Block, *rpcError) {
+    path, rerr := s.resolve(dbPath)
+    if rerr != nil {
+        return nil, rerr
+    }
+    storage, release, rerr := s.open(path)
+    if rerr != nil {
+        return nil, rerr
+    }
+    defer release()
+
+    block, err := storage.LoadBlock(height)
+    if stderrors.Is(err, db.ErrBlockMissing) {
+        return nil, notFound("block %d does not exist", height)
+    }
+    if err != nil {
+        return nil, &rpcError{http.StatusInternalServerError, "data_loss", err.Error()}
+    }
+    return block, nil
+}
+
+type streamBlocksRequest struct {
+    DBPath     string `json:"db_path"`
+    FromHeight int    `json:"from_height"`
+    // ToHeight is nil to stream up to the tip.
+    ToHeight   *int   `json:"to_height"`
+}
+
+// streamBlocks writes one JSON block per line, flushing after each, and
+// stops early if the client goes away.
+func (s *Server) streamBlocks(w http.ResponseWriter, r *http.Request) {
+    var req streamBlocksRequest
+    if !decode(w, r, &req) {
+        return
+    }
+    flusher, _ := w.(http.Flusher)
+    enc := json.NewEncoder(w)
+    started := false
+    rerr := s.eachBlock(r.Context(), req.DBPath, req.FromHeight, req.ToHeight, func(block *blocks.Block) error {
+        if !started {
+            w.Header().Set("Content-Type", "application/x-ndjson")
+            started = true
+        }
+        if err := enc.Encode(block); err != nil {
+            return err
+        }
+        if flusher != nil {
+            flusher.Flush()
+        }
+        return nil
+    })
+    switch {
+    case rerr != nil && !started:
+        writeError(w, rerr)
+    case !started:
+        w.Header().Set("Content-Type", "application/x-ndjson")
+    }
+}
+
+// eachBlock calls send with every block of a served database from from
+// through *to, or through the chain tip if to is nil. Missing and
+// corrupted blocks are skipped; scans report them. It stops when ctx is
+// done or send fails.
+func (s *Server) eachBlock(ctx context.Context, dbPath string, from int, to *int, send func(*blocks.Block) error) *rpcError {
+    switch {
+    case from < 0:
+        return invalidArgument("from_height %d is negative", from)
+    case to != nil && *to < from:
+        return invalidArgument("empty range %d..%d", from, *to)
+    }
+    path, rerr := s.resolve(dbPath)
+    if rerr != nil {
+        return rerr
+    }
+    storage, release, rerr := s.open(path)
+    if rerr != nil {
+        return rerr
+    }
+    defer release()
+
+    last := storage.GetMaxHeight()
+    if to != nil {
+        last = *to
+    }
+    for h := from; h <= last; h++ {
+        if ctx.Err() != nil {
+            return nil
+        }
+        block, err := storage.LoadBlock(h)
+        if err != nil {
+            continue
+        }
+        if err := send(block); err != nil {
+            return nil
+        }
+    }
+    return nil
+}
+
+func decodeRequest(body json.RawMessage, v interface{}) *rpcError {
+    if err := json.Unmarshal(body, v); err != nil {
+        return invalidArgument("malformed request: %v", err)
+    }
+    return nil
+}
+
+// ScanEvent is one WebSocket message of a live scan: a "block" event per
+// checked height, then a single "result" event with the full report, or an
+// "error" event if the database cannot be opened.
+type ScanEvent struct {
+    Type   string                  `json:"type"`
+    Height *int                    `json:"height,omitempty"`
+    Issues []errors.Issue          `json:"issues,omitempty"`
+    Result *errors.ErrorScanResult `json:"result,omitempty"`
+    Error  string                  `json:"error,omitempty"`
+}
+
+// scanEvents runs a scan of ?db_path= and streams its progress over a
+// WebSocket. A client that disconnects, or stops reading, cancels the
+// scan.
+func (s *Server) scanEvents(w http.ResponseWriter, r *http.Request) {
+    path, rerr := s.resolve(r.URL.Query().Get("db_path"))
+    if rerr != nil {
+        writeError(w, rerr)
+        return
+    }
+    ws, err := upgradeWebSocket(w, r, s.AllowedOrigins)
+    if err != nil {
+        slog.Warn("websocket upgrade failed", "err", err)
+        return
+    }
+    defer ws.Close()
+
+    // A hijacked request's context no longer notices the client leaving;
+    // the WebSocket read loop does.
+    ctx, cancel := context.WithCancel(r.Context())
+    defer cancel()
+    go func() {
+        select {
+        case <-ws.Done():
+            cancel()
+        case <-ctx.Done():
+        }
+    }()
+    send := func(event ScanEvent) {
+        if ctx.Err() != nil {
+            return
+        }
+        data, _ := json.Marshal(event)
+        if err := ws.WriteText(data); err != nil {
+            slog.Debug("websocket write failed", "err", err)
+            cancel()
+        }
+    }
+
+    storage, release, rerr := s.open(path)
+    if rerr != nil {
+        send(ScanEvent{Type: "error", Error: rerr.Msg})
+        return
+    }
+    defer release()
+
+    opts := s.Scan
+    opts.Context = ctx
+    opts.OnBlock = func(v errors.BlockVerdict) {
+        height := v.Height
+        send(ScanEvent{Type: "block", Height: &height, Issues: v.Issues})
+    }
+    result := errors.ScanErrors(storage, path, opts)
+    send(ScanEvent{Type: "result", Result: result})
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+    "bufio"
+    "crypto/sha1"
+    "encoding/base64"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "net/url"
+    "slices"
+    "strings"
+    "sync"
+    "time"
+)
+
+// A minimal RFC 6455 server side: enough to push text messages to a
+// browser and notice when it goes away. Incoming data frames are ignored.
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// writeTimeout bounds each frame write, so a client that stops reading
+// cannot hold a scan or its database lock.
+const writeTimeout = 10 * time.Second
+
+const (
+    opText  = 0x1
+    opClose = 0x8
+    opPing  = 0x9
+    opPong  = 0xA
+)
+
+type wsConn struct {
+    conn   net.Conn
+    buf    *bufio.ReadWriter
+    mu     sync.Mutex // serializes writes
+    closed chan struct{}
+}
+
+// upgradeWebSocket completes the opening handshake and hijacks the
+// connection. Browsers may only connect from a page served by this host
+// or from one of allowedOrigins. On failure it has already written an
+// HTTP error.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, allowedOrigins []string) (*wsConn, error) {
+    if r.Method != http.MethodGet ||
+        !headerContains(r.Header, "Connection", "upgrade") ||
+        !headerContains(r.Header, "Upgrade", "websocket") {
+        http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+        return nil, fmt.Errorf("not a websocket request")
+    }
+    if r.Header.Get("Sec-WebSocket-Version") != "13" {
+        w.Header().Set("Sec-WebSocket-Version", "13")
+        http.Error(w, "unsupported websocket version", http.StatusBadRequest)
+        return nil, fmt.Errorf("unsupported websocket version")
+    }
+    if origin := r.Header.Get("Origin"); !originAllowed(origin, r.Host, allowedOrigins) {
+        http.Error(w, "origin not allowed", http.StatusForbidden)
+        return nil, fmt.Errorf("origin %q not allowed", origin)
+    }
+    key := r.Header.Get("Sec-WebSocket-Key")
+    if key == "" {
+        http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+        return nil, fmt.Errorf("missing Sec-WebSocket-Key")
+    }
+    hijacker, ok := w.(http.Hijacker)
+    if !ok {
+        http.Error(w, "websocket not supported", http.StatusInternalServerError)
+        return nil, fmt.Errorf("response does not support hijacking")
+    }
+    conn, buf, err := hijacker.Hijack()
+    if err != nil {
+        return nil, err
+    }
+
+    sum := sha1.Sum([]byte(key + websocketGUID))
+    fmt.Fprintf(buf, "HTTP/1.1 101 Switching Protocols\r\n"+
+        "Upgrade: websocket\r\nConnection: Upgrade\r\n"+
+        "Sec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+    if err := buf.Flush(); err != nil {
+        conn.Close()
+        return nil, err
+    }
+
+    ws := &wsConn{conn: conn, buf: buf, closed: make(chan struct{})}
+    go ws.readLoop()
+    return ws, nil
+}
+
+// originAllowed reports whether a request from origin may open a
+// WebSocket on host. Clients other than browsers send no Origin.
+func originAllowed(origin, host string, allowed []string) bool {
+    if origin == "" || slices.Contains(allowed, "*") || slices.Contains(allowed, origin) {
+        return true
+    }
+    u, err := url.Parse(origin)
+    return err == nil && strings.EqualFold(u.Host, host)
+}
+
+func headerContains(h http.Header, name, token string) bool {
+    for _, value := range h.Values(name) {
+        for _, part := range strings.Split(value, ",") {
+            if strings.EqualFold(strings.TrimSpace(part), token) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// readLoop answers pings and closes, and marks the connection closed when
+// the client disconnects.
+func (c *wsConn) readLoop() {
+    defer close(c.closed)
+    for {
+        op, payload, err := c.readFrame()
+        if err != nil {
+            return
+        }
+        switch op {
+        case opPing:
+            c.writeFrame(opPong, payload)
+        case opClose:
+            c.writeFrame(opClose, nil)
+            return
+        }
+    }
+}
+
+func (c *wsConn) readFrame() (byte, []byte, error) {
+    var head [2]byte
+    if _, err := io.ReadFull(c.buf, head[:]); err != nil {
+        return 0, nil, err
+    }
+    op := head[0] & 0x0F
+    masked := head[1]&0x80 != 0
+    length := uint64(head[1] & 0x7F)
+    switch length {
+    case 126:
+        var ext [2]byte
+        if _, err := io.ReadFull(c.buf, ext[:]); err != nil {
+            return 0, nil, err
+        }
+        length = uint64(binary.BigEndian.Uint16(ext[:]))
+    case 127:
+        var ext [8]byte
+        if _, err := io.ReadFull(c.buf, ext[:]); err != nil {
+            return 0, nil, err
+        }
+        length = binary.BigEndian.Uint64(ext[:])
+    }
+    if length > 1<<20 {
+        return 0, nil, fmt.Errorf("websocket frame too large (%d bytes)", length)
+    }
+    var mask [4]byte
+    if masked {
+        if _, err := io.ReadFull(c.buf, mask[:]); err != nil {
+            return 0, nil, err
+        }
+    }
+    payload := make([]byte, length)
+    if _, err := io.ReadFull(c.buf, payload); err != nil {
+        return 0, nil, err
+    }
+    if masked {
+        for i := range payload {
+            payload[i] ^= mask[i%4]
+        }
+    }
+    return op, payload, nil
+}
+
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+    header := []byte{0x80 | op}
+    switch n := len(payload); {
+    case n < 126:
+        header = append(header, byte(n))
+    case n <= 0xFFFF:
+        header = append(header, 126, byte(n>>8), byte(n))
+    default:
+        header = append(header, 127)
+        header = binary.BigEndian.AppendUint64(header, uint64(n))
+    }
+    if _, err := c.buf.Write(header); err != nil {
+        return err
+    }
+    if _, err := c.buf.Write(payload); err != nil {
+        return err
+    }
+    return c.buf.Flush()
+}
+
+// WriteText sends one text message.
+func (c *wsConn) WriteText(data []byte) error {
+    return c.writeFrame(opText, data)
+}
+
+// Done is closed once the client has disconnected.
+func (c *wsConn) Done() <-chan struct{} {
+    return c.closed
+}
+
+// Close sends a normal-closure frame and closes the connection.
+func (c *wsConn) Close() error {
+    c.writeFrame(opClose, []byte{0x03, 0xE8}) // 1000 normal closure
+    return c.conn.Close()
+}
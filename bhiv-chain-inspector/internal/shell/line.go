@@ -0,0 +1,190 @@
+package shell
+
+import (
+    "bufio"
+    "io"
+    "os"
+    "strings"
+
+    "github.com/charmbracelet/bubbles/textinput"
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// Completer returns the candidate completions of the word ending at the
+// end of line, each as the full replacement for that word.
+type Completer func(line string) []string
+
+// LineEditor reads command lines. On a terminal it supports cursor
+// movement, history (up/down) and tab completion; otherwise it reads plain
+// lines, so scripts can pipe commands in.
+type LineEditor struct {
+    Complete Completer
+
+    in      *os.File
+    plain   *bufio.Reader
+    history []string
+}
+
+// NewLineEditor reads from in, normally os.Stdin.
+func NewLineEditor(in *os.File) *LineEditor {
+    e := &LineEditor{in: in}
+    if info, err := in.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+        e.plain = bufio.NewReader(in)
+    }
+    return e
+}
+
+// Interactive reports whether lines are edited on a terminal.
+func (e *LineEditor) Interactive() bool {
+    return e.plain == nil
+}
+
+// ReadLine prompts for a line. It returns io.EOF at end of input or when
+// Ctrl-D is pressed on an empty line; Ctrl-C discards the current line.
+func (e *LineEditor) ReadLine(prompt string) (string, error) {
+    if e.plain == nil {
+        return e.edit(prompt)
+    }
+    line, err := e.plain.ReadString('\n')
+    if err != nil && (err != io.EOF || line == "") {
+        return "", err
+    }
+    return strings.TrimRight(line, "\r\n"), nil
+}
+
+// edit runs a Bubble Tea program for one line, inline below the output
+// of earlier commands.
+func (e *LineEditor) edit(prompt string) (string, error) {
+    input := textinput.New()
+    input.Prompt = prompt
+    input.Focus()
+    m := &lineModel{input: input, editor: e, hist: len(e.history)}
+    if _, err := tea.NewProgram(m, tea.WithInput(e.in)).Run(); err != nil {
+        return "", err
+    }
+    if m.eof {
+        return "", io.EOF
+    }
+    line := m.input.Value()
+    if strings.TrimSpace(line) != "" && (len(e.history) == 0 || e.history[len(e.history)-1] != line) {
+        e.history = append(e.history, line)
+    }
+    return line, nil
+}
+
+// lineModel is the Bubble Tea model of one line being edited. The text
+// input handles editing keys; lineModel adds history, completion and
+// the keys that end the line.
+type lineModel struct {
+    input   textinput.Model
+    editor  *LineEditor
+    hist    int
+    lastTab bool
+    done    bool
+    eof     bool
+}
+
+func (m *lineModel) Init() tea.Cmd {
+    return textinput.Blink
+}
+
+func (m *lineModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+    key, ok := msg.(tea.KeyMsg)
+    if !ok {
+        var cmd tea.Cmd
+        m.input, cmd = m.input.Update(msg)
+        return m, cmd
+    }
+    tab := key.Type == tea.KeyTab
+    defer func() { m.lastTab = tab }()
+
+    history := m.editor.history
+    switch key.Type {
+    case tea.KeyEnter:
+        m.done = true
+        return m, tea.Quit
+    case tea.KeyCtrlD:
+        if m.input.Value() == "" {
+            m.done, m.eof = true, true
+            return m, tea.Quit
+        }
+    case tea.KeyCtrlC:
+        cmd := tea.Println(m.input.Prompt + m.input.Value() + "^C")
+        m.input.Reset()
+        return m, cmd
+    case tea.KeyCtrlL:
+        return m, tea.ClearScreen
+    case tea.KeyUp:
+        if m.hist > 0 {
+            m.hist--
+            m.input.SetValue(history[m.hist])
+            m.input.CursorEnd()
+        }
+        return m, nil
+    case tea.KeyDown:
+        if m.hist < len(history) {
+            m.hist++
+            m.input.SetValue("")
+            if m.hist < len(history) {
+                m.input.SetValue(history[m.hist])
+                m.input.CursorEnd()
+            }
+        }
+        return m, nil
+    case tea.KeyTab:
+        return m, m.complete()
+    }
+    var cmd tea.Cmd
+    m.input, cmd = m.input.Update(msg)
+    return m, cmd
+}
+
+// complete replaces the word before the cursor with its only completion or
+// the candidates' common prefix; a second tab lists the candidates.
+func (m *lineModel) complete() tea.Cmd {
+    line := m.input.Value()
+    if m.editor.Complete == nil || m.input.Position() != len([]rune(line)) {
+        return nil
+    }
+    candidates := m.editor.Complete(line)
+    word := line[strings.LastIndex(line, " ")+1:]
+    base := line[:len(line)-len(word)]
+    switch {
+    case len(candidates) == 1:
+        line = base + candidates[0]
+        if !strings.HasSuffix(candidates[0], "/") {
+            line += " "
+        }
+    case len(candidates) > 1:
+        prefix := commonPrefix(candidates)
+        if len(prefix) > len(word) {
+            line = base + prefix
+        } else if m.lastTab {
+            return tea.Println(strings.Join(candidates, "  "))
+        }
+    }
+    m.input.SetValue(line)
+    m.input.CursorEnd()
+    return nil
+}
+
+// View leaves the finished line on screen once the program quits.
+func (m *lineModel) View() string {
+    if m.done {
+        if m.eof {
+            return m.input.Prompt
+        }
+        return m.input.Prompt + m.input.Value()
+    }
+    return m.input.View()
+}
+
+func commonPrefix(words []string) string {
+    prefix := words[0]
+    for _, w := range words[1:] {
+        for !strings.HasPrefix(w, prefix) {
+            prefix = prefix[:len(prefix)-1]
+        }
+    }
+    return prefix
+}
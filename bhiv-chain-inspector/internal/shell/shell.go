@@ -0,0 +1,240 @@
+// Package shell is an interactive prompt over one open database, so that
+// exploring a chain does not reopen it for every query:
+//
+//  inspector> view 42
+//  inspector> scan 0..100
+//  inspector> compare ./node2
+package shell
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// Opener opens another chain for compare, local or remote. The returned
+// function closes it.
+type Opener func(path string) (db.BlockReader, func(), error)
+
+// Shell runs commands against Storage.
+type Shell struct {
+    Storage *db.Storage
+    DBPath  string
+    Out     errors.OutputOptions
+    Scan    errors.ScanOptions
+    Open    Opener
+}
+
+type command struct {
+    usage string
+    help  string
+    run   func(s *Shell, args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+    commands = map[string]command{
+        "help":        {"help", "List commands", (*Shell).help},
+        "height":      {"height", "Print the chain height", (*Shell).height},
+        "view":        {"view <height>", "Show one block", (*Shell).view},
+        "scan":        {"scan [from[..to]]", "Scan all heights, or only a range", (*Shell).scan},
+        "compare":     {"compare <db|agent://host:port>", "Compare this chain with another node", (*Shell).compare},
+        "fingerprint": {"fingerprint [segment]", "Digest of the chain and of each segment", (*Shell).fingerprint},
+        "exit":        {"exit", "Leave the shell (also quit, Ctrl-D)", nil},
+    }
+}
+
+// Run reads and executes commands until exit or end of input.
+func (s *Shell) Run() error {
+    editor := NewLineEditor(os.Stdin)
+    editor.Complete = s.complete
+    prompt := "inspector> "
+    if !editor.Interactive() {
+        prompt = ""
+    } else {
+        fmt.Fprintf(s.Out.Writer(), "Connected to %s (height %d). Type help for commands.\n", s.DBPath, s.Storage.GetMaxHeight())
+    }
+
+    for {
+        line, err := editor.ReadLine(prompt)
+        if err == io.EOF {
+            return nil
+        }
+        if err != nil {
+            return err
+        }
+        fields := strings.Fields(line)
+        if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+            continue
+        }
+        name, args := fields[0], fields[1:]
+        if name == "exit" || name == "quit" {
+            return nil
+        }
+        cmd, ok := commands[name]
+        if !ok {
+            fmt.Fprintf(s.Out.Writer(), "unknown command %q (try help)\n", name)
+            continue
+        }
+        if err := cmd.run(s, args); err != nil {
+            fmt.Fprintf(s.Out.Writer(), "%s: %v\n", name, err)
+        }
+    }
+}
+
+func (s *Shell) help(args []string) error {
+    names := make([]string, 0, len(commands))
+    for name := range commands {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        fmt.Fprintf(s.Out.Writer(), "  %-32s %s\n", commands[name].usage, commands[name].help)
+    }
+    return nil
+}
+
+func (s *Shell) height(args []string) error {
+    fmt.Fprintln(s.Out.Writer(), s.Storage.GetMaxHeight())
+    return nil
+}
+
+func (s *Shell) view(args []string) error {
+    if len(args) != 1 {
+        return fmt.Errorf("usage: view <height>")
+    }
+    height, err := strconv.Atoi(args[0])
+    if err != nil || height < 0 {
+        return fmt.Errorf("bad height %q", args[0])
+    }
+    block, err := s.Storage.LoadBlock(height)
+    if err != nil {
+        return err
+    }
+    errors.OutputBlock(block, s.Out)
+    return nil
+}
+
+func (s *Shell) scan(args []string) error {
+    opts := s.Scan
+    if len(args) > 1 {
+        return fmt.Errorf("usage: scan [from[..to]]")
+    }
+    if len(args) == 1 {
+        from, to, err := parseRange(args[0])
+        if err != nil {
+            return err
+        }
+        if tip := s.Storage.GetMaxHeight(); from > tip {
+            return fmt.Errorf("height %d is past the tip (%d)", from, tip)
+        }
+        opts.FromHeight = from
+        if to >= 0 {
+            opts.Heights = to - from + 1
+        }
+    }
+    result := errors.ScanErrors(s.Storage, s.DBPath, opts)
+    errors.OutputScanResult(result, s.Out)
+    return nil
+}
+
+// parseRange parses "a..b" or "a"; to is -1 when open-ended.
+func parseRange(arg string) (from, to int, err error) {
+    lo, hi, ranged := strings.Cut(arg, "..")
+    if from, err = strconv.Atoi(lo); err != nil || from < 0 {
+        return 0, 0, fmt.Errorf("bad range %q", arg)
+    }
+    if !ranged {
+        return from, -1, nil
+    }
+    if to, err = strconv.Atoi(hi); err != nil || to < from {
+        return 0, 0, fmt.Errorf("bad range %q", arg)
+    }
+    return from, to, nil
+}
+
+func (s *Shell) compare(args []string) error {
+    if len(args) != 1 {
+        return fmt.Errorf("usage: compare <db|agent://host:port>")
+    }
+    other, closeOther, err := s.Open(args[0])
+    if err != nil {
+        return err
+    }
+    defer closeOther()
+    result := errors.CompareNodes(s.Storage, other, s.DBPath, args[0], errors.CompareOptions{})
+    // Remote readers report transport failures after the fact.
+    if r, ok := other.(interface{ Err() error }); ok && r.Err() != nil {
+        return r.Err()
+    }
+    errors.OutputComparisonResult(result, s.Out)
+    return nil
+}
+
+func (s *Shell) fingerprint(args []string) error {
+    size := errors.DefaultSegmentSize
+    if len(args) == 1 {
+        n, err := strconv.Atoi(args[0])
+        if err != nil || n <= 0 {
+            return fmt.Errorf("bad segment size %q", args[0])
+        }
+        size = n
+    }
+    errors.OutputFingerprint(errors.ComputeFingerprint(s.Storage, size), s.DBPath, s.Out)
+    return nil
+}
+
+// complete completes command names, and paths for compare.
+func (s *Shell) complete(line string) []string {
+    fields := strings.Fields(line)
+    if len(fields) == 0 || (len(fields) == 1 && !strings.HasSuffix(line, " ")) {
+        prefix := ""
+        if len(fields) == 1 {
+            prefix = fields[0]
+        }
+        var out []string
+        for name := range commands {
+            if strings.HasPrefix(name, prefix) {
+                out = append(out, name)
+            }
+        }
+        sort.Strings(out)
+        return out
+    }
+    if fields[0] != "compare" {
+        return nil
+    }
+    word := ""
+    if !strings.HasSuffix(line, " ") {
+        word = fields[len(fields)-1]
+    }
+    return completePath(word)
+}
+
+// completePath lists directories (databases) starting with prefix.
+func completePath(prefix string) []string {
+    dir, base := filepath.Split(prefix)
+    read := dir
+    if read == "" {
+        read = "."
+    }
+    entries, err := os.ReadDir(read)
+    if err != nil {
+        return nil
+    }
+    var out []string
+    for _, e := range entries {
+        if e.IsDir() && strings.HasPrefix(e.Name(), base) {
+            out = append(out, dir+e.Name()+"/")
+        }
+    }
+    return out
+}
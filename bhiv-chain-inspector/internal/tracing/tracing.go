@@ -0,0 +1,133 @@
+// Package tracing records spans with the OpenTelemetry SDK and exports them
+// to an OTLP/HTTP collector (POST <endpoint>/v1/traces).
+//
+// Tracing is off until Setup is called; until then Start returns a nil
+// *Span, and every Span method is a no-op on nil, so instrumented code
+// pays almost nothing when tracing is disabled. Once on, finished spans
+// are queued for a background exporter; when the collector falls behind,
+// the queue drops spans rather than slow down the caller.
+package tracing
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+    "strings"
+    "sync/atomic"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+    "go.opentelemetry.io/otel/sdk/resource"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// shutdownTimeout bounds the export of the spans still queued at exit.
+const shutdownTimeout = 5 * time.Second
+
+// Span is one timed operation.
+type Span struct {
+    span trace.Span
+}
+
+var provider atomic.Pointer[sdktrace.TracerProvider]
+
+// Setup enables tracing, exporting to the OTLP/HTTP endpoint (for example
+// http://localhost:4318) under the given service name.
+func Setup(endpoint, service string) {
+    exporter, err := otlptracehttp.New(context.Background(),
+        otlptracehttp.WithEndpointURL(strings.TrimSuffix(endpoint, "/")+"/v1/traces"))
+    if err != nil {
+        slog.Warn("tracing disabled: cannot create the OTLP exporter", "endpoint", endpoint, "err", err)
+        return
+    }
+    // The batcher's queue is bounded and drops spans when full.
+    tp := sdktrace.NewTracerProvider(
+        sdktrace.WithBatcher(exporter),
+        sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+    )
+    if old := provider.Swap(tp); old != nil {
+        old.Shutdown(context.Background())
+    }
+}
+
+// Enabled reports whether Setup has been called.
+func Enabled() bool {
+    return provider.Load() != nil
+}
+
+// Shutdown exports the queued spans and turns tracing off. It is safe to
+// call when tracing is disabled and more than once.
+func Shutdown() {
+    tp := provider.Swap(nil)
+    if tp == nil {
+        return
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+    defer cancel()
+    if err := tp.Shutdown(ctx); err != nil {
+        slog.Warn("cannot export trace spans", "err", err)
+    }
+}
+
+// Start begins a span named name, as a child of the span in ctx if any.
+// A nil ctx is treated as context.Background.
+func Start(ctx context.Context, name string, attrs ...interface{}) (context.Context, *Span) {
+    if ctx == nil {
+        ctx = context.Background()
+    }
+    tp := provider.Load()
+    if tp == nil {
+        return ctx, nil
+    }
+    ctx, span := tp.Tracer("bhiv-chain-inspector").Start(ctx, name)
+    s := &Span{span: span}
+    s.SetAttributes(attrs...)
+    return ctx, s
+}
+
+// SetAttributes adds key/value pairs, e.g. SetAttributes("db", path).
+func (s *Span) SetAttributes(kv ...interface{}) {
+    if s == nil {
+        return
+    }
+    for i := 0; i+1 < len(kv); i += 2 {
+        s.span.SetAttributes(keyValue(fmt.Sprint(kv[i]), kv[i+1]))
+    }
+}
+
+// RecordError marks the span as failed.
+func (s *Span) RecordError(err error) {
+    if s == nil || err == nil {
+        return
+    }
+    s.span.RecordError(err)
+    s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End finishes the span and queues it for export.
+func (s *Span) End() {
+    if s == nil {
+        return
+    }
+    s.span.End()
+}
+
+func keyValue(key string, value interface{}) attribute.KeyValue {
+    switch v := value.(type) {
+    case bool:
+        return attribute.Bool(key, v)
+    case int:
+        return attribute.Int(key, v)
+    case int64:
+        return attribute.Int64(key, v)
+    case float64:
+        return attribute.Float64(key, v)
+    case string:
+        return attribute.String(key, v)
+    default:
+        return attribute.String(key, fmt.Sprint(v))
+    }
+}
@@ -0,0 +1,375 @@
+// Package tui is a full-screen terminal dashboard for one chain: an
+// overview line with the latest scan result, a scrollable block list, a
+// detail pane for the selected block, and a key to re-scan. It is a Bubble
+// Tea program drawn with Lip Gloss on the terminal's alternate screen.
+package tui
+
+import (
+    "fmt"
+    "os"
+    "strings"
+    "sync/atomic"
+    "time"
+    "unicode/utf8"
+
+    tea "github.com/charmbracelet/bubbletea"
+    "github.com/charmbracelet/lipgloss"
+    "github.com/charmbracelet/x/term"
+
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// Options controls how the dashboard is drawn.
+type Options struct {
+    ASCII bool
+    Color bool
+}
+
+// styles are the Lip Gloss styles of the dashboard. Without color only the
+// reverse video of the title and the selected row remains.
+type styles struct {
+    title, selected, help, bold lipgloss.Style
+    ok, warning, failure        lipgloss.Style
+    detail                      lipgloss.Style
+    rule                        string
+}
+
+func newStyles(opts Options) styles {
+    plain := lipgloss.NewStyle()
+    s := styles{
+        title:    plain.Reverse(true),
+        selected: plain.Reverse(true),
+        help:     plain,
+        bold:     plain,
+        ok:       plain,
+        warning:  plain,
+        failure:  plain,
+        detail:   plain.Border(lipgloss.NormalBorder(), false, false, false, true).PaddingLeft(1),
+        rule:     "─",
+    }
+    if opts.ASCII {
+        s.detail = s.detail.BorderStyle(lipgloss.ASCIIBorder())
+        s.rule = "-"
+    }
+    if opts.Color {
+        s.help = plain.Faint(true)
+        s.bold = plain.Bold(true)
+        s.ok = plain.Foreground(lipgloss.Color("2"))
+        s.warning = plain.Foreground(lipgloss.Color("3"))
+        s.failure = plain.Foreground(lipgloss.Color("1"))
+    }
+    return s
+}
+
+// scanDoneMsg carries the result of a background scan.
+type scanDoneMsg struct {
+    verdicts map[int][]errors.Issue
+    result   *errors.ErrorScanResult
+    tip      int
+}
+
+// tickMsg redraws the progress of a running scan.
+type tickMsg struct{}
+
+// dashboard is the Bubble Tea model.
+type dashboard struct {
+    storage *db.Storage
+    dbPath  string
+    scan    errors.ScanOptions
+    styles  styles
+
+    tip      int
+    verdicts map[int][]errors.Issue
+    result   *errors.ErrorScanResult
+    scanning bool
+    // progress counts the heights the running scan has visited; the scan
+    // goroutine writes it.
+    progress *atomic.Int64
+
+    cursor, offset int
+    width, height  int
+}
+
+// Run shows the dashboard until q or Ctrl-C is pressed. It scans the chain
+// once on start; r re-scans, picking up blocks appended meanwhile.
+func Run(storage *db.Storage, dbPath string, scan errors.ScanOptions, opts Options) error {
+    if !term.IsTerminal(os.Stdin.Fd()) || !term.IsTerminal(os.Stdout.Fd()) {
+        return fmt.Errorf("tui needs an interactive terminal")
+    }
+    d := &dashboard{
+        storage:  storage,
+        dbPath:   dbPath,
+        scan:     scan,
+        styles:   newStyles(opts),
+        tip:      storage.GetMaxHeight(),
+        progress: new(atomic.Int64),
+        width:    80,
+        height:   24,
+    }
+    _, err := tea.NewProgram(d, tea.WithAltScreen()).Run()
+    return err
+}
+
+func (d *dashboard) Init() tea.Cmd {
+    return d.startScan()
+}
+
+func (d *dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+    switch msg := msg.(type) {
+    case tea.WindowSizeMsg:
+        d.width, d.height = msg.Width, msg.Height
+    case scanDoneMsg:
+        d.verdicts, d.result, d.tip, d.scanning = msg.verdicts, msg.result, msg.tip, false
+        d.move(0)
+    case tickMsg:
+        if d.scanning {
+            return d, tick()
+        }
+    case tea.KeyMsg:
+        return d, d.handle(msg)
+    }
+    return d, nil
+}
+
+// handle applies a key press.
+func (d *dashboard) handle(key tea.KeyMsg) tea.Cmd {
+    page := d.listHeight()
+    switch key.String() {
+    case "ctrl+c", "q":
+        return tea.Quit
+    case "up", "k":
+        d.move(-1)
+    case "down", "j":
+        d.move(1)
+    case "pgup":
+        d.move(-page)
+    case "pgdown", " ":
+        d.move(page)
+    case "home", "g":
+        d.move(-d.cursor)
+    case "end", "G":
+        d.move(d.tip - d.cursor)
+    case "n":
+        d.jumpIssue(1)
+    case "p":
+        d.jumpIssue(-1)
+    case "r":
+        return d.startScan()
+    case "ctrl+l":
+        return tea.ClearScreen
+    }
+    return nil
+}
+
+// startScan runs a scan in the background unless one is already running.
+func (d *dashboard) startScan() tea.Cmd {
+    if d.scanning {
+        return nil
+    }
+    d.scanning = true
+    d.progress.Store(0)
+
+    storage, dbPath, opts, progress := d.storage, d.dbPath, d.scan, d.progress
+    scan := func() tea.Msg {
+        verdicts := make(map[int][]errors.Issue)
+        opts.OnBlock = func(v errors.BlockVerdict) {
+            progress.Add(1)
+            if len(v.Issues) > 0 {
+                verdicts[v.Height] = v.Issues
+            }
+        }
+        result := errors.ScanErrors(storage, dbPath, opts)
+        return scanDoneMsg{verdicts: verdicts, result: result, tip: storage.GetMaxHeight()}
+    }
+    return tea.Batch(scan, tick())
+}
+
+func tick() tea.Cmd {
+    return tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+// listHeight is the number of block rows: the screen minus the title,
+// overview, rule and help lines.
+func (d *dashboard) listHeight() int {
+    if n := d.height - 4; n > 1 {
+        return n
+    }
+    return 1
+}
+
+func (d *dashboard) move(delta int) {
+    d.cursor += delta
+    if d.cursor > d.tip {
+        d.cursor = d.tip
+    }
+    if d.cursor < 0 {
+        d.cursor = 0
+    }
+}
+
+// jumpIssue moves to the next (dir 1) or previous (dir -1) height with
+// findings from the last scan.
+func (d *dashboard) jumpIssue(dir int) {
+    for h := d.cursor + dir; h >= 0 && h <= d.tip; h += dir {
+        if len(d.verdicts[h]) > 0 {
+            d.cursor = h
+            return
+        }
+    }
+}
+
+func (d *dashboard) View() string {
+    rows := d.listHeight()
+    if d.cursor < d.offset {
+        d.offset = d.cursor
+    }
+    if d.cursor >= d.offset+rows {
+        d.offset = d.cursor - rows + 1
+    }
+
+    listWidth := min(d.width/2, 44)
+    list := make([]string, rows)
+    for i := range list {
+        list[i] = strings.Repeat(" ", listWidth)
+        if h := d.offset + i; h <= d.tip {
+            list[i] = d.blockRow(h, listWidth)
+        }
+    }
+    detail := d.detailLines(d.width - listWidth - 3)
+    body := lipgloss.JoinHorizontal(lipgloss.Top,
+        strings.Join(list, "\n")+" ",
+        d.styles.detail.Height(rows).MaxHeight(rows).Render(strings.Join(detail, "\n")))
+
+    help := " up/down j/k move  PgUp/PgDn page  g/G top/tip  n/p next/prev issue  r re-scan  q quit"
+    return lipgloss.JoinVertical(lipgloss.Left,
+        d.styles.title.Render(fit(" BHIV Chain Inspector - "+d.dbPath, d.width)),
+        d.overview(),
+        strings.Repeat(d.styles.rule, d.width),
+        body,
+        d.styles.help.Render(fit(help, d.width)),
+    )
+}
+
+func (d *dashboard) overview() string {
+    if d.scanning {
+        return fit(fmt.Sprintf(" Height %d | Scanning... %d/%d heights", d.tip, d.progress.Load(), d.tip+1), d.width)
+    }
+    r := d.result
+    if r == nil {
+        return ""
+    }
+    text := fit(fmt.Sprintf(" Height %d | Health %d | Errors %d | Warnings %d | %s | Scanned %s",
+        d.tip, r.HealthScore, r.TotalErrors, r.TotalWarnings, r.Status, r.ScanTime), d.width)
+    style := d.styles.ok
+    switch {
+    case r.TotalErrors > 0:
+        style = d.styles.failure
+    case r.TotalWarnings > 0:
+        style = d.styles.warning
+    }
+    return style.Render(text)
+}
+
+// issueStyle is the style of a height's findings: failure if any is an
+// error, warning otherwise.
+func (d *dashboard) issueStyle(issues []errors.Issue) lipgloss.Style {
+    for _, issue := range issues {
+        if issue.Severity == errors.SeverityError {
+            return d.styles.failure
+        }
+    }
+    return d.styles.warning
+}
+
+// blockRow is one line of the block list, highlighted when selected.
+func (d *dashboard) blockRow(h, width int) string {
+    hash := "-"
+    if block, err := d.storage.LoadBlock(h); err == nil {
+        hash = block.Hash
+        if len(hash) > 12 {
+            hash = hash[:12]
+        }
+    }
+    status, style := "OK", d.styles.ok
+    switch issues := d.verdicts[h]; {
+    case d.result == nil:
+        status, style = "-", lipgloss.NewStyle()
+    case len(issues) > 0:
+        status, style = fmt.Sprintf("%d issue(s)", len(issues)), d.issueStyle(issues)
+    }
+    text := fit(fmt.Sprintf(" %8d  %-12s  %s", h, hash, status), width)
+    if h == d.cursor {
+        return d.styles.selected.Render(text)
+    }
+    return style.Render(text)
+}
+
+// detailLines describes the selected block, wrapped to width.
+func (d *dashboard) detailLines(width int) []string {
+    if width < 10 {
+        return nil
+    }
+    h := d.cursor
+    lines := []string{d.styles.bold.Render(fmt.Sprintf("Block %d", h)), ""}
+    block, err := d.storage.LoadBlock(h)
+    if err != nil {
+        lines = append(lines, wrap("Unavailable: "+err.Error(), width)...)
+    } else {
+        lines = append(lines, wrap("Hash:      "+block.Hash, width)...)
+        lines = append(lines, wrap("Prev Hash: "+block.PrevHash, width)...)
+        lines = append(lines, wrap(fmt.Sprintf("Timestamp: %d (%s)", block.Timestamp,
+            errors.FormatUnix(block.Timestamp)), width)...)
+        lines = append(lines, wrap(fmt.Sprintf("Data:      %q", block.Data), width)...)
+    }
+
+    lines = append(lines, "")
+    issues := d.verdicts[h]
+    switch {
+    case d.result == nil:
+        lines = append(lines, "Not scanned yet")
+    case len(issues) == 0:
+        lines = append(lines, d.styles.ok.Render("No issues"))
+    default:
+        lines = append(lines, fmt.Sprintf("Issues (%d):", len(issues)))
+        for _, issue := range issues {
+            style := d.issueStyle([]errors.Issue{issue})
+            for _, l := range wrap(fmt.Sprintf("- [%s] %s", issue.Class, issue.Message), width) {
+                lines = append(lines, style.Render(l))
+            }
+        }
+    }
+    return lines
+}
+
+// fit truncates or pads s to exactly width runes.
+func fit(s string, width int) string {
+    s = printable(s)
+    n := utf8.RuneCountInString(s)
+    if n > width {
+        return string([]rune(s)[:width])
+    }
+    return s + strings.Repeat(" ", width-n)
+}
+
+// wrap breaks s into lines of at most width runes.
+func wrap(s string, width int) []string {
+    runes := []rune(printable(s))
+    var lines []string
+    for len(runes) > width {
+        lines = append(lines, string(runes[:width]))
+        runes = runes[width:]
+    }
+    return append(lines, string(runes))
+}
+
+// printable replaces control characters, which stored block fields may
+// contain, so they cannot move the cursor or change colors.
+func printable(s string) string {
+    return strings.Map(func(r rune) rune {
+        if r < 32 || r == 127 {
+            return '?'
+        }
+        return r
+    }, s)
+}
@@ -0,0 +1,202 @@
+// Package inspector is the importable API of the chain inspector, for Go
+// services that want to validate or compare chains in process instead of
+// running the CLI and parsing its output:
+//
+//  chain, err := inspector.Open("./leveldb-data")
+//  if err != nil {
+//      return err
+//  }
+//  defer chain.Close()
+//  result := chain.Scan(inspector.ScanOptions{})
+//  if result.TotalErrors > 0 {
+//      for _, issue := range result.Issues() {
+//          log.Println(issue.Class, issue.Message)
+//      }
+//  }
+//
+// The functions and types here are the stable surface; they are backed by
+// the same scanner, comparator and storage the CLI uses, so results match
+// the CLI's --json output field for field. Everything under internal/ may
+// change without notice.
+package inspector
+
+import (
+    "sync"
+
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// Chain is an open block database. It is safe for concurrent reads.
+type Chain struct {
+    path       string
+    storage    *db.Storage
+    // hashAlg is set by WithHashAlgorithm. Otherwise VerifyBlock detects
+    // an algorithm on first use, into detected.
+    hashAlg    HashAlgorithm
+    detected   HashAlgorithm
+    detectOnce sync.Once
+}
+
+// Open opens the LevelDB database at path. The database is created if it
+// does not exist. LevelDB allows a database to be opened only once at a
+// time, so a chain served by a running node must be copied first.
+func Open(path string) (*Chain, error) {
+    storage, err := db.NewStorage(path)
+    if err != nil {
+        return nil, err
+    }
+    return &Chain{path: path, storage: storage}, nil
+}
+
+// WithEncoding returns a view of the chain whose block values are decoded
+// as e instead of JSON. Closing either closes both.
+func (c *Chain) WithEncoding(e Encoding) *Chain {
+    return &Chain{path: c.path, storage: c.storage.WithEncoding(e)}
+}
+
+// WithHashAlgorithm returns a view of the chain whose block hashes are
+// checked with alg instead of the detected algorithm. Closing either
+// closes both.
+func (c *Chain) WithHashAlgorithm(alg HashAlgorithm) *Chain {
+    return &Chain{path: c.path, storage: c.storage, hashAlg: alg}
+}
+
+// HashAlgorithm returns the algorithm VerifyBlock checks hashes with: the
+// one set by WithHashAlgorithm, else the one DetectHashAlgorithm finds, or
+// SHA-256 if no algorithm matches, as Scan chooses.
+func (c *Chain) HashAlgorithm() HashAlgorithm {
+    if c.hashAlg != "" {
+        return c.hashAlg
+    }
+    c.detectOnce.Do(func() {
+        if c.detected = c.DetectHashAlgorithm().Algorithm; c.detected == "" {
+            c.detected = blocks.SHA256
+        }
+    })
+    return c.detected
+}
+
+// Close closes the database.
+func (c *Chain) Close() error {
+    return c.storage.Close()
+}
+
+// Path returns the path the chain was opened from.
+func (c *Chain) Path() string {
+    return c.path
+}
+
+// Height returns the highest height holding a block, whatever gaps lie
+// below it, or -1 for an empty chain. Scan reports the missing heights.
+func (c *Chain) Height() int {
+    return c.storage.GetMaxHeight()
+}
+
+// Block loads the block at height.
+func (c *Chain) Block(height int) (*Block, error) {
+    return c.storage.LoadBlock(height)
+}
+
+// VerifyBlock loads the block at height and checks its hash with the
+// chain's HashAlgorithm. The error wraps ErrBlockMissing, ErrCorruptJSON
+// or ErrHashMismatch.
+func (c *Chain) VerifyBlock(height int) (*Block, error) {
+    block, err := c.storage.LoadBlock(height)
+    if err != nil {
+        return nil, err
+    }
+    if err := block.VerifyHashWith(c.HashAlgorithm()); err != nil {
+        return block, err
+    }
+    return block, nil
+}
+
+// GetMaxHeight is Height; with LoadBlock it makes a Chain a BlockReader,
+// so it can be passed to Compare and FingerprintOf.
+func (c *Chain) GetMaxHeight() int { return c.Height() }
+
+// LoadBlock is Block.
+func (c *Chain) LoadBlock(height int) (*Block, error) { return c.Block(height) }
+
+// Scan runs every registered check (or opts.Checks) over the chain. Unless
+// opts.HashAlgorithm is set, hashes are checked with the algorithm set by
+// WithHashAlgorithm, or one detected by the scan.
+func (c *Chain) Scan(opts ScanOptions) *ScanResult {
+    if opts.HashAlgorithm == "" {
+        opts.HashAlgorithm = c.hashAlg
+    }
+    return errors.ScanErrors(c.storage, c.path, opts)
+}
+
+// Verify scans the chain and groups the findings into one pass/fail
+// verdict per error class.
+func (c *Chain) Verify(opts ScanOptions) *VerifyReport {
+    return errors.NewVerifyReport(c.Scan(opts))
+}
+
+// Stats computes the block count, average block time, gaps and duplicate
+// hashes of the chain.
+func (c *Chain) Stats() *ChainStats {
+    return errors.ComputeStats(c.storage)
+}
+
+// DetectHashAlgorithm reports which supported hash algorithm the chain's
+// blocks appear to be hashed with, from a sample of them. Scan does this
+// itself unless ScanOptions.HashAlgorithm is set.
+func (c *Chain) DetectHashAlgorithm() *HashDetection {
+    return errors.DetectHashAlgorithm(c.storage, errors.HashSampleSize)
+}
+
+// Compare compares chains a and b block by block. Either may be a Chain
+// or any other BlockReader, such as a client of a remote agent; the names
+// are only used in the result.
+func Compare(a, b BlockReader, nameA, nameB string, opts CompareOptions) *ComparisonResult {
+    return errors.CompareNodes(a, b, nameA, nameB, opts)
+}
+
+// FingerprintOf returns the digest of r and of each segment of
+// segmentSize heights (0 means DefaultSegmentSize).
+func FingerprintOf(r BlockReader, segmentSize int) (*Fingerprint, error) {
+    return errors.FingerprintOf(r, segmentSize)
+}
+
+// RegisterCheck adds c to the checks run by every scan. It panics if a
+// check with the same name is already registered.
+func RegisterCheck(c Check) {
+    errors.RegisterCheck(c)
+}
+
+// RegisterFormatter makes f the formatter of OutputOptions.Format name
+// for the Print functions. It panics if the name is taken.
+func RegisterFormatter(name string, f Formatter) {
+    errors.RegisterFormatter(name, f)
+}
+
+// KnownClasses lists the built-in error classes followed by those
+// declared by registered checks.
+func KnownClasses() []string {
+    return errors.KnownClasses()
+}
+
+// CodeOf returns the stable code of an error class, e.g. E_HASH_MISMATCH
+// for bad_hash.
+func CodeOf(class string) Code {
+    return errors.CodeOf(class)
+}
+
+// PrintScanResult prints result as the scan command does.
+func PrintScanResult(result *ScanResult, opts OutputOptions) {
+    errors.OutputScanResult(result, opts)
+}
+
+// PrintVerifyReport prints report as the verify command does.
+func PrintVerifyReport(report *VerifyReport, opts OutputOptions) {
+    errors.OutputVerifyReport(report, opts)
+}
+
+// PrintComparisonResult prints result as the compare command does.
+func PrintComparisonResult(result *ComparisonResult, opts OutputOptions) {
+    errors.OutputComparisonResult(result, opts)
+}
@@ -0,0 +1,132 @@
+package inspector
+
+import (
+    "bhiv-chain-inspector/internal/blocks"
+    "bhiv-chain-inspector/internal/db"
+    "bhiv-chain-inspector/internal/errors"
+)
+
+// The result and option types are aliases of the types the CLI uses, so a
+// result from this package can be handed to anything that accepts the
+// CLI's JSON.
+type (
+    // Block is one decoded block.
+    Block = blocks.Block
+    // BlockReader is the read side of a chain: a Chain, or a remote agent.
+    BlockReader = db.BlockReader
+
+    // Encoding is the format block values are stored in.
+    Encoding = db.Encoding
+
+    // ScanOptions tunes a scan; the zero value runs every check.
+    ScanOptions = errors.ScanOptions
+    // ScanResult lists the findings of a scan by class.
+    ScanResult = errors.ErrorScanResult
+    // ErrorEntry is one finding in a ScanResult class list: height,
+    // code, expected and actual values, and message.
+    ErrorEntry = errors.ErrorEntry
+    // Issue is a single finding at one height.
+    Issue = errors.Issue
+    // Code is the stable identifier of an error class, e.g.
+    // E_HASH_MISMATCH; match on it rather than on messages.
+    Code = errors.Code
+    // BlockVerdict is the outcome for one height, passed to OnBlock.
+    BlockVerdict = errors.BlockVerdict
+    // Severity is error, warning or info.
+    Severity = errors.Severity
+    // SeverityMap downgrades error classes.
+    SeverityMap = errors.SeverityMap
+    // VerifyReport is a scan grouped into pass/fail verdicts per class.
+    VerifyReport = errors.VerifyReport
+    // ChainStats summarises a chain.
+    ChainStats = errors.ChainStats
+    // HashAlgorithm names a block hash function; see ScanOptions.
+    HashAlgorithm = blocks.HashAlgorithm
+    // HashDetection reports the hash algorithm a chain appears to use.
+    HashDetection = errors.HashDetection
+
+    // CompareOptions tunes a comparison.
+    CompareOptions = errors.CompareOptions
+    // ComparisonResult describes how two chains differ.
+    ComparisonResult = errors.ComparisonResult
+    // Fingerprint is a digest of a chain and of its segments.
+    Fingerprint = errors.Fingerprint
+
+    // OutputOptions selects the format of the Print functions and the
+    // writer they print to (Out, default os.Stdout).
+    OutputOptions = errors.OutputOptions
+    // Formatter renders reports in an output format; see
+    // RegisterFormatter.
+    Formatter = errors.Formatter
+    // Table is implemented by reports the csv, ndjson and html formats
+    // print row by row, such as ScanResult.
+    Table = errors.Table
+
+    // Check validates one decoded block; see RegisterCheck.
+    Check = errors.Check
+    // CheckContext is the chain state passed to a Check.
+    CheckContext = errors.CheckContext
+    // Finding is a problem reported by a Check.
+    Finding = errors.Finding
+)
+
+const (
+    EncodingJSON  = db.EncodingJSON
+    EncodingProto = db.EncodingProto
+    EncodingCBOR  = db.EncodingCBOR
+    EncodingGob   = db.EncodingGob
+    // EncodingAuto detects the encoding of each block; ScanResult.Encodings
+    // counts what was found.
+    EncodingAuto  = db.EncodingAuto
+
+    SeverityError   = errors.SeverityError
+    SeverityWarning = errors.SeverityWarning
+    SeverityInfo    = errors.SeverityInfo
+
+    // Output formats of OutputOptions.Format.
+    FormatText   = errors.FormatText
+    FormatJSON   = errors.FormatJSON
+    FormatNDJSON = errors.FormatNDJSON
+    FormatCSV    = errors.FormatCSV
+    FormatHTML   = errors.FormatHTML
+
+    // DefaultSegmentSize is the fingerprint segment size used for 0.
+    DefaultSegmentSize = errors.DefaultSegmentSize
+
+    // Codes of the error classes; see Code.
+    CodeCorruptBlock      = errors.CodeCorruptBlock
+    CodeHashMismatch      = errors.CodeHashMismatch
+    CodeTSFuture          = errors.CodeTSFuture
+    CodeTSPast            = errors.CodeTSPast
+    CodeTSNotIncreasing   = errors.CodeTSNotIncreasing
+    CodeDuplicateHash     = errors.CodeDuplicateHash
+    CodeEmptyBlock        = errors.CodeEmptyBlock
+    CodePrevHashBroken    = errors.CodePrevHashBroken
+    CodeHeightMismatch    = errors.CodeHeightMismatch
+    CodeMissingBlock      = errors.CodeMissingBlock
+    CodeOutOfOrder        = errors.CodeOutOfOrder
+    CodeMerkleRoot        = errors.CodeMerkleRoot
+    CodeChainID           = errors.CodeChainID
+    CodePoW               = errors.CodePoW
+    CodeDifficulty        = errors.CodeDifficulty
+    CodeSignature         = errors.CodeSignature
+    CodeValidator         = errors.CodeValidator
+    CodeTamperedSeal      = errors.CodeTamperedSeal
+    CodeUnknownKey        = errors.CodeUnknownKey
+    CodeOrphanBlock       = errors.CodeOrphanBlock
+    CodeIndex             = errors.CodeIndex
+    CodeDuplicateData     = errors.CodeDuplicateData
+    CodeNearDuplicateData = errors.CodeNearDuplicateData
+)
+
+// Errors returned by Chain.Block and other block readers wrap one of these
+// sentinels; test for them with errors.Is.
+var (
+    // ErrBlockMissing means no block is stored at the height.
+    ErrBlockMissing = db.ErrBlockMissing
+    // ErrCorruptJSON means the stored block does not decode.
+    ErrCorruptJSON = db.ErrCorruptJSON
+    // ErrHashMismatch means a block's hash does not match its contents;
+    // see Block.VerifyHash.
+    ErrHashMismatch = blocks.ErrHashMismatch
+)
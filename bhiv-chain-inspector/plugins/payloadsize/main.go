@@ -0,0 +1,42 @@
+// Command payloadsize is an example validation plugin. Build it with
+//
+//  go build -buildmode=plugin -o payloadsize.so ./plugins/payloadsize
+//
+// and load it with: inspector scan --plugin payloadsize.so
+package main
+
+import (
+    "fmt"
+
+    "bhiv-chain-inspector/pkg/inspector"
+)
+
+// maxDataBytes is the largest payload our chain accepts per block.
+const maxDataBytes = 4096
+
+const classDataTooLarge = "data_too_large"
+
+type payloadSizeCheck struct{}
+
+func (payloadSizeCheck) Name() string { return "payload-size" }
+
+func (payloadSizeCheck) Classes() []string { return []string{classDataTooLarge} }
+
+func (payloadSizeCheck) Validate(block *inspector.Block, ctx *inspector.CheckContext) []inspector.Finding {
+    if len(block.Data) > maxDataBytes {
+        return []inspector.Finding{{
+            Class:   classDataTooLarge,
+            Message: fmt.Sprintf("Block %d: Data is %d bytes (limit %d)", ctx.Height, len(block.Data), maxDataBytes),
+        }}
+    }
+    return nil
+}
+
+// InspectorChecks is looked up by the inspector when the plugin is loaded.
+func InspectorChecks() []inspector.Check {
+    return []inspector.Check{payloadSizeCheck{}}
+}
+
+// main is required for the package to build as a regular binary with
+// "go build ./..."; it is never called when loaded as a plugin.
+func main() {}